@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/service/settingsmigration"
+)
+
+// Copies the campaign attributes of the configured database to the typed
+// settings tables. Safe to run while the API is serving requests and to
+// run again after a failure
+func main() {
+	batchSize := flag.Int("batch-size", settingsmigration.DefaultBatchSize, "number of campaigns migrated per batch")
+	dryRun := flag.Bool("dry-run", false, "report what would be migrated without writing")
+	flag.Parse()
+
+	configuration := config.Get()
+	log.New(&configuration)
+
+	report, err := settingsmigration.New(&configuration, *batchSize, *dryRun).Migrate(context.Background())
+	fmt.Printf("campaigns: %d, migrated: %d, skipped: %d, possibly truncated: %d\n",
+		report.Campaigns, report.Migrated, report.Skipped, report.Truncated)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
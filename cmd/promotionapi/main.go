@@ -1,14 +1,41 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
 	_ "github.com/zdarovich/promotion-api/docs" // Needed for swagger doc linking
 	"github.com/zdarovich/promotion-api/internal/api"
 	"github.com/zdarovich/promotion-api/internal/api/requests/root"
 	"github.com/zdarovich/promotion-api/internal/api/router"
+	router2 "github.com/zdarovich/promotion-api/internal/api/router/v2"
+	"github.com/zdarovich/promotion-api/internal/bus"
 	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/standalone"
+	"github.com/zdarovich/promotion-api/internal/requests/createapikey"
+	"github.com/zdarovich/promotion-api/internal/requests/deletecampaigncustomers"
 	"github.com/zdarovich/promotion-api/internal/requests/deletecampaigns"
+	"github.com/zdarovich/promotion-api/internal/requests/deletesegments"
+	"github.com/zdarovich/promotion-api/internal/requests/deletewebhook"
+	"github.com/zdarovich/promotion-api/internal/requests/getapikeys"
+	"github.com/zdarovich/promotion-api/internal/requests/getcampaignchanges"
 	"github.com/zdarovich/promotion-api/internal/requests/getcampaigns"
+	"github.com/zdarovich/promotion-api/internal/requests/getcustomeroffers"
+	"github.com/zdarovich/promotion-api/internal/requests/getsegments"
+	"github.com/zdarovich/promotion-api/internal/requests/getwebhookdeliveries"
+	"github.com/zdarovich/promotion-api/internal/requests/getwebhooks"
+	"github.com/zdarovich/promotion-api/internal/requests/iscustomereligible"
+	"github.com/zdarovich/promotion-api/internal/requests/retrywebhookdelivery"
+	"github.com/zdarovich/promotion-api/internal/requests/revokeapikey"
+	"github.com/zdarovich/promotion-api/internal/requests/savecampaigncustomers"
 	"github.com/zdarovich/promotion-api/internal/requests/savecampaigns"
+	"github.com/zdarovich/promotion-api/internal/requests/savesegments"
+	"github.com/zdarovich/promotion-api/internal/requests/savewebhook"
+	"github.com/zdarovich/promotion-api/internal/requests/streamcampaigns"
+	"github.com/zdarovich/promotion-api/internal/service/outboxrelay"
+	"github.com/zdarovich/promotion-api/internal/service/webhookdelivery"
 )
 
 // @title Promotion API
@@ -17,15 +44,70 @@ import (
 //
 // @tag.name general
 // @tag.name campaign
+// @tag.name segment
+// @tag.name apikey
+// @tag.name webhook
 //
 // @BasePath /api/v1/
 func main() {
 
 	configuration := config.Get()
+	if configuration.Standalone.Enabled {
+		// Application cannot start without the standalone database
+		err := standalone.Setup(&configuration)
+		if err != nil {
+			panic("Failed to set up the standalone database: " + err.Error())
+		}
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrate(&configuration, os.Args[2:]))
+	}
+
 	handlers := make(map[string]root.IRoot)
 	handlers["getCampaigns"] = getcampaigns.New(&configuration)
+	handlers["getCampaignChanges"] = getcampaignchanges.New(&configuration)
 	handlers["saveCampaigns"] = savecampaigns.New(&configuration)
 	handlers["deleteCampaigns"] = deletecampaigns.New(&configuration)
+	handlers["getSegments"] = getsegments.New(&configuration)
+	handlers["saveSegments"] = savesegments.New(&configuration)
+	handlers["deleteSegments"] = deletesegments.New(&configuration)
+	handlers["isCustomerEligible"] = iscustomereligible.New(&configuration)
+	handlers["saveCampaignCustomers"] = savecampaigncustomers.New(&configuration)
+	handlers["deleteCampaignCustomers"] = deletecampaigncustomers.New(&configuration)
+	handlers["getCustomerOffers"] = getcustomeroffers.New(&configuration)
+	handlers["createAPIKey"] = createapikey.New(&configuration)
+	handlers["getAPIKeys"] = getapikeys.New(&configuration)
+	handlers["revokeAPIKey"] = revokeapikey.New(&configuration)
+	handlers["saveWebhook"] = savewebhook.New(&configuration)
+	handlers["getWebhooks"] = getwebhooks.New(&configuration)
+	handlers["deleteWebhook"] = deletewebhook.New(&configuration)
+	handlers["getWebhookDeliveries"] = getwebhookdeliveries.New(&configuration)
+	handlers["retryWebhookDelivery"] = retrywebhookdelivery.New(&configuration)
+	if configuration.Webhooks.Enabled {
+		go webhookdelivery.New(&configuration).Run(context.Background())
+	}
+	if configuration.Events.Enabled {
+		b, err := bus.New(&configuration)
+		if err != nil {
+			panic("Failed to set up the events bus: " + err.Error())
+		}
+		go outboxrelay.New(&configuration, b).Run(context.Background())
+	}
+	if configuration.PortV2 != 0 {
+		// The middleware of the v2 router sets the database of the request
+		// on a configuration of its own
+		configurationV2 := configuration
+		var routes []router2.Route
+		if configuration.Events.Enabled {
+			routes = append(routes, router2.Route{
+				Method:      http.MethodGet,
+				Pattern:     "/campaigns/stream",
+				HandlerFunc: streamcampaigns.New(&configurationV2).Handle,
+				Stream:      true,
+			})
+		}
+		go router2.New(&configurationV2, routes).GetEngine().Run(fmt.Sprintf(":%d", configuration.PortV2))
+	}
 	route := router.New(&configuration, handlers)
 	apiEngine := api.New(&configuration, route)
 	apiEngine.Run()
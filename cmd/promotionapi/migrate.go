@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	"github.com/zdarovich/promotion-api/internal/database/migration"
+	"github.com/zdarovich/promotion-api/internal/service/databasediscovery"
+)
+
+// migrate applies the schema migrations to the tenant databases and returns
+// the exit code. Usage:
+//
+//	promotionapi migrate [-tenant clientCode | -all] [-dry-run] [-down n] [-lock-timeout s]
+func migrate(configuration *config.Configuration, args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	tenant := flags.String("tenant", "", "client code of the tenant to migrate")
+	all := flags.Bool("all", false, "migrate all tenants known to database discovery")
+	dryRun := flags.Bool("dry-run", false, "list the migrations without running them")
+	down := flags.Int("down", 0, "number of migrations to roll back instead of applying the pending ones")
+	lockTimeout := flags.Int("lock-timeout", 10, "seconds to wait for another migration of the tenant to finish")
+	flags.Parse(args)
+
+	databases, err := tenantDatabases(configuration, *tenant, *all)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	code := 0
+	for _, database := range databases {
+		err := migrateTenant(*configuration, database, *dryRun, *down, *lockTimeout)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", database.Tenant, err)
+			code = 1
+		}
+	}
+	return code
+}
+
+// tenantDatabases returns the databases to migrate. Without database discovery
+// the configured database is migrated
+func tenantDatabases(configuration *config.Configuration, tenant string, all bool) ([]databasediscovery.Database, error) {
+	if !configuration.Database.Discovery.Enabled {
+		if tenant != "" || all {
+			return nil, errors.New("-tenant and -all require database discovery")
+		}
+		return []databasediscovery.Database{{
+			Tenant:       configuration.Database.Name,
+			DatabaseName: configuration.Database.Name,
+			Host:         configuration.Database.Server,
+			Port:         configuration.Database.Port,
+			User:         configuration.Database.Username,
+			Password:     configuration.Database.Password,
+		}}, nil
+	}
+
+	discovery := databasediscovery.New(configuration)
+	if all {
+		return discovery.GetDatabases(context.Background())
+	}
+	if tenant == "" {
+		return nil, errors.New("-tenant or -all is required with database discovery")
+	}
+	database, err := discovery.GetDatabase(context.Background(), tenant)
+	if err != nil {
+		return nil, err
+	}
+	return []databasediscovery.Database{database}, nil
+}
+
+func migrateTenant(configuration config.Configuration, database databasediscovery.Database, dryRun bool, down int, lockTimeout int) error {
+	configuration.Database.Name = database.DatabaseName
+	configuration.Database.Server = database.Host
+	configuration.Database.Port = database.Port
+	configuration.Database.Username = database.User
+	configuration.Database.Password = database.Password
+	if database.Driver != "" {
+		configuration.Database.Driver = database.Driver
+	}
+
+	db, err := migration.Open(&configuration)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migration.New(db, dialect.For(&configuration).Name(), lockTimeout)
+	if err != nil {
+		return err
+	}
+
+	var migrations []migration.Migration
+	direction := migration.Up
+	if down > 0 {
+		direction = migration.Down
+		migrations, err = migrator.Down(down, dryRun)
+	} else {
+		migrations, err = migrator.Up(dryRun)
+	}
+
+	prefix := ""
+	if dryRun {
+		prefix = "dry run: "
+	}
+	for _, m := range migrations {
+		fmt.Printf("%s: %s%s %d_%s\n", database.Tenant, prefix, direction, m.Version, m.Name)
+	}
+	if err == nil && len(migrations) == 0 {
+		fmt.Printf("%s: up to date\n", database.Tenant)
+	}
+	return err
+}
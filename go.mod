@@ -1,6 +1,6 @@
 module github.com/zdarovich/promotion-api
 
-go 1.13
+go 1.16
 
 require (
 	github.com/alecthomas/template v0.0.0-20190718012654-fb15b899a751
@@ -8,7 +8,9 @@ require (
 	github.com/go-redis/redis v6.15.7+incompatible
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/jmoiron/sqlx v1.2.0
+	github.com/lib/pq v1.10.9
 	github.com/mattn/go-colorable v0.1.6
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/onsi/ginkgo v1.12.0 // indirect
 	github.com/onsi/gomega v1.9.0 // indirect
 	github.com/proullon/ramsql v0.0.0-20181213202341-817cee58a244
@@ -0,0 +1,82 @@
+package caller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+)
+
+type (
+	// Caller authenticated caller of a request. A session caller has the
+	// session key and the user resolved with it, a token caller has the user name of the token claims and
+	// an api key caller has the id, the name and the scope of the key
+	Caller struct {
+		ClientCode string
+		SessionKey string
+		Username   string
+		APIKeyID   int
+		APIKey     string
+		Scope      string
+		Expires    time.Time
+		User       user.User
+	}
+	callerKey struct{}
+)
+
+// NewContext returns the context carrying the caller
+func NewContext(ctx context.Context, c Caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+// FromContext returns the caller of the request and whether the request has
+// been authenticated
+func FromContext(ctx context.Context) (Caller, bool) {
+	c, ok := ctx.Value(callerKey{}).(Caller)
+	return c, ok
+}
+
+// Key returns the key identifying the caller, the session key, the name of
+// the api key or the user name of a token
+func (c Caller) Key() string {
+	if c.SessionKey != "" {
+		return c.SessionKey
+	}
+	if c.APIKeyID != 0 {
+		return "apikey:" + c.APIKey
+	}
+	return "user:" + c.Username
+}
+
+// User returns the user of the request: the user already resolved for the
+// caller in the context, the user of a token caller, or else the user of the
+// session. An api key has no user, its user has the id of the key and the
+// name of the key as short name
+func User(ctx context.Context, repository user.IRepository, sessionKey string) (user.User, error) {
+
+	c, ok := FromContext(ctx)
+	if ok && c.User.ID != 0 {
+		return c.User, nil
+	}
+	if ok && c.SessionKey == "" {
+		if c.APIKeyID != 0 {
+			return user.User{ID: c.APIKeyID, Name: c.APIKey, ShortName: c.APIKey}, nil
+		}
+		if c.Username != "" {
+			return repository.GetUserByShortName(ctx, c.Username)
+		}
+	}
+	return repository.GetUserBySessionKey(ctx, sessionKey)
+}
+
+// BearerToken returns the token of the Authorization header value and
+// whether it has the Bearer scheme
+func BearerToken(authorization string) (string, bool) {
+
+	const scheme = "bearer "
+	if len(authorization) <= len(scheme) || !strings.EqualFold(authorization[:len(scheme)], scheme) {
+		return "", false
+	}
+	return strings.TrimSpace(authorization[len(scheme):]), true
+}
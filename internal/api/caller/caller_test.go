@@ -0,0 +1,77 @@
+package caller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+	"github.com/zdarovich/promotion-api/internal/repositories/user/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFromContext(t *testing.T) {
+
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := NewContext(context.Background(), Caller{ClientCode: "104235", Username: "service"})
+	c, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "104235", c.ClientCode)
+	assert.Equal(t, "user:service", c.Key())
+	assert.Equal(t, "key", Caller{SessionKey: "key", Username: "service"}.Key())
+}
+
+func TestUser(t *testing.T) {
+
+	repository := new(mocks.IRepository)
+	repository.On("GetUserBySessionKey", mock.Anything, "key").Return(user.User{ShortName: "session"}, nil)
+	repository.On("GetUserByShortName", mock.Anything, "service").Return(user.User{ShortName: "service"}, nil)
+
+	u, err := User(context.Background(), repository, "key")
+	assert.Nil(t, err)
+	assert.Equal(t, "session", u.ShortName)
+
+	ctx := NewContext(context.Background(), Caller{SessionKey: "key"})
+	u, err = User(ctx, repository, "key")
+	assert.Nil(t, err)
+	assert.Equal(t, "session", u.ShortName)
+
+	// The user resolved with the session is not read again
+	ctx = NewContext(context.Background(), Caller{SessionKey: "key", User: user.User{ID: 7, ShortName: "cached"}})
+	u, err = User(ctx, repository, "key")
+	assert.Nil(t, err)
+	assert.Equal(t, "cached", u.ShortName)
+	repository.AssertNumberOfCalls(t, "GetUserBySessionKey", 2)
+
+	ctx = NewContext(context.Background(), Caller{ClientCode: "104235", Username: "service"})
+	u, err = User(ctx, repository, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "service", u.ShortName)
+
+	// An api key has no user
+	ctx = NewContext(context.Background(), Caller{ClientCode: "104235", APIKeyID: 3, APIKey: "webshop"})
+	u, err = User(ctx, repository, "")
+	assert.Nil(t, err)
+	assert.Equal(t, user.User{ID: 3, Name: "webshop", ShortName: "webshop"}, u)
+	assert.Equal(t, "apikey:webshop", Caller{APIKeyID: 3, APIKey: "webshop"}.Key())
+}
+
+func TestBearerToken(t *testing.T) {
+
+	token, ok := BearerToken("Bearer abc.def.ghi")
+	assert.True(t, ok)
+	assert.Equal(t, "abc.def.ghi", token)
+
+	token, ok = BearerToken("bearer abc")
+	assert.True(t, ok)
+	assert.Equal(t, "abc", token)
+
+	_, ok = BearerToken("Basic dXNlcjpwYXNz")
+	assert.False(t, ok)
+
+	_, ok = BearerToken("Bearer ")
+	assert.False(t, ok)
+}
@@ -15,10 +15,22 @@ const (
 	CodeDBDiscovery string = "1203"
 	// CodeDatabase Failed to connect to the database
 	CodeDatabase string = "1003"
+	// CodeTimeout Request did not finish within its deadline
+	CodeTimeout string = "1004"
 	// CodeUnknownRequest Unknown request or 'request' input missing
 	CodeUnknownRequest = "1005"
 	// CodeRequiredParameterMissing Required parameter is missing
 	CodeRequiredParameterMissing = 1010
 	// CodeUnauthenticated Status code when authentication fails
 	CodeUnauthenticated string = "1051"
+	// CodeNoViewRights Status when the user has no rights for the request
+	CodeNoViewRights string = "1052"
+	// CodeTooManyRequests Status when the request is over a rate limit
+	CodeTooManyRequests string = "1053"
+	// CodeIdempotencyKeyReused Status when the idempotency key was used for
+	// another request
+	CodeIdempotencyKeyReused string = "1054"
+	// CodeIdempotencyKeyInProgress Status when the request of the idempotency
+	// key has not completed yet
+	CodeIdempotencyKeyInProgress string = "1055"
 )
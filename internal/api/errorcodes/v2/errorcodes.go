@@ -27,6 +27,16 @@ const (
 	CodeNoViewRights = 2010
 	// CodeDebugModeDisabled Status when debug is used but it has been disabled
 	CodeDebugModeDisabled = 2011
+	// CodeTooManyRequests Status when the request is over a rate limit
+	CodeTooManyRequests = 2012
+	// CodeIdempotencyKeyReused Status when the idempotency key was used for
+	// another request
+	CodeIdempotencyKeyReused = 2013
+	// CodeIdempotencyKeyInProgress Status when the request of the idempotency
+	// key has not completed yet
+	CodeIdempotencyKeyInProgress = 2014
+	// CodeInvalidParameter Status when a parameter has an invalid value
+	CodeInvalidParameter = 2015
 )
 
 // GetDescriptions returns error code descriptions
@@ -45,6 +55,10 @@ func GetDescriptions() map[int]string {
 		CodeRequiredParameterMissing: "Required parameter missing",
 		CodeUnauthenticated:          "Unable to authenticate the request",
 		CodeNoViewRights:             "User has no access to the request",
+		CodeTooManyRequests:          "Too many requests, retry later",
+		CodeIdempotencyKeyReused:     "Idempotency key was used for a different request",
+		CodeIdempotencyKeyInProgress: "Request with the idempotency key is still in progress",
+		CodeInvalidParameter:         "Invalid parameter value",
 	}
 }
 
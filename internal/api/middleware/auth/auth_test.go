@@ -1,19 +1,27 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/jwthelper"
+	"github.com/zdarovich/promotion-api/internal/helpers/sessionhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
+	apikeyMocks "github.com/zdarovich/promotion-api/internal/repositories/apikey/mocks"
 	"github.com/zdarovich/promotion-api/internal/repositories/session"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 type (
-	MockGinContext        struct{}
-	MockSessionRepository struct{}
+	MockGinContext    struct{}
+	MockSessionHelper struct{}
+	MockJWTHelper     struct{}
 )
 
 var postFormReturn string = ""
@@ -31,11 +39,27 @@ func (m *MockGinContext) Next() {
 var getSessionFail bool = false
 var getSessionResult session.Session
 
-func (m *MockSessionRepository) GetSessionByKey(sessionKey string) (session.Session, error) {
+func (m *MockSessionHelper) Resolve(ctx context.Context, clientCode string, sessionKey string) (sessionhelper.Resolved, error) {
 	if getSessionFail {
-		return getSessionResult, errors.New("Failure")
+		return sessionhelper.Resolved{}, errors.New("Failure")
 	}
-	return getSessionResult, nil
+	return sessionhelper.Resolved{Session: getSessionResult, User: user.User{ID: 1, ShortName: getSessionResult.User}}, nil
+}
+
+var invalidateCalled bool = false
+
+func (m *MockSessionHelper) Invalidate(ctx context.Context, clientCode string, sessionKey string) error {
+	invalidateCalled = true
+	return nil
+}
+
+var verifyFail bool = false
+
+func (m *MockJWTHelper) Verify(token string) (jwthelper.Claims, error) {
+	if verifyFail {
+		return jwthelper.Claims{}, errors.New("Failure")
+	}
+	return jwthelper.Claims{ClientCode: "104235", Username: "service", Expires: time.Unix(2000000000, 0)}, nil
 }
 
 func Test_New(t *testing.T) {
@@ -66,12 +90,13 @@ func Test_confirmAuthentication(t *testing.T) {
 	}
 
 	auth := Auth{
-		sessionRepository: new(MockSessionRepository),
+		sessionHelper: new(MockSessionHelper),
 	}
 
-	res := auth.confirmAuthentication("test")
+	resolved, res := auth.confirmAuthentication(context.Background(), "104235", "test")
 
 	assert.True(t, res)
+	assert.Equal(t, "test", resolved.User.ShortName)
 }
 
 func Test_confirmAuthenticationDatabaseFail(t *testing.T) {
@@ -80,10 +105,10 @@ func Test_confirmAuthenticationDatabaseFail(t *testing.T) {
 	getSessionResult = session.Session{}
 
 	auth := Auth{
-		sessionRepository: new(MockSessionRepository),
+		sessionHelper: new(MockSessionHelper),
 	}
 
-	res := auth.confirmAuthentication("test")
+	_, res := auth.confirmAuthentication(context.Background(), "104235", "test")
 
 	assert.False(t, res)
 }
@@ -107,10 +132,100 @@ func Test_confirmAuthenticationExpired(t *testing.T) {
 	}
 
 	auth := Auth{
-		sessionRepository: new(MockSessionRepository),
+		sessionHelper: new(MockSessionHelper),
 	}
 
-	res := auth.confirmAuthentication("test")
+	invalidateCalled = false
+	_, res := auth.confirmAuthentication(context.Background(), "104235", "test")
 
 	assert.False(t, res)
+	assert.True(t, invalidateCalled)
+}
+
+func Test_confirmToken(t *testing.T) {
+
+	verifyFail = false
+	configuration := config.Configuration{}
+	configuration.Identity.JWT.Enabled = true
+	auth := Auth{
+		Configuration: &configuration,
+		jwtHelper:     new(MockJWTHelper),
+	}
+
+	c, err := auth.confirmToken("token", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "104235", c.ClientCode)
+	assert.Equal(t, "service", c.Username)
+	assert.Equal(t, "", c.SessionKey)
+
+	_, err = auth.confirmToken("token", "104235")
+	assert.Nil(t, err)
+}
+
+func Test_confirmTokenOtherClientCode(t *testing.T) {
+
+	verifyFail = false
+	configuration := config.Configuration{}
+	configuration.Identity.JWT.Enabled = true
+	auth := Auth{
+		Configuration: &configuration,
+		jwtHelper:     new(MockJWTHelper),
+	}
+
+	_, err := auth.confirmToken("token", "100000")
+
+	assert.NotNil(t, err)
+}
+
+func Test_confirmTokenInvalid(t *testing.T) {
+
+	verifyFail = true
+	configuration := config.Configuration{}
+	configuration.Identity.JWT.Enabled = true
+	auth := Auth{
+		Configuration: &configuration,
+		jwtHelper:     new(MockJWTHelper),
+	}
+
+	_, err := auth.confirmToken("token", "")
+
+	assert.NotNil(t, err)
+}
+
+func Test_confirmTokenDisabled(t *testing.T) {
+
+	verifyFail = false
+	auth := Auth{
+		Configuration: &config.Configuration{},
+		jwtHelper:     new(MockJWTHelper),
+	}
+
+	_, err := auth.confirmToken("token", "")
+
+	assert.NotNil(t, err)
+}
+
+func Test_confirmAPIKey(t *testing.T) {
+
+	repository := new(apikeyMocks.IRepository)
+	repository.On("GetAPIKeyByHash", mock.Anything, apikey.Hash("active")).Return(apikey.APIKey{ID: 3, Name: "webshop", Scope: apikey.ScopeRead}, nil)
+	repository.On("GetAPIKeyByHash", mock.Anything, apikey.Hash("revoked")).Return(apikey.APIKey{ID: 4, Name: "old", Revoked: 100}, nil)
+	repository.On("GetAPIKeyByHash", mock.Anything, apikey.Hash("expired")).Return(apikey.APIKey{ID: 5, Name: "temp", Expires: 100}, nil)
+	repository.On("GetAPIKeyByHash", mock.Anything, apikey.Hash("unknown")).Return(apikey.APIKey{}, sql.ErrNoRows)
+	auth := Auth{
+		apiKeyRepository: repository,
+	}
+
+	c, ok := auth.confirmAPIKey(context.Background(), "active", "104235")
+	assert.True(t, ok)
+	assert.Equal(t, "104235", c.ClientCode)
+	assert.Equal(t, 3, c.APIKeyID)
+	assert.Equal(t, "webshop", c.APIKey)
+	assert.Equal(t, apikey.ScopeRead, c.Scope)
+
+	for _, key := range []string{"revoked", "expired", "unknown"} {
+		_, ok = auth.confirmAPIKey(context.Background(), key, "104235")
+		assert.False(t, ok, key)
+	}
 }
@@ -1,13 +1,20 @@
 package auth
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/zdarovich/promotion-api/internal/api/caller"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes/v2"
 	response2 "github.com/zdarovich/promotion-api/internal/api/response/v2"
 	"github.com/zdarovich/promotion-api/internal/config"
-	"github.com/zdarovich/promotion-api/internal/repositories/session"
+	"github.com/zdarovich/promotion-api/internal/helpers/jwthelper"
+	"github.com/zdarovich/promotion-api/internal/helpers/sessionhelper"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,11 +22,14 @@ import (
 type (
 	// Auth struct
 	Auth struct {
-		Configuration     *config.Configuration
-		sessionRepository session.IRepository
+		Configuration    *config.Configuration
+		sessionHelper    sessionhelper.ISessionHelper
+		apiKeyRepository apikey.IRepository
+		jwtHelper        jwthelper.IJWTHelper
 	}
 	// IAuth interface
 	IAuth interface {
+		Bearer() gin.HandlerFunc
 		Authenticate() gin.HandlerFunc
 	}
 )
@@ -28,8 +38,36 @@ type (
 func New(configuration *config.Configuration) IAuth {
 
 	return &Auth{
-		Configuration:     configuration,
-		sessionRepository: session.New(configuration),
+		Configuration:    configuration,
+		sessionHelper:    sessionhelper.New(configuration),
+		apiKeyRepository: apikey.New(configuration),
+		jwtHelper:        jwthelper.New(configuration),
+	}
+}
+
+// Bearer authenticates the request with the identity token of the
+// Authorization header. The caller of the token is set to the request
+// context, requests without the header are left to the session check
+func (auth *Auth) Bearer() gin.HandlerFunc {
+
+	return func(context *gin.Context) {
+
+		token, ok := caller.BearerToken(context.GetHeader("Authorization"))
+		if !ok {
+			context.Next()
+			return
+		}
+
+		c, err := auth.confirmToken(token, context.GetHeader("clientCode"))
+		if err != nil {
+			log.Warn(fmt.Sprintf("bearer authentication failed: %s", err))
+			response := response2.New(auth.Configuration)
+			response.Error(context, http.StatusUnauthorized, errorcodes.New("Authorization", errorcodes.CodeUnauthenticated))
+			return
+		}
+
+		context.Request = context.Request.WithContext(caller.NewContext(context.Request.Context(), c))
+		context.Next()
 	}
 }
 
@@ -38,7 +76,28 @@ func (auth *Auth) Authenticate() gin.HandlerFunc {
 
 	return func(context *gin.Context) {
 
-		authenticated := auth.confirmAuthentication(context.GetHeader("sessionKey"))
+		if _, ok := caller.FromContext(context.Request.Context()); ok {
+			// Already authenticated by the bearer token
+			context.Next()
+			return
+		}
+
+		if key := context.GetHeader("apiKey"); key != "" {
+			// The api key is used in place of the session key
+			c, authenticated := auth.confirmAPIKey(context.Request.Context(), key, context.GetHeader("clientCode"))
+			if !authenticated {
+				response := response2.New(auth.Configuration)
+				response.Error(context, http.StatusBadRequest, errorcodes.New("apiKey", errorcodes.CodeUnauthenticated))
+				return
+			}
+			context.Request = context.Request.WithContext(caller.NewContext(context.Request.Context(), c))
+			context.Next()
+			return
+		}
+
+		clientCode := context.GetHeader("clientCode")
+		sessionKey := context.GetHeader("sessionKey")
+		resolved, authenticated := auth.confirmAuthentication(context.Request.Context(), clientCode, sessionKey)
 
 		if !authenticated {
 			response := response2.New(auth.Configuration)
@@ -46,24 +105,73 @@ func (auth *Auth) Authenticate() gin.HandlerFunc {
 			return
 		}
 
+		// The user of the session is kept for the permission check and the handlers
+		c := caller.Caller{ClientCode: clientCode, SessionKey: sessionKey, User: resolved.User}
+		context.Request = context.Request.WithContext(caller.NewContext(context.Request.Context(), c))
 		context.Next()
 	}
 }
 
-// The function that will be doing the actual authentication
-func (auth *Auth) confirmAuthentication(sessionKey string) bool {
+// confirmToken verifies the token and returns its caller. A client code sent
+// with the request has to be the one of the token
+func (auth *Auth) confirmToken(token string, clientCode string) (caller.Caller, error) {
+
+	if !auth.Configuration.Identity.JWT.Enabled {
+		return caller.Caller{}, errors.New("jwt authentication is disabled")
+	}
+
+	claims, err := auth.jwtHelper.Verify(token)
+	if err != nil {
+		return caller.Caller{}, err
+	}
+	if clientCode != "" && clientCode != claims.ClientCode {
+		return caller.Caller{}, fmt.Errorf("client code %s is not the one of the token", clientCode)
+	}
+
+	return caller.Caller{
+		ClientCode: claims.ClientCode,
+		Username:   claims.Username,
+		Expires:    claims.Expires,
+	}, nil
+}
+
+// confirmAPIKey returns the caller of the api key when the key exists and
+// is neither revoked nor expired
+func (auth *Auth) confirmAPIKey(ctx context.Context, key string, clientCode string) (caller.Caller, bool) {
+
+	k, err := auth.apiKeyRepository.GetAPIKeyByHash(ctx, apikey.Hash(key))
+	if err != nil || !k.Active(time.Now().Unix()) {
+		return caller.Caller{}, false
+	}
+
+	c := caller.Caller{
+		ClientCode: clientCode,
+		APIKeyID:   k.ID,
+		APIKey:     k.Name,
+		Scope:      k.Scope,
+	}
+	if k.Expires != 0 {
+		c.Expires = time.Unix(k.Expires, 0)
+	}
+	return c, true
+}
+
+// The function that will be doing the actual authentication, it returns
+// the resolved session and its user
+func (auth *Auth) confirmAuthentication(ctx context.Context, clientCode string, sessionKey string) (sessionhelper.Resolved, bool) {
 
-	session, err := auth.sessionRepository.GetSessionByKey(sessionKey)
+	resolved, err := auth.sessionHelper.Resolve(ctx, clientCode, sessionKey)
 
 	if err != nil {
-		return false
+		return resolved, false
 	}
 
 	// Check that a session exists and when it does then check that
 	// it is not expired
-	if session.ID == 0 || session.Expires.Int64 < time.Now().Unix() {
-		return false
+	if resolved.Session.ID == 0 || resolved.Session.Expires.Int64 < time.Now().Unix() {
+		auth.sessionHelper.Invalidate(ctx, clientCode, sessionKey)
+		return resolved, false
 	}
 
-	return true
+	return resolved, true
 }
@@ -0,0 +1,65 @@
+package deadline
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	// Deadline struct
+	Deadline struct {
+		Configuration *config.Configuration
+	}
+	// IDeadline interface
+	IDeadline interface {
+		Deadline() gin.HandlerFunc
+	}
+)
+
+// New returns configured deadline
+func New(configuration *config.Configuration) IDeadline {
+
+	return &Deadline{
+		Configuration: configuration,
+	}
+}
+
+// Deadline sets the deadline of the request type to the request context. The
+// context is also cancelled when the client disconnects
+func (deadline *Deadline) Deadline() gin.HandlerFunc {
+
+	return func(context *gin.Context) {
+
+		request, cancel := deadline.withTimeout(context.Request, deadline.Timeout(context.PostForm("request")))
+		defer cancel()
+
+		context.Request = request
+		context.Next()
+	}
+}
+
+// Timeout returns the timeout of the request type, the default timeout when
+// the request type has none. Zero means no deadline
+func (deadline *Deadline) Timeout(request string) time.Duration {
+
+	timeout, ok := deadline.Configuration.RequestTimeout.Requests[request]
+	if !ok {
+		timeout = deadline.Configuration.RequestTimeout.Default
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// withTimeout returns the request with the timeout set to its context
+func (deadline *Deadline) withTimeout(request *http.Request, timeout time.Duration) (*http.Request, context.CancelFunc) {
+
+	if timeout <= 0 {
+		return request, func() {}
+	}
+	ctx, cancel := context.WithTimeout(request.Context(), timeout)
+	return request.WithContext(ctx), cancel
+}
@@ -0,0 +1,96 @@
+package deadline
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConfiguration() *config.Configuration {
+
+	configuration := &config.Configuration{}
+	configuration.RequestTimeout.Default = 30
+	configuration.RequestTimeout.Requests = map[string]int{"getCampaigns": 10, "saveCampaigns": 0}
+	return configuration
+}
+
+func Test_New(t *testing.T) {
+
+	deadline := New(&config.Configuration{})
+	assert.NotNil(t, deadline)
+}
+
+func Test_Timeout(t *testing.T) {
+
+	deadline := &Deadline{Configuration: newConfiguration()}
+
+	assert.Equal(t, 10*time.Second, deadline.Timeout("getCampaigns"))
+	assert.Equal(t, 30*time.Second, deadline.Timeout("getSegments"))
+	assert.Equal(t, time.Duration(0), deadline.Timeout("saveCampaigns"))
+}
+
+func Test_Deadline_SetsRequestDeadline(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	deadline := &Deadline{Configuration: newConfiguration()}
+
+	var remaining time.Duration
+	var hasDeadline bool
+	engine := gin.New()
+	engine.Use(deadline.Deadline())
+	engine.POST("/", func(context *gin.Context) {
+		var at time.Time
+		at, hasDeadline = context.Request.Context().Deadline()
+		remaining = time.Until(at)
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("request=getCampaigns"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.True(t, hasDeadline)
+	assert.True(t, remaining > 9*time.Second && remaining <= 10*time.Second)
+}
+
+func Test_Deadline_WithoutTimeout_HasNoDeadline(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	deadline := &Deadline{Configuration: newConfiguration()}
+
+	hasDeadline := true
+	engine := gin.New()
+	engine.Use(deadline.Deadline())
+	engine.POST("/", func(context *gin.Context) {
+		_, hasDeadline = context.Request.Context().Deadline()
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("request=saveCampaigns"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	engine.ServeHTTP(httptest.NewRecorder(), request)
+
+	assert.False(t, hasDeadline)
+}
+
+func Test_Deadline_CancelsAfterRequest(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	deadline := &Deadline{Configuration: newConfiguration()}
+
+	var request *http.Request
+	engine := gin.New()
+	engine.Use(deadline.Deadline())
+	engine.POST("/", func(context *gin.Context) {
+		request = context.Request
+	})
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+
+	assert.NotNil(t, request.Context().Err())
+}
@@ -0,0 +1,61 @@
+package deadline
+
+import (
+	"context"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	// Deadline struct
+	Deadline struct {
+		Configuration *config.Configuration
+	}
+	// IDeadline interface
+	IDeadline interface {
+		Deadline() gin.HandlerFunc
+	}
+)
+
+// New returns configured deadline
+func New(configuration *config.Configuration) IDeadline {
+
+	return &Deadline{
+		Configuration: configuration,
+	}
+}
+
+// Deadline sets the deadline of the route to the request context. The
+// request type of a route is its method and path, e.g. "GET /v1/campaigns".
+// The context is also cancelled when the client disconnects
+func (deadline *Deadline) Deadline() gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		timeout := deadline.Timeout(c.Request.Method + " " + c.FullPath())
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// Timeout returns the timeout of the request type, the default timeout when
+// the request type has none. Zero means no deadline
+func (deadline *Deadline) Timeout(request string) time.Duration {
+
+	timeout, ok := deadline.Configuration.RequestTimeout.Requests[request]
+	if !ok {
+		timeout = deadline.Configuration.RequestTimeout.Default
+	}
+	return time.Duration(timeout) * time.Second
+}
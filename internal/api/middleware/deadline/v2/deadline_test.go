@@ -0,0 +1,53 @@
+package deadline
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConfiguration() *config.Configuration {
+
+	configuration := &config.Configuration{}
+	configuration.RequestTimeout.Default = 30
+	configuration.RequestTimeout.Requests = map[string]int{"GET /v1/campaigns": 10, "DELETE /v1/campaigns/:id": 0}
+	return configuration
+}
+
+func Test_Timeout(t *testing.T) {
+
+	deadline := &Deadline{Configuration: newConfiguration()}
+
+	assert.Equal(t, 10*time.Second, deadline.Timeout("GET /v1/campaigns"))
+	assert.Equal(t, 30*time.Second, deadline.Timeout("POST /v1/campaigns"))
+	assert.Equal(t, time.Duration(0), deadline.Timeout("DELETE /v1/campaigns/:id"))
+}
+
+func Test_Deadline_SetsRouteDeadline(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	deadline := &Deadline{Configuration: newConfiguration()}
+
+	deadlines := make(map[string]time.Duration)
+	engine := gin.New()
+	engine.Use(deadline.Deadline())
+	handler := func(c *gin.Context) {
+		if at, ok := c.Request.Context().Deadline(); ok {
+			deadlines[c.Request.Method] = time.Until(at)
+		}
+	}
+	engine.GET("/v1/campaigns", handler)
+	engine.DELETE("/v1/campaigns/:id", handler)
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/campaigns", nil))
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/v1/campaigns/1", nil))
+
+	assert.Len(t, deadlines, 1)
+	assert.True(t, deadlines[http.MethodGet] > 9*time.Second && deadlines[http.MethodGet] <= 10*time.Second)
+}
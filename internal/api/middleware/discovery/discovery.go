@@ -1,10 +1,13 @@
 package discovery
 
 import (
+	"context"
 	"errors"
+	"github.com/zdarovich/promotion-api/internal/api/caller"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	response2 "github.com/zdarovich/promotion-api/internal/api/response"
 	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
 	"github.com/zdarovich/promotion-api/internal/service/databasediscovery"
 
 	"github.com/gin-gonic/gin"
@@ -38,7 +41,17 @@ func (discovery *Discovery) Discover() gin.HandlerFunc {
 	return func(context *gin.Context) {
 
 		clientCode := context.PostForm("clientCode")
-		err := discovery.setDatabaseConfig(clientCode)
+		session := context.PostForm("sessionKey")
+		if key := context.PostForm("apiKey"); key != "" {
+			session = "apikey:" + apikey.Hash(key)
+		}
+		if c, ok := caller.FromContext(context.Request.Context()); ok {
+			// The client of a bearer token comes from its claims
+			clientCode = c.ClientCode
+			session = c.Key()
+		}
+		err := discovery.setDatabaseConfig(context.Request.Context(), clientCode)
+		discovery.Configuration.Database.Session = session
 
 		if err != nil {
 			response := response2.New(discovery.Configuration, context.PostForm("request"))
@@ -51,7 +64,7 @@ func (discovery *Discovery) Discover() gin.HandlerFunc {
 }
 
 // Depending on the configuration sets the database configuration
-func (discovery *Discovery) setDatabaseConfig(clientCode string) error {
+func (discovery *Discovery) setDatabaseConfig(ctx context.Context, clientCode string) error {
 
 	if discovery.Configuration.Database.Discovery.Enabled == false {
 		// At this state the configuration has already been mapped from the
@@ -59,7 +72,7 @@ func (discovery *Discovery) setDatabaseConfig(clientCode string) error {
 		return nil
 	}
 
-	database, err := discovery.DatabaseDiscovery.GetDatabase(clientCode)
+	database, err := discovery.DatabaseDiscovery.GetDatabase(ctx, clientCode)
 
 	if err != nil {
 		return err
@@ -70,6 +83,13 @@ func (discovery *Discovery) setDatabaseConfig(clientCode string) error {
 	discovery.Configuration.Database.Port = database.Port
 	discovery.Configuration.Database.Username = database.User
 	discovery.Configuration.Database.Password = database.Password
+	if database.Driver != "" {
+		discovery.Configuration.Database.Driver = database.Driver
+	}
+	discovery.Configuration.Database.Replicas = make([]config.Replica, len(database.Replicas))
+	for idx, replica := range database.Replicas {
+		discovery.Configuration.Database.Replicas[idx] = config.Replica{Server: replica.Host, Port: replica.Port}
+	}
 
 	return nil
 }
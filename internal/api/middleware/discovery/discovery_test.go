@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"context"
 	"errors"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/config"
@@ -15,13 +16,17 @@ type MockDatabaseDiscovery struct{}
 var failGetDatabase bool = false
 var getDatabaseResult databasediscovery.Database
 
-func (m *MockDatabaseDiscovery) GetDatabase(clientCode string) (databasediscovery.Database, error) {
+func (m *MockDatabaseDiscovery) GetDatabase(ctx context.Context, clientCode string) (databasediscovery.Database, error) {
 	if failGetDatabase {
 		return getDatabaseResult, errors.New(errorcodes.CodeDatabase)
 	}
 	return getDatabaseResult, nil
 }
 
+func (m *MockDatabaseDiscovery) GetDatabases(ctx context.Context) ([]databasediscovery.Database, error) {
+	return []databasediscovery.Database{getDatabaseResult}, nil
+}
+
 func Test_New(t *testing.T) {
 
 	configuration := config.Configuration{}
@@ -51,7 +56,7 @@ func Test_setDatabaseConfig(t *testing.T) {
 		DatabaseDiscovery: new(MockDatabaseDiscovery),
 	}
 
-	err := discovery.setDatabaseConfig("123")
+	err := discovery.setDatabaseConfig(context.Background(), "123")
 
 	assert.Nil(t, err)
 	assert.Equal(t, getDatabaseResult.DatabaseName, configuration.Database.Name)
@@ -80,7 +85,7 @@ func Test_setDatabaseConfigDiscoveryDisabled(t *testing.T) {
 		DatabaseDiscovery: new(MockDatabaseDiscovery),
 	}
 
-	err := discovery.setDatabaseConfig("123")
+	err := discovery.setDatabaseConfig(context.Background(), "123")
 
 	assert.Nil(t, err)
 	assert.Equal(t, "crmx_500", configuration.Database.Name)
@@ -101,7 +106,33 @@ func Test_setDatabaseConfigDatabaseFail(t *testing.T) {
 		DatabaseDiscovery: new(MockDatabaseDiscovery),
 	}
 
-	err := discovery.setDatabaseConfig("123")
+	err := discovery.setDatabaseConfig(context.Background(), "123")
 
 	assert.NotNil(t, err)
 }
+
+func Test_setDatabaseConfigReplicas(t *testing.T) {
+
+	failGetDatabase = false
+	getDatabaseResult = databasediscovery.Database{
+		Tenant:       "100",
+		DatabaseName: "crmx_100",
+		Host:         "0.0.0.0",
+		Port:         3306,
+		Replicas:     []databasediscovery.Replica{{Host: "0.0.0.1", Port: 3306}},
+	}
+
+	configuration := config.Configuration{}
+	configuration.Database.Discovery.Enabled = true
+	configuration.Database.Replicas = []config.Replica{{Server: "1.1.1.2", Port: 1000}}
+
+	discovery := &Discovery{
+		Configuration:     &configuration,
+		DatabaseDiscovery: new(MockDatabaseDiscovery),
+	}
+
+	err := discovery.setDatabaseConfig(context.Background(), "123")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []config.Replica{{Server: "0.0.0.1", Port: 3306}}, configuration.Database.Replicas)
+}
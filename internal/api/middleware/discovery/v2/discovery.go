@@ -1,11 +1,14 @@
 package discovery
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/zdarovich/promotion-api/internal/api/caller"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes/v2"
 	response2 "github.com/zdarovich/promotion-api/internal/api/response/v2"
 	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
 	"github.com/zdarovich/promotion-api/internal/service/databasediscovery"
 
 	"github.com/gin-gonic/gin"
@@ -39,7 +42,17 @@ func (discovery *Discovery) Discover() gin.HandlerFunc {
 	return func(context *gin.Context) {
 
 		clientCode := context.GetHeader("clientCode")
-		err := discovery.setDatabaseConfig(clientCode)
+		session := context.GetHeader("sessionKey")
+		if key := context.GetHeader("apiKey"); key != "" {
+			session = "apikey:" + apikey.Hash(key)
+		}
+		if c, ok := caller.FromContext(context.Request.Context()); ok {
+			// The client of a bearer token comes from its claims
+			clientCode = c.ClientCode
+			session = c.Key()
+		}
+		err := discovery.setDatabaseConfig(context.Request.Context(), clientCode)
+		discovery.Configuration.Database.Session = session
 
 		if err != nil {
 			response := response2.New(discovery.Configuration)
@@ -52,7 +65,7 @@ func (discovery *Discovery) Discover() gin.HandlerFunc {
 }
 
 // Depending on the configuration sets the database configuration
-func (discovery *Discovery) setDatabaseConfig(clientCode string) error {
+func (discovery *Discovery) setDatabaseConfig(ctx context.Context, clientCode string) error {
 
 	if discovery.Configuration.Database.Discovery.Enabled == false {
 		// At this state the configuration has already been mapped from the
@@ -60,7 +73,7 @@ func (discovery *Discovery) setDatabaseConfig(clientCode string) error {
 		return nil
 	}
 
-	database, err := discovery.DatabaseDiscovery.GetDatabase(clientCode)
+	database, err := discovery.DatabaseDiscovery.GetDatabase(ctx, clientCode)
 
 	if err != nil {
 		return err
@@ -71,6 +84,13 @@ func (discovery *Discovery) setDatabaseConfig(clientCode string) error {
 	discovery.Configuration.Database.Port = database.Port
 	discovery.Configuration.Database.Username = database.User
 	discovery.Configuration.Database.Password = database.Password
+	if database.Driver != "" {
+		discovery.Configuration.Database.Driver = database.Driver
+	}
+	discovery.Configuration.Database.Replicas = make([]config.Replica, len(database.Replicas))
+	for idx, replica := range database.Replicas {
+		discovery.Configuration.Database.Replicas[idx] = config.Replica{Server: replica.Host, Port: replica.Port}
+	}
 
 	return nil
 }
@@ -0,0 +1,221 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	response2 "github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/idempotencyhelper"
+	"github.com/zdarovich/promotion-api/internal/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ParameterIdempotencyKey form field of the idempotency key
+	ParameterIdempotencyKey = "idempotencyKey"
+	// HeaderIdempotencyKey header of the idempotency key, used when the form
+	// field is not sent
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderReplayed header set to the replayed responses
+	HeaderReplayed = "Idempotent-Replayed"
+	// KeyHandled context key of the data of a handler that succeeded, set
+	// by the router when the client gets an error as the request ran out of
+	// time after the handler had succeeded
+	KeyHandled = "idempotencyHandled"
+)
+
+// finishTimeout time the response is stored or the key released in, after
+// the request context may be done
+const finishTimeout = 5 * time.Second
+
+// Requests write requests accepting an idempotency key. createAPIKey and
+// saveWebhook are not listed, their responses have the key or the secret and
+// are not stored
+var Requests = map[string]bool{
+	"saveCampaigns":           true,
+	"deleteCampaigns":         true,
+	"saveSegments":            true,
+	"deleteSegments":          true,
+	"saveCampaignCustomers":   true,
+	"deleteCampaignCustomers": true,
+	"revokeAPIKey":            true,
+	"deleteWebhook":           true,
+	"retryWebhookDelivery":    true,
+}
+
+// credentials form fields that are not part of the payload of a request
+var credentials = []string{"clientCode", "sessionKey", "apiKey", ParameterIdempotencyKey}
+
+type (
+	// Idempotency struct
+	Idempotency struct {
+		Configuration     *config.Configuration
+		idempotencyHelper idempotencyhelper.IIdempotencyHelper
+	}
+	// IIdempotency interface
+	IIdempotency interface {
+		Idempotent() gin.HandlerFunc
+	}
+	// recorder keeps a copy of the response body
+	recorder struct {
+		gin.ResponseWriter
+		body bytes.Buffer
+	}
+	// renderer keeps the response rendered by the response package
+	renderer struct {
+		response interface{}
+	}
+)
+
+// New returns configured idempotency
+func New(configuration *config.Configuration) IIdempotency {
+
+	return &Idempotency{
+		Configuration:     configuration,
+		idempotencyHelper: idempotencyhelper.New(configuration),
+	}
+}
+
+// Idempotent runs a write request with an idempotency key once. The response
+// of a successful request is stored and replayed to the requests with the
+// same key and payload, a failed request can be retried with the key
+func (idempotency *Idempotency) Idempotent() gin.HandlerFunc {
+
+	return func(context *gin.Context) {
+
+		request := context.PostForm("request")
+		key := context.PostForm(ParameterIdempotencyKey)
+		if key == "" {
+			key = context.GetHeader(HeaderIdempotencyKey)
+		}
+		if !idempotency.Configuration.Idempotency.Enabled || key == "" || !Requests[request] {
+			context.Next()
+			return
+		}
+
+		ctx := context.Request.Context()
+		c, _ := caller.FromContext(ctx)
+		form := make(map[string][]string, len(context.Request.PostForm))
+		for name, values := range context.Request.PostForm {
+			form[name] = values
+		}
+		for _, name := range credentials {
+			delete(form, name)
+		}
+		payload, _ := json.Marshal(form)
+		fingerprint := idempotencyhelper.Fingerprint(request, string(payload))
+
+		record, replay, err := idempotency.idempotencyHelper.Begin(ctx, c.ClientCode, key, fingerprint)
+		switch {
+		case err == idempotencyhelper.ErrKeyReused:
+			response := response2.New(idempotency.Configuration, request)
+			response.Error(context, errors.New(errorcodes.CodeIdempotencyKeyReused))
+			return
+		case err == idempotencyhelper.ErrInProgress:
+			response := response2.New(idempotency.Configuration, request)
+			response.Error(context, errors.New(errorcodes.CodeIdempotencyKeyInProgress))
+			return
+		case err != nil:
+			// The request is run without the key while the records are unavailable
+			log.Warn(fmt.Sprintf("idempotency key not checked: %s", err))
+			context.Next()
+			return
+		case replay:
+			context.Header(HeaderReplayed, "true")
+			context.Data(record.Status, record.ContentType, record.Body)
+			context.Abort()
+			return
+		}
+
+		writer := &recorder{ResponseWriter: context.Writer}
+		context.Writer = writer
+		context.Next()
+
+		var status response2.ErrorResponse
+		json.Unmarshal(writer.body.Bytes(), &status)
+		var stored *idempotencyhelper.Record
+		if status.Status.ResponseStatus == response2.StatusOK {
+			stored = &idempotencyhelper.Record{
+				Status:      writer.Status(),
+				ContentType: writer.Header().Get("Content-Type"),
+				Body:        writer.body.Bytes(),
+			}
+		} else if data, ok := context.Get(KeyHandled); ok {
+			// The handler succeeded after all, a retry gets its response
+			// instead of running it again
+			stored, err = idempotency.render(request, data.(*response2.Data))
+			if err != nil {
+				log.Warn(fmt.Sprintf("idempotency response not rendered: %s", err))
+			}
+		}
+		idempotency.finish(c.ClientCode, key, fingerprint, stored)
+	}
+}
+
+// finish stores the response of the key, or releases the key for a retry
+// without a response. The request context may be done by now, the records
+// are written on a context of their own
+func (idempotency *Idempotency) finish(clientCode string, key string, fingerprint string, record *idempotencyhelper.Record) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	var err error
+	if record == nil {
+		err = idempotency.idempotencyHelper.Release(ctx, clientCode, key)
+	} else {
+		record.Fingerprint = fingerprint
+		err = idempotency.idempotencyHelper.Complete(ctx, clientCode, key, *record)
+	}
+	if err != nil {
+		log.Warn(fmt.Sprintf("idempotency key not stored: %s", err))
+	}
+}
+
+// render returns the successful response of the data
+func (idempotency *Idempotency) render(request string, data *response2.Data) (*idempotencyhelper.Record, error) {
+
+	r := &renderer{}
+	response2.New(idempotency.Configuration, request).OK(r, data)
+	body, err := json.Marshal(r.response)
+	if err != nil {
+		return nil, err
+	}
+	return &idempotencyhelper.Record{
+		Status:      http.StatusOK,
+		ContentType: "application/json; charset=utf-8",
+		Body:        body,
+	}, nil
+}
+
+// JSON keeps the response
+func (r *renderer) JSON(code int, obj interface{}) { r.response = obj }
+
+// PostForm the renderer has no form
+func (r *renderer) PostForm(string) string { return "" }
+
+// Abort the renderer has no handlers
+func (r *renderer) Abort() {}
+
+// Write keeps a copy of the data written to the response
+func (recorder *recorder) Write(data []byte) (int, error) {
+
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+// WriteString keeps a copy of the string written to the response
+func (recorder *recorder) WriteString(s string) (int, error) {
+
+	recorder.body.WriteString(s)
+	return recorder.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,174 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	response2 "github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/idempotencyhelper"
+	"github.com/zdarovich/promotion-api/internal/helpers/idempotencyhelper/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newIdempotency(idempotencyHelper *mocks.IIdempotencyHelper) *Idempotency {
+
+	configuration := &config.Configuration{}
+	configuration.Idempotency.Enabled = true
+	return &Idempotency{Configuration: configuration, idempotencyHelper: idempotencyHelper}
+}
+
+// serve runs the form through the middleware, the handler fails when
+// failing is set, and returns the response and the handled requests
+func serve(idempotency *Idempotency, body string, failing bool) (*httptest.ResponseRecorder, int) {
+
+	gin.SetMode(gin.TestMode)
+	handled := 0
+	engine := gin.New()
+	engine.Use(func(context *gin.Context) {
+		c := caller.Caller{ClientCode: "104235", SessionKey: "key"}
+		context.Request = context.Request.WithContext(caller.NewContext(context.Request.Context(), c))
+	})
+	engine.Use(idempotency.Idempotent())
+	engine.POST("/", func(context *gin.Context) {
+		handled++
+		if failing {
+			context.JSON(http.StatusOK, gin.H{"status": gin.H{"responseStatus": "error", "errorCode": 1003}})
+			return
+		}
+		context.JSON(http.StatusOK, gin.H{"status": gin.H{"responseStatus": "ok"}, "records": []int{1}})
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	return recorder, handled
+}
+
+func fingerprint(request string, payload string) string {
+	return idempotencyhelper.Fingerprint(request, payload)
+}
+
+func Test_New(t *testing.T) {
+
+	idempotency := New(&config.Configuration{})
+	assert.NotNil(t, idempotency)
+}
+
+func Test_Idempotent_StoresSuccessfulResponse(t *testing.T) {
+
+	idempotencyHelper := new(mocks.IIdempotencyHelper)
+	expected := fingerprint("saveCampaigns", `{"name":["Spring"],"request":["saveCampaigns"]}`)
+	idempotencyHelper.On("Begin", mock.Anything, "104235", "retry-1", expected).Return(idempotencyhelper.Record{}, false, nil)
+	idempotencyHelper.On("Complete", mock.Anything, "104235", "retry-1", mock.MatchedBy(func(record idempotencyhelper.Record) bool {
+		return record.Fingerprint == expected && record.Status == http.StatusOK && strings.Contains(string(record.Body), `"records":[1]`)
+	})).Return(nil)
+
+	// The credentials are not part of the payload
+	_, handled := serve(newIdempotency(idempotencyHelper), "request=saveCampaigns&sessionKey=key&idempotencyKey=retry-1&name=Spring", false)
+
+	assert.Equal(t, 1, handled)
+	idempotencyHelper.AssertExpectations(t)
+}
+
+func Test_Idempotent_ReleasesFailedRequest(t *testing.T) {
+
+	idempotencyHelper := new(mocks.IIdempotencyHelper)
+	idempotencyHelper.On("Begin", mock.Anything, "104235", "retry-1", mock.Anything).Return(idempotencyhelper.Record{}, false, nil)
+	idempotencyHelper.On("Release", mock.Anything, "104235", "retry-1").Return(nil)
+
+	_, handled := serve(newIdempotency(idempotencyHelper), "request=saveCampaigns&idempotencyKey=retry-1", true)
+
+	assert.Equal(t, 1, handled)
+	idempotencyHelper.AssertExpectations(t)
+}
+
+func Test_Idempotent_StoresResponseOfWriteOutOfTime(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	idempotencyHelper := new(mocks.IIdempotencyHelper)
+	idempotencyHelper.On("Begin", mock.Anything, "104235", "retry-1", mock.Anything).Return(idempotencyhelper.Record{}, false, nil)
+	idempotencyHelper.On("Complete", mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Err() == nil
+	}), "104235", "retry-1", mock.MatchedBy(func(record idempotencyhelper.Record) bool {
+		return record.Status == http.StatusOK &&
+			strings.Contains(string(record.Body), `"responseStatus":"ok"`) &&
+			strings.Contains(string(record.Body), `"records":[1]`)
+	})).Return(nil)
+
+	// The handler succeeds, the client gets the timeout error of the router
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(caller.NewContext(c.Request.Context(), caller.Caller{ClientCode: "104235"}))
+	})
+	engine.Use(newIdempotency(idempotencyHelper).Idempotent())
+	engine.POST("/", func(c *gin.Context) {
+		c.Set(KeyHandled, &response2.Data{Total: 1, TotalInResponse: 1, Records: []int{1}})
+		c.JSON(http.StatusOK, gin.H{"status": gin.H{"responseStatus": "error", "errorCode": 1004}})
+	})
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("request=saveCampaigns&idempotencyKey=retry-1"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// The client went away
+	ctx, cancel := context.WithCancel(request.Context())
+	cancel()
+	engine.ServeHTTP(httptest.NewRecorder(), request.WithContext(ctx))
+
+	idempotencyHelper.AssertExpectations(t)
+	idempotencyHelper.AssertNotCalled(t, "Release", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func Test_Idempotent_Replays(t *testing.T) {
+
+	idempotencyHelper := new(mocks.IIdempotencyHelper)
+	record := idempotencyhelper.Record{Completed: true, Status: http.StatusOK, ContentType: "application/json", Body: []byte(`{"records":[1]}`)}
+	idempotencyHelper.On("Begin", mock.Anything, "104235", "retry-1", mock.Anything).Return(record, true, nil)
+
+	recorder, handled := serve(newIdempotency(idempotencyHelper), "request=saveCampaigns&idempotencyKey=retry-1", false)
+
+	assert.Equal(t, 0, handled)
+	assert.Equal(t, `{"records":[1]}`, recorder.Body.String())
+	assert.Equal(t, "true", recorder.Header().Get(HeaderReplayed))
+}
+
+func Test_Idempotent_Rejected(t *testing.T) {
+
+	errs := map[error]string{
+		idempotencyhelper.ErrKeyReused:  `"errorCode":1054`,
+		idempotencyhelper.ErrInProgress: `"errorCode":1055`,
+	}
+	for err, code := range errs {
+		idempotencyHelper := new(mocks.IIdempotencyHelper)
+		idempotencyHelper.On("Begin", mock.Anything, "104235", "retry-1", mock.Anything).Return(idempotencyhelper.Record{}, false, err)
+
+		recorder, handled := serve(newIdempotency(idempotencyHelper), "request=saveCampaigns&idempotencyKey=retry-1", false)
+
+		assert.Equal(t, 0, handled)
+		assert.Contains(t, recorder.Body.String(), code)
+	}
+}
+
+func Test_Idempotent_Skipped(t *testing.T) {
+
+	idempotencyHelper := new(mocks.IIdempotencyHelper)
+	idempotency := newIdempotency(idempotencyHelper)
+
+	// Reads, requests without a key and unavailable records run as usual
+	_, handled := serve(idempotency, "request=getCampaigns&idempotencyKey=retry-1", false)
+	assert.Equal(t, 1, handled)
+	_, handled = serve(idempotency, "request=saveCampaigns", false)
+	assert.Equal(t, 1, handled)
+	idempotencyHelper.AssertNotCalled(t, "Begin", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	idempotencyHelper.On("Begin", mock.Anything, "104235", "retry-1", mock.Anything).Return(idempotencyhelper.Record{}, false, errors.New("connection refused"))
+	_, handled = serve(idempotency, "request=saveCampaigns&idempotencyKey=retry-1", false)
+	assert.Equal(t, 1, handled)
+}
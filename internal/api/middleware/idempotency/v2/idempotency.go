@@ -0,0 +1,167 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes/v2"
+	response2 "github.com/zdarovich/promotion-api/internal/api/response/v2"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/idempotencyhelper"
+	"github.com/zdarovich/promotion-api/internal/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// HeaderIdempotencyKey header of the idempotency key
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderReplayed header set to the replayed responses
+	HeaderReplayed = "Idempotent-Replayed"
+)
+
+// finishTimeout time the response is stored or the key released in, after
+// the request context may be done
+const finishTimeout = 5 * time.Second
+
+// Methods write methods accepting an idempotency key
+var Methods = map[string]bool{
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+type (
+	// Idempotency struct
+	Idempotency struct {
+		Configuration     *config.Configuration
+		idempotencyHelper idempotencyhelper.IIdempotencyHelper
+	}
+	// IIdempotency interface
+	IIdempotency interface {
+		Idempotent() gin.HandlerFunc
+	}
+	// recorder keeps a copy of the response body
+	recorder struct {
+		gin.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+// New returns configured idempotency
+func New(configuration *config.Configuration) IIdempotency {
+
+	return &Idempotency{
+		Configuration:     configuration,
+		idempotencyHelper: idempotencyhelper.New(configuration),
+	}
+}
+
+// Idempotent runs a write request with an idempotency key once. The response
+// of a successful request is stored and replayed to the requests with the
+// same key, method, path, query and body, a failed request can be retried
+// with the key
+func (idempotency *Idempotency) Idempotent() gin.HandlerFunc {
+
+	return func(context *gin.Context) {
+
+		key := context.GetHeader(HeaderIdempotencyKey)
+		if !idempotency.Configuration.Idempotency.Enabled || key == "" || !Methods[context.Request.Method] {
+			context.Next()
+			return
+		}
+
+		body, err := io.ReadAll(context.Request.Body)
+		if err != nil {
+			response := response2.New(idempotency.Configuration)
+			response.Error(context, http.StatusBadRequest, errorcodes.New("", errorcodes.CodeUnknownRequest))
+			return
+		}
+		context.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := context.Request.Context()
+		c, _ := caller.FromContext(ctx)
+		fingerprint := idempotencyhelper.Fingerprint(
+			context.Request.Method,
+			context.Request.URL.Path,
+			context.Request.URL.RawQuery,
+			string(body),
+		)
+
+		record, replay, err := idempotency.idempotencyHelper.Begin(ctx, c.ClientCode, key, fingerprint)
+		switch {
+		case err == idempotencyhelper.ErrKeyReused:
+			response := response2.New(idempotency.Configuration)
+			response.Error(context, http.StatusUnprocessableEntity, errorcodes.New(HeaderIdempotencyKey, errorcodes.CodeIdempotencyKeyReused))
+			return
+		case err == idempotencyhelper.ErrInProgress:
+			response := response2.New(idempotency.Configuration)
+			response.Error(context, http.StatusConflict, errorcodes.New(HeaderIdempotencyKey, errorcodes.CodeIdempotencyKeyInProgress))
+			return
+		case err != nil:
+			// The request is run without the key while the records are unavailable
+			log.Warn(fmt.Sprintf("idempotency key not checked: %s", err))
+			context.Next()
+			return
+		case replay:
+			context.Header(HeaderReplayed, "true")
+			context.Data(record.Status, record.ContentType, record.Body)
+			context.Abort()
+			return
+		}
+
+		writer := &recorder{ResponseWriter: context.Writer}
+		context.Writer = writer
+		context.Next()
+
+		var stored *idempotencyhelper.Record
+		if writer.Status() >= http.StatusOK && writer.Status() < http.StatusMultipleChoices {
+			stored = &idempotencyhelper.Record{
+				Fingerprint: fingerprint,
+				Status:      writer.Status(),
+				ContentType: writer.Header().Get("Content-Type"),
+				Body:        writer.body.Bytes(),
+			}
+		}
+		idempotency.finish(c.ClientCode, key, stored)
+	}
+}
+
+// finish stores the response of the key, or releases the key for a retry
+// without a response. The request context may be done by now, the records
+// are written on a context of their own
+func (idempotency *Idempotency) finish(clientCode string, key string, record *idempotencyhelper.Record) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	var err error
+	if record == nil {
+		err = idempotency.idempotencyHelper.Release(ctx, clientCode, key)
+	} else {
+		err = idempotency.idempotencyHelper.Complete(ctx, clientCode, key, *record)
+	}
+	if err != nil {
+		log.Warn(fmt.Sprintf("idempotency key not stored: %s", err))
+	}
+}
+
+// Write keeps a copy of the data written to the response
+func (recorder *recorder) Write(data []byte) (int, error) {
+
+	recorder.body.Write(data)
+	return recorder.ResponseWriter.Write(data)
+}
+
+// WriteString keeps a copy of the string written to the response
+func (recorder *recorder) WriteString(s string) (int, error) {
+
+	recorder.body.WriteString(s)
+	return recorder.ResponseWriter.WriteString(s)
+}
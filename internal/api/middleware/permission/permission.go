@@ -0,0 +1,141 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	response2 "github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
+	"github.com/zdarovich/promotion-api/internal/repositories/rights"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Requests rights the requests need. saveCampaigns needs createCampaigns,
+// or editCampaigns when it updates a campaign. A request that is not listed
+// is denied
+var Requests = map[string][]rights.Right{
+	"getCampaigns":            {rights.ViewCampaigns},
+	"getCampaignChanges":      {rights.ViewCampaigns},
+	"saveCampaigns":           {rights.CreateCampaigns},
+	"deleteCampaigns":         {rights.DeleteCampaigns},
+	"getSegments":             {rights.ViewCampaigns},
+	"saveSegments":            {rights.EditCampaigns},
+	"deleteSegments":          {rights.EditCampaigns},
+	"isCustomerEligible":      {rights.ViewCampaigns},
+	"saveCampaignCustomers":   {rights.EditCampaigns},
+	"deleteCampaignCustomers": {rights.EditCampaigns},
+	"getCustomerOffers":       {rights.ViewCampaigns},
+	"createAPIKey":            {rights.ManageAPIKeys},
+	"getAPIKeys":              {rights.ManageAPIKeys},
+	"revokeAPIKey":            {rights.ManageAPIKeys},
+	"saveWebhook":             {rights.ManageWebhooks},
+	"getWebhooks":             {rights.ManageWebhooks},
+	"deleteWebhook":           {rights.ManageWebhooks},
+	"getWebhookDeliveries":    {rights.ManageWebhooks},
+	"retryWebhookDelivery":    {rights.ManageWebhooks},
+}
+
+type (
+	// Permission struct
+	Permission struct {
+		Configuration    *config.Configuration
+		userRepository   user.IRepository
+		rightsRepository rights.IRepository
+	}
+	// IPermission interface
+	IPermission interface {
+		Authorize() gin.HandlerFunc
+	}
+	// IGinContext gin context
+	IGinContext interface {
+		PostForm(key string) string
+	}
+)
+
+// New returns configured permission
+func New(configuration *config.Configuration) IPermission {
+
+	return &Permission{
+		Configuration:    configuration,
+		userRepository:   user.New(configuration),
+		rightsRepository: rights.New(configuration),
+	}
+}
+
+// Authorize lets the request through when the user group of the session
+// has the rights the request needs
+func (permission *Permission) Authorize() gin.HandlerFunc {
+
+	return func(context *gin.Context) {
+
+		err := permission.confirmRights(context.Request.Context(), context)
+
+		if err != nil {
+			response := response2.New(permission.Configuration, context.PostForm("request"))
+			response.Error(context, err)
+			return
+		}
+
+		context.Next()
+	}
+}
+
+// confirmRights returns an error when the user of the session is missing a
+// right of the request
+func (permission *Permission) confirmRights(ctx context.Context, context IGinContext) error {
+
+	request := context.PostForm("request")
+	required, ok := Required(request, context)
+	if !ok {
+		log.Warn(fmt.Sprintf("permission denied: request %s has no rights defined", request))
+		return errors.New(errorcodes.CodeNoViewRights)
+	}
+
+	if c, ok := caller.FromContext(ctx); ok && c.APIKeyID != 0 {
+		// An api key has the rights of its scope
+		missing := apikey.ScopeRights(c.Scope).Missing(required)
+		if len(missing) > 0 {
+			log.Warn(fmt.Sprintf("permission denied: api key %s of scope %s has no %v for request %s", c.APIKey, c.Scope, missing, request))
+			return errors.New(errorcodes.CodeNoViewRights)
+		}
+		return nil
+	}
+
+	u, err := caller.User(ctx, permission.userRepository, context.PostForm("sessionKey"))
+	if err != nil {
+		return errors.New(errorcodes.CodeDatabase)
+	}
+	r, err := permission.rightsRepository.GetRights(ctx, u.GroupID)
+	if err != nil {
+		return errors.New(errorcodes.CodeDatabase)
+	}
+
+	missing := r.Missing(required)
+	if len(missing) > 0 {
+		log.Warn(fmt.Sprintf("permission denied: user %s of group %d has no %v for request %s", u.ShortName, u.GroupID, missing, request))
+		return errors.New(errorcodes.CodeNoViewRights)
+	}
+	return nil
+}
+
+// Required returns the rights the request needs and whether the request is
+// known. saveCampaigns with a campaign id saves a new campaign in place of
+// the campaign, it needs the right to edit as well as to create
+func Required(request string, context IGinContext) ([]rights.Right, bool) {
+
+	required, ok := Requests[request]
+	if request == "saveCampaigns" {
+		if campaignID, _ := strconv.Atoi(context.PostForm("campaignID")); campaignID != 0 {
+			required = []rights.Right{rights.CreateCampaigns, rights.EditCampaigns}
+		}
+	}
+	return required, ok
+}
@@ -0,0 +1,93 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/rights"
+	rightsMocks "github.com/zdarovich/promotion-api/internal/repositories/rights/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+	userMocks "github.com/zdarovich/promotion-api/internal/repositories/user/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type form map[string]string
+
+func (f form) PostForm(key string) string {
+	return f[key]
+}
+
+func newPermission(r rights.Rights, err error) *Permission {
+
+	ur := new(userMocks.IRepository)
+	ur.On("GetUserBySessionKey", mock.Anything, "key").Return(user.User{ShortName: "tester", GroupID: r.GroupID}, nil)
+	rr := new(rightsMocks.IRepository)
+	rr.On("GetRights", mock.Anything, r.GroupID).Return(r, err)
+
+	return &Permission{
+		Configuration:    &config.Configuration{},
+		userRepository:   ur,
+		rightsRepository: rr,
+	}
+}
+
+func Test_New(t *testing.T) {
+
+	permission := New(&config.Configuration{})
+	assert.NotNil(t, permission)
+}
+
+func Test_confirmRights(t *testing.T) {
+
+	permission := newPermission(rights.Rights{GroupID: 1, ViewCampaigns: true}, nil)
+
+	err := permission.confirmRights(context.Background(), form{"request": "getCampaigns", "sessionKey": "key"})
+
+	assert.Nil(t, err)
+}
+
+func Test_confirmRights_MissingRight(t *testing.T) {
+
+	permission := newPermission(rights.Rights{GroupID: 1, ViewCampaigns: true}, nil)
+
+	err := permission.confirmRights(context.Background(), form{"request": "deleteCampaigns", "sessionKey": "key"})
+
+	assert.Equal(t, errors.New(errorcodes.CodeNoViewRights), err)
+}
+
+func Test_confirmRights_UnknownRequest(t *testing.T) {
+
+	permission := newPermission(rights.Rights{GroupID: 1, ViewCampaigns: true}, nil)
+
+	err := permission.confirmRights(context.Background(), form{"request": "dropDatabase", "sessionKey": "key"})
+
+	assert.Equal(t, errors.New(errorcodes.CodeNoViewRights), err)
+}
+
+func Test_confirmRights_RightsFailure(t *testing.T) {
+
+	permission := newPermission(rights.Rights{GroupID: 1}, errors.New("connection refused"))
+
+	err := permission.confirmRights(context.Background(), form{"request": "getCampaigns", "sessionKey": "key"})
+
+	assert.Equal(t, errors.New(errorcodes.CodeDatabase), err)
+}
+
+func Test_Required_SaveCampaigns(t *testing.T) {
+
+	required, ok := Required("saveCampaigns", form{})
+	assert.True(t, ok)
+	assert.Equal(t, []rights.Right{rights.CreateCampaigns}, required)
+
+	required, ok = Required("saveCampaigns", form{"campaignID": "5"})
+	assert.True(t, ok)
+	assert.Equal(t, []rights.Right{rights.CreateCampaigns, rights.EditCampaigns}, required)
+
+	_, ok = Required("unknown", form{})
+	assert.False(t, ok)
+}
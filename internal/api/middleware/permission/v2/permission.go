@@ -0,0 +1,116 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes/v2"
+	response2 "github.com/zdarovich/promotion-api/internal/api/response/v2"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
+	"github.com/zdarovich/promotion-api/internal/repositories/rights"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Methods rights the CRUD routes need per HTTP method. A method that is not
+// listed is denied
+var Methods = map[string][]rights.Right{
+	http.MethodGet:    {rights.ViewCampaigns},
+	http.MethodHead:   {rights.ViewCampaigns},
+	http.MethodPost:   {rights.CreateCampaigns},
+	http.MethodPut:    {rights.EditCampaigns},
+	http.MethodPatch:  {rights.EditCampaigns},
+	http.MethodDelete: {rights.DeleteCampaigns},
+}
+
+type (
+	// Permission struct
+	Permission struct {
+		Configuration    *config.Configuration
+		userRepository   user.IRepository
+		rightsRepository rights.IRepository
+	}
+	// IPermission interface
+	IPermission interface {
+		Authorize() gin.HandlerFunc
+	}
+)
+
+// New returns configured permission
+func New(configuration *config.Configuration) IPermission {
+
+	return &Permission{
+		Configuration:    configuration,
+		userRepository:   user.New(configuration),
+		rightsRepository: rights.New(configuration),
+	}
+}
+
+// Authorize lets the request through when the user group of the session
+// has the rights the route needs
+func (permission *Permission) Authorize() gin.HandlerFunc {
+
+	return func(context *gin.Context) {
+
+		code := permission.confirmRights(
+			context.Request.Context(),
+			context.Request.Method,
+			context.FullPath(),
+			context.GetHeader("sessionKey"),
+		)
+
+		if code != errorcodes.CodeOK {
+			status := http.StatusForbidden
+			if code == errorcodes.CodeDatabase {
+				status = http.StatusInternalServerError
+			}
+			response := response2.New(permission.Configuration)
+			response.Error(context, status, errorcodes.New("", code))
+			return
+		}
+
+		context.Next()
+	}
+}
+
+// confirmRights returns the error code when the user of the session is
+// missing a right of the route
+func (permission *Permission) confirmRights(ctx context.Context, method string, path string, sessionKey string) int {
+
+	required, ok := Methods[method]
+	if !ok {
+		log.Warn(fmt.Sprintf("permission denied: %s %s has no rights defined", method, path))
+		return errorcodes.CodeNoViewRights
+	}
+
+	if c, ok := caller.FromContext(ctx); ok && c.APIKeyID != 0 {
+		// An api key has the rights of its scope
+		missing := apikey.ScopeRights(c.Scope).Missing(required)
+		if len(missing) > 0 {
+			log.Warn(fmt.Sprintf("permission denied: api key %s of scope %s has no %v for %s %s", c.APIKey, c.Scope, missing, method, path))
+			return errorcodes.CodeNoViewRights
+		}
+		return errorcodes.CodeOK
+	}
+
+	u, err := caller.User(ctx, permission.userRepository, sessionKey)
+	if err != nil {
+		return errorcodes.CodeDatabase
+	}
+	r, err := permission.rightsRepository.GetRights(ctx, u.GroupID)
+	if err != nil {
+		return errorcodes.CodeDatabase
+	}
+
+	missing := r.Missing(required)
+	if len(missing) > 0 {
+		log.Warn(fmt.Sprintf("permission denied: user %s of group %d has no %v for %s %s", u.ShortName, u.GroupID, missing, method, path))
+		return errorcodes.CodeNoViewRights
+	}
+	return errorcodes.CodeOK
+}
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	response2 "github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/ratelimithelper"
+	"github.com/zdarovich/promotion-api/internal/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	// RateLimit struct
+	RateLimit struct {
+		Configuration   *config.Configuration
+		rateLimitHelper ratelimithelper.IRateLimitHelper
+	}
+	// IRateLimit interface
+	IRateLimit interface {
+		Limit() gin.HandlerFunc
+	}
+)
+
+// New returns configured rate limit
+func New(configuration *config.Configuration) IRateLimit {
+
+	return &RateLimit{
+		Configuration:   configuration,
+		rateLimitHelper: ratelimithelper.New(configuration),
+	}
+}
+
+// Limit lets the request through when the tenant, the caller and the
+// caller's requests of the type are within their limits. The limit headers
+// are set to the response
+func (rateLimit *RateLimit) Limit() gin.HandlerFunc {
+
+	return func(context *gin.Context) {
+
+		c, _ := caller.FromContext(context.Request.Context())
+		request := context.PostForm("request")
+
+		result, err := rateLimit.rateLimitHelper.Allow(context.Request.Context(), c.ClientCode, c.Key(), request)
+		if err != nil {
+			// The limits are not enforced while the counters are unavailable
+			log.Warn(fmt.Sprintf("rate limit not checked: %s", err))
+		}
+		result.SetHeaders(context.Writer.Header())
+
+		if !result.Allowed {
+			log.Warn(fmt.Sprintf("rate limited: %s of %s over the limit of %d", request, c.ClientCode, result.Limit))
+			response := response2.New(rateLimit.Configuration, request)
+			response.Error(context, errors.New(errorcodes.CodeTooManyRequests))
+			return
+		}
+
+		context.Next()
+	}
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/ratelimithelper"
+	"github.com/zdarovich/promotion-api/internal/helpers/ratelimithelper/mocks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func serve(result ratelimithelper.Result, err error) (*httptest.ResponseRecorder, bool) {
+
+	gin.SetMode(gin.TestMode)
+	rateLimitHelper := new(mocks.IRateLimitHelper)
+	rateLimitHelper.On("Allow", mock.Anything, "104235", "key", "getCampaigns").Return(result, err)
+	rateLimit := &RateLimit{Configuration: &config.Configuration{}, rateLimitHelper: rateLimitHelper}
+
+	handled := false
+	engine := gin.New()
+	engine.Use(func(context *gin.Context) {
+		c := caller.Caller{ClientCode: "104235", SessionKey: "key"}
+		context.Request = context.Request.WithContext(caller.NewContext(context.Request.Context(), c))
+	})
+	engine.Use(rateLimit.Limit())
+	engine.POST("/", func(context *gin.Context) {
+		handled = true
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("request=getCampaigns"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, request)
+	return recorder, handled
+}
+
+func Test_New(t *testing.T) {
+
+	rateLimit := New(&config.Configuration{})
+	assert.NotNil(t, rateLimit)
+}
+
+func Test_Limit_Allowed(t *testing.T) {
+
+	recorder, handled := serve(ratelimithelper.Result{Allowed: true, Limit: 10, Remaining: 9, Reset: 30 * time.Second}, nil)
+
+	assert.True(t, handled)
+	assert.Equal(t, "10", recorder.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "9", recorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "30", recorder.Header().Get("RateLimit-Reset"))
+}
+
+func Test_Limit_Rejected(t *testing.T) {
+
+	recorder, handled := serve(ratelimithelper.Result{Allowed: false, Limit: 10, Reset: 30 * time.Second}, nil)
+
+	assert.False(t, handled)
+	assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+	assert.Contains(t, recorder.Body.String(), `"errorCode":1053`)
+}
+
+func Test_Limit_CacheFailure(t *testing.T) {
+
+	_, handled := serve(ratelimithelper.Result{Allowed: true}, errors.New("connection refused"))
+
+	assert.True(t, handled)
+}
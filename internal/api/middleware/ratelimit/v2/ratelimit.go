@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes/v2"
+	response2 "github.com/zdarovich/promotion-api/internal/api/response/v2"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/ratelimithelper"
+	"github.com/zdarovich/promotion-api/internal/log"
+
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	// RateLimit struct
+	RateLimit struct {
+		Configuration   *config.Configuration
+		rateLimitHelper ratelimithelper.IRateLimitHelper
+	}
+	// IRateLimit interface
+	IRateLimit interface {
+		Limit() gin.HandlerFunc
+	}
+)
+
+// New returns configured rate limit
+func New(configuration *config.Configuration) IRateLimit {
+
+	return &RateLimit{
+		Configuration:   configuration,
+		rateLimitHelper: ratelimithelper.New(configuration),
+	}
+}
+
+// Limit lets the request through when the tenant, the caller and the
+// caller's requests of the route are within their limits. The limit headers
+// are set to the response. The request type of a route is its method and
+// path, e.g. "GET /v1/campaigns"
+func (rateLimit *RateLimit) Limit() gin.HandlerFunc {
+
+	return func(context *gin.Context) {
+
+		c, _ := caller.FromContext(context.Request.Context())
+		request := context.Request.Method + " " + context.FullPath()
+
+		result, err := rateLimit.rateLimitHelper.Allow(context.Request.Context(), c.ClientCode, c.Key(), request)
+		if err != nil {
+			// The limits are not enforced while the counters are unavailable
+			log.Warn(fmt.Sprintf("rate limit not checked: %s", err))
+		}
+		result.SetHeaders(context.Writer.Header())
+
+		if !result.Allowed {
+			log.Warn(fmt.Sprintf("rate limited: %s of %s over the limit of %d", request, c.ClientCode, result.Limit))
+			response := response2.New(rateLimit.Configuration)
+			response.Error(context, http.StatusTooManyRequests, errorcodes.New("", errorcodes.CodeTooManyRequests))
+			return
+		}
+
+		context.Next()
+	}
+}
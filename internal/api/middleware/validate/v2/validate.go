@@ -3,6 +3,7 @@ package validate
 import (
 	"net/http"
 
+	"github.com/zdarovich/promotion-api/internal/api/caller"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes/v2"
 	response2 "github.com/zdarovich/promotion-api/internal/api/response/v2"
 	"github.com/zdarovich/promotion-api/internal/config"
@@ -14,6 +15,7 @@ import (
 const (
 	HeaderClientCode string = "clientCode"
 	HeaderSessionKey string = "sessionKey"
+	HeaderAPIKey     string = "apiKey"
 )
 
 // The list of parameters that are checked when requests come in
@@ -22,6 +24,12 @@ var requiredParameters = []string{
 	HeaderSessionKey,
 }
 
+// The parameters the bearer token of a request replaces
+var tokenParameters = map[string]bool{
+	HeaderClientCode: true,
+	HeaderSessionKey: true,
+}
+
 type (
 	// Validate struct
 	Validate struct {
@@ -47,8 +55,17 @@ func (validate *Validate) RequiredHeaders() gin.HandlerFunc {
 
 	return func(context *gin.Context) {
 
+		_, bearer := caller.FromContext(context.Request.Context())
 		for _, param := range requiredParameters {
 
+			if bearer && tokenParameters[param] {
+				// Set by the bearer token of the request
+				continue
+			}
+			if param == HeaderSessionKey && context.GetHeader(HeaderAPIKey) != "" {
+				// The api key is used in place of the session key
+				continue
+			}
 			if context.GetHeader(param) == "" {
 
 				response := response2.New(validate.Configuration)
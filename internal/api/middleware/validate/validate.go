@@ -1,6 +1,7 @@
 package validate
 
 import (
+	"github.com/zdarovich/promotion-api/internal/api/caller"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	response2 "github.com/zdarovich/promotion-api/internal/api/response"
 	"github.com/zdarovich/promotion-api/internal/config"
@@ -13,6 +14,7 @@ const (
 	ParameterClientCode string = "clientCode"
 	ParameterSessionKey string = "sessionKey"
 	ParameterRequest    string = "request"
+	ParameterAPIKey     string = "apiKey"
 )
 
 // The list of parameters that are checked when requests come in
@@ -22,6 +24,12 @@ var requiredParameters = []string{
 	ParameterRequest,
 }
 
+// The parameters the bearer token of a request replaces
+var tokenParameters = map[string]bool{
+	ParameterClientCode: true,
+	ParameterSessionKey: true,
+}
+
 type (
 	// Validate struct
 	Validate struct {
@@ -47,8 +55,17 @@ func (validate *Validate) RequiredParameters() gin.HandlerFunc {
 
 	return func(context *gin.Context) {
 
+		_, bearer := caller.FromContext(context.Request.Context())
 		for _, param := range requiredParameters {
 
+			if bearer && tokenParameters[param] {
+				// Set by the bearer token of the request
+				continue
+			}
+			if param == ParameterSessionKey && context.PostForm(ParameterAPIKey) != "" {
+				// The api key is used in place of the session key
+				continue
+			}
 			if context.PostForm(param) == "" {
 
 				response := response2.New(validate.Configuration, context.PostForm(ParameterRequest))
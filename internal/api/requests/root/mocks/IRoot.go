@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	root "github.com/zdarovich/promotion-api/internal/api/requests/root"
 	response "github.com/zdarovich/promotion-api/internal/api/response"
@@ -13,13 +15,13 @@ type IRoot struct {
 	mock.Mock
 }
 
-// Handle provides a mock function with given fields: context
-func (_m *IRoot) Handle(context root.IGinContext) (*response.Data, error) {
-	ret := _m.Called(context)
+// Handle provides a mock function with given fields: ctx, _a1
+func (_m *IRoot) Handle(ctx context.Context, _a1 root.IGinContext) (*response.Data, error) {
+	ret := _m.Called(ctx, _a1)
 
 	var r0 *response.Data
-	if rf, ok := ret.Get(0).(func(root.IGinContext) *response.Data); ok {
-		r0 = rf(context)
+	if rf, ok := ret.Get(0).(func(context.Context, root.IGinContext) *response.Data); ok {
+		r0 = rf(ctx, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*response.Data)
@@ -27,8 +29,8 @@ func (_m *IRoot) Handle(context root.IGinContext) (*response.Data, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(root.IGinContext) error); ok {
-		r1 = rf(context)
+	if rf, ok := ret.Get(1).(func(context.Context, root.IGinContext) error); ok {
+		r1 = rf(ctx, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
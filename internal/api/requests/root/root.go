@@ -1,6 +1,8 @@
 package root
 
 import (
+	"context"
+
 	"github.com/zdarovich/promotion-api/internal/api/response"
 	"github.com/zdarovich/promotion-api/internal/config"
 )
@@ -8,7 +10,7 @@ import (
 type (
 	// IRoot interface
 	IRoot interface {
-		Handle(context IGinContext) (*response.Data, error)
+		Handle(ctx context.Context, context IGinContext) (*response.Data, error)
 	}
 	// IGinContext gin context interface
 	IGinContext interface {
@@ -26,7 +28,7 @@ func New(configuration *config.Configuration) IRoot {
 }
 
 // Handle handels root
-func (root *Root) Handle(context IGinContext) (*response.Data, error) {
+func (root *Root) Handle(ctx context.Context, context IGinContext) (*response.Data, error) {
 
 	return &response.Data{}, nil
 }
@@ -1,6 +1,7 @@
 package root
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,7 +24,7 @@ func Test_Handle(t *testing.T) {
 
 	root := Root{}
 
-	data, err := root.Handle(&ginContextMock{})
+	data, err := root.Handle(context.Background(), &ginContextMock{})
 
 	assert.Nil(t, err)
 	assert.NotNil(t, data)
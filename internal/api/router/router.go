@@ -8,7 +8,11 @@ import (
 
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/api/middleware/auth"
+	"github.com/zdarovich/promotion-api/internal/api/middleware/deadline"
 	"github.com/zdarovich/promotion-api/internal/api/middleware/discovery"
+	"github.com/zdarovich/promotion-api/internal/api/middleware/idempotency"
+	"github.com/zdarovich/promotion-api/internal/api/middleware/permission"
+	"github.com/zdarovich/promotion-api/internal/api/middleware/ratelimit"
 	"github.com/zdarovich/promotion-api/internal/api/middleware/validate"
 	"github.com/zdarovich/promotion-api/internal/api/requests/root"
 	"github.com/zdarovich/promotion-api/internal/api/response"
@@ -26,9 +30,13 @@ type (
 	// router router
 	router struct {
 		Middleware struct {
-			Validate  validate.IValidate
-			Discovery discovery.IDiscovery
-			Auth      auth.IAuth
+			Validate    validate.IValidate
+			Deadline    deadline.IDeadline
+			Discovery   discovery.IDiscovery
+			Auth        auth.IAuth
+			RateLimit   ratelimit.IRateLimit
+			Permission  permission.IPermission
+			Idempotency idempotency.IIdempotency
 		}
 		Configuration *config.Configuration
 		Response      response.IResponse
@@ -51,13 +59,21 @@ func New(configuration *config.Configuration, handlers map[string]root.IRoot) IR
 
 	return &router{
 		Middleware: struct {
-			Validate  validate.IValidate
-			Discovery discovery.IDiscovery
-			Auth      auth.IAuth
+			Validate    validate.IValidate
+			Deadline    deadline.IDeadline
+			Discovery   discovery.IDiscovery
+			Auth        auth.IAuth
+			RateLimit   ratelimit.IRateLimit
+			Permission  permission.IPermission
+			Idempotency idempotency.IIdempotency
 		}{
-			Validate:  validate.New(configuration),
-			Discovery: discovery.New(configuration),
-			Auth:      auth.New(configuration),
+			Validate:    validate.New(configuration),
+			Deadline:    deadline.New(configuration),
+			Discovery:   discovery.New(configuration),
+			Auth:        auth.New(configuration),
+			RateLimit:   ratelimit.New(configuration),
+			Permission:  permission.New(configuration),
+			Idempotency: idempotency.New(configuration),
 		},
 		Configuration: configuration,
 		Handlers:      handlers,
@@ -93,9 +109,14 @@ func (apiRouter *router) GetEngine() IGINEngine {
 	// thus all requests here need to be authenticated
 	apiV1Group := router.Group("/api/v1")
 
+	apiV1Group.Use(apiRouter.Middleware.Auth.Bearer())
 	apiV1Group.Use(apiRouter.Middleware.Validate.RequiredParameters())
+	apiV1Group.Use(apiRouter.Middleware.Deadline.Deadline())
 	apiV1Group.Use(apiRouter.Middleware.Discovery.Discover())
 	apiV1Group.Use(apiRouter.Middleware.Auth.Authenticate())
+	apiV1Group.Use(apiRouter.Middleware.RateLimit.Limit())
+	apiV1Group.Use(apiRouter.Middleware.Permission.Authorize())
+	apiV1Group.Use(apiRouter.Middleware.Idempotency.Idempotent())
 
 	apiV1Group.POST("*any", apiRouter.handlePostRequest)
 
@@ -145,8 +166,19 @@ func (apiRouter *router) handlePostRequest(context *gin.Context) {
 		apiRouter.handleUnknownRequest(context)
 		return
 	} else {
-		data, err := handler.Handle(context)
-
+		ctx := context.Request.Context()
+		data, err := handler.Handle(ctx, context)
+
+		// The request ran out of time or the client went away, whatever the
+		// handler returned is incomplete. A write that succeeded is kept for
+		// the retry with its idempotency key
+		if ctx.Err() != nil {
+			if err == nil {
+				context.Set(idempotency.KeyHandled, data)
+			}
+			apiRouter.Response.Error(context, errors.New(errorcodes.CodeTimeout))
+			return
+		}
 		if err != nil {
 			apiRouter.Response.Error(context, err)
 			return
@@ -170,7 +202,7 @@ func (apiRouter *router) handlePostRequest(context *gin.Context) {
 func (apiRouter *router) handleRoot(context *gin.Context) {
 
 	apiRouter.startStatus("Root")
-	data, _ := apiRouter.Root.Handle(context)
+	data, _ := apiRouter.Root.Handle(context.Request.Context(), context)
 
 	apiRouter.Response.OK(context, data)
 }
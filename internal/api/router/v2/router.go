@@ -5,7 +5,11 @@ import (
 
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes/v2"
 	"github.com/zdarovich/promotion-api/internal/api/middleware/auth/v2"
+	"github.com/zdarovich/promotion-api/internal/api/middleware/deadline/v2"
 	"github.com/zdarovich/promotion-api/internal/api/middleware/discovery/v2"
+	"github.com/zdarovich/promotion-api/internal/api/middleware/idempotency/v2"
+	"github.com/zdarovich/promotion-api/internal/api/middleware/permission/v2"
+	"github.com/zdarovich/promotion-api/internal/api/middleware/ratelimit/v2"
 	"github.com/zdarovich/promotion-api/internal/api/middleware/validate/v2"
 	"github.com/zdarovich/promotion-api/internal/api/response/v2"
 	"github.com/zdarovich/promotion-api/internal/config"
@@ -22,19 +26,25 @@ type (
 	// router router
 	router struct {
 		Middleware struct {
-			Validate  validate.IValidate
-			Discovery discovery.IDiscovery
-			Auth      auth.IAuth
+			Validate    validate.IValidate
+			Deadline    deadline.IDeadline
+			Discovery   discovery.IDiscovery
+			Auth        auth.IAuth
+			RateLimit   ratelimit.IRateLimit
+			Permission  permission.IPermission
+			Idempotency idempotency.IIdempotency
 		}
 		Configuration *config.Configuration
 		Response      response.IResponse
 		CRUDHandlers  []Route
 	}
-	// Route struct
+	// Route struct, a stream route responds for as long as the client stays
+	// connected and has no deadline
 	Route struct {
 		Method      string
 		Pattern     string
 		HandlerFunc gin.HandlerFunc
+		Stream      bool
 	}
 	// IRouter irouter
 	IRouter interface {
@@ -57,13 +67,21 @@ func New(configuration *config.Configuration, handlers []Route) IRouter {
 
 	return &router{
 		Middleware: struct {
-			Validate  validate.IValidate
-			Discovery discovery.IDiscovery
-			Auth      auth.IAuth
+			Validate    validate.IValidate
+			Deadline    deadline.IDeadline
+			Discovery   discovery.IDiscovery
+			Auth        auth.IAuth
+			RateLimit   ratelimit.IRateLimit
+			Permission  permission.IPermission
+			Idempotency idempotency.IIdempotency
 		}{
-			Validate:  validate.New(configuration),
-			Discovery: discovery.New(configuration),
-			Auth:      auth.New(configuration),
+			Validate:    validate.New(configuration),
+			Deadline:    deadline.New(configuration),
+			Discovery:   discovery.New(configuration),
+			Auth:        auth.New(configuration),
+			RateLimit:   ratelimit.New(configuration),
+			Permission:  permission.New(configuration),
+			Idempotency: idempotency.New(configuration),
 		},
 		Configuration: configuration,
 		CRUDHandlers:  handlers,
@@ -89,17 +107,35 @@ func (apiRouter *router) GetEngine() IGINEngine {
 	// thus all requests here need to be authenticated
 	apiV1Group := router.Group("/v1")
 
+	apiV1Group.Use(apiRouter.Middleware.Auth.Bearer())
 	apiV1Group.Use(apiRouter.Middleware.Validate.RequiredHeaders())
-	apiV1Group.Use(apiRouter.Middleware.Discovery.Discover())
-	apiV1Group.Use(apiRouter.Middleware.Auth.Authenticate())
+
+	// The requests of a route have its deadline, streams have none
+	requestGroup := apiRouter.use(apiV1Group.Group("", apiRouter.Middleware.Deadline.Deadline()))
+	streamGroup := apiRouter.use(apiV1Group.Group(""))
 
 	for _, route := range apiRouter.CRUDHandlers {
-		apiV1Group.Handle(route.Method, route.Pattern, route.HandlerFunc)
+		group := requestGroup
+		if route.Stream {
+			group = streamGroup
+		}
+		group.Handle(route.Method, route.Pattern, route.HandlerFunc)
 	}
 
 	return router
 }
 
+// use adds the middleware that follows the deadline to the group
+func (apiRouter *router) use(group *gin.RouterGroup) *gin.RouterGroup {
+
+	group.Use(apiRouter.Middleware.Discovery.Discover())
+	group.Use(apiRouter.Middleware.Auth.Authenticate())
+	group.Use(apiRouter.Middleware.RateLimit.Limit())
+	group.Use(apiRouter.Middleware.Permission.Authorize())
+	group.Use(apiRouter.Middleware.Idempotency.Idempotent())
+	return group
+}
+
 // configure internal function to set the path and the type
 // of the logger and all other configurations
 func (apiRouter *router) configure() {
@@ -0,0 +1,80 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+// Drivers of the message bus
+const (
+	NATS   = "nats"
+	Kafka  = "kafka"
+	Memory = "memory"
+)
+
+// Defaults of the events configuration
+const (
+	DefaultTopic   = "promotions.campaigns"
+	DefaultTimeout = 10 * time.Second
+)
+
+type (
+	// IBus publishes the messages to the topic of the message bus
+	IBus interface {
+		Publish(ctx context.Context, m Message) error
+		Close() error
+	}
+	// Message a message of the bus. Consumers deduplicate the messages
+	// published again by their ID, the messages of the same Key are
+	// published in order
+	Message struct {
+		ID    string
+		Key   string
+		Event string
+		Body  []byte
+	}
+)
+
+// New returns the bus of the configured driver
+func New(configuration *config.Configuration) (IBus, error) {
+
+	topic := configuration.Events.Topic
+	if topic == "" {
+		topic = DefaultTopic
+	}
+	timeout := DefaultTimeout
+	if configuration.Events.Timeout > 0 {
+		timeout = time.Duration(configuration.Events.Timeout) * time.Second
+	}
+
+	switch configuration.Events.Driver {
+	case NATS:
+		if configuration.Events.NATS.URL == "" {
+			return nil, fmt.Errorf("events: nats url not set")
+		}
+		return &NATSBus{
+			URL:      configuration.Events.NATS.URL,
+			Token:    configuration.Events.NATS.Token,
+			User:     configuration.Events.NATS.User,
+			Password: configuration.Events.NATS.Password,
+			Subject:  topic,
+			Timeout:  timeout,
+		}, nil
+	case Kafka:
+		if configuration.Events.Kafka.URL == "" {
+			return nil, fmt.Errorf("events: kafka url not set")
+		}
+		return &KafkaBus{
+			URL:    configuration.Events.Kafka.URL,
+			Topic:  topic,
+			Client: &http.Client{Timeout: timeout},
+		}, nil
+	case Memory:
+		return NewMemory(), nil
+	}
+	return nil, fmt.Errorf("events: unsupported bus driver %q", configuration.Events.Driver)
+}
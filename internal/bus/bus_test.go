@@ -0,0 +1,48 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	configuration := &config.Configuration{}
+
+	configuration.Events.Driver = NATS
+	_, err := New(configuration)
+	assert.NotNil(t, err)
+	configuration.Events.NATS.URL = "nats://127.0.0.1:4222"
+	b, err := New(configuration)
+	require.Nil(t, err)
+	assert.Equal(t, DefaultTopic, b.(*NATSBus).Subject)
+
+	configuration.Events.Driver = Kafka
+	configuration.Events.Topic = "campaigns"
+	configuration.Events.Kafka.URL = "http://127.0.0.1:8082"
+	b, err = New(configuration)
+	require.Nil(t, err)
+	assert.Equal(t, "campaigns", b.(*KafkaBus).Topic)
+
+	configuration.Events.Driver = "rabbitmq"
+	_, err = New(configuration)
+	assert.NotNil(t, err)
+}
+
+func TestMemoryBus(t *testing.T) {
+	b := NewMemory()
+
+	assert.Nil(t, b.Publish(context.Background(), Message{ID: "1", Key: "campaign.4"}))
+	b.Fail(errors.New("unavailable"))
+	assert.NotNil(t, b.Publish(context.Background(), Message{ID: "2", Key: "campaign.4"}))
+	b.Fail(nil)
+	assert.Nil(t, b.Publish(context.Background(), Message{ID: "3", Key: "campaign.5"}))
+
+	messages := b.Messages()
+	require.Len(t, messages, 2)
+	assert.Equal(t, "3", messages[1].ID)
+}
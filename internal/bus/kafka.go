@@ -0,0 +1,94 @@
+package bus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// KafkaContentType content type of the records of the REST proxy
+const KafkaContentType = "application/vnd.kafka.json.v2+json"
+
+type (
+	// KafkaBus publishes to a Kafka topic through the Kafka REST proxy. The
+	// messages are keyed, the messages of the same key go to the same
+	// partition and keep their order
+	KafkaBus struct {
+		URL    string
+		Topic  string
+		Client *http.Client
+	}
+	kafkaRecord struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+	}
+	kafkaRequest struct {
+		Records []kafkaRecord `json:"records"`
+	}
+	kafkaResponse struct {
+		Offsets []struct {
+			Partition int     `json:"partition"`
+			Offset    int64   `json:"offset"`
+			ErrorCode *int    `json:"error_code"`
+			Error     *string `json:"error"`
+		} `json:"offsets"`
+	}
+)
+
+// Publish produces the message to the topic, it is published once the proxy
+// has the offset of the record
+func (kafkaBus *KafkaBus) Publish(ctx context.Context, m Message) error {
+
+	body, err := json.Marshal(kafkaRequest{Records: []kafkaRecord{{Key: m.Key, Value: m.Body}}})
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		strings.TrimRight(kafkaBus.URL, "/")+"/topics/"+url.PathEscape(kafkaBus.Topic), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", KafkaContentType)
+	request.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	response, err := kafkaBus.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka rest proxy responded %s", response.Status)
+	}
+
+	var produced kafkaResponse
+	err = json.Unmarshal(responseBody, &produced)
+	if err != nil {
+		return err
+	}
+	if len(produced.Offsets) != 1 {
+		return fmt.Errorf("kafka rest proxy returned %d offsets", len(produced.Offsets))
+	}
+	if offset := produced.Offsets[0]; offset.ErrorCode != nil {
+		message := ""
+		if offset.Error != nil {
+			message = *offset.Error
+		}
+		return fmt.Errorf("kafka rest proxy error %d: %s", *offset.ErrorCode, message)
+	}
+	return nil
+}
+
+// Close does nothing, the requests do not keep a connection
+func (kafkaBus *KafkaBus) Close() error {
+	return nil
+}
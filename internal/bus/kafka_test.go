@@ -0,0 +1,53 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaBus_Publish(t *testing.T) {
+	var path, contentType string
+	var produced kafkaRequest
+	response := `{"offsets":[{"partition":2,"offset":17,"error_code":null,"error":null}]}`
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &produced)
+		w.Write([]byte(response))
+	}))
+	defer proxy.Close()
+	b := &KafkaBus{URL: proxy.URL + "/", Topic: "promotions.campaigns", Client: proxy.Client()}
+
+	err := b.Publish(context.Background(), Message{ID: "104235-1", Key: "104235.campaign.4", Body: []byte(`{"event":"campaign.created"}`)})
+
+	require.Nil(t, err)
+	assert.Equal(t, "/topics/promotions.campaigns", path)
+	assert.Equal(t, KafkaContentType, contentType)
+	require.Len(t, produced.Records, 1)
+	assert.Equal(t, "104235.campaign.4", produced.Records[0].Key)
+	assert.JSONEq(t, `{"event":"campaign.created"}`, string(produced.Records[0].Value))
+
+	response = `{"offsets":[{"partition":null,"offset":null,"error_code":50301,"error":"leader not available"}]}`
+	err = b.Publish(context.Background(), Message{Key: "104235.campaign.4", Body: []byte(`{}`)})
+	assert.EqualError(t, err, "kafka rest proxy error 50301: leader not available")
+}
+
+func TestKafkaBus_PublishFailure(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer proxy.Close()
+	b := &KafkaBus{URL: proxy.URL, Topic: "missing", Client: proxy.Client()}
+
+	err := b.Publish(context.Background(), Message{Body: []byte(`{}`)})
+
+	assert.EqualError(t, err, "kafka rest proxy responded 404 Not Found")
+}
@@ -0,0 +1,55 @@
+package bus
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryBus in-memory bus keeping the published messages, safe for
+// concurrent use
+type MemoryBus struct {
+	mutex    sync.RWMutex
+	messages []Message
+	err      error
+}
+
+// NewMemory returns new empty in-memory bus
+func NewMemory() *MemoryBus {
+
+	return &MemoryBus{}
+}
+
+// Publish keeps the message, or returns the error set with Fail
+func (memoryBus *MemoryBus) Publish(ctx context.Context, m Message) error {
+	memoryBus.mutex.Lock()
+	defer memoryBus.mutex.Unlock()
+
+	if memoryBus.err != nil {
+		return memoryBus.err
+	}
+	memoryBus.messages = append(memoryBus.messages, m)
+	return nil
+}
+
+// Close does nothing
+func (memoryBus *MemoryBus) Close() error {
+	return nil
+}
+
+// Fail makes the publishing fail with the error, nil publishes again
+func (memoryBus *MemoryBus) Fail(err error) {
+	memoryBus.mutex.Lock()
+	defer memoryBus.mutex.Unlock()
+
+	memoryBus.err = err
+}
+
+// Messages returns the published messages in order
+func (memoryBus *MemoryBus) Messages() []Message {
+	memoryBus.mutex.RLock()
+	defer memoryBus.mutex.RUnlock()
+
+	messages := make([]Message, len(memoryBus.messages))
+	copy(messages, memoryBus.messages)
+	return messages
+}
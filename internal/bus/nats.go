@@ -0,0 +1,317 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// NATSBus publishes to a NATS subject captured by a JetStream stream
+	// over the NATS client protocol. A message is published with a reply
+	// subject of the inbox of the connection and it is published once the
+	// stream has acknowledged it. The ID is sent as the Nats-Msg-Id header,
+	// the stream deduplicates the messages published again
+	NATSBus struct {
+		URL      string
+		Token    string
+		User     string
+		Password string
+		Subject  string
+		Timeout  time.Duration
+		mutex    sync.Mutex
+		conn     net.Conn
+		reader   *bufio.Reader
+		headers  bool
+		inbox    string
+		sequence uint64
+	}
+	natsInfo struct {
+		Headers bool `json:"headers"`
+	}
+	natsConnect struct {
+		Verbose   bool   `json:"verbose"`
+		Pedantic  bool   `json:"pedantic"`
+		Headers   bool   `json:"headers"`
+		Name      string `json:"name"`
+		Lang      string `json:"lang"`
+		Protocol  int    `json:"protocol"`
+		AuthToken string `json:"auth_token,omitempty"`
+		User      string `json:"user,omitempty"`
+		Pass      string `json:"pass,omitempty"`
+		// NoResponders the server answers a publication to a subject
+		// without a stream with the status 503
+		NoResponders bool `json:"no_responders"`
+	}
+	// natsPubAck acknowledgement of JetStream to a publication
+	natsPubAck struct {
+		Stream    string `json:"stream"`
+		Sequence  uint64 `json:"seq"`
+		Duplicate bool   `json:"duplicate"`
+		Error     *struct {
+			Code        int    `json:"code"`
+			Description string `json:"description"`
+		} `json:"error"`
+	}
+)
+
+// ErrNoStream the subject is not captured by a JetStream stream
+var ErrNoStream = errors.New("nats: no JetStream stream on the subject")
+
+// Publish publishes the message, the connection is opened again after a
+// failure
+func (natsBus *NATSBus) Publish(ctx context.Context, m Message) error {
+	natsBus.mutex.Lock()
+	defer natsBus.mutex.Unlock()
+
+	err := natsBus.publish(ctx, m)
+	if err != nil && natsBus.conn != nil {
+		natsBus.conn.Close()
+		natsBus.conn = nil
+	}
+	return err
+}
+
+// Close closes the connection
+func (natsBus *NATSBus) Close() error {
+	natsBus.mutex.Lock()
+	defer natsBus.mutex.Unlock()
+
+	if natsBus.conn == nil {
+		return nil
+	}
+	err := natsBus.conn.Close()
+	natsBus.conn = nil
+	return err
+}
+
+func (natsBus *NATSBus) publish(ctx context.Context, m Message) error {
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(natsBus.Timeout)
+	}
+	if natsBus.conn == nil {
+		err := natsBus.connect(ctx, deadline)
+		if err != nil {
+			return err
+		}
+	}
+	err := natsBus.conn.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	// Each publication has its own reply subject, an acknowledgement of an
+	// earlier publication is not taken for the one of this message
+	natsBus.sequence++
+	reply := natsBus.inbox + "." + strconv.FormatUint(natsBus.sequence, 10)
+
+	var frame strings.Builder
+	if natsBus.headers {
+		headers := "NATS/1.0\r\nNats-Msg-Id: " + m.ID + "\r\nPromotion-Event: " + m.Event + "\r\nPromotion-Key: " + m.Key + "\r\n\r\n"
+		fmt.Fprintf(&frame, "HPUB %s %s %d %d\r\n%s", natsBus.Subject, reply, len(headers), len(headers)+len(m.Body), headers)
+	} else {
+		fmt.Fprintf(&frame, "PUB %s %s %d\r\n", natsBus.Subject, reply, len(m.Body))
+	}
+	frame.Write(m.Body)
+	frame.WriteString("\r\n")
+
+	_, err = natsBus.conn.Write([]byte(frame.String()))
+	if err != nil {
+		return err
+	}
+	return natsBus.ack(reply)
+}
+
+// connect opens the connection and sends the CONNECT of the client
+func (natsBus *NATSBus) connect(ctx context.Context, deadline time.Time) error {
+
+	u, err := url.Parse(natsBus.URL)
+	if err != nil {
+		return err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	natsBus.conn = conn
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+	natsBus.reader = bufio.NewReader(conn)
+
+	line, err := natsBus.reader.ReadString('\n')
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("nats: unexpected greeting %q", strings.TrimSpace(line))
+	}
+	var info natsInfo
+	err = json.Unmarshal([]byte(strings.TrimSpace(line[5:])), &info)
+	if err != nil {
+		return err
+	}
+	natsBus.headers = info.Headers
+
+	if u.Scheme == "tls" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		natsBus.conn = tlsConn
+		natsBus.reader = bufio.NewReader(tlsConn)
+	}
+
+	connect := natsConnect{
+		Headers:      info.Headers,
+		Name:         "promotion-api",
+		Lang:         "go",
+		Protocol:     1,
+		AuthToken:    natsBus.Token,
+		User:         natsBus.User,
+		Pass:         natsBus.Password,
+		NoResponders: info.Headers,
+	}
+	if u.User != nil {
+		connect.User = u.User.Username()
+		connect.Pass, _ = u.User.Password()
+	}
+	options, err := json.Marshal(connect)
+	if err != nil {
+		return err
+	}
+	token := make([]byte, 12)
+	_, err = rand.Read(token)
+	if err != nil {
+		return err
+	}
+	natsBus.inbox = "_INBOX." + hex.EncodeToString(token)
+	natsBus.sequence = 0
+
+	_, err = natsBus.conn.Write([]byte("CONNECT " + string(options) + "\r\nSUB " + natsBus.inbox + ".* 1\r\nPING\r\n"))
+	if err != nil {
+		return err
+	}
+	return natsBus.pong()
+}
+
+// ack reads the server messages up to the acknowledgement of JetStream sent
+// to the reply subject
+func (natsBus *NATSBus) ack(reply string) error {
+
+	for {
+		line, err := natsBus.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "PING":
+			_, err = natsBus.conn.Write([]byte("PONG\r\n"))
+			if err != nil {
+				return err
+			}
+		case fields[0] == "-ERR":
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "-ERR")))
+		case fields[0] == "MSG" || fields[0] == "HMSG":
+			headers, body, err := natsBus.message(fields)
+			if err != nil {
+				return err
+			}
+			if fields[1] != reply {
+				continue
+			}
+			return pubAck(headers, body)
+		}
+	}
+}
+
+// message reads the headers and the payload of a MSG or of a HMSG
+func (natsBus *NATSBus) message(fields []string) ([]byte, []byte, error) {
+
+	headerSize := 0
+	size, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return nil, nil, fmt.Errorf("nats: unexpected message %q", strings.Join(fields, " "))
+	}
+	if fields[0] == "HMSG" {
+		headerSize, err = strconv.Atoi(fields[len(fields)-2])
+		if err != nil || headerSize > size {
+			return nil, nil, fmt.Errorf("nats: unexpected message %q", strings.Join(fields, " "))
+		}
+	}
+	payload := make([]byte, size+2)
+	_, err = io.ReadFull(natsBus.reader, payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return payload[:headerSize], payload[headerSize:size], nil
+}
+
+// pubAck returns the error of the acknowledgement of JetStream
+func pubAck(headers []byte, body []byte) error {
+
+	// A status in the headers, 503 when no stream captures the subject
+	status := strings.Fields(strings.SplitN(string(headers), "\r\n", 2)[0])
+	if len(status) > 1 {
+		if status[1] == "503" {
+			return ErrNoStream
+		}
+		return fmt.Errorf("nats: %s", strings.Join(status[1:], " "))
+	}
+
+	var ack natsPubAck
+	err := json.Unmarshal(body, &ack)
+	if err != nil {
+		return fmt.Errorf("nats: unexpected acknowledgement %q", body)
+	}
+	if ack.Error != nil {
+		return fmt.Errorf("nats: %s (%d)", ack.Error.Description, ack.Error.Code)
+	}
+	if ack.Stream == "" {
+		return fmt.Errorf("nats: unexpected acknowledgement %q", body)
+	}
+	return nil
+}
+
+// pong reads the server messages up to the PONG of the PING sent
+func (natsBus *NATSBus) pong() error {
+
+	for {
+		line, err := natsBus.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			_, err = natsBus.conn.Write([]byte("PONG\r\n"))
+			if err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package bus
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// natsServer local stand-in of a NATS server with a JetStream stream, it
+// sends the publications it reads to the channel and acknowledges them on
+// their reply subject. A publication to the subject "denied" is answered
+// with an error, one to "unknown" with no responders and one to "full" with
+// a JetStream error
+type natsServer struct {
+	listener     net.Listener
+	connects     chan string
+	publications chan string
+	replies      chan string
+}
+
+func newNATSServer(t *testing.T, headers bool) *natsServer {
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	s := &natsServer{listener: listener, connects: make(chan string, 10), publications: make(chan string, 10), replies: make(chan string, 10)}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, headers)
+		}
+	}()
+	return s
+}
+
+func (s *natsServer) serve(conn net.Conn, headers bool) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	conn.Write([]byte(`INFO {"server_id":"test","headers":` + strconv.FormatBool(headers) + "}\r\n"))
+	sequence := 0
+	inbox := ""
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case fields[0] == "CONNECT":
+			s.connects <- strings.TrimSpace(line)
+		case fields[0] == "SUB":
+			inbox = strings.TrimSuffix(fields[1], "*")
+		case fields[0] == "PING":
+			conn.Write([]byte("PONG\r\n"))
+		case fields[0] == "PUB" || fields[0] == "HPUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			io.ReadFull(reader, payload)
+			reply := fields[2]
+			if fields[1] == "denied" {
+				conn.Write([]byte("-ERR 'Permissions Violation for Publish to denied'\r\n"))
+				return
+			}
+			s.replies <- reply
+			s.publications <- strings.Join(append(fields[:2:2], fields[3:]...), " ") + "\n" + string(payload[:size])
+			if !strings.HasPrefix(reply, inbox) {
+				continue
+			}
+
+			// A message to another reply subject first, it is not the
+			// acknowledgement of the publication
+			conn.Write([]byte("MSG " + inbox + "0 1 2\r\n{}\r\n"))
+			switch fields[1] {
+			case "unknown":
+				conn.Write([]byte("HMSG " + reply + " 1 16 16\r\nNATS/1.0 503\r\n\r\n\r\n"))
+			case "full":
+				ack := `{"error":{"code":503,"err_code":10077,"description":"maximum messages exceeded"}}`
+				conn.Write([]byte("MSG " + reply + " 1 " + strconv.Itoa(len(ack)) + "\r\n" + ack + "\r\n"))
+			default:
+				sequence++
+				ack := `{"stream":"PROMOTIONS","seq":` + strconv.Itoa(sequence) + `}`
+				conn.Write([]byte("MSG " + reply + " 1 " + strconv.Itoa(len(ack)) + "\r\n" + ack + "\r\n"))
+			}
+		}
+	}
+}
+
+func TestNATSBus_Publish(t *testing.T) {
+	s := newNATSServer(t, true)
+	b := &NATSBus{URL: "nats://publisher:secret@" + s.listener.Addr().String(), Subject: "promotions.campaigns", Timeout: time.Second}
+	defer b.Close()
+
+	err := b.Publish(context.Background(), Message{ID: "104235-1", Key: "104235.campaign.4", Event: "campaign.created", Body: []byte(`{"id":1}`)})
+	require.Nil(t, err)
+	err = b.Publish(context.Background(), Message{ID: "104235-2", Key: "104235.campaign.4", Event: "campaign.deleted", Body: []byte(`{"id":2}`)})
+	require.Nil(t, err)
+
+	connect := <-s.connects
+	assert.Contains(t, connect, `"headers":true`)
+	assert.Contains(t, connect, `"user":"publisher","pass":"secret"`)
+	assert.Contains(t, connect, `"no_responders":true`)
+	assert.Len(t, s.connects, 0, "the connection is kept")
+
+	first, second := <-s.replies, <-s.replies
+	assert.True(t, strings.HasPrefix(first, "_INBOX."))
+	assert.NotEqual(t, first, second, "each publication has its own reply subject")
+
+	headers := "NATS/1.0\r\nNats-Msg-Id: 104235-1\r\nPromotion-Event: campaign.created\r\nPromotion-Key: 104235.campaign.4\r\n\r\n"
+	assert.Equal(t, "HPUB promotions.campaigns "+strconv.Itoa(len(headers))+" "+strconv.Itoa(len(headers)+8)+"\n"+headers+`{"id":1}`, <-s.publications)
+	assert.Contains(t, <-s.publications, `{"id":2}`)
+}
+
+func TestNATSBus_PublishWithoutHeaders(t *testing.T) {
+	s := newNATSServer(t, false)
+	b := &NATSBus{URL: "nats://" + s.listener.Addr().String(), Subject: "promotions.campaigns", Timeout: time.Second}
+	defer b.Close()
+
+	require.Nil(t, b.Publish(context.Background(), Message{ID: "104235-1", Body: []byte(`{"id":1}`)}))
+
+	assert.Equal(t, "PUB promotions.campaigns 8\n"+`{"id":1}`, <-s.publications)
+}
+
+func TestNATSBus_PublishError(t *testing.T) {
+	s := newNATSServer(t, true)
+	b := &NATSBus{URL: "nats://" + s.listener.Addr().String(), Subject: "denied", Timeout: time.Second}
+	defer b.Close()
+
+	err := b.Publish(context.Background(), Message{ID: "104235-1", Body: []byte(`{}`)})
+	assert.EqualError(t, err, "nats: 'Permissions Violation for Publish to denied'")
+
+	// The connection is opened again
+	b.Subject = "promotions.campaigns"
+	require.Nil(t, b.Publish(context.Background(), Message{ID: "104235-1", Body: []byte(`{}`)}))
+	assert.Len(t, s.connects, 2)
+}
+
+func TestNATSBus_PublishNotAcknowledged(t *testing.T) {
+	s := newNATSServer(t, true)
+	b := &NATSBus{URL: "nats://" + s.listener.Addr().String(), Subject: "unknown", Timeout: time.Second}
+	defer b.Close()
+
+	err := b.Publish(context.Background(), Message{ID: "104235-1", Body: []byte(`{}`)})
+	assert.Equal(t, ErrNoStream, err)
+
+	b.Subject = "full"
+	err = b.Publish(context.Background(), Message{ID: "104235-1", Body: []byte(`{}`)})
+	assert.EqualError(t, err, "nats: maximum messages exceeded (503)")
+}
+
+func TestNATSBus_Unreachable(t *testing.T) {
+	b := &NATSBus{URL: "nats://127.0.0.1:1", Subject: "promotions.campaigns", Timeout: time.Second}
+
+	assert.NotNil(t, b.Publish(context.Background(), Message{Body: []byte(`{}`)}))
+}
@@ -1,6 +1,7 @@
 package redis
 
 import (
+	"context"
 	"time"
 
 	"github.com/zdarovich/promotion-api/internal/config"
@@ -8,6 +9,9 @@ import (
 	"github.com/go-redis/redis"
 )
 
+// Nil error of Get when the key does not exist
+const Nil = redis.Nil
+
 type (
 	// Redis struct
 	Redis struct {
@@ -16,10 +20,13 @@ type (
 	}
 	// IRedis interface
 	IRedis interface {
-		Exists(key string) (interface{}, error)
-		Get(key string) (interface{}, error)
-		Set(key string, value interface{}) error
-		SetX(key string, value interface{}, ttl time.Duration) error
+		Exists(ctx context.Context, key string) (interface{}, error)
+		Get(ctx context.Context, key string) (interface{}, error)
+		Set(ctx context.Context, key string, value interface{}) error
+		SetX(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+		Del(ctx context.Context, key string) error
+		Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+		SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
 	}
 )
 
@@ -42,29 +49,55 @@ func New(configuration *config.Configuration) IRedis {
 }
 
 // Exists returns true or false depending if the key exists
-func (r *Redis) Exists(key string) (interface{}, error) {
+func (r *Redis) Exists(ctx context.Context, key string) (interface{}, error) {
 
-	result := r.Client.Exists(key)
+	result := r.Client.WithContext(ctx).Exists(key)
 	return result.Val(), result.Err()
 }
 
 // Get returns the value of the key from the redis cache
-func (r *Redis) Get(key string) (interface{}, error) {
+func (r *Redis) Get(ctx context.Context, key string) (interface{}, error) {
 
-	result := r.Client.Get(key)
+	result := r.Client.WithContext(ctx).Get(key)
 	return result.Val(), result.Err()
 }
 
 // Set sets the new value to database
-func (r *Redis) Set(key string, value interface{}) error {
+func (r *Redis) Set(ctx context.Context, key string, value interface{}) error {
 
-	res := r.Client.Set(key, value, 0)
+	res := r.Client.WithContext(ctx).Set(key, value, 0)
 	return res.Err()
 }
 
 // SetX sets the new value to database with a timeout
-func (r *Redis) SetX(key string, value interface{}, ttl time.Duration) error {
+func (r *Redis) SetX(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+
+	res := r.Client.WithContext(ctx).Set(key, value, ttl)
+	return res.Err()
+}
+
+// Del removes the key from the database
+func (r *Redis) Del(ctx context.Context, key string) error {
 
-	res := r.Client.Set(key, value, ttl)
+	res := r.Client.WithContext(ctx).Del(key)
 	return res.Err()
 }
+
+// Incr increments the counter of the key and sets its timeout, a missing key
+// is incremented from 0
+func (r *Redis) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+
+	pipe := r.Client.WithContext(ctx).TxPipeline()
+	incr := pipe.Incr(key)
+	pipe.Expire(key, ttl)
+	_, err := pipe.Exec()
+	return incr.Val(), err
+}
+
+// SetNX sets the new value with a timeout when the key does not exist and
+// returns whether it was set
+func (r *Redis) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+
+	res := r.Client.WithContext(ctx).SetNX(key, value, ttl)
+	return res.Val(), res.Err()
+}
@@ -19,6 +19,7 @@ type (
 		LogRotateFiles     int    `yaml:"logRotateFiles"`
 		LogDebugMode       bool   `yaml:"logDebugMode"`
 		Port               int    `yaml:"port"`
+		PortV2             int    `yaml:"portV2"`
 		Database           struct {
 			Discovery struct {
 				Enabled bool   `yaml:"enabled"`
@@ -31,6 +32,19 @@ type (
 			Name     string `yaml:"name"`
 			Server   string `yaml:"server"`
 			Port     int    `yaml:"port"`
+			SSLMode  string `yaml:"sslMode"`
+			// Replicas are the read replicas of the database, read-only
+			// queries are spread over the healthy ones
+			Replicas    []Replica `yaml:"replicas"`
+			Replication struct {
+				MaxLag         int `yaml:"maxLag"`
+				CheckInterval  int `yaml:"checkInterval"`
+				RetryAfter     int `yaml:"retryAfter"`
+				ReadYourWrites int `yaml:"readYourWrites"`
+			} `yaml:"replication"`
+			// Session is the session key of the current request, its reads go
+			// to the primary for a while after it has written
+			Session string `yaml:"-"`
 		} `yaml:"database"`
 		Redis struct {
 			Server       string `yaml:"server"`
@@ -44,7 +58,104 @@ type (
 			Server  string `yaml:"server"`
 			Timeout int    `yaml:"timeout"`
 			Token   string `yaml:"token"`
+			// JWT verifies the bearer tokens of the identity service locally
+			JWT struct {
+				Enabled    bool     `yaml:"enabled"`
+				PublicKeys []string `yaml:"publicKeys"`
+				JWKS       string   `yaml:"jwks"`
+				Issuer     string   `yaml:"issuer"`
+				Audience   string   `yaml:"audience"`
+				Leeway     int      `yaml:"leeway"`
+			} `yaml:"jwt"`
 		} `yaml:"identity"`
+		// Session caches the resolved sessions and their users in redis for
+		// CacheTTL seconds, 0 disables the cache
+		Session struct {
+			CacheTTL int `yaml:"cacheTTL"`
+		} `yaml:"session"`
+		Standalone struct {
+			Enabled  bool   `yaml:"enabled"`
+			Database string `yaml:"database"`
+			Fixtures string `yaml:"fixtures"`
+		} `yaml:"standalone"`
+		// RateLimit requests allowed per window of the tenant, of a caller
+		// and of a caller per request type, counted in redis. Tenants
+		// override the limits per client code
+		RateLimit struct {
+			Enabled    bool `yaml:"enabled"`
+			Window     int  `yaml:"window"`
+			RateLimits `yaml:",inline"`
+			Tenants    map[string]RateLimits `yaml:"tenants"`
+		} `yaml:"rateLimit"`
+		// Idempotency keeps the response of a write request with an
+		// idempotency key for Window seconds and replays it to duplicates
+		Idempotency struct {
+			Enabled bool `yaml:"enabled"`
+			Window  int  `yaml:"window"`
+		} `yaml:"idempotency"`
+		// Webhooks sends the campaign events to the webhooks of the tenants.
+		// The deliveries due are sent every Interval seconds, a failed
+		// delivery is retried MaxAttempts times with a backoff doubling from
+		// RetryBackoff seconds. Endpoints on the internal network are refused
+		// unless AllowInternal is set
+		Webhooks struct {
+			Enabled       bool `yaml:"enabled"`
+			Interval      int  `yaml:"interval"`
+			Timeout       int  `yaml:"timeout"`
+			MaxAttempts   int  `yaml:"maxAttempts"`
+			RetryBackoff  int  `yaml:"retryBackoff"`
+			AllowInternal bool `yaml:"allowInternal"`
+		} `yaml:"webhooks"`
+		// Events records the campaign changes in the outbox of the tenant
+		// and publishes them to the Topic of the message bus every Interval
+		// seconds. Driver is nats, kafka or memory, published messages are
+		// kept Retention seconds
+		Events struct {
+			Enabled   bool   `yaml:"enabled"`
+			Driver    string `yaml:"driver"`
+			Topic     string `yaml:"topic"`
+			Interval  int    `yaml:"interval"`
+			Timeout   int    `yaml:"timeout"`
+			BatchSize int    `yaml:"batchSize"`
+			Retention int    `yaml:"retention"`
+			NATS      struct {
+				URL      string `yaml:"url"`
+				Token    string `yaml:"token"`
+				User     string `yaml:"user"`
+				Password string `yaml:"password"`
+			} `yaml:"nats"`
+			// Kafka is reached through its REST proxy
+			Kafka struct {
+				URL string `yaml:"url"`
+			} `yaml:"kafka"`
+		} `yaml:"events"`
+		// Stream sends the campaign events of the outbox to the clients of
+		// the campaign stream of the v2 router. The outbox is read every
+		// Interval seconds and a heartbeat is sent every Heartbeat seconds,
+		// a client more than BufferSize events behind is disconnected
+		Stream struct {
+			Interval   int `yaml:"interval"`
+			Heartbeat  int `yaml:"heartbeat"`
+			BufferSize int `yaml:"bufferSize"`
+		} `yaml:"stream"`
+		// RequestTimeout seconds a request may take, per request type
+		RequestTimeout struct {
+			Default  int            `yaml:"default"`
+			Requests map[string]int `yaml:"requests"`
+		} `yaml:"requestTimeout"`
+	}
+	// RateLimits requests allowed per window, 0 is unlimited. In a tenant
+	// override 0 keeps the default limit and a negative limit is unlimited
+	RateLimits struct {
+		Tenant   int            `yaml:"tenant"`
+		Caller   int            `yaml:"caller"`
+		Requests map[string]int `yaml:"requests"`
+	}
+	// Replica read replica of the database, it has the credentials and the
+	// name of the primary
+	Replica struct {
+		Server string `yaml:"server"`
+		Port   int    `yaml:"port"`
 	}
 )
 
@@ -0,0 +1,208 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+// Supported database drivers
+const (
+	MySQL    = "mysql"
+	Postgres = "postgres"
+	SQLite   = "sqlite3"
+)
+
+type (
+	// IDialect differences of the SQL databases the repositories run on.
+	// Queries are written with ? placeholders and rebound for the driver
+	IDialect interface {
+		Name() string
+		DSN(configuration *config.Configuration) string
+		Rebind(query string) string
+		Paginate(records int, page int) (string, []interface{})
+		InsertID(ctx context.Context, db NamedExecQueryer, query string, arg interface{}) (int64, error)
+		InsertIgnore(query string) string
+		Upsert(query string, keys []string, columns []string) string
+		ReplicationLag() []string
+	}
+	// NamedExecQueryer is implemented by sqlx.DB and sqlx.Tx
+	NamedExecQueryer interface {
+		NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+		PrepareNamedContext(ctx context.Context, query string) (*sqlx.NamedStmt, error)
+	}
+	mysql    struct{}
+	postgres struct{}
+	sqlite   struct{}
+)
+
+// Get returns the dialect of the driver
+func Get(driver string) (IDialect, error) {
+	switch driver {
+	case MySQL:
+		return mysql{}, nil
+	case Postgres:
+		return postgres{}, nil
+	case SQLite:
+		return sqlite{}, nil
+	}
+	return nil, fmt.Errorf("unsupported database driver %q", driver)
+}
+
+// For returns the dialect of the configured driver, MySQL by default
+func For(configuration *config.Configuration) IDialect {
+	if configuration == nil {
+		return mysql{}
+	}
+	d, err := Get(configuration.Database.Driver)
+	if err != nil {
+		return mysql{}
+	}
+	return d
+}
+
+func (mysql) Name() string { return MySQL }
+
+func (mysql) DSN(configuration *config.Configuration) string {
+	return fmt.Sprintf(
+		"%s:%s@tcp(%s:%d)/%s?parseTime=true",
+		configuration.Database.Username,
+		configuration.Database.Password,
+		configuration.Database.Server,
+		configuration.Database.Port,
+		configuration.Database.Name,
+	)
+}
+
+func (mysql) Rebind(query string) string { return query }
+
+func (mysql) Paginate(records int, page int) (string, []interface{}) {
+	return " LIMIT ? OFFSET ?", []interface{}{records, records * page}
+}
+
+func (mysql) InsertID(ctx context.Context, db NamedExecQueryer, query string, arg interface{}) (int64, error) {
+	return lastInsertID(ctx, db, query, arg)
+}
+
+func (mysql) InsertIgnore(query string) string {
+	return strings.Replace(query, "INSERT INTO", "INSERT IGNORE INTO", 1)
+}
+
+func (mysql) Upsert(query string, keys []string, columns []string) string {
+	updates := make([]string, len(columns))
+	for idx, column := range columns {
+		updates[idx] = column + " = VALUES(" + column + ")"
+	}
+	return query + " ON DUPLICATE KEY UPDATE " + strings.Join(updates, ", ")
+}
+
+// ReplicationLag SHOW REPLICA STATUS since MySQL 8.0.22, SHOW SLAVE STATUS
+// before, it is removed in 8.4. The Seconds_Behind_Source column, or
+// Seconds_Behind_Master, is NULL when the replication is not running
+func (mysql) ReplicationLag() []string {
+	return []string{"SHOW REPLICA STATUS", "SHOW SLAVE STATUS"}
+}
+
+func (postgres) Name() string { return Postgres }
+
+func (postgres) DSN(configuration *config.Configuration) string {
+	sslMode := configuration.Database.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(configuration.Database.Username, configuration.Database.Password),
+		Host:     fmt.Sprintf("%s:%d", configuration.Database.Server, configuration.Database.Port),
+		Path:     "/" + configuration.Database.Name,
+		RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+func (postgres) Rebind(query string) string { return sqlx.Rebind(sqlx.DOLLAR, query) }
+
+func (postgres) Paginate(records int, page int) (string, []interface{}) {
+	return " LIMIT ? OFFSET ?", []interface{}{records, records * page}
+}
+
+// InsertID returns the id of the inserted row with RETURNING, the driver does
+// not support LastInsertId
+func (postgres) InsertID(ctx context.Context, db NamedExecQueryer, query string, arg interface{}) (int64, error) {
+	stmt, err := db.PrepareNamedContext(ctx, query+" RETURNING id")
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var id int64
+	err = stmt.QueryRowxContext(ctx, arg).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (postgres) InsertIgnore(query string) string { return onConflictDoNothing(query) }
+
+func (postgres) Upsert(query string, keys []string, columns []string) string {
+	return onConflictUpdate(query, keys, columns)
+}
+
+// ReplicationLag a replica that has replayed all it has received is not
+// behind however long ago the last transaction was
+func (postgres) ReplicationLag() []string {
+	return []string{"SELECT CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 " +
+		"ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END AS seconds_behind_source"}
+}
+
+func (sqlite) Name() string { return SQLite }
+
+// DSN the database name is the path of the database file
+func (sqlite) DSN(configuration *config.Configuration) string {
+	return fmt.Sprintf("file:%s?_busy_timeout=5000", configuration.Database.Name)
+}
+
+func (sqlite) Rebind(query string) string { return query }
+
+func (sqlite) Paginate(records int, page int) (string, []interface{}) {
+	return " LIMIT ? OFFSET ?", []interface{}{records, records * page}
+}
+
+func (sqlite) InsertID(ctx context.Context, db NamedExecQueryer, query string, arg interface{}) (int64, error) {
+	return lastInsertID(ctx, db, query, arg)
+}
+
+func (sqlite) InsertIgnore(query string) string { return onConflictDoNothing(query) }
+
+func (sqlite) Upsert(query string, keys []string, columns []string) string {
+	return onConflictUpdate(query, keys, columns)
+}
+
+// ReplicationLag the database file has no replicas
+func (sqlite) ReplicationLag() []string { return nil }
+
+func lastInsertID(ctx context.Context, db NamedExecQueryer, query string, arg interface{}) (int64, error) {
+	res, err := db.NamedExecContext(ctx, query, arg)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+func onConflictDoNothing(query string) string {
+	return query + " ON CONFLICT DO NOTHING"
+}
+
+func onConflictUpdate(query string, keys []string, columns []string) string {
+	updates := make([]string, len(columns))
+	for idx, column := range columns {
+		updates[idx] = column + " = excluded." + column
+	}
+	return query + " ON CONFLICT (" + strings.Join(keys, ", ") + ") DO UPDATE SET " + strings.Join(updates, ", ")
+}
@@ -0,0 +1,201 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+// LockName name of the MySQL user lock held while migrating
+const LockName = "promotion_api_schema_migrations"
+
+// LockKey key of the PostgreSQL advisory lock held while migrating
+const LockKey = 7242019
+
+// lockPollInterval interval of the PostgreSQL advisory lock attempts
+var lockPollInterval = 100 * time.Millisecond
+
+type (
+	// Database migrated tenant database. All statements run on a single
+	// connection because MySQL user locks and PostgreSQL advisory locks
+	// belong to the connection. SQLite has no named locks, a migration
+	// applied by two processes at once fails in one of them and its
+	// transaction is rolled back
+	Database struct {
+		DB     *sqlx.DB
+		Conn   *sql.Conn
+		Driver string
+	}
+	// IDatabase interface
+	IDatabase interface {
+		Lock(timeout int) (bool, error)
+		Unlock() error
+		CreateVersionTable() error
+		GetVersions() (map[int]bool, error)
+		Apply(m Migration, direction string) error
+		Close() error
+	}
+	execer interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	}
+)
+
+// Open connects to the configured database
+func Open(configuration *config.Configuration) (IDatabase, error) {
+	mysql := &sqlx2.Mysql{Configuration: configuration}
+	err := mysql.Connect()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := mysql.DB.Conn(context.Background())
+	if err != nil {
+		mysql.DB.Close()
+		return nil, err
+	}
+
+	return &Database{
+		DB:     mysql.DB,
+		Conn:   conn,
+		Driver: dialect.For(configuration).Name(),
+	}, nil
+}
+
+// Lock acquires the migration lock, waiting at most timeout seconds
+func (database *Database) Lock(timeout int) (bool, error) {
+	ctx := context.Background()
+	if database.Driver == dialect.SQLite {
+		return true, nil
+	}
+	if database.Driver != dialect.Postgres {
+		var locked sql.NullInt64
+		err := database.Conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", LockName, timeout).Scan(&locked)
+		if err != nil {
+			return false, err
+		}
+		return locked.Valid && locked.Int64 == 1, nil
+	}
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		var locked bool
+		err := database.Conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", LockKey).Scan(&locked)
+		if err != nil || locked || time.Now().After(deadline) {
+			return locked, err
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// Unlock releases the migration lock
+func (database *Database) Unlock() error {
+	if database.Driver == dialect.SQLite {
+		return nil
+	}
+	query := "DO RELEASE_LOCK(?)"
+	var arg interface{} = LockName
+	if database.Driver == dialect.Postgres {
+		query = "SELECT pg_advisory_unlock($1)"
+		arg = LockKey
+	}
+	_, err := database.Conn.ExecContext(context.Background(), query, arg)
+	return err
+}
+
+// CreateVersionTable creates the table of the applied migrations
+func (database *Database) CreateVersionTable() error {
+	_, err := database.Conn.ExecContext(context.Background(), "CREATE TABLE IF NOT EXISTS schema_migrations ("+
+		"version integer NOT NULL, "+
+		"name varchar(255) NOT NULL, "+
+		"applied integer NOT NULL, "+
+		"PRIMARY KEY (version))")
+	return err
+}
+
+// GetVersions returns the versions of the applied migrations
+func (database *Database) GetVersions() (map[int]bool, error) {
+	ctx := context.Background()
+	versions := make(map[int]bool)
+
+	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = 'schema_migrations'"
+	switch database.Driver {
+	case dialect.Postgres:
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'"
+	case dialect.SQLite:
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"
+	}
+	var tables int
+	err := database.Conn.QueryRowContext(ctx, query).Scan(&tables)
+	if err != nil || tables == 0 {
+		return versions, err
+	}
+
+	rows, err := database.Conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		err := rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+		versions[version] = true
+	}
+	return versions, rows.Err()
+}
+
+// Apply runs the statements of the migration and records it. PostgreSQL
+// and SQLite apply them in a transaction, a failed migration leaves no
+// trace. MySQL commits every DDL statement on its own
+func (database *Database) Apply(m Migration, direction string) error {
+	ctx := context.Background()
+	if database.Driver == dialect.MySQL {
+		return database.apply(ctx, database.Conn, m, direction)
+	}
+
+	tx, err := database.Conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	err = database.apply(ctx, tx, m, direction)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+	return tx.Commit()
+}
+
+func (database *Database) apply(ctx context.Context, db execer, m Migration, direction string) error {
+	statements := m.Up
+	if direction == Down {
+		statements = m.Down
+	}
+	for _, statement := range statements {
+		_, err := db.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if direction == Down {
+		_, err = db.ExecContext(ctx, database.DB.Rebind("DELETE FROM schema_migrations WHERE version = ?"), m.Version)
+	} else {
+		_, err = db.ExecContext(ctx, database.DB.Rebind("INSERT INTO schema_migrations (version, name, applied) VALUES (?, ?, ?)"),
+			m.Version, m.Name, time.Now().Unix())
+	}
+	return err
+}
+
+// Close closes the connection
+func (database *Database) Close() error {
+	database.Conn.Close()
+	return database.DB.Close()
+}
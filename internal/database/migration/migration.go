@@ -0,0 +1,231 @@
+package migration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Directions of a migration
+const (
+	Up   = "up"
+	Down = "down"
+)
+
+//go:embed migrations
+var files embed.FS
+
+// ErrLocked another process is migrating the database
+var ErrLocked = errors.New("schema migrations are locked by another process")
+
+type (
+	// Migration versioned schema change. Statements are run in order, MySQL
+	// commits DDL implicitly so statements should be safe to run again.
+	// Every driver has its own migrations with the same versions
+	Migration struct {
+		Version int
+		Name    string
+		Up      []string
+		Down    []string
+	}
+	// Migrator struct
+	Migrator struct {
+		Database    IDatabase
+		Migrations  []Migration
+		LockTimeout int
+	}
+	// IMigrator interface
+	IMigrator interface {
+		Pending() ([]Migration, error)
+		Up(dryRun bool) ([]Migration, error)
+		Down(steps int, dryRun bool) ([]Migration, error)
+	}
+)
+
+// New returns migrator of the database with the embedded migrations of the driver
+func New(database IDatabase, driver string, lockTimeout int) (IMigrator, error) {
+	migrations, err := Load(files, "migrations/"+driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		Database:    database,
+		Migrations:  migrations,
+		LockTimeout: lockTimeout,
+	}, nil
+}
+
+// Load reads the migrations from the files of the directory named
+// <version>_<name>.<up|down>.sql and returns them ordered by version
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	names, err := fs.Glob(fsys, dir+"/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no migrations in %s", dir)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, name := range names {
+		base := strings.TrimSuffix(strings.TrimPrefix(name, dir+"/"), ".sql")
+		dot := strings.LastIndex(base, ".")
+		underscore := strings.Index(base, "_")
+		if dot < 0 || underscore < 0 || underscore > dot {
+			return nil, fmt.Errorf("invalid migration file name %s", name)
+		}
+		version, err := strconv.Atoi(base[:underscore])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version %s", name)
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: base[underscore+1 : dot]}
+			byVersion[version] = m
+		} else if m.Name != base[underscore+1:dot] {
+			return nil, fmt.Errorf("migration version %d used by %s and %s", version, m.Name, base[underscore+1:dot])
+		}
+		switch base[dot+1:] {
+		case Up:
+			m.Up = Statements(string(content))
+		case Down:
+			m.Down = Statements(string(content))
+		default:
+			return nil, fmt.Errorf("invalid migration direction %s", name)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if len(m.Up) == 0 {
+			return nil, fmt.Errorf("migration %d_%s has no up statements", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Statements splits the SQL script into statements, dropping comment lines
+func Statements(script string) []string {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var statements []string
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";") {
+		statement = strings.TrimSpace(statement)
+		if statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// Pending returns the migrations that have not been applied yet
+func (migrator *Migrator) Pending() ([]Migration, error) {
+	applied, err := migrator.Database.GetVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]Migration, 0)
+	for _, m := range migrator.Migrations {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up applies the pending migrations in version order and returns them. In
+// dry run mode the migrations are only returned
+func (migrator *Migrator) Up(dryRun bool) ([]Migration, error) {
+	var pending []Migration
+	err := migrator.locked(func() error {
+		var err error
+		pending, err = migrator.Pending()
+		if err != nil || dryRun {
+			return err
+		}
+
+		err = migrator.Database.CreateVersionTable()
+		if err != nil {
+			return err
+		}
+		for idx, m := range pending {
+			err = migrator.Database.Apply(m, Up)
+			if err != nil {
+				pending = pending[:idx]
+				return fmt.Errorf("migration %d_%s: %v", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+	return pending, err
+}
+
+// Down rolls back the latest applied migrations and returns them. In dry run
+// mode the migrations are only returned
+func (migrator *Migrator) Down(steps int, dryRun bool) ([]Migration, error) {
+	var rollback []Migration
+	err := migrator.locked(func() error {
+		applied, err := migrator.Database.GetVersions()
+		if err != nil {
+			return err
+		}
+
+		for idx := len(migrator.Migrations) - 1; idx >= 0 && len(rollback) < steps; idx-- {
+			if applied[migrator.Migrations[idx].Version] {
+				rollback = append(rollback, migrator.Migrations[idx])
+			}
+		}
+		if dryRun {
+			return nil
+		}
+
+		for idx, m := range rollback {
+			if len(m.Down) == 0 {
+				rollback = rollback[:idx]
+				return fmt.Errorf("migration %d_%s has no down statements", m.Version, m.Name)
+			}
+			err = migrator.Database.Apply(m, Down)
+			if err != nil {
+				rollback = rollback[:idx]
+				return fmt.Errorf("migration %d_%s: %v", m.Version, m.Name, err)
+			}
+		}
+		return nil
+	})
+	return rollback, err
+}
+
+// locked runs the function holding the migration lock of the database
+func (migrator *Migrator) locked(f func() error) error {
+	ok, err := migrator.Database.Lock(migrator.LockTimeout)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLocked
+	}
+	defer migrator.Database.Unlock()
+
+	return f()
+}
@@ -0,0 +1,205 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+type databaseMock struct {
+	locked   bool
+	applied  map[int]bool
+	calls    []string
+	failOn   int
+	unlocked bool
+}
+
+func (d *databaseMock) Lock(timeout int) (bool, error) { return !d.locked, nil }
+func (d *databaseMock) Unlock() error {
+	d.unlocked = true
+	return nil
+}
+func (d *databaseMock) CreateVersionTable() error {
+	d.calls = append(d.calls, "create")
+	return nil
+}
+func (d *databaseMock) GetVersions() (map[int]bool, error) {
+	versions := make(map[int]bool)
+	for v := range d.applied {
+		versions[v] = true
+	}
+	return versions, nil
+}
+func (d *databaseMock) Apply(m Migration, direction string) error {
+	if m.Version == d.failOn {
+		return errors.New("1003")
+	}
+	d.calls = append(d.calls, direction+" "+m.Name)
+	if direction == Up {
+		d.applied[m.Version] = true
+	} else {
+		delete(d.applied, m.Version)
+	}
+	return nil
+}
+func (d *databaseMock) Close() error { return nil }
+
+var testMigrations = []Migration{
+	{Version: 1, Name: "one", Up: []string{"CREATE TABLE one (id int)"}, Down: []string{"DROP TABLE one"}},
+	{Version: 2, Name: "two", Up: []string{"CREATE TABLE two (id int)"}, Down: []string{"DROP TABLE two"}},
+	{Version: 3, Name: "three", Up: []string{"CREATE TABLE three (id int)"}},
+}
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load(fstest.MapFS{
+		"migrations/0002_two.up.sql":   {Data: []byte("-- second\nCREATE TABLE two (id int);\nCREATE TABLE three (id int);\n")},
+		"migrations/0001_one.up.sql":   {Data: []byte("CREATE TABLE one (id int);")},
+		"migrations/0001_one.down.sql": {Data: []byte("DROP TABLE one;")},
+	}, "migrations")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "one", Up: []string{"CREATE TABLE one (id int)"}, Down: []string{"DROP TABLE one"}},
+		{Version: 2, Name: "two", Up: []string{"CREATE TABLE two (id int)", "CREATE TABLE three (id int)"}},
+	}, migrations)
+}
+
+func TestLoad_InvalidFiles(t *testing.T) {
+	_, err := Load(fstest.MapFS{"migrations/one.up.sql": {Data: []byte("SELECT 1")}}, "migrations")
+	assert.NotNil(t, err)
+
+	_, err = Load(fstest.MapFS{"migrations/0001_one.sideways.sql": {Data: []byte("SELECT 1")}}, "migrations")
+	assert.NotNil(t, err)
+
+	_, err = Load(fstest.MapFS{"migrations/0001_one.down.sql": {Data: []byte("SELECT 1")}}, "migrations")
+	assert.NotNil(t, err)
+
+	_, err = Load(fstest.MapFS{
+		"migrations/0001_one.up.sql": {Data: []byte("SELECT 1")},
+		"migrations/0001_two.up.sql": {Data: []byte("SELECT 1")},
+	}, "migrations")
+	assert.NotNil(t, err)
+
+	_, err = Load(fstest.MapFS{}, "migrations/sqlserver")
+	assert.NotNil(t, err)
+}
+
+func TestLoad_Embedded(t *testing.T) {
+	mysql, err := Load(files, "migrations/mysql")
+	assert.Nil(t, err)
+	assert.NotEmpty(t, mysql)
+	for idx, m := range mysql {
+		assert.Equal(t, idx+1, m.Version)
+		assert.NotEmpty(t, m.Down, m.Name)
+	}
+
+	for _, driver := range []string{"postgres", "sqlite3"} {
+		migrations, err := Load(files, "migrations/"+driver)
+		assert.Nil(t, err)
+		assert.Equal(t, len(mysql), len(migrations), driver)
+		for idx, m := range migrations {
+			assert.Equal(t, mysql[idx].Version, m.Version)
+			assert.Equal(t, mysql[idx].Name, m.Name)
+			assert.NotEmpty(t, m.Down, m.Name)
+		}
+	}
+}
+
+func TestMigrator_Up(t *testing.T) {
+	d := &databaseMock{applied: map[int]bool{1: true}}
+	m := &Migrator{Database: d, Migrations: testMigrations}
+
+	applied, err := m.Up(false)
+
+	assert.Nil(t, err)
+	assert.Equal(t, testMigrations[1:], applied)
+	assert.Equal(t, []string{"create", "up two", "up three"}, d.calls)
+	assert.True(t, d.unlocked)
+}
+
+func TestMigrator_Up_DryRun(t *testing.T) {
+	d := &databaseMock{applied: map[int]bool{}}
+	m := &Migrator{Database: d, Migrations: testMigrations}
+
+	pending, err := m.Up(true)
+
+	assert.Nil(t, err)
+	assert.Equal(t, testMigrations, pending)
+	assert.Empty(t, d.calls)
+}
+
+func TestMigrator_Up_Failure(t *testing.T) {
+	d := &databaseMock{applied: map[int]bool{}, failOn: 2}
+	m := &Migrator{Database: d, Migrations: testMigrations}
+
+	applied, err := m.Up(false)
+
+	assert.EqualError(t, err, "migration 2_two: 1003")
+	assert.Equal(t, testMigrations[:1], applied)
+	assert.True(t, d.unlocked)
+}
+
+func TestMigrator_Up_Locked(t *testing.T) {
+	d := &databaseMock{applied: map[int]bool{}, locked: true}
+	m := &Migrator{Database: d, Migrations: testMigrations}
+
+	_, err := m.Up(false)
+
+	assert.Equal(t, ErrLocked, err)
+	assert.Empty(t, d.calls)
+	assert.False(t, d.unlocked)
+}
+
+func TestMigrator_Down(t *testing.T) {
+	d := &databaseMock{applied: map[int]bool{1: true, 2: true}}
+	m := &Migrator{Database: d, Migrations: testMigrations}
+
+	rolledBack, err := m.Down(5, false)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Migration{testMigrations[1], testMigrations[0]}, rolledBack)
+	assert.Equal(t, []string{"down two", "down one"}, d.calls)
+	assert.Empty(t, d.applied)
+}
+
+func TestMigrator_Down_NoDownStatements(t *testing.T) {
+	d := &databaseMock{applied: map[int]bool{1: true, 2: true, 3: true}}
+	m := &Migrator{Database: d, Migrations: testMigrations}
+
+	rolledBack, err := m.Down(1, true)
+	assert.Nil(t, err)
+	assert.Equal(t, testMigrations[2:], rolledBack)
+
+	_, err = m.Down(1, false)
+	assert.EqualError(t, err, "migration 3_three has no down statements")
+	assert.Empty(t, d.calls)
+}
+
+func TestDatabase_Apply_SQLite_RollsBackFailedMigration(t *testing.T) {
+	configuration := &config.Configuration{}
+	configuration.Database.Driver = "sqlite3"
+	configuration.Database.Name = t.TempDir() + "/migrations.db"
+	database, err := Open(configuration)
+	require.Nil(t, err)
+	defer database.Close()
+	require.Nil(t, database.CreateVersionTable())
+
+	err = database.Apply(Migration{Version: 1, Name: "one", Up: []string{
+		"CREATE TABLE one (id int)",
+		"INSERT INTO missing VALUES (1)",
+	}}, Up)
+	assert.NotNil(t, err)
+
+	// Neither the table nor the version is left behind
+	versions, err := database.GetVersions()
+	assert.Nil(t, err)
+	assert.Empty(t, versions)
+	assert.Nil(t, database.Apply(Migration{Version: 1, Name: "one", Up: []string{"CREATE TABLE one (id int)"}}, Up))
+	versions, err = database.GetVersions()
+	assert.Nil(t, err)
+	assert.Equal(t, map[int]bool{1: true}, versions)
+}
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `campaign_tier` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `campaign_id` int(11) NOT NULL,
+  `position` int(11) NOT NULL,
+  `purchased_amount` int(11) NOT NULL,
+  `purchase_total_value` decimal(15,2) NOT NULL,
+  `percentage_off` decimal(5,2) NOT NULL,
+  `sum_off` decimal(15,2) NOT NULL,
+  `special_price` decimal(15,2) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `campaign_id` (`campaign_id`)
+) ENGINE=InnoDB;
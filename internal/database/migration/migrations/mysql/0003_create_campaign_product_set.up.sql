@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `campaign_product_set` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `campaign_id` int(11) NOT NULL,
+  `position` int(11) NOT NULL,
+  `name` varchar(50) NOT NULL,
+  `amount` int(11) NOT NULL,
+  `products` text NOT NULL,
+  `prodgroup_id` int(11) NOT NULL,
+  `prodcategory_id` int(11) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `campaign_id` (`campaign_id`)
+) ENGINE=InnoDB;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `campaign_schedule` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `campaign_id` int(11) NOT NULL,
+  `position` int(11) NOT NULL,
+  `days_of_week` varchar(20) NOT NULL,
+  `days_of_month` varchar(255) NOT NULL,
+  `weeks_of_month` varchar(20) NOT NULL,
+  `start_time` varchar(5) NOT NULL,
+  `end_time` varchar(5) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `campaign_id` (`campaign_id`)
+) ENGINE=InnoDB;
@@ -0,0 +1 @@
+ALTER TABLE `campaign` MODIFY `start_date` date NOT NULL, MODIFY `end_date` date NOT NULL;
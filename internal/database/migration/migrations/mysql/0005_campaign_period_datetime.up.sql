@@ -0,0 +1,6 @@
+-- Campaign periods are stored as UTC instants. A date ends with its last
+-- second. The existing dates are kept as the wall-clock time of the tenant,
+-- with a timezone other than UTC they are shifted to UTC after the migration
+ALTER TABLE `campaign` MODIFY `start_date` datetime NOT NULL, MODIFY `end_date` datetime NOT NULL;
+-- Only the dates still at midnight are moved, a run again does not move them twice
+UPDATE `campaign` SET `end_date` = `end_date` + INTERVAL 1 DAY - INTERVAL 1 SECOND WHERE TIME(`end_date`) = '00:00:00';
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS `customer_segment` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(100) NOT NULL,
+  `description` varchar(255) NOT NULL,
+  `added` int(11) NOT NULL,
+  `addedby` varchar(50) NOT NULL,
+  `changed` int(11) NOT NULL,
+  `changedby` varchar(50) NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `customer_segment_rule` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `segment_id` int(11) NOT NULL,
+  `position` int(11) NOT NULL,
+  `attribute` varchar(50) NOT NULL,
+  `operator` varchar(20) NOT NULL,
+  `value` varchar(255) NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `segment_id` (`segment_id`)
+) ENGINE=InnoDB;
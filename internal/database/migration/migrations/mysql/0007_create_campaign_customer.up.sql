@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `campaign_customer` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `campaign_id` int(11) NOT NULL,
+  `customer_id` int(11) NOT NULL,
+  `expires` int(11) NOT NULL DEFAULT 0,
+  `added` int(11) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `campaign_customer` (`campaign_id`, `customer_id`),
+  KEY `customer_id` (`customer_id`)
+) ENGINE=InnoDB;
@@ -0,0 +1,83 @@
+CREATE TABLE IF NOT EXISTS `campaign_settings` (
+  `campaign_id` int(11) NOT NULL,
+  `awarded_brand_id` int(11) NOT NULL DEFAULT 0,
+  `discount_for_one_line` int(11) NOT NULL DEFAULT 0,
+  `required_coupon_id` varchar(255) NOT NULL DEFAULT '',
+  `required_coupon_code` varchar(255) NOT NULL DEFAULT '',
+  `awarded_amount` int(11) NOT NULL DEFAULT 0,
+  `purchased_product_category_id` int(11) NOT NULL DEFAULT 0,
+  `awarded_product_category_id` int(11) NOT NULL DEFAULT 0,
+  `maximum_points_discount` int(11) NOT NULL DEFAULT 0,
+  `customer_can_use_only_once` int(11) NOT NULL DEFAULT 0,
+  `price_at_least` int(11) NOT NULL DEFAULT 0,
+  `price_at_most` int(11) NOT NULL DEFAULT 0,
+  `requires_manager_override` int(11) NOT NULL DEFAULT 0,
+  `sum_off_matching_items` int(11) NOT NULL DEFAULT 0,
+  `exclude_discounted_from_percentage_off_entire_purchase` int(11) NOT NULL DEFAULT 0,
+  `exclude_promotion_items_from_percentage_off_entire_purchase` int(11) NOT NULL DEFAULT 0,
+  `reason_id` int(11) NOT NULL DEFAULT 0,
+  `special_unit_price` int(11) NOT NULL DEFAULT 0,
+  `max_items_with_special_unit_price` int(11) NOT NULL DEFAULT 0,
+  `redemption_limit` int(11) NOT NULL DEFAULT 0,
+  `store_group` varchar(255) NOT NULL DEFAULT '',
+  `can_be_applied_manually_multiple_times` int(11) NOT NULL DEFAULT 0,
+  `purchased_brand_id` int(11) NOT NULL DEFAULT 0,
+  `awarded_product_group_id` int(11) NOT NULL DEFAULT 0,
+  `lowest_price_item_is_awarded` int(11) NOT NULL DEFAULT 0,
+  `percentage_off_matching_items` int(11) NOT NULL DEFAULT 0,
+  `purchased_product_group_id` int(11) NOT NULL DEFAULT 0,
+  `reward_points` int(11) NOT NULL DEFAULT 0,
+  `percentage_off_entire_purchase` int(11) NOT NULL DEFAULT 0,
+  `bundle_price` decimal(15,2) NOT NULL DEFAULT 0,
+  `bundle_sum_off` decimal(15,2) NOT NULL DEFAULT 0,
+  `bundle_percentage_off` decimal(15,2) NOT NULL DEFAULT 0,
+  `priority` int(11) NOT NULL DEFAULT 0,
+  `exclusive` int(11) NOT NULL DEFAULT 0,
+  `stacking_group` varchar(50) NOT NULL DEFAULT '',
+  `customer_allowlist` int(11) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`campaign_id`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `campaign_product` (
+  `campaign_id` int(11) NOT NULL,
+  `list` varchar(50) NOT NULL,
+  `position` int(11) NOT NULL,
+  `product` varchar(255) NOT NULL,
+  PRIMARY KEY (`campaign_id`, `list`, `position`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `campaign_subsidy` (
+  `campaign_id` int(11) NOT NULL,
+  `list` varchar(50) NOT NULL,
+  `position` int(11) NOT NULL,
+  `subsidy` decimal(15,2) NOT NULL,
+  PRIMARY KEY (`campaign_id`, `list`, `position`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `campaign_store_region` (
+  `campaign_id` int(11) NOT NULL,
+  `store_region_id` int(11) NOT NULL,
+  PRIMARY KEY (`campaign_id`, `store_region_id`),
+  KEY `store_region_id` (`store_region_id`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `campaign_customer_group` (
+  `campaign_id` int(11) NOT NULL,
+  `customer_group_id` int(11) NOT NULL,
+  PRIMARY KEY (`campaign_id`, `customer_group_id`),
+  KEY `customer_group_id` (`customer_group_id`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `campaign_customer_segment` (
+  `campaign_id` int(11) NOT NULL,
+  `customer_segment_id` int(11) NOT NULL,
+  PRIMARY KEY (`campaign_id`, `customer_segment_id`),
+  KEY `customer_segment_id` (`customer_segment_id`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `campaign_combinable` (
+  `campaign_id` int(11) NOT NULL,
+  `combinable_campaign_id` int(11) NOT NULL,
+  PRIMARY KEY (`campaign_id`, `combinable_campaign_id`),
+  KEY `combinable_campaign_id` (`combinable_campaign_id`)
+) ENGINE=InnoDB;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `user_group_right` (
+  `group_id` int(11) NOT NULL,
+  `view_campaigns` tinyint(1) NOT NULL DEFAULT 0,
+  `create_campaigns` tinyint(1) NOT NULL DEFAULT 0,
+  `edit_campaigns` tinyint(1) NOT NULL DEFAULT 0,
+  `delete_campaigns` tinyint(1) NOT NULL DEFAULT 0,
+  `approve_campaigns` tinyint(1) NOT NULL DEFAULT 0,
+  `view_reports` tinyint(1) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`group_id`)
+) ENGINE=InnoDB;
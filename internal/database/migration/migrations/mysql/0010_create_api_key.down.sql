@@ -0,0 +1,3 @@
+ALTER TABLE `user_group_right` DROP COLUMN `manage_api_keys`;
+
+DROP TABLE IF EXISTS `api_key`;
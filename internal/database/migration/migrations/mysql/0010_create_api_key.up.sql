@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `api_key` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(50) NOT NULL,
+  `key_hash` char(64) NOT NULL,
+  `scope` varchar(10) NOT NULL,
+  `expires` int(11) NOT NULL DEFAULT 0,
+  `added` int(11) NOT NULL,
+  `addedby` varchar(50) NOT NULL,
+  `revoked` int(11) NOT NULL DEFAULT 0,
+  `revokedby` varchar(50) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `key_hash` (`key_hash`)
+) ENGINE=InnoDB;
+
+ALTER TABLE `user_group_right` ADD COLUMN `manage_api_keys` tinyint(1) NOT NULL DEFAULT 0;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS `webhook` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `url` varchar(255) NOT NULL,
+  `secret` varchar(100) NOT NULL,
+  `events` varchar(255) NOT NULL,
+  `active` tinyint(1) NOT NULL DEFAULT 1,
+  `added` int(11) NOT NULL,
+  `addedby` varchar(50) NOT NULL,
+  `changed` int(11) NOT NULL,
+  `changedby` varchar(50) NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `webhook_delivery` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `webhook_id` int(11) NOT NULL,
+  `event` varchar(50) NOT NULL,
+  `payload` text NOT NULL,
+  `status` varchar(10) NOT NULL,
+  `attempts` int(11) NOT NULL DEFAULT 0,
+  `next_attempt` int(11) NOT NULL DEFAULT 0,
+  `last_status` int(11) NOT NULL DEFAULT 0,
+  `last_error` varchar(255) NOT NULL DEFAULT '',
+  `added` int(11) NOT NULL,
+  `delivered` int(11) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`),
+  KEY `webhook_id` (`webhook_id`),
+  KEY `status_next_attempt` (`status`, `next_attempt`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `webhook_sweep` (
+  `id` int(11) NOT NULL,
+  `swept` int(11) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB;
+
+INSERT INTO `webhook_sweep` (`id`, `swept`) VALUES (1, 0);
+
+ALTER TABLE `user_group_right` ADD COLUMN `manage_webhooks` tinyint(1) NOT NULL DEFAULT 0;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS `outbox` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `aggregate` varchar(50) NOT NULL,
+  `aggregate_id` int(11) NOT NULL,
+  `event` varchar(50) NOT NULL,
+  `payload` text NOT NULL,
+  `added` int(11) NOT NULL,
+  `published` int(11) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`),
+  KEY `published_id` (`published`, `id`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `outbox_relay` (
+  `id` int(11) NOT NULL,
+  `locked_until` int(11) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB;
+
+INSERT INTO `outbox_relay` (`id`, `locked_until`) VALUES (1, 0);
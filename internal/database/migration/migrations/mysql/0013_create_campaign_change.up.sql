@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS `campaign_change` (
+  `campaign_id` int(11) NOT NULL,
+  `seq` int(11) NOT NULL,
+  `deleted` tinyint(1) NOT NULL DEFAULT 0,
+  `changed` int(11) NOT NULL,
+  PRIMARY KEY (`campaign_id`),
+  UNIQUE KEY `seq` (`seq`)
+) ENGINE=InnoDB;
+
+CREATE TABLE IF NOT EXISTS `campaign_change_sequence` (
+  `id` int(11) NOT NULL,
+  `seq` int(11) NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB;
+
+INSERT INTO `campaign_change` (`campaign_id`, `seq`, `deleted`, `changed`)
+SELECT `id`, `id`, 0, `changed` FROM `campaign`;
+
+INSERT INTO `campaign_change_sequence` (`id`, `seq`)
+SELECT 1, COALESCE(MAX(`id`), 0) FROM `campaign`;
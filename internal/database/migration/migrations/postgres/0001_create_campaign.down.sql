@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS attributes;
+DROP TABLE IF EXISTS campaign;
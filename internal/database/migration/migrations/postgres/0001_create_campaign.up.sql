@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS campaign (
+  id SERIAL,
+  start_date timestamp NOT NULL,
+  end_date timestamp NOT NULL,
+  name varchar(255) NOT NULL,
+  warehouse_id integer NOT NULL,
+  purchased_amount integer NOT NULL,
+  purchased_prodgroup_id integer NOT NULL,
+  purchase_total_value decimal(15,2) NOT NULL,
+  award_lowest_priced_item boolean NOT NULL,
+  special_price decimal(15,2) NOT NULL,
+  percentage_off integer NOT NULL,
+  sum_off decimal(15,2) NOT NULL,
+  awarded_prodgroup_id integer NOT NULL,
+  percentage_off_all_items integer NOT NULL,
+  sum_off_entire_purchase decimal(15,2) NOT NULL,
+  rewardpoints integer NOT NULL,
+  percentage_off_any_one_line integer NOT NULL,
+  type varchar(6) NOT NULL,
+  added integer NOT NULL,
+  addedby varchar(16) NOT NULL,
+  changed integer NOT NULL,
+  changedby varchar(16) NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS attributes (
+  id SERIAL,
+  obj_id integer NOT NULL,
+  obj_table varchar(35) NOT NULL,
+  name varchar(50) NOT NULL,
+  type varchar(6) NOT NULL DEFAULT 'text' CHECK (type IN ('int','text','double')),
+  value_text varchar(255) NOT NULL,
+  value_int integer NOT NULL,
+  value_double double precision NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS attributes_obj_id ON attributes (obj_id);
+
+CREATE INDEX IF NOT EXISTS attributes_obj_table ON attributes (obj_table);
+
+CREATE INDEX IF NOT EXISTS attributes_name ON attributes (name);
+
+CREATE INDEX IF NOT EXISTS attributes_value_text ON attributes (value_text);
+
+CREATE INDEX IF NOT EXISTS attributes_value_int ON attributes (value_int);
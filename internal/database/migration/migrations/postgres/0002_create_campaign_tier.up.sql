@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS campaign_tier (
+  id SERIAL,
+  campaign_id integer NOT NULL,
+  position integer NOT NULL,
+  purchased_amount integer NOT NULL,
+  purchase_total_value decimal(15,2) NOT NULL,
+  percentage_off decimal(5,2) NOT NULL,
+  sum_off decimal(15,2) NOT NULL,
+  special_price decimal(15,2) NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_tier_campaign_id ON campaign_tier (campaign_id);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS campaign_product_set (
+  id SERIAL,
+  campaign_id integer NOT NULL,
+  position integer NOT NULL,
+  name varchar(50) NOT NULL,
+  amount integer NOT NULL,
+  products text NOT NULL,
+  prodgroup_id integer NOT NULL,
+  prodcategory_id integer NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_product_set_campaign_id ON campaign_product_set (campaign_id);
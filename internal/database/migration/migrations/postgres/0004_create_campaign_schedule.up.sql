@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS campaign_schedule (
+  id SERIAL,
+  campaign_id integer NOT NULL,
+  position integer NOT NULL,
+  days_of_week varchar(20) NOT NULL,
+  days_of_month varchar(255) NOT NULL,
+  weeks_of_month varchar(20) NOT NULL,
+  start_time varchar(5) NOT NULL,
+  end_time varchar(5) NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_schedule_campaign_id ON campaign_schedule (campaign_id);
@@ -0,0 +1 @@
+ALTER TABLE campaign ALTER COLUMN start_date TYPE date, ALTER COLUMN end_date TYPE date;
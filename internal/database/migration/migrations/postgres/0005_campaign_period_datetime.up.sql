@@ -0,0 +1,6 @@
+-- Campaign periods are stored as UTC instants. A date ends with its last
+-- second. The existing dates are kept as the wall-clock time of the tenant,
+-- with a timezone other than UTC they are shifted to UTC after the migration
+ALTER TABLE campaign ALTER COLUMN start_date TYPE timestamp, ALTER COLUMN end_date TYPE timestamp;
+-- Only the dates still at midnight are moved, a run again does not move them twice
+UPDATE campaign SET end_date = end_date + INTERVAL '1 day' - INTERVAL '1 second' WHERE end_date::time = '00:00:00';
@@ -0,0 +1,87 @@
+CREATE TABLE IF NOT EXISTS campaign_settings (
+  campaign_id integer NOT NULL,
+  awarded_brand_id integer NOT NULL DEFAULT 0,
+  discount_for_one_line integer NOT NULL DEFAULT 0,
+  required_coupon_id varchar(255) NOT NULL DEFAULT '',
+  required_coupon_code varchar(255) NOT NULL DEFAULT '',
+  awarded_amount integer NOT NULL DEFAULT 0,
+  purchased_product_category_id integer NOT NULL DEFAULT 0,
+  awarded_product_category_id integer NOT NULL DEFAULT 0,
+  maximum_points_discount integer NOT NULL DEFAULT 0,
+  customer_can_use_only_once integer NOT NULL DEFAULT 0,
+  price_at_least integer NOT NULL DEFAULT 0,
+  price_at_most integer NOT NULL DEFAULT 0,
+  requires_manager_override integer NOT NULL DEFAULT 0,
+  sum_off_matching_items integer NOT NULL DEFAULT 0,
+  exclude_discounted_from_percentage_off_entire_purchase integer NOT NULL DEFAULT 0,
+  exclude_promotion_items_from_percentage_off_entire_purchase integer NOT NULL DEFAULT 0,
+  reason_id integer NOT NULL DEFAULT 0,
+  special_unit_price integer NOT NULL DEFAULT 0,
+  max_items_with_special_unit_price integer NOT NULL DEFAULT 0,
+  redemption_limit integer NOT NULL DEFAULT 0,
+  store_group varchar(255) NOT NULL DEFAULT '',
+  can_be_applied_manually_multiple_times integer NOT NULL DEFAULT 0,
+  purchased_brand_id integer NOT NULL DEFAULT 0,
+  awarded_product_group_id integer NOT NULL DEFAULT 0,
+  lowest_price_item_is_awarded integer NOT NULL DEFAULT 0,
+  percentage_off_matching_items integer NOT NULL DEFAULT 0,
+  purchased_product_group_id integer NOT NULL DEFAULT 0,
+  reward_points integer NOT NULL DEFAULT 0,
+  percentage_off_entire_purchase integer NOT NULL DEFAULT 0,
+  bundle_price decimal(15,2) NOT NULL DEFAULT 0,
+  bundle_sum_off decimal(15,2) NOT NULL DEFAULT 0,
+  bundle_percentage_off decimal(15,2) NOT NULL DEFAULT 0,
+  priority integer NOT NULL DEFAULT 0,
+  exclusive integer NOT NULL DEFAULT 0,
+  stacking_group varchar(50) NOT NULL DEFAULT '',
+  customer_allowlist integer NOT NULL DEFAULT 0,
+  PRIMARY KEY (campaign_id)
+);
+
+CREATE TABLE IF NOT EXISTS campaign_product (
+  campaign_id integer NOT NULL,
+  list varchar(50) NOT NULL,
+  position integer NOT NULL,
+  product varchar(255) NOT NULL,
+  PRIMARY KEY (campaign_id, list, position)
+);
+
+CREATE TABLE IF NOT EXISTS campaign_subsidy (
+  campaign_id integer NOT NULL,
+  list varchar(50) NOT NULL,
+  position integer NOT NULL,
+  subsidy decimal(15,2) NOT NULL,
+  PRIMARY KEY (campaign_id, list, position)
+);
+
+CREATE TABLE IF NOT EXISTS campaign_store_region (
+  campaign_id integer NOT NULL,
+  store_region_id integer NOT NULL,
+  PRIMARY KEY (campaign_id, store_region_id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_store_region_store_region_id ON campaign_store_region (store_region_id);
+
+CREATE TABLE IF NOT EXISTS campaign_customer_group (
+  campaign_id integer NOT NULL,
+  customer_group_id integer NOT NULL,
+  PRIMARY KEY (campaign_id, customer_group_id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_customer_group_customer_group_id ON campaign_customer_group (customer_group_id);
+
+CREATE TABLE IF NOT EXISTS campaign_customer_segment (
+  campaign_id integer NOT NULL,
+  customer_segment_id integer NOT NULL,
+  PRIMARY KEY (campaign_id, customer_segment_id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_customer_segment_customer_segment_id ON campaign_customer_segment (customer_segment_id);
+
+CREATE TABLE IF NOT EXISTS campaign_combinable (
+  campaign_id integer NOT NULL,
+  combinable_campaign_id integer NOT NULL,
+  PRIMARY KEY (campaign_id, combinable_campaign_id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_combinable_combinable_campaign_id ON campaign_combinable (combinable_campaign_id);
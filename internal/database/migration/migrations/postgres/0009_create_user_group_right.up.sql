@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS user_group_right (
+  group_id integer NOT NULL,
+  view_campaigns smallint NOT NULL DEFAULT 0,
+  create_campaigns smallint NOT NULL DEFAULT 0,
+  edit_campaigns smallint NOT NULL DEFAULT 0,
+  delete_campaigns smallint NOT NULL DEFAULT 0,
+  approve_campaigns smallint NOT NULL DEFAULT 0,
+  view_reports smallint NOT NULL DEFAULT 0,
+  PRIMARY KEY (group_id)
+);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS api_key (
+  id SERIAL,
+  name varchar(50) NOT NULL,
+  key_hash char(64) NOT NULL,
+  scope varchar(10) NOT NULL,
+  expires integer NOT NULL DEFAULT 0,
+  added integer NOT NULL,
+  addedby varchar(50) NOT NULL,
+  revoked integer NOT NULL DEFAULT 0,
+  revokedby varchar(50) NOT NULL DEFAULT '',
+  PRIMARY KEY (id),
+  UNIQUE (key_hash)
+);
+
+ALTER TABLE user_group_right ADD COLUMN manage_api_keys smallint NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+ALTER TABLE user_group_right DROP COLUMN manage_webhooks;
+
+DROP TABLE IF EXISTS webhook_sweep;
+
+DROP TABLE IF EXISTS webhook_delivery;
+
+DROP TABLE IF EXISTS webhook;
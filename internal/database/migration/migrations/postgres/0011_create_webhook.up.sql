@@ -0,0 +1,41 @@
+CREATE TABLE IF NOT EXISTS webhook (
+  id SERIAL,
+  url varchar(255) NOT NULL,
+  secret varchar(100) NOT NULL,
+  events varchar(255) NOT NULL,
+  active boolean NOT NULL DEFAULT TRUE,
+  added integer NOT NULL,
+  addedby varchar(50) NOT NULL,
+  changed integer NOT NULL,
+  changedby varchar(50) NOT NULL,
+  PRIMARY KEY (id)
+);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+  id SERIAL,
+  webhook_id integer NOT NULL,
+  event varchar(50) NOT NULL,
+  payload text NOT NULL,
+  status varchar(10) NOT NULL,
+  attempts integer NOT NULL DEFAULT 0,
+  next_attempt integer NOT NULL DEFAULT 0,
+  last_status integer NOT NULL DEFAULT 0,
+  last_error varchar(255) NOT NULL DEFAULT '',
+  added integer NOT NULL,
+  delivered integer NOT NULL DEFAULT 0,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_webhook_id ON webhook_delivery (webhook_id);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_status_next_attempt ON webhook_delivery (status, next_attempt);
+
+CREATE TABLE IF NOT EXISTS webhook_sweep (
+  id integer NOT NULL,
+  swept integer NOT NULL DEFAULT 0,
+  PRIMARY KEY (id)
+);
+
+INSERT INTO webhook_sweep (id, swept) VALUES (1, 0);
+
+ALTER TABLE user_group_right ADD COLUMN manage_webhooks smallint NOT NULL DEFAULT 0;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS outbox (
+  id SERIAL,
+  aggregate varchar(50) NOT NULL,
+  aggregate_id integer NOT NULL,
+  event varchar(50) NOT NULL,
+  payload text NOT NULL,
+  added integer NOT NULL,
+  published integer NOT NULL DEFAULT 0,
+  PRIMARY KEY (id)
+);
+
+CREATE INDEX IF NOT EXISTS outbox_published_id ON outbox (published, id);
+
+CREATE TABLE IF NOT EXISTS outbox_relay (
+  id integer NOT NULL,
+  locked_until integer NOT NULL DEFAULT 0,
+  PRIMARY KEY (id)
+);
+
+INSERT INTO outbox_relay (id, locked_until) VALUES (1, 0);
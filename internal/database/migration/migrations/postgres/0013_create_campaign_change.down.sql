@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS campaign_change_sequence;
+
+DROP TABLE IF EXISTS campaign_change;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS campaign_change (
+  campaign_id integer NOT NULL,
+  seq integer NOT NULL,
+  deleted boolean NOT NULL DEFAULT FALSE,
+  changed integer NOT NULL,
+  PRIMARY KEY (campaign_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS campaign_change_seq ON campaign_change (seq);
+
+CREATE TABLE IF NOT EXISTS campaign_change_sequence (
+  id integer NOT NULL,
+  seq integer NOT NULL DEFAULT 0,
+  PRIMARY KEY (id)
+);
+
+INSERT INTO campaign_change (campaign_id, seq, deleted, changed)
+SELECT id, id, FALSE, changed FROM campaign;
+
+INSERT INTO campaign_change_sequence (id, seq)
+SELECT 1, COALESCE(MAX(id), 0) FROM campaign;
@@ -0,0 +1,2 @@
+-- SQLite columns have no fixed type, campaign periods are created as datetime
+SELECT 1;
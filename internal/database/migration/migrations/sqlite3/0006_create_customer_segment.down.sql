@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS customer_segment_rule;
+DROP TABLE IF EXISTS customer_segment;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS customer_segment (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name varchar(100) NOT NULL,
+  description varchar(255) NOT NULL,
+  added integer NOT NULL,
+  addedby varchar(50) NOT NULL,
+  changed integer NOT NULL,
+  changedby varchar(50) NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS customer_segment_rule (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  segment_id integer NOT NULL,
+  position integer NOT NULL,
+  attribute varchar(50) NOT NULL,
+  operator varchar(20) NOT NULL,
+  value varchar(255) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS customer_segment_rule_segment_id ON customer_segment_rule (segment_id);
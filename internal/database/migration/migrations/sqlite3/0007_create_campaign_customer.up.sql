@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS campaign_customer (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  campaign_id integer NOT NULL,
+  customer_id integer NOT NULL,
+  expires integer NOT NULL DEFAULT 0,
+  added integer NOT NULL,
+  UNIQUE (campaign_id, customer_id)
+);
+
+CREATE INDEX IF NOT EXISTS campaign_customer_customer_id ON campaign_customer (customer_id);
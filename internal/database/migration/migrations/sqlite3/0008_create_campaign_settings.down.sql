@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS campaign_combinable;
+DROP TABLE IF EXISTS campaign_customer_segment;
+DROP TABLE IF EXISTS campaign_customer_group;
+DROP TABLE IF EXISTS campaign_store_region;
+DROP TABLE IF EXISTS campaign_subsidy;
+DROP TABLE IF EXISTS campaign_product;
+DROP TABLE IF EXISTS campaign_settings;
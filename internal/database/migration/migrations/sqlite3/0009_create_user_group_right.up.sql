@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS user_group_right (
+  group_id integer NOT NULL PRIMARY KEY,
+  view_campaigns integer NOT NULL DEFAULT 0,
+  create_campaigns integer NOT NULL DEFAULT 0,
+  edit_campaigns integer NOT NULL DEFAULT 0,
+  delete_campaigns integer NOT NULL DEFAULT 0,
+  approve_campaigns integer NOT NULL DEFAULT 0,
+  view_reports integer NOT NULL DEFAULT 0
+);
@@ -0,0 +1,20 @@
+-- SQLite cannot drop a column, the rights table is copied without it
+CREATE TABLE user_group_right_copy (
+  group_id integer NOT NULL PRIMARY KEY,
+  view_campaigns integer NOT NULL DEFAULT 0,
+  create_campaigns integer NOT NULL DEFAULT 0,
+  edit_campaigns integer NOT NULL DEFAULT 0,
+  delete_campaigns integer NOT NULL DEFAULT 0,
+  approve_campaigns integer NOT NULL DEFAULT 0,
+  view_reports integer NOT NULL DEFAULT 0
+);
+
+INSERT INTO user_group_right_copy
+SELECT group_id, view_campaigns, create_campaigns, edit_campaigns, delete_campaigns, approve_campaigns, view_reports
+FROM user_group_right;
+
+DROP TABLE user_group_right;
+
+ALTER TABLE user_group_right_copy RENAME TO user_group_right;
+
+DROP TABLE IF EXISTS api_key;
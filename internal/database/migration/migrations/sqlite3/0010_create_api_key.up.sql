@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS api_key (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name varchar(50) NOT NULL,
+  key_hash char(64) NOT NULL UNIQUE,
+  scope varchar(10) NOT NULL,
+  expires integer NOT NULL DEFAULT 0,
+  added integer NOT NULL,
+  addedby varchar(50) NOT NULL,
+  revoked integer NOT NULL DEFAULT 0,
+  revokedby varchar(50) NOT NULL DEFAULT ''
+);
+
+ALTER TABLE user_group_right ADD COLUMN manage_api_keys integer NOT NULL DEFAULT 0;
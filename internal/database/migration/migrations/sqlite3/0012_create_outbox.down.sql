@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS outbox_relay;
+
+DROP TABLE IF EXISTS outbox;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS campaign_change (
+  campaign_id integer NOT NULL PRIMARY KEY,
+  seq integer NOT NULL,
+  deleted integer NOT NULL DEFAULT 0,
+  changed integer NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS campaign_change_seq ON campaign_change (seq);
+
+CREATE TABLE IF NOT EXISTS campaign_change_sequence (
+  id integer NOT NULL PRIMARY KEY,
+  seq integer NOT NULL DEFAULT 0
+);
+
+INSERT INTO campaign_change (campaign_id, seq, deleted, changed)
+SELECT id, id, 0, changed FROM campaign;
+
+INSERT INTO campaign_change_sequence (id, seq)
+SELECT 1, COALESCE(MAX(id), 0) FROM campaign;
@@ -1,13 +1,16 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
 
 	_ "github.com/go-sql-driver/mysql" // Mysql driver
+	_ "github.com/lib/pq"              // PostgreSQL driver
+	_ "github.com/mattn/go-sqlite3"    // SQLite driver, used in standalone mode
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,14 +22,16 @@ type (
 	}
 	// IMysql interface
 	IMysql interface {
-		Query(query string, args ...interface{}) (IROWS, error)
-		QueryRow(query string, args ...interface{}) (IROW, error)
+		QueryContext(ctx context.Context, query string, args ...interface{}) (IROWS, error)
+		QueryRowContext(ctx context.Context, query string, args ...interface{}) (IROW, error)
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 		Connect() error
 	}
 	// IDB interface
 	IDB interface {
-		Query(query string, args ...interface{}) (*sql.Rows, error)
-		QueryRow(query string, args ...interface{}) *sql.Row
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+		QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 		Close() error
 	}
 	// IROWS interface
@@ -48,56 +53,74 @@ func New(configuration *config.Configuration) IMysql {
 	}
 }
 
-// Query returns database rows result when successful
-func (mysql *Mysql) Query(query string, args ...interface{}) (IROWS, error) {
+// QueryContext returns database rows result when successful
+func (mysql *Mysql) QueryContext(ctx context.Context, query string, args ...interface{}) (IROWS, error) {
 
-	err := mysql.Connect()
+	db, err := mysql.connect()
 
 	if err != nil {
 		mysql.logError(err.Error(), query)
 		return nil, errors.New(errorcodes.CodeDatabase)
 	}
 
-	defer mysql.DB.Close()
-	return mysql.DB.Query(query, args...)
+	defer db.Close()
+	return db.QueryContext(ctx, query, args...)
 }
 
-// QueryRow returns single database row result when successful
-func (mysql *Mysql) QueryRow(query string, args ...interface{}) (IROW, error) {
+// QueryRowContext returns single database row result when successful
+func (mysql *Mysql) QueryRowContext(ctx context.Context, query string, args ...interface{}) (IROW, error) {
 
-	err := mysql.Connect()
+	db, err := mysql.connect()
 
 	if err != nil {
 		mysql.logError(err.Error(), query)
 		return nil, errors.New(errorcodes.CodeDatabase)
 	}
 
-	defer mysql.DB.Close()
-	return mysql.DB.QueryRow(query, args...), nil
+	defer db.Close()
+	return db.QueryRowContext(ctx, query, args...), nil
 }
 
-// Connect opens a connection to the configured database
+// ExecContext executes the statement and returns its result when successful
+func (mysql *Mysql) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+
+	db, err := mysql.connect()
+
+	if err != nil {
+		mysql.logError(err.Error(), query)
+		return nil, errors.New(errorcodes.CodeDatabase)
+	}
+
+	defer db.Close()
+	return db.ExecContext(ctx, query, args...)
+}
+
+// Connect opens a connection to the configured database as DB
 func (mysql *Mysql) Connect() error {
 
+	db, err := mysql.connect()
+	if err != nil {
+		return err
+	}
+	mysql.DB = db
+	return nil
+}
+
+// connect opens a connection to the configured database, every call has its
+// own as the struct is shared by the requests
+func (mysql *Mysql) connect() (*sql.DB, error) {
+
 	db, err := sql.Open(
 		mysql.Configuration.Database.Driver,
-		fmt.Sprintf(
-			"%s:%s@tcp(%s:%d)/%s",
-			mysql.Configuration.Database.Username,
-			mysql.Configuration.Database.Password,
-			mysql.Configuration.Database.Server,
-			mysql.Configuration.Database.Port,
-			mysql.Configuration.Database.Name,
-		),
+		dialect.For(mysql.Configuration).DSN(mysql.Configuration),
 	)
 
 	if err != nil {
 		mysql.logError(err.Error(), "")
-		return errors.New(errorcodes.CodeDatabase)
+		return nil, errors.New(errorcodes.CodeDatabase)
 	}
 
-	mysql.DB = db
-	return nil
+	return db, nil
 }
 
 // logs errors
@@ -1,6 +1,7 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
@@ -15,7 +16,7 @@ type MockDB struct{}
 var queryCalled bool = false
 var failQuery bool = false
 
-func (m *MockDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+func (m *MockDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	queryCalled = true
 	if failQuery {
 		return nil, errors.New(errorcodes.CodeDatabase)
@@ -25,11 +26,18 @@ func (m *MockDB) Query(query string, args ...interface{}) (*sql.Rows, error) {
 
 var queryRowCalled bool = false
 
-func (m *MockDB) QueryRow(query string, args ...interface{}) *sql.Row {
+func (m *MockDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	queryRowCalled = true
 	return &sql.Row{}
 }
 
+var execCalled bool = false
+
+func (m *MockDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	execCalled = true
+	return nil, nil
+}
+
 var failClose bool = false
 
 func (m *MockDB) Close() error {
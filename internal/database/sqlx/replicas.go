@@ -0,0 +1,271 @@
+package sqlx
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+// Defaults of the replication configuration
+const (
+	DefaultCheckInterval = 10 * time.Second
+	DefaultRetryAfter    = 30 * time.Second
+)
+
+type (
+	// replicas routes the reads over the healthy replicas of the databases.
+	// The state is shared by all connections: a replica that fails or lags is
+	// out of rotation for every repository
+	replicas struct {
+		mutex  sync.Mutex
+		states map[string]*replicaState
+		writes map[string]time.Time
+		next   int
+		now    func() time.Time
+		lag    func(ctx context.Context, configuration *config.Configuration) (time.Duration, error)
+	}
+	replicaState struct {
+		checked time.Time
+		failed  time.Time
+	}
+)
+
+var replicaSet = newReplicas()
+
+func newReplicas() *replicas {
+
+	return &replicas{
+		states: make(map[string]*replicaState),
+		writes: make(map[string]time.Time),
+		now:    time.Now,
+		lag:    replicationLag,
+	}
+}
+
+// read returns the configuration of the database to read from: a replica
+// in rotation or the primary when there is none, or when the session has
+// written recently
+func (r *replicas) read(ctx context.Context, configuration *config.Configuration) *config.Configuration {
+
+	if len(configuration.Database.Replicas) == 0 || r.readsOwnWrites(configuration) {
+		return configuration
+	}
+
+	checkInterval := seconds(configuration.Database.Replication.CheckInterval, DefaultCheckInterval)
+	for range configuration.Database.Replicas {
+		replica, check := r.pick(configuration, checkInterval)
+		if replica == nil {
+			return configuration
+		}
+		if !check {
+			return replica
+		}
+		lag, err := r.lag(ctx, replica)
+		if ctx.Err() != nil {
+			return configuration
+		}
+		if err == nil && lag > time.Duration(configuration.Database.Replication.MaxLag)*time.Second {
+			err = fmt.Errorf("replica is %s behind the primary", lag)
+		}
+		if err != nil {
+			r.fail(replica, err)
+			continue
+		}
+		return replica
+	}
+	return configuration
+}
+
+// pick returns the next replica in rotation and whether its lag has to be
+// checked before reading from it
+func (r *replicas) pick(configuration *config.Configuration, checkInterval time.Duration) (*config.Configuration, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.now()
+	retryAfter := seconds(configuration.Database.Replication.RetryAfter, DefaultRetryAfter)
+	count := len(configuration.Database.Replicas)
+	for idx := 0; idx < count; idx++ {
+		r.next++
+		replica := replicaConfiguration(configuration, configuration.Database.Replicas[r.next%count])
+		state, ok := r.states[replicaKey(replica)]
+		if !ok {
+			state = &replicaState{}
+			r.states[replicaKey(replica)] = state
+		}
+		if !state.failed.IsZero() && now.Before(state.failed.Add(retryAfter)) {
+			continue
+		}
+		check := configuration.Database.Replication.MaxLag > 0 && !now.Before(state.checked.Add(checkInterval))
+		if check {
+			// Other reads keep using the replica while it is being checked
+			state.checked = now
+		}
+		return replica, check
+	}
+	return nil, false
+}
+
+// fail takes the replica out of rotation
+func (r *replicas) fail(replica *config.Configuration, err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	log.Errorf("replica %s is out of rotation: %s", replicaKey(replica), err)
+	state, ok := r.states[replicaKey(replica)]
+	if !ok {
+		state = &replicaState{}
+		r.states[replicaKey(replica)] = state
+	}
+	state.failed = r.now()
+	state.checked = time.Time{}
+}
+
+// written records a write of the session, its reads go to the primary for a
+// while so that it reads what it has written
+func (r *replicas) written(configuration *config.Configuration) {
+
+	window := time.Duration(configuration.Database.Replication.ReadYourWrites) * time.Second
+	if len(configuration.Database.Replicas) == 0 || window <= 0 || configuration.Database.Session == "" {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := r.now()
+	for key, until := range r.writes {
+		if !now.Before(until) {
+			delete(r.writes, key)
+		}
+	}
+	r.writes[sessionKey(configuration)] = now.Add(window)
+}
+
+// readsOwnWrites returns whether the session has written within the
+// read-your-writes window
+func (r *replicas) readsOwnWrites(configuration *config.Configuration) bool {
+
+	if configuration.Database.Session == "" {
+		return false
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	until, ok := r.writes[sessionKey(configuration)]
+	return ok && r.now().Before(until)
+}
+
+// replicationLag returns how far the replica is behind the primary
+func replicationLag(ctx context.Context, configuration *config.Configuration) (time.Duration, error) {
+
+	d := dialect.For(configuration)
+	if len(d.ReplicationLag()) == 0 {
+		return 0, nil
+	}
+
+	db, err := sqlx.Open(configuration.Database.Driver, d.DSN(configuration))
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+	db.Mapper = reflectx.NewMapper("json")
+
+	return replicaStatus(ctx, db, d.ReplicationLag())
+}
+
+// replicaStatus returns the lag of the replica status, read with the first
+// of the statements the server accepts
+func replicaStatus(ctx context.Context, db *sqlx.DB, statements []string) (time.Duration, error) {
+
+	var rows *sqlx.Rows
+	var err error
+	for _, statement := range statements {
+		rows, err = db.QueryxContext(ctx, statement)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("replication is not configured")
+	}
+	status := make(map[string]interface{})
+	if err = rows.MapScan(status); err != nil {
+		return 0, err
+	}
+	for column, value := range status {
+		if strings.EqualFold(column, "seconds_behind_source") || strings.EqualFold(column, "seconds_behind_master") {
+			return parseLag(value)
+		}
+	}
+	return 0, fmt.Errorf("replication status has no seconds_behind_source")
+}
+
+// parseLag returns the lag of the seconds_behind_source value
+func parseLag(value interface{}) (time.Duration, error) {
+
+	var lag float64
+	var err error
+	switch v := value.(type) {
+	case nil:
+		return 0, fmt.Errorf("replication is not running")
+	case []byte:
+		lag, err = strconv.ParseFloat(string(v), 64)
+	case string:
+		lag, err = strconv.ParseFloat(v, 64)
+	case int64:
+		lag = float64(v)
+	case float64:
+		lag = v
+	default:
+		err = fmt.Errorf("unexpected seconds_behind_source %v", value)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(lag * float64(time.Second)), nil
+}
+
+// replicaConfiguration returns the configuration of the primary pointed to
+// the replica
+func replicaConfiguration(configuration *config.Configuration, replica config.Replica) *config.Configuration {
+
+	c := *configuration
+	c.Database.Server = replica.Server
+	c.Database.Port = replica.Port
+	c.Database.Replicas = nil
+	return &c
+}
+
+func replicaKey(configuration *config.Configuration) string {
+	return fmt.Sprintf("%s:%d/%s", configuration.Database.Server, configuration.Database.Port, configuration.Database.Name)
+}
+
+func sessionKey(configuration *config.Configuration) string {
+	return configuration.Database.Name + "/" + configuration.Database.Session
+}
+
+func seconds(value int, fallback time.Duration) time.Duration {
+	if value <= 0 {
+		return fallback
+	}
+	return time.Duration(value) * time.Second
+}
@@ -0,0 +1,201 @@
+package sqlx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time { return c.now }
+
+func newReplicaConfiguration(servers ...string) *config.Configuration {
+	configuration := &config.Configuration{}
+	configuration.Database.Driver = "mysql"
+	configuration.Database.Server = "primary"
+	configuration.Database.Port = 3306
+	configuration.Database.Name = "crmx_100"
+	for _, server := range servers {
+		configuration.Database.Replicas = append(configuration.Database.Replicas, config.Replica{Server: server, Port: 3306})
+	}
+	return configuration
+}
+
+func newTestReplicas(lags map[string]time.Duration, failing map[string]bool) (*replicas, *clock, *int) {
+	c := &clock{now: time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)}
+	checks := 0
+	r := newReplicas()
+	r.now = c.Now
+	r.lag = func(ctx context.Context, configuration *config.Configuration) (time.Duration, error) {
+		checks++
+		if failing[configuration.Database.Server] {
+			return 0, errors.New("connection refused")
+		}
+		return lags[configuration.Database.Server], nil
+	}
+	return r, c, &checks
+}
+
+func TestReplicas_Read_WithoutReplicas_ReturnsPrimary(t *testing.T) {
+	r, _, _ := newTestReplicas(nil, nil)
+	configuration := newReplicaConfiguration()
+
+	assert.Equal(t, configuration, r.read(context.Background(), configuration))
+}
+
+func TestReplicas_Read_RoundRobin(t *testing.T) {
+	r, _, _ := newTestReplicas(nil, nil)
+	configuration := newReplicaConfiguration("replica1", "replica2")
+
+	servers := make([]string, 0)
+	for idx := 0; idx < 4; idx++ {
+		replica := r.read(context.Background(), configuration)
+		assert.Equal(t, configuration.Database.Name, replica.Database.Name)
+		assert.Nil(t, replica.Database.Replicas)
+		servers = append(servers, replica.Database.Server)
+	}
+
+	assert.Equal(t, []string{"replica2", "replica1", "replica2", "replica1"}, servers)
+	assert.Equal(t, "primary", configuration.Database.Server)
+}
+
+func TestReplicas_Read_LaggingReplicaOutOfRotation(t *testing.T) {
+	r, c, _ := newTestReplicas(map[string]time.Duration{"replica1": time.Minute, "replica2": time.Second}, nil)
+	configuration := newReplicaConfiguration("replica1", "replica2")
+	configuration.Database.Replication.MaxLag = 5
+
+	for idx := 0; idx < 4; idx++ {
+		assert.Equal(t, "replica2", r.read(context.Background(), configuration).Database.Server)
+	}
+
+	// Back in rotation after the retry period when it has caught up
+	r.lag = func(ctx context.Context, configuration *config.Configuration) (time.Duration, error) { return 0, nil }
+	c.now = c.now.Add(DefaultRetryAfter)
+	servers := map[string]bool{}
+	for idx := 0; idx < 4; idx++ {
+		servers[r.read(context.Background(), configuration).Database.Server] = true
+	}
+	assert.Equal(t, map[string]bool{"replica1": true, "replica2": true}, servers)
+}
+
+func TestReplicas_Read_ChecksLagOncePerInterval(t *testing.T) {
+	r, c, checks := newTestReplicas(nil, nil)
+	configuration := newReplicaConfiguration("replica1")
+	configuration.Database.Replication.MaxLag = 5
+	configuration.Database.Replication.CheckInterval = 60
+
+	r.read(context.Background(), configuration)
+	r.read(context.Background(), configuration)
+	assert.Equal(t, 1, *checks)
+
+	c.now = c.now.Add(time.Minute)
+	r.read(context.Background(), configuration)
+	assert.Equal(t, 2, *checks)
+}
+
+func TestReplicas_Read_AllReplicasFailing_ReturnsPrimary(t *testing.T) {
+	r, _, _ := newTestReplicas(nil, map[string]bool{"replica1": true, "replica2": true})
+	configuration := newReplicaConfiguration("replica1", "replica2")
+	configuration.Database.Replication.MaxLag = 5
+
+	assert.Equal(t, configuration, r.read(context.Background(), configuration))
+	assert.Equal(t, configuration, r.read(context.Background(), configuration))
+}
+
+func TestReplicas_Fail_TakesReplicaOutOfRotation(t *testing.T) {
+	r, c, _ := newTestReplicas(nil, nil)
+	configuration := newReplicaConfiguration("replica1")
+	configuration.Database.Replication.RetryAfter = 10
+
+	r.fail(r.read(context.Background(), configuration), errors.New("connection refused"))
+	assert.Equal(t, configuration, r.read(context.Background(), configuration))
+
+	c.now = c.now.Add(10 * time.Second)
+	assert.Equal(t, "replica1", r.read(context.Background(), configuration).Database.Server)
+}
+
+func TestReplicas_Read_ReadYourWrites(t *testing.T) {
+	r, c, _ := newTestReplicas(nil, nil)
+	configuration := newReplicaConfiguration("replica1")
+	configuration.Database.Replication.ReadYourWrites = 5
+	configuration.Database.Session = "abc"
+
+	r.written(configuration)
+	assert.Equal(t, configuration, r.read(context.Background(), configuration))
+
+	// Other sessions keep reading from the replicas
+	other := newReplicaConfiguration("replica1")
+	other.Database.Session = "def"
+	assert.Equal(t, "replica1", r.read(context.Background(), other).Database.Server)
+
+	c.now = c.now.Add(5 * time.Second)
+	assert.Equal(t, "replica1", r.read(context.Background(), configuration).Database.Server)
+}
+
+func TestReplicas_Written_WithoutWindow_IsNotRecorded(t *testing.T) {
+	r, _, _ := newTestReplicas(nil, nil)
+	configuration := newReplicaConfiguration("replica1")
+	configuration.Database.Session = "abc"
+
+	r.written(configuration)
+
+	assert.Empty(t, r.writes)
+	assert.Equal(t, "replica1", r.read(context.Background(), configuration).Database.Server)
+}
+
+func TestParseLag(t *testing.T) {
+	lag, err := parseLag([]byte("3"))
+	assert.Nil(t, err)
+	assert.Equal(t, 3*time.Second, lag)
+
+	lag, err = parseLag(0.5)
+	assert.Nil(t, err)
+	assert.Equal(t, 500*time.Millisecond, lag)
+
+	lag, err = parseLag(int64(2))
+	assert.Nil(t, err)
+	assert.Equal(t, 2*time.Second, lag)
+
+	_, err = parseLag(nil)
+	assert.NotNil(t, err)
+
+	_, err = parseLag([]byte("x"))
+	assert.NotNil(t, err)
+}
+
+func TestReplicationLag_SQLite_IsNotBehind(t *testing.T) {
+	configuration := &config.Configuration{}
+	configuration.Database.Driver = "sqlite3"
+	configuration.Database.Name = t.TempDir() + "/replica.db"
+
+	lag, err := replicationLag(context.Background(), configuration)
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), lag)
+}
+
+func TestReplicaStatus_FallsBackToTheNextStatement(t *testing.T) {
+	db, err := sqlx.Open("sqlite3", t.TempDir()+"/replica.db")
+	require.Nil(t, err)
+	defer db.Close()
+
+	lag, err := replicaStatus(context.Background(), db, []string{"SHOW REPLICA STATUS", "SELECT 2 AS Seconds_Behind_Master"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2*time.Second, lag)
+
+	lag, err = replicaStatus(context.Background(), db, []string{"SELECT 3 AS Seconds_Behind_Source"})
+	assert.Nil(t, err)
+	assert.Equal(t, 3*time.Second, lag)
+
+	_, err = replicaStatus(context.Background(), db, []string{"SHOW REPLICA STATUS", "SHOW SLAVE STATUS"})
+	assert.NotNil(t, err)
+}
@@ -1,20 +1,25 @@
 package sqlx
 
 import (
+	"context"
 	"database/sql"
 	"errors"
-	"fmt"
 
 	_ "github.com/go-sql-driver/mysql" // Mysql driver
 	"github.com/jmoiron/sqlx"
 	"github.com/jmoiron/sqlx/reflectx"
+	_ "github.com/lib/pq"           // PostgreSQL driver
+	_ "github.com/mattn/go-sqlite3" // SQLite driver, used in standalone mode
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
 	"github.com/zdarovich/promotion-api/internal/log"
 )
 
 type (
-	// Mysql struct
+	// Mysql struct, connects to the database of the configured driver. Every
+	// call opens its own connection, DB is only set by Connect for the
+	// callers that own the struct
 	Mysql struct {
 		Configuration *config.Configuration
 		DB            *sqlx.DB
@@ -22,10 +27,10 @@ type (
 
 	// IDB interface
 	IDB interface {
-		Beginx() (*sqlx.Tx, error)
-		Queryx(query string, args ...interface{}) (*sqlx.Rows, error)
-		QueryRowx(query string, args ...interface{}) (*sqlx.Row, error)
-		NamedExec(query string, arg interface{}) (sql.Result, error)
+		BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error)
+		QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+		QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error)
+		NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
 		Close() error
 	}
 )
@@ -38,84 +43,124 @@ func New(configuration *config.Configuration) IDB {
 	}
 }
 
-// Close ...
+// Close closes the connection opened by Connect
 func (mysql *Mysql) Close() error {
+	if mysql.DB == nil {
+		return nil
+	}
 	return mysql.DB.Close()
 }
 
-// Beginx ...
-func (mysql *Mysql) Beginx() (*sqlx.Tx, error) {
-	err := mysql.Connect()
+// BeginTxx begins a transaction on the primary, the transaction keeps its
+// connection until it is committed or rolled back
+func (mysql *Mysql) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	db, err := mysql.connect(mysql.Configuration)
 
 	if err != nil {
 		mysql.logError(err.Error(), "BEGIN")
 		return nil, errors.New(errorcodes.CodeDatabase)
 	}
 
-	return mysql.DB.Beginx()
+	defer db.Close()
+	replicaSet.written(mysql.Configuration)
+	return db.BeginTxx(ctx, opts)
 }
 
-// Queryx ...
-func (mysql *Mysql) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
-	err := mysql.Connect()
+// QueryxContext reads from a replica when the database has replicas. A
+// failing replica is taken out of rotation and the query is run on the primary
+func (mysql *Mysql) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	configuration := replicaSet.read(ctx, mysql.Configuration)
+	rows, err := mysql.queryx(ctx, configuration, query, args...)
+
+	if err != nil && ctx.Err() == nil && configuration != mysql.Configuration {
+		rows, primaryErr := mysql.queryx(ctx, mysql.Configuration, query, args...)
+		if primaryErr == nil {
+			replicaSet.fail(configuration, err)
+		}
+		return rows, primaryErr
+	}
+	return rows, err
+}
+
+// QueryRowxContext reads from a replica like QueryxContext
+func (mysql *Mysql) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
+	configuration := replicaSet.read(ctx, mysql.Configuration)
+	row, err := mysql.queryRowx(ctx, configuration, query, args...)
+
+	if err == nil && row.Err() != nil && ctx.Err() == nil && configuration != mysql.Configuration {
+		primaryRow, primaryErr := mysql.queryRowx(ctx, mysql.Configuration, query, args...)
+		if primaryErr == nil && primaryRow.Err() == nil {
+			replicaSet.fail(configuration, row.Err())
+		}
+		return primaryRow, primaryErr
+	}
+	return row, err
+}
+
+// NamedExecContext query returns database rows result when successful, it
+// runs on the primary
+func (mysql *Mysql) NamedExecContext(ctx context.Context, query string, args interface{}) (sql.Result, error) {
+	db, err := mysql.connect(mysql.Configuration)
 
 	if err != nil {
 		mysql.logError(err.Error(), query)
 		return nil, errors.New(errorcodes.CodeDatabase)
 	}
 
-	defer mysql.DB.Close()
-	return mysql.DB.Queryx(query, args...)
+	defer db.Close()
+	replicaSet.written(mysql.Configuration)
+	return db.NamedExecContext(ctx, query, args)
+}
+
+// Connect opens a connection to the configured primary database as DB, it
+// is closed by Close
+func (mysql *Mysql) Connect() error {
+	db, err := mysql.connect(mysql.Configuration)
+	if err != nil {
+		return err
+	}
+	mysql.DB = db
+	return nil
 }
 
-// QueryRowx ...
-func (mysql *Mysql) QueryRowx(query string, args ...interface{}) (*sqlx.Row, error) {
-	err := mysql.Connect()
+func (mysql *Mysql) queryx(ctx context.Context, configuration *config.Configuration, query string, args ...interface{}) (*sqlx.Rows, error) {
+	db, err := mysql.connect(configuration)
 
 	if err != nil {
 		mysql.logError(err.Error(), query)
 		return nil, errors.New(errorcodes.CodeDatabase)
 	}
 
-	defer mysql.DB.Close()
-	return mysql.DB.QueryRowx(query, args...), nil
+	defer db.Close()
+	return db.QueryxContext(ctx, db.Rebind(query), args...)
 }
 
-// NamedExec query returns database rows result when successful
-func (mysql *Mysql) NamedExec(query string, args interface{}) (sql.Result, error) {
-	err := mysql.Connect()
+func (mysql *Mysql) queryRowx(ctx context.Context, configuration *config.Configuration, query string, args ...interface{}) (*sqlx.Row, error) {
+	db, err := mysql.connect(configuration)
 
 	if err != nil {
 		mysql.logError(err.Error(), query)
 		return nil, errors.New(errorcodes.CodeDatabase)
 	}
 
-	defer mysql.DB.Close()
-	return mysql.DB.NamedExec(query, args)
+	defer db.Close()
+	return db.QueryRowxContext(ctx, db.Rebind(query), args...), nil
 }
 
-// Connect opens a connection to the configured database
-func (mysql *Mysql) Connect() error {
+// connect opens a connection to the database of the configuration
+func (mysql *Mysql) connect(configuration *config.Configuration) (*sqlx.DB, error) {
 
 	db, err := sqlx.Open(
-		mysql.Configuration.Database.Driver,
-		fmt.Sprintf(
-			"%s:%s@tcp(%s:%d)/%s?parseTime=true",
-			mysql.Configuration.Database.Username,
-			mysql.Configuration.Database.Password,
-			mysql.Configuration.Database.Server,
-			mysql.Configuration.Database.Port,
-			mysql.Configuration.Database.Name,
-		),
+		configuration.Database.Driver,
+		dialect.For(configuration).DSN(configuration),
 	)
 
 	if err != nil {
 		mysql.logError(err.Error(), "")
-		return errors.New(errorcodes.CodeDatabase)
+		return nil, errors.New(errorcodes.CodeDatabase)
 	}
 	db.Mapper = reflectx.NewMapper("json")
-	mysql.DB = db
-	return nil
+	return db, nil
 }
 
 // logs errors
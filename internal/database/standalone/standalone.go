@@ -0,0 +1,220 @@
+package standalone
+
+import (
+	"os"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	"github.com/zdarovich/promotion-api/internal/database/migration"
+	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"gopkg.in/yaml.v2"
+)
+
+// Defaults of the standalone configuration
+const (
+	DefaultDatabase = "promotions.db"
+	DefaultFixtures = "standalone-fixtures.yml"
+)
+
+// SessionDuration lifetime of the seeded sessions that have no expiry
+var SessionDuration = 365 * 24 * time.Hour
+
+type (
+	// Fixtures accounts seeded into the standalone database
+	Fixtures struct {
+		Users    []User    `yaml:"users"`
+		Sessions []Session `yaml:"sessions"`
+		Conf     []Conf    `yaml:"conf"`
+		Rights   []Rights  `yaml:"rights"`
+	}
+	// User fixture of the user table
+	User struct {
+		ID          int    `yaml:"id"`
+		OrgPerIDDat int    `yaml:"orgperIdDat"`
+		GroupID     int    `yaml:"groupId"`
+		Name        string `yaml:"name"`
+		ShortName   string `yaml:"shortname"`
+	}
+	// Session fixture of the session table, the key is sent as sessionKey
+	Session struct {
+		User    string `yaml:"user"`
+		Key     string `yaml:"key"`
+		Device  string `yaml:"device"`
+		Expires int64  `yaml:"expires"`
+	}
+	// Conf fixture of the conf table
+	Conf struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	}
+	// Rights fixture of the user_group_right table
+	Rights struct {
+		GroupID          int  `yaml:"groupId"`
+		ViewCampaigns    bool `yaml:"viewCampaigns"`
+		CreateCampaigns  bool `yaml:"createCampaigns"`
+		EditCampaigns    bool `yaml:"editCampaigns"`
+		DeleteCampaigns  bool `yaml:"deleteCampaigns"`
+		ApproveCampaigns bool `yaml:"approveCampaigns"`
+		ViewReports      bool `yaml:"viewReports"`
+		ManageAPIKeys    bool `yaml:"manageAPIKeys"`
+		ManageWebhooks   bool `yaml:"manageWebhooks"`
+	}
+)
+
+// The account tables belong to the account database, which the migrations
+// do not manage
+var accountTables = []string{
+	"CREATE TABLE IF NOT EXISTS user (" +
+		"id INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"orgper_idDat integer NOT NULL DEFAULT 0, " +
+		"group_id integer NOT NULL DEFAULT 0, " +
+		"name varchar(50) NOT NULL, " +
+		"shortname varchar(16) NOT NULL UNIQUE)",
+	"CREATE TABLE IF NOT EXISTS session (" +
+		"id INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"user varchar(16) NOT NULL, " +
+		"key varchar(64) NOT NULL UNIQUE, " +
+		"device varchar(50) NOT NULL DEFAULT '', " +
+		"started integer, " +
+		"expires integer)",
+	"CREATE TABLE IF NOT EXISTS conf (" +
+		"id INTEGER PRIMARY KEY AUTOINCREMENT, " +
+		"name varchar(50) NOT NULL UNIQUE, " +
+		"\"desc\" varchar(255) NOT NULL DEFAULT '', " +
+		"value text NOT NULL DEFAULT '', " +
+		"added integer NOT NULL DEFAULT 0, " +
+		"addedby varchar(16) NOT NULL DEFAULT '', " +
+		"changed integer NOT NULL DEFAULT 0, " +
+		"changedby varchar(16) NOT NULL DEFAULT '')",
+}
+
+// Configure points the database configuration to the SQLite database of
+// the standalone mode, disables database discovery and the features
+// depending on redis, the session cache, the rate limits and the
+// idempotency keys
+func Configure(configuration *config.Configuration) {
+	configuration.Database.Discovery.Enabled = false
+	configuration.Session.CacheTTL = 0
+	configuration.RateLimit.Enabled = false
+	configuration.Idempotency.Enabled = false
+	configuration.Database.Driver = dialect.SQLite
+	configuration.Database.Name = configuration.Standalone.Database
+	if configuration.Database.Name == "" {
+		configuration.Database.Name = DefaultDatabase
+	}
+}
+
+// Setup configures the standalone mode, migrates the database and seeds
+// the fixtures
+func Setup(configuration *config.Configuration) error {
+	Configure(configuration)
+
+	db, err := migration.Open(configuration)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migration.New(db, dialect.SQLite, 0)
+	if err != nil {
+		return err
+	}
+	applied, err := migrator.Up(false)
+	if err != nil {
+		return err
+	}
+	for _, m := range applied {
+		log.Infof("standalone: migrated %d_%s", m.Version, m.Name)
+	}
+
+	path := configuration.Standalone.Fixtures
+	if path == "" {
+		path = DefaultFixtures
+	}
+	fixtures, err := LoadFixtures(path)
+	if err != nil {
+		return err
+	}
+	return Seed(configuration, fixtures)
+}
+
+// LoadFixtures reads the fixtures file
+func LoadFixtures(path string) (Fixtures, error) {
+	var fixtures Fixtures
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fixtures, err
+	}
+	defer file.Close()
+
+	err = yaml.NewDecoder(file).Decode(&fixtures)
+	return fixtures, err
+}
+
+// Seed creates the account tables and replaces the fixture rows, the
+// sessions without an expiry are valid for SessionDuration
+func Seed(configuration *config.Configuration, fixtures Fixtures) error {
+	db := &sqlx2.Mysql{Configuration: configuration}
+	err := db.Connect()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.DB.Beginx()
+	if err != nil {
+		return err
+	}
+	for _, table := range accountTables {
+		_, err = tx.Exec(table)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+	}
+
+	now := time.Now()
+	for _, u := range fixtures.Users {
+		_, err = tx.Exec("INSERT OR REPLACE INTO user (id, orgper_idDat, group_id, name, shortname) VALUES (?, ?, ?, ?, ?)",
+			u.ID, u.OrgPerIDDat, u.GroupID, u.Name, u.ShortName)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+	}
+	for _, s := range fixtures.Sessions {
+		expires := s.Expires
+		if expires == 0 {
+			expires = now.Add(SessionDuration).Unix()
+		}
+		_, err = tx.Exec("INSERT OR REPLACE INTO session (user, key, device, started, expires) VALUES (?, ?, ?, ?, ?)",
+			s.User, s.Key, s.Device, now.Unix(), expires)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+	}
+	for _, c := range fixtures.Conf {
+		_, err = tx.Exec("INSERT OR REPLACE INTO conf (name, value, added, addedby) VALUES (?, ?, ?, ?)",
+			c.Name, c.Value, now.Unix(), "standalone")
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+	}
+	for _, r := range fixtures.Rights {
+		_, err = tx.Exec("INSERT OR REPLACE INTO user_group_right (group_id, view_campaigns, create_campaigns, "+
+			"edit_campaigns, delete_campaigns, approve_campaigns, view_reports, manage_api_keys, manage_webhooks) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			r.GroupID, r.ViewCampaigns, r.CreateCampaigns, r.EditCampaigns, r.DeleteCampaigns, r.ApproveCampaigns, r.ViewReports,
+			r.ManageAPIKeys, r.ManageWebhooks)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
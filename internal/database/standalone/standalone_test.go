@@ -0,0 +1,681 @@
+package standalone
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/router"
+	router2 "github.com/zdarovich/promotion-api/internal/api/router/v2"
+	"github.com/zdarovich/promotion-api/internal/bus"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	"github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
+	"github.com/zdarovich/promotion-api/internal/repositories/assignment"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/outbox"
+	"github.com/zdarovich/promotion-api/internal/repositories/rights"
+	"github.com/zdarovich/promotion-api/internal/repositories/session"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+	"github.com/zdarovich/promotion-api/internal/requests/createapikey"
+	"github.com/zdarovich/promotion-api/internal/requests/getapikeys"
+	"github.com/zdarovich/promotion-api/internal/requests/getcampaigns"
+	"github.com/zdarovich/promotion-api/internal/requests/getwebhookdeliveries"
+	"github.com/zdarovich/promotion-api/internal/requests/getwebhooks"
+	"github.com/zdarovich/promotion-api/internal/requests/retrywebhookdelivery"
+	"github.com/zdarovich/promotion-api/internal/requests/revokeapikey"
+	"github.com/zdarovich/promotion-api/internal/requests/savecampaigns"
+	"github.com/zdarovich/promotion-api/internal/requests/savewebhook"
+	"github.com/zdarovich/promotion-api/internal/requests/streamcampaigns"
+	"github.com/zdarovich/promotion-api/internal/service/outboxrelay"
+	"github.com/zdarovich/promotion-api/internal/service/webhookdelivery"
+)
+
+const testFixtures = `
+users:
+    - id: 7
+      name: "tester"
+      shortname: "tester"
+      groupId: 1
+    - id: 8
+      name: "viewer"
+      shortname: "viewer"
+      groupId: 2
+    - id: 9
+      name: "admin"
+      shortname: "admin"
+      groupId: 4
+sessions:
+    - user: "tester"
+      key: "valid"
+    - user: "tester"
+      key: "expired"
+      expires: 1
+    - user: "viewer"
+      key: "viewer"
+    - user: "admin"
+      key: "admin"
+conf:
+    - name: "timezone"
+      value: "UTC"
+rights:
+    - groupId: 1
+      viewCampaigns: true
+      createCampaigns: true
+    - groupId: 2
+      viewCampaigns: true
+    - groupId: 4
+      viewCampaigns: true
+      manageAPIKeys: true
+      manageWebhooks: true
+`
+
+// setup returns the configuration of a migrated and seeded standalone
+// database in a temporary directory
+func setup(t *testing.T) *config.Configuration {
+	dir := t.TempDir()
+	fixtures := filepath.Join(dir, "fixtures.yml")
+	require.Nil(t, os.WriteFile(fixtures, []byte(testFixtures), 0600))
+
+	configuration := &config.Configuration{}
+	configuration.Database.Discovery.Enabled = true
+	configuration.Standalone.Enabled = true
+	configuration.Standalone.Database = filepath.Join(dir, "promotions.db")
+	configuration.Standalone.Fixtures = fixtures
+
+	require.Nil(t, Setup(configuration))
+	return configuration
+}
+
+func TestConfigure(t *testing.T) {
+	configuration := &config.Configuration{}
+	configuration.Database.Discovery.Enabled = true
+
+	Configure(configuration)
+
+	assert.False(t, configuration.Database.Discovery.Enabled)
+	assert.Equal(t, dialect.SQLite, configuration.Database.Driver)
+	assert.Equal(t, DefaultDatabase, configuration.Database.Name)
+}
+
+func TestSetup_MissingFixtures(t *testing.T) {
+	configuration := &config.Configuration{}
+	configuration.Standalone.Database = filepath.Join(t.TempDir(), "promotions.db")
+	configuration.Standalone.Fixtures = "/does/not/exist.yml"
+
+	assert.NotNil(t, Setup(configuration))
+}
+
+func TestSetup_Sessions(t *testing.T) {
+	configuration := setup(t)
+	// Starting again keeps the schema and replaces the fixtures
+	require.Nil(t, Setup(configuration))
+
+	s, err := session.New(configuration).GetSessionByKey(context.Background(), "valid")
+	assert.Nil(t, err)
+	assert.Equal(t, "tester", s.User)
+	assert.True(t, s.Expires.Int64 > time.Now().Unix())
+
+	s, err = session.New(configuration).GetSessionByKey(context.Background(), "expired")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), s.Expires.Int64)
+
+	u, err := user.New(configuration).GetUserBySessionKey(context.Background(), "valid")
+	assert.Nil(t, err)
+	assert.Equal(t, 7, u.ID)
+	assert.Equal(t, "tester", u.ShortName)
+}
+
+func TestRightsRepository(t *testing.T) {
+	configuration := setup(t)
+	repository := rights.New(configuration)
+
+	r, err := repository.GetRights(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, rights.Rights{GroupID: 1, ViewCampaigns: true, CreateCampaigns: true}, r)
+
+	r, err = repository.GetRights(context.Background(), 3)
+	assert.Nil(t, err)
+	assert.Equal(t, rights.Rights{GroupID: 3}, r)
+}
+
+func TestCampaignRepository(t *testing.T) {
+	configuration := setup(t)
+	repository := campaign.New(configuration)
+
+	for _, name := range []string{"first", "second", "third"} {
+		c := &campaign.Campaign{
+			Name:      name,
+			Type:      "auto",
+			StartDate: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2020, 5, 31, 23, 59, 59, 0, time.UTC),
+		}
+		require.Nil(t, repository.SaveCampaigns(context.Background(), c))
+		assert.NotZero(t, c.ID)
+	}
+
+	count, err := repository.GetCampaignsCount(context.Background(), 0, "auto")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	campaigns, err := repository.GetCampaigns(context.Background(), 0, "auto", 2, 1)
+	assert.Nil(t, err)
+	require.Len(t, campaigns, 1)
+	assert.Equal(t, "third", campaigns[0].Name)
+	assert.True(t, time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC).Equal(campaigns[0].StartDate))
+
+	require.Nil(t, repository.DeleteCampaigns(context.Background(), campaigns[0].ID))
+	count, err = repository.GetCampaignsCount(context.Background(), 0, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+}
+
+func TestCampaignRepository_Outbox(t *testing.T) {
+	configuration := setup(t)
+	configuration.Events.Enabled = true
+	repository := campaign.New(configuration)
+
+	spring := &campaign.Campaign{Name: "spring", Type: "auto"}
+	require.Nil(t, repository.SaveCampaigns(context.Background(), spring))
+	// Saved with a campaign id it is a new campaign
+	summer := &campaign.Campaign{ID: spring.ID, Name: "summer", Type: "auto"}
+	require.Nil(t, repository.SaveCampaigns(context.Background(), summer))
+	require.Nil(t, repository.DeleteCampaigns(context.Background(), spring.ID))
+	require.Nil(t, repository.DeleteCampaigns(context.Background(), 99))
+
+	b := bus.NewMemory()
+	require.Nil(t, outboxrelay.New(configuration, b).Relay(context.Background(), configuration, "1"))
+
+	messages := b.Messages()
+	require.Len(t, messages, 3)
+	var events []string
+	for _, m := range messages {
+		events = append(events, m.Key+" "+m.Event)
+	}
+	assert.Equal(t, []string{
+		fmt.Sprintf("1.campaign.%d campaign.created", spring.ID),
+		fmt.Sprintf("1.campaign.%d campaign.created", summer.ID),
+		fmt.Sprintf("1.campaign.%d campaign.deleted", spring.ID),
+	}, events)
+	var envelope outboxrelay.Envelope
+	require.Nil(t, json.Unmarshal(messages[2].Body, &envelope))
+	assert.Equal(t, "1-3", envelope.ID)
+	assert.Contains(t, string(envelope.Data), `"name":"spring"`)
+
+	pending, err := outbox.New(configuration).GetPending(context.Background(), 10)
+	assert.Nil(t, err)
+	assert.Empty(t, pending)
+
+	// A change whose event is not recorded is rolled back
+	db := &sqlx.Mysql{Configuration: configuration}
+	require.Nil(t, db.Connect())
+	_, err = db.DB.Exec("DROP TABLE outbox")
+	require.Nil(t, err)
+	require.Nil(t, db.Close())
+	assert.NotNil(t, repository.SaveCampaigns(context.Background(), &campaign.Campaign{Name: "autumn"}))
+	count, err := repository.GetCampaignsCount(context.Background(), 0, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestCampaignRepository_Changes(t *testing.T) {
+	configuration := setup(t)
+	repository := campaign.New(configuration)
+
+	// The campaigns of the fixtures are in the feed
+	existing, err := repository.GetChanges(context.Background(), 0, true, 1000)
+	require.Nil(t, err)
+	count, _ := repository.GetCampaignsCount(context.Background(), 0, "")
+	assert.Len(t, existing, count)
+	var last int64
+	if len(existing) > 0 {
+		last = existing[len(existing)-1].Seq
+	}
+
+	spring := &campaign.Campaign{Name: "spring", Type: "auto"}
+	require.Nil(t, repository.SaveCampaigns(context.Background(), spring))
+	summer := &campaign.Campaign{Name: "summer", Type: "auto"}
+	require.Nil(t, repository.SaveCampaigns(context.Background(), summer))
+	require.Nil(t, repository.RecordChange(context.Background(), spring.ID))
+	require.Nil(t, repository.DeleteCampaigns(context.Background(), summer.ID))
+	require.Nil(t, repository.DeleteCampaigns(context.Background(), 99))
+
+	changes, err := repository.GetChanges(context.Background(), last, true, 10)
+	require.Nil(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, spring.ID, changes[0].CampaignID)
+	assert.Equal(t, last+3, changes[0].Seq)
+	assert.False(t, changes[0].Deleted)
+	assert.Equal(t, summer.ID, changes[1].CampaignID)
+	assert.Equal(t, last+4, changes[1].Seq)
+	assert.True(t, changes[1].Deleted)
+
+	changes, err = repository.GetChanges(context.Background(), last, false, 10)
+	require.Nil(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, spring.ID, changes[0].CampaignID)
+
+	// Concurrent writers get one sequence number each
+	var wg sync.WaitGroup
+	for idx := 0; idx < 5; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, repository.SaveCampaigns(context.Background(), &campaign.Campaign{Name: "concurrent"}))
+		}()
+	}
+	wg.Wait()
+	changes, err = repository.GetChanges(context.Background(), last+4, true, 10)
+	require.Nil(t, err)
+	require.Len(t, changes, 5)
+	for idx, c := range changes {
+		assert.Equal(t, last+5+int64(idx), c.Seq)
+	}
+}
+
+func TestSettingsRepository(t *testing.T) {
+	configuration := setup(t)
+	repository := settings.New(configuration)
+
+	s := &settings.Settings{
+		CampaignID:     1,
+		Priority:       5,
+		BundlePrice:    9.99,
+		Products:       map[string][]string{settings.ListPurchasedProducts: {"milk", "cookie"}},
+		Subsidies:      map[string][]float64{},
+		CombinableWith: []int{2, 3},
+	}
+	require.Nil(t, repository.SaveSettings(context.Background(), s))
+
+	saved, err := repository.GetSettings(context.Background(), []int{1})
+	assert.Nil(t, err)
+	require.NotNil(t, saved[1])
+	assert.Equal(t, 5, saved[1].Priority)
+	assert.Equal(t, 9.99, saved[1].BundlePrice)
+	assert.Equal(t, []string{"milk", "cookie"}, saved[1].Products[settings.ListPurchasedProducts])
+	assert.Equal(t, []int{2, 3}, saved[1].CombinableWith)
+
+	migrated, err := repository.MigrateSettings(context.Background(), &settings.Settings{CampaignID: 1, Priority: 1})
+	assert.Nil(t, err)
+	assert.False(t, migrated)
+}
+
+func TestAssignmentRepository(t *testing.T) {
+	configuration := setup(t)
+	repository := assignment.New(configuration)
+
+	require.Nil(t, repository.SaveAssignments(context.Background(), []*assignment.Assignment{
+		{CampaignID: 1, CustomerID: 10, Added: 1},
+		{CampaignID: 1, CustomerID: 11, Added: 1},
+	}))
+	require.Nil(t, repository.SaveAssignments(context.Background(), []*assignment.Assignment{
+		{CampaignID: 1, CustomerID: 10, Expires: 100, Added: 2},
+	}))
+
+	assignments, err := repository.GetAssignments(context.Background(), 1, 10, 0)
+	assert.Nil(t, err)
+	require.Len(t, assignments, 2)
+	assert.Equal(t, int64(100), assignments[0].Expires)
+	assert.Equal(t, int64(0), assignments[1].Expires)
+}
+
+func TestEndToEnd(t *testing.T) {
+	configuration := setup(t)
+	handlers := map[string]root.IRoot{
+		"getCampaigns":  getcampaigns.New(configuration),
+		"saveCampaigns": savecampaigns.New(configuration),
+	}
+	engine := router.New(configuration, handlers).GetEngine()
+
+	post := func(form url.Values) map[string]interface{} {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+
+		var body map[string]interface{}
+		require.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		return body
+	}
+	status := func(body map[string]interface{}) map[string]interface{} {
+		return body["status"].(map[string]interface{})
+	}
+
+	body := post(url.Values{
+		"clientCode":                  {"1"},
+		"sessionKey":                  {"valid"},
+		"request":                     {"saveCampaigns"},
+		"name":                        {"standalone"},
+		"type":                        {"auto"},
+		"warehouseID":                 {"1"},
+		"startDate":                   {time.Now().AddDate(0, 1, 0).Format("2006-01-02")},
+		"endDate":                     {time.Now().AddDate(0, 2, 0).Format("2006-01-02")},
+		"purchasedProducts":           {"milk,cookie"},
+		"purchasedAmount":             {"2"},
+		"percentageOffEntirePurchase": {"10"},
+	})
+	assert.Equal(t, "ok", status(body)["responseStatus"], body)
+
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"valid"}, "request": {"getCampaigns"}})
+	assert.Equal(t, "ok", status(body)["responseStatus"], body)
+	records := body["records"].([]interface{})
+	require.Len(t, records, 1)
+	record := records[0].(map[string]interface{})
+	assert.Equal(t, "standalone", record["name"])
+	assert.Equal(t, "milk,cookie", record["purchasedProducts"])
+	assert.Equal(t, "tester", record["addedby"])
+
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"expired"}, "request": {"getCampaigns"}})
+	assert.Equal(t, "error", status(body)["responseStatus"])
+
+	// The viewer group may read the campaigns but not save them
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"viewer"}, "request": {"getCampaigns"}})
+	assert.Equal(t, "ok", status(body)["responseStatus"], body)
+
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"viewer"}, "request": {"saveCampaigns"}, "name": {"denied"}})
+	assert.Equal(t, "error", status(body)["responseStatus"])
+	assert.Equal(t, float64(1052), status(body)["errorCode"])
+}
+
+func TestEndToEnd_APIKeys(t *testing.T) {
+	configuration := setup(t)
+	handlers := map[string]root.IRoot{
+		"getCampaigns":  getcampaigns.New(configuration),
+		"saveCampaigns": savecampaigns.New(configuration),
+		"createAPIKey":  createapikey.New(configuration),
+		"getAPIKeys":    getapikeys.New(configuration),
+		"revokeAPIKey":  revokeapikey.New(configuration),
+	}
+	engine := router.New(configuration, handlers).GetEngine()
+
+	post := func(form url.Values) map[string]interface{} {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+
+		var body map[string]interface{}
+		require.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		return body
+	}
+	status := func(body map[string]interface{}) map[string]interface{} {
+		return body["status"].(map[string]interface{})
+	}
+	create := func(name string, scope string) (float64, string) {
+		body := post(url.Values{"clientCode": {"1"}, "sessionKey": {"admin"}, "request": {"createAPIKey"}, "name": {name}, "scope": {scope}})
+		require.Equal(t, "ok", status(body)["responseStatus"], body)
+		record := body["records"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, "admin", record["addedby"])
+		return record["apiKeyID"].(float64), record["key"].(string)
+	}
+
+	readID, readKey := create("reports", apikey.ScopeRead)
+	_, writeKey := create("webshop", apikey.ScopeWrite)
+
+	// The keys are sent in place of the session key
+	body := post(url.Values{"clientCode": {"1"}, "apiKey": {readKey}, "request": {"getCampaigns"}})
+	assert.Equal(t, "ok", status(body)["responseStatus"], body)
+
+	body = post(url.Values{"clientCode": {"1"}, "apiKey": {readKey}, "request": {"saveCampaigns"}, "name": {"denied"}})
+	assert.Equal(t, float64(1052), status(body)["errorCode"], body)
+
+	body = post(url.Values{
+		"clientCode":                  {"1"},
+		"apiKey":                      {writeKey},
+		"request":                     {"saveCampaigns"},
+		"name":                        {"webshop"},
+		"type":                        {"auto"},
+		"warehouseID":                 {"1"},
+		"startDate":                   {time.Now().AddDate(0, 1, 0).Format("2006-01-02")},
+		"endDate":                     {time.Now().AddDate(0, 2, 0).Format("2006-01-02")},
+		"purchasedProducts":           {"milk"},
+		"purchasedAmount":             {"1"},
+		"percentageOffEntirePurchase": {"10"},
+	})
+	assert.Equal(t, "ok", status(body)["responseStatus"], body)
+	body = post(url.Values{"clientCode": {"1"}, "apiKey": {writeKey}, "request": {"getCampaigns"}})
+	require.Len(t, body["records"], 1, body)
+	assert.Equal(t, "webshop", body["records"].([]interface{})[0].(map[string]interface{})["addedby"])
+
+	// A key cannot manage the keys
+	body = post(url.Values{"clientCode": {"1"}, "apiKey": {writeKey}, "request": {"createAPIKey"}, "name": {"escalated"}, "scope": {"write"}})
+	assert.Equal(t, float64(1052), status(body)["errorCode"], body)
+
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"admin"}, "request": {"getAPIKeys"}})
+	assert.Equal(t, "ok", status(body)["responseStatus"], body)
+	records := body["records"].([]interface{})
+	require.Len(t, records, 2)
+	assert.NotContains(t, records[0], "key")
+
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"admin"}, "request": {"revokeAPIKey"}, "apiKeyID": {fmt.Sprint(readID)}})
+	assert.Equal(t, "ok", status(body)["responseStatus"], body)
+
+	body = post(url.Values{"clientCode": {"1"}, "apiKey": {readKey}, "request": {"getCampaigns"}})
+	assert.Equal(t, float64(1051), status(body)["errorCode"], body)
+	body = post(url.Values{"clientCode": {"1"}, "apiKey": {"pak_unknown"}, "request": {"getCampaigns"}})
+	assert.Equal(t, float64(1051), status(body)["errorCode"], body)
+}
+
+func TestEndToEnd_Webhooks(t *testing.T) {
+	configuration := setup(t)
+	configuration.Webhooks.Enabled = true
+	// The receiver listens on the loopback address
+	configuration.Webhooks.AllowInternal = true
+	configuration.Webhooks.MaxAttempts = 1
+	handlers := map[string]root.IRoot{
+		"saveCampaigns":        savecampaigns.New(configuration),
+		"saveWebhook":          savewebhook.New(configuration),
+		"getWebhooks":          getwebhooks.New(configuration),
+		"getWebhookDeliveries": getwebhookdeliveries.New(configuration),
+		"retryWebhookDelivery": retrywebhookdelivery.New(configuration),
+	}
+	engine := router.New(configuration, handlers).GetEngine()
+
+	var received []*http.Request
+	var bodies [][]byte
+	statuses := []int{http.StatusInternalServerError}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		bodies = append(bodies, body)
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	post := func(form url.Values) map[string]interface{} {
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/", strings.NewReader(form.Encode()))
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, request)
+
+		var body map[string]interface{}
+		require.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		return body
+	}
+	status := func(body map[string]interface{}) map[string]interface{} {
+		return body["status"].(map[string]interface{})
+	}
+	deliver := func() {
+		webhookdelivery.New(configuration).Deliver(context.Background(), configuration, "1")
+	}
+
+	// Managing the webhooks needs its right
+	body := post(url.Values{"clientCode": {"1"}, "sessionKey": {"valid"}, "request": {"getWebhooks"}})
+	assert.Equal(t, float64(1052), status(body)["errorCode"], body)
+
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"admin"}, "request": {"saveWebhook"},
+		"url": {receiver.URL}, "events": {webhookhelper.EventCreated}})
+	require.Equal(t, "ok", status(body)["responseStatus"], body)
+	secret := body["records"].([]interface{})[0].(map[string]interface{})["secret"].(string)
+	assert.True(t, strings.HasPrefix(secret, webhookhelper.SecretPrefix))
+
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"admin"}, "request": {"getWebhooks"}})
+	require.Len(t, body["records"], 1, body)
+	assert.NotContains(t, body["records"].([]interface{})[0], "secret")
+
+	body = post(url.Values{
+		"clientCode":                  {"1"},
+		"sessionKey":                  {"valid"},
+		"request":                     {"saveCampaigns"},
+		"name":                        {"hooked"},
+		"type":                        {"auto"},
+		"warehouseID":                 {"1"},
+		"startDate":                   {time.Now().AddDate(0, 1, 0).Format("2006-01-02")},
+		"endDate":                     {time.Now().AddDate(0, 2, 0).Format("2006-01-02")},
+		"purchasedProducts":           {"milk"},
+		"purchasedAmount":             {"1"},
+		"percentageOffEntirePurchase": {"10"},
+	})
+	require.Equal(t, "ok", status(body)["responseStatus"], body)
+
+	// The first attempt fails and the delivery is dead after it
+	deliver()
+	require.Len(t, received, 1)
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"admin"}, "request": {"getWebhookDeliveries"}, "status": {"dead"}})
+	require.Len(t, body["records"], 1, body)
+	dead := body["records"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(http.StatusInternalServerError), dead["lastStatus"])
+	assert.Equal(t, "hooked", dead["payload"].(map[string]interface{})["data"].(map[string]interface{})["name"])
+
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"admin"}, "request": {"retryWebhookDelivery"},
+		"deliveryID": {fmt.Sprint(dead["deliveryID"])}})
+	require.Equal(t, "ok", status(body)["responseStatus"], body)
+	deliver()
+
+	require.Len(t, received, 2)
+	timestamp, err := strconv.ParseInt(received[1].Header.Get(webhookhelper.HeaderTimestamp), 10, 64)
+	require.Nil(t, err)
+	assert.Equal(t, webhookhelper.Sign(secret, timestamp, bodies[1]), received[1].Header.Get(webhookhelper.HeaderSignature))
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"admin"}, "request": {"getWebhookDeliveries"}, "status": {"delivered"}})
+	assert.Len(t, body["records"], 1, body)
+
+	// Only dead deliveries are retried
+	body = post(url.Values{"clientCode": {"1"}, "sessionKey": {"admin"}, "request": {"retryWebhookDelivery"},
+		"deliveryID": {fmt.Sprint(dead["deliveryID"])}})
+	assert.Equal(t, float64(1014), status(body)["errorCode"], body)
+}
+
+func TestEndToEnd_CampaignStream(t *testing.T) {
+	configuration := setup(t)
+	configuration.Events.Enabled = true
+	routes := []router2.Route{{
+		Method:      http.MethodGet,
+		Pattern:     "/campaigns/stream",
+		HandlerFunc: streamcampaigns.New(configuration).Handle,
+		Stream:      true,
+	}}
+	server := httptest.NewServer(router2.New(configuration, routes).GetEngine())
+	defer server.Close()
+
+	type event struct{ id, name, data string }
+	connect := func(query string, lastEventID string) (chan event, func()) {
+		request, err := http.NewRequest(http.MethodGet, server.URL+"/v1/campaigns/stream"+query, nil)
+		require.Nil(t, err)
+		request.Header.Set("clientCode", "1")
+		request.Header.Set("sessionKey", "viewer")
+		if lastEventID != "" {
+			request.Header.Set("Last-Event-ID", lastEventID)
+		}
+		response, err := http.DefaultClient.Do(request)
+		require.Nil(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+		events := make(chan event, 10)
+		reader := bufio.NewReader(response.Body)
+		heartbeat, err := reader.ReadString('\n')
+		require.Nil(t, err)
+		assert.Equal(t, ": heartbeat\n", heartbeat)
+		go func() {
+			var e event
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					close(events)
+					return
+				}
+				line = strings.TrimSuffix(line, "\n")
+				switch {
+				case strings.HasPrefix(line, "id: "):
+					e.id = strings.TrimPrefix(line, "id: ")
+				case strings.HasPrefix(line, "event: "):
+					e.name = strings.TrimPrefix(line, "event: ")
+				case strings.HasPrefix(line, "data: "):
+					e.data = strings.TrimPrefix(line, "data: ")
+				case line == "" && e.id != "":
+					events <- e
+					e = event{}
+				}
+			}
+		}()
+		return events, func() { response.Body.Close() }
+	}
+	next := func(events chan event) event {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(5 * time.Second):
+			t.Fatal("no event")
+			return event{}
+		}
+	}
+
+	events, disconnect := connect("?warehouseID=2", "")
+	repository := campaign.New(configuration)
+	spring := &campaign.Campaign{Name: "spring", Type: "auto", WarehouseID: 2}
+	require.Nil(t, repository.SaveCampaigns(context.Background(), spring))
+	require.Nil(t, repository.SaveCampaigns(context.Background(), &campaign.Campaign{Name: "elsewhere", Type: "auto", WarehouseID: 3}))
+	require.Nil(t, repository.DeleteCampaigns(context.Background(), spring.ID))
+
+	created := next(events)
+	assert.Equal(t, campaign.EventCreated, created.name)
+	var envelope outboxrelay.Envelope
+	require.Nil(t, json.Unmarshal([]byte(created.data), &envelope))
+	assert.Equal(t, spring.ID, envelope.AggregateID)
+	assert.Equal(t, "1", envelope.ClientCode)
+	deleted := next(events)
+	assert.Equal(t, campaign.EventDeleted, deleted.name)
+	disconnect()
+
+	// A client resumes after the last event it has
+	events, disconnect = connect("", created.id)
+	defer disconnect()
+	assert.Equal(t, "elsewhere", func() string {
+		var payload campaign.EventPayload
+		require.Nil(t, json.Unmarshal([]byte(next(events).data), &envelope))
+		require.Nil(t, json.Unmarshal(envelope.Data, &payload))
+		return payload.Name
+	}())
+	assert.Equal(t, deleted.id, next(events).id)
+
+	request, _ := http.NewRequest(http.MethodGet, server.URL+"/v1/campaigns/stream", nil)
+	request.Header.Set("clientCode", "1")
+	request.Header.Set("sessionKey", "viewer")
+	request.Header.Set("Last-Event-ID", "last")
+	response, err := http.DefaultClient.Do(request)
+	require.Nil(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
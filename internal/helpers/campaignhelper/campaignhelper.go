@@ -1,14 +1,19 @@
 package campaignhelper
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/config"
 	"github.com/zdarovich/promotion-api/internal/log"
-	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
 	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
 	configurationRepo "github.com/zdarovich/promotion-api/internal/repositories/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	"github.com/zdarovich/promotion-api/internal/repositories/segment"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
 	"reflect"
 	"strings"
 	"time"
@@ -20,127 +25,180 @@ type (
 		Configuration      *config.Configuration
 		CampaignRepository campaign.IRepository
 		ConfigRepository   configurationRepo.IRepository
+		SegmentRepository  segment.IRepository
+	}
+	// Relations settings and child records of the campaigns grouped by campaign id
+	Relations struct {
+		Settings    map[int]*settings.Settings
+		Tiers       map[int][]*tier.Tier
+		ProductSets map[int][]*productset.ProductSet
+		Schedules   map[int][]*schedule.Schedule
 	}
 	// ICampaignHelper interface
 	ICampaignHelper interface {
-		MapToArray(cs []campaign.Campaign, attrs map[int][]*attributes.Attribute) ([]RecordOutput, error)
+		MapToArray(ctx context.Context, cs []campaign.Campaign, relations Relations) ([]RecordOutput, error)
 		MapToOutput(records []Record) ([]RecordOutput, error)
-		Validate(attrs *Record) error
+		GetLocation(ctx context.Context, warehouseID int) (*time.Location, error)
+		Validate(ctx context.Context, attrs *Record) error
 	}
 	// record structure of the output record
 	RecordOutput struct {
-		CampaignID                                           int       `json:"campaignID"`
-		StartDate                                            time.Time `json:"startDate"`
-		EndDate                                              time.Time `json:"endDate"`
-		Name                                                 string    `json:"name"`
-		Type                                                 string    `json:"type"`
-		WarehouseID                                          int       `json:"warehouseID"`
-		AwardedProductGroupID                                int       `json:"awardedProductGroupID"`
-		AwardedBrandID                                       int       `json:"awardedBrandID"`
-		LowestPriceItemIsAwarded                             int       `json:"lowestPriceItemIsAwarded"`
-		PercentageOFF                                        float64   `json:"percentageOFF"`
-		SumOFF                                               float64   `json:"sumOFF"`
-		DiscountForOneLine                                   int       `json:"discountForOneLine"`
-		RequiredCouponID                                     string    `json:"requiredCouponID"`
-		RequiredCouponCode                                   string    `json:"requiredCouponCode"`
-		PurchasedProducts                                    string    `json:"purchasedProducts"`
-		AwardedProducts                                      string    `json:"awardedProducts"`
-		ExcludedProducts                                     string    `json:"excludedProducts"`
-		PercentageOffExcludedProducts                        string    `json:"percentageOffExcludedProducts"`
-		PercentageOffIncludedProducts                        string    `json:"percentageOffIncludedProducts"`
-		PurchasedProductSubsidies                            string    `json:"purchasedProductSubsidies"`
-		SumOffExcludedProducts                               string    `json:"sumOffExcludedProducts"`
-		SumOffIncludedProducts                               string    `json:"sumOffIncludedProducts"`
-		AwardedProductSubsidies                              string    `json:"awardedProductSubsidies"`
-		StoreRegionIDs                                       string    `json:"storeRegionIDs"`
-		CustomerGroupIDs                                     string    `json:"customerGroupIDs"`
-		AwardedAmount                                        int       `json:"awardedAmount"`
-		PurchasedProductCategoryID                           int       `json:"purchasedProductCategoryID"`
-		AwardedProductCategoryID                             int       `json:"awardedProductCategoryID"`
-		MaximumPointsDiscount                                int       `json:"maximumPointsDiscount"`
-		CustomerCanUseOnlyOnce                               int       `json:"customerCanUseOnlyOnce"`
-		PriceAtLeast                                         int       `json:"priceAtLeast"`
-		PriceAtMost                                          int       `json:"priceAtMost"`
-		RequiresManagerOverride                              int       `json:"requiresManagerOverride"`
-		SumOffMatchingItems                                  int       `json:"sumOffMatchingItems"`
-		PercentageOffMatchingItems                           int       `json:"percentageOffMatchingItems"`
-		ExcludeDiscountedFromPercentageOffEntirePurchase     int       `json:"excludeDiscountedFromPercentageOffEntirePurchase"`
-		ExcludePromotionItemsFromPercentageOffEntirePurchase int       `json:"excludePromotionItemsFromPercentageOffEntirePurchase"`
-		ReasonID                                             int       `json:"reasonID"`
-		SpecialUnitPrice                                     int       `json:"specialUnitPrice"`
-		MaxItemsWithSpecialUnitPrice                         int       `json:"maxItemsWithSpecialUnitPrice"`
-		RedemptionLimit                                      int       `json:"redemptionLimit"`
-		StoreGroup                                           string    `json:"storeGroup"`
-		CanBeAppliedManuallyMultipleTimes                    int       `json:"canBeAppliedManuallyMultipleTimes"`
-		PurchasedProductGroupID                              int       `json:"purchasedProductGroupID"`
-		PurchasedBrandID                                     int       `json:"purchasedBrandID"`
-		PurchasedAmount                                      int       `json:"purchasedAmount"`
-		PurchaseTotalValue                                   float64   `json:"purchaseTotalValue"`
-		RewardPoints                                         int       `json:"rewardPoints"`
-		PercentageOffEntirePurchase                          int       `json:"percentageOffEntirePurchase"`
-		SumOffEntirePurchase                                 float64   `json:"sumOffEntirePurchase"`
-		SpecialPrice                                         float64   `json:"specialPrice"`
-		Added                                                int64     `json:"added"`
-		Addedby                                              string    `json:"addedby"`
-		Changed                                              int64     `json:"changed"`
-		Changedby                                            string    `json:"changedby"`
+		CampaignID                                           int          `json:"campaignID"`
+		StartDate                                            time.Time    `json:"startDate"`
+		EndDate                                              time.Time    `json:"endDate"`
+		StartDateLocal                                       string       `json:"startDateLocal"`
+		EndDateLocal                                         string       `json:"endDateLocal"`
+		Timezone                                             string       `json:"timezone"`
+		Name                                                 string       `json:"name"`
+		Type                                                 string       `json:"type"`
+		WarehouseID                                          int          `json:"warehouseID"`
+		AwardedProductGroupID                                int          `json:"awardedProductGroupID"`
+		AwardedBrandID                                       int          `json:"awardedBrandID"`
+		LowestPriceItemIsAwarded                             int          `json:"lowestPriceItemIsAwarded"`
+		PercentageOFF                                        float64      `json:"percentageOFF"`
+		SumOFF                                               float64      `json:"sumOFF"`
+		DiscountForOneLine                                   int          `json:"discountForOneLine"`
+		RequiredCouponID                                     string       `json:"requiredCouponID"`
+		RequiredCouponCode                                   string       `json:"requiredCouponCode"`
+		PurchasedProducts                                    string       `json:"purchasedProducts"`
+		AwardedProducts                                      string       `json:"awardedProducts"`
+		ExcludedProducts                                     string       `json:"excludedProducts"`
+		PercentageOffExcludedProducts                        string       `json:"percentageOffExcludedProducts"`
+		PercentageOffIncludedProducts                        string       `json:"percentageOffIncludedProducts"`
+		PurchasedProductSubsidies                            string       `json:"purchasedProductSubsidies"`
+		SumOffExcludedProducts                               string       `json:"sumOffExcludedProducts"`
+		SumOffIncludedProducts                               string       `json:"sumOffIncludedProducts"`
+		AwardedProductSubsidies                              string       `json:"awardedProductSubsidies"`
+		StoreRegionIDs                                       string       `json:"storeRegionIDs"`
+		CustomerGroupIDs                                     string       `json:"customerGroupIDs"`
+		CustomerSegmentIDs                                   string       `json:"customerSegmentIDs"`
+		CustomerAllowlist                                    int          `json:"customerAllowlist"`
+		AwardedAmount                                        int          `json:"awardedAmount"`
+		PurchasedProductCategoryID                           int          `json:"purchasedProductCategoryID"`
+		AwardedProductCategoryID                             int          `json:"awardedProductCategoryID"`
+		MaximumPointsDiscount                                int          `json:"maximumPointsDiscount"`
+		CustomerCanUseOnlyOnce                               int          `json:"customerCanUseOnlyOnce"`
+		PriceAtLeast                                         int          `json:"priceAtLeast"`
+		PriceAtMost                                          int          `json:"priceAtMost"`
+		RequiresManagerOverride                              int          `json:"requiresManagerOverride"`
+		SumOffMatchingItems                                  int          `json:"sumOffMatchingItems"`
+		PercentageOffMatchingItems                           int          `json:"percentageOffMatchingItems"`
+		ExcludeDiscountedFromPercentageOffEntirePurchase     int          `json:"excludeDiscountedFromPercentageOffEntirePurchase"`
+		ExcludePromotionItemsFromPercentageOffEntirePurchase int          `json:"excludePromotionItemsFromPercentageOffEntirePurchase"`
+		ReasonID                                             int          `json:"reasonID"`
+		SpecialUnitPrice                                     int          `json:"specialUnitPrice"`
+		MaxItemsWithSpecialUnitPrice                         int          `json:"maxItemsWithSpecialUnitPrice"`
+		RedemptionLimit                                      int          `json:"redemptionLimit"`
+		StoreGroup                                           string       `json:"storeGroup"`
+		CanBeAppliedManuallyMultipleTimes                    int          `json:"canBeAppliedManuallyMultipleTimes"`
+		PurchasedProductGroupID                              int          `json:"purchasedProductGroupID"`
+		PurchasedBrandID                                     int          `json:"purchasedBrandID"`
+		PurchasedAmount                                      int          `json:"purchasedAmount"`
+		PurchaseTotalValue                                   float64      `json:"purchaseTotalValue"`
+		RewardPoints                                         int          `json:"rewardPoints"`
+		PercentageOffEntirePurchase                          int          `json:"percentageOffEntirePurchase"`
+		SumOffEntirePurchase                                 float64      `json:"sumOffEntirePurchase"`
+		SpecialPrice                                         float64      `json:"specialPrice"`
+		Tiers                                                []Tier       `json:"tiers"`
+		ProductSets                                          []ProductSet `json:"productSets"`
+		BundlePrice                                          float64      `json:"bundlePrice"`
+		BundleSumOff                                         float64      `json:"bundleSumOff"`
+		BundlePercentageOff                                  float64      `json:"bundlePercentageOff"`
+		Schedules                                            []Schedule   `json:"schedules"`
+		Priority                                             int          `json:"priority"`
+		Exclusive                                            int          `json:"exclusive"`
+		StackingGroup                                        string       `json:"stackingGroup"`
+		CombinableWith                                       string       `json:"combinableWith"`
+		Added                                                int64        `json:"added"`
+		Addedby                                              string       `json:"addedby"`
+		Changed                                              int64        `json:"changed"`
+		Changedby                                            string       `json:"changedby"`
 	}
+	// Record structure of the input record
 	Record struct {
-		CampaignID                                           int       `json:"campaignID"`
-		StartDate                                            time.Time `json:"startDate"`
-		EndDate                                              time.Time `json:"endDate"`
-		Name                                                 string    `json:"name"`
-		Type                                                 string    `json:"type"`
-		WarehouseID                                          int       `json:"warehouseID"`
-		AwardedProductGroupID                                int       `json:"awardedProductGroupID"`
-		AwardedBrandID                                       int       `json:"awardedBrandID"`
-		LowestPriceItemIsAwarded                             bool      `json:"lowestPriceItemIsAwarded"`
-		PercentageOFF                                        float64   `json:"percentageOFF"`
-		SumOFF                                               float64   `json:"sumOFF"`
-		DiscountForOneLine                                   int       `json:"discountForOneLine"`
-		RequiredCouponID                                     string    `json:"requiredCouponID"`
-		RequiredCouponCode                                   string    `json:"requiredCouponCode"`
-		PurchasedProducts                                    []string  `json:"purchasedProducts"`
-		AwardedProducts                                      []string  `json:"awardedProducts"`
-		ExcludedProducts                                     []string  `json:"excludedProducts"`
-		PercentageOffExcludedProducts                        []string  `json:"percentageOffExcludedProducts"`
-		PercentageOffIncludedProducts                        []string  `json:"percentageOffIncludedProducts"`
-		PurchasedProductSubsidies                            []string  `json:"purchasedProductSubsidies"`
-		SumOffExcludedProducts                               []string  `json:"sumOffExcludedProducts"`
-		SumOffIncludedProducts                               []string  `json:"sumOffIncludedProducts"`
-		AwardedProductSubsidies                              []string  `json:"awardedProductSubsidies"`
-		StoreRegionIDs                                       []int     `json:"storeRegionIDs"`
-		CustomerGroupIDs                                     []int     `json:"customerGroupIDs"`
-		AwardedAmount                                        int       `json:"awardedAmount"`
-		PurchasedProductCategoryID                           int       `json:"purchasedProductCategoryID"`
-		AwardedProductCategoryID                             int       `json:"awardedProductCategoryID"`
-		MaximumPointsDiscount                                int       `json:"maximumPointsDiscount"`
-		CustomerCanUseOnlyOnce                               bool      `json:"customerCanUseOnlyOnce"`
-		PriceAtLeast                                         int       `json:"priceAtLeast"`
-		PriceAtMost                                          int       `json:"priceAtMost"`
-		RequiresManagerOverride                              bool      `json:"requiresManagerOverride"`
-		SumOffMatchingItems                                  int       `json:"sumOffMatchingItems"`
-		PercentageOffMatchingItems                           int       `json:"percentageOffMatchingItems"`
-		ExcludeDiscountedFromPercentageOffEntirePurchase     bool      `json:"excludeDiscountedFromPercentageOffEntirePurchase"`
-		ExcludePromotionItemsFromPercentageOffEntirePurchase bool      `json:"excludePromotionItemsFromPercentageOffEntirePurchase"`
-		ReasonID                                             int       `json:"reasonID"`
-		SpecialUnitPrice                                     int       `json:"specialUnitPrice"`
-		MaxItemsWithSpecialUnitPrice                         int       `json:"maxItemsWithSpecialUnitPrice"`
-		RedemptionLimit                                      int       `json:"redemptionLimit"`
-		StoreGroup                                           string    `json:"storeGroup"`
-		CanBeAppliedManuallyMultipleTimes                    int       `json:"canBeAppliedManuallyMultipleTimes"`
-		PurchasedProductGroupID                              int       `json:"purchasedProductGroupID"`
-		PurchasedBrandID                                     int       `json:"purchasedBrandID"`
-		PurchasedAmount                                      int       `json:"purchasedAmount"`
-		PurchaseTotalValue                                   float64   `json:"purchaseTotalValue"`
-		RewardPoints                                         int       `json:"rewardPoints"`
-		PercentageOffEntirePurchase                          int       `json:"percentageOffEntirePurchase"`
-		SumOffEntirePurchase                                 float64   `json:"sumOffEntirePurchase"`
-		SpecialPrice                                         float64   `json:"specialPrice"`
-		Added                                                int64     `json:"added"`
-		Addedby                                              string    `json:"addedby"`
-		Changed                                              int64     `json:"changed"`
-		Changedby                                            string    `json:"changedby"`
+		CampaignID                                           int          `json:"campaignID"`
+		StartDate                                            time.Time    `json:"startDate"`
+		EndDate                                              time.Time    `json:"endDate"`
+		Name                                                 string       `json:"name"`
+		Type                                                 string       `json:"type"`
+		WarehouseID                                          int          `json:"warehouseID"`
+		AwardedProductGroupID                                int          `json:"awardedProductGroupID"`
+		AwardedBrandID                                       int          `json:"awardedBrandID"`
+		LowestPriceItemIsAwarded                             bool         `json:"lowestPriceItemIsAwarded"`
+		PercentageOFF                                        float64      `json:"percentageOFF"`
+		SumOFF                                               float64      `json:"sumOFF"`
+		DiscountForOneLine                                   int          `json:"discountForOneLine"`
+		RequiredCouponID                                     string       `json:"requiredCouponID"`
+		RequiredCouponCode                                   string       `json:"requiredCouponCode"`
+		PurchasedProducts                                    []string     `json:"purchasedProducts"`
+		AwardedProducts                                      []string     `json:"awardedProducts"`
+		ExcludedProducts                                     []string     `json:"excludedProducts"`
+		PercentageOffExcludedProducts                        []string     `json:"percentageOffExcludedProducts"`
+		PercentageOffIncludedProducts                        []string     `json:"percentageOffIncludedProducts"`
+		PurchasedProductSubsidies                            []string     `json:"purchasedProductSubsidies"`
+		SumOffExcludedProducts                               []string     `json:"sumOffExcludedProducts"`
+		SumOffIncludedProducts                               []string     `json:"sumOffIncludedProducts"`
+		AwardedProductSubsidies                              []string     `json:"awardedProductSubsidies"`
+		StoreRegionIDs                                       []int        `json:"storeRegionIDs"`
+		CustomerGroupIDs                                     []int        `json:"customerGroupIDs"`
+		CustomerSegmentIDs                                   []int        `json:"customerSegmentIDs"`
+		CustomerAllowlist                                    bool         `json:"customerAllowlist"`
+		AwardedAmount                                        int          `json:"awardedAmount"`
+		PurchasedProductCategoryID                           int          `json:"purchasedProductCategoryID"`
+		AwardedProductCategoryID                             int          `json:"awardedProductCategoryID"`
+		MaximumPointsDiscount                                int          `json:"maximumPointsDiscount"`
+		CustomerCanUseOnlyOnce                               bool         `json:"customerCanUseOnlyOnce"`
+		PriceAtLeast                                         int          `json:"priceAtLeast"`
+		PriceAtMost                                          int          `json:"priceAtMost"`
+		RequiresManagerOverride                              bool         `json:"requiresManagerOverride"`
+		SumOffMatchingItems                                  int          `json:"sumOffMatchingItems"`
+		PercentageOffMatchingItems                           int          `json:"percentageOffMatchingItems"`
+		ExcludeDiscountedFromPercentageOffEntirePurchase     bool         `json:"excludeDiscountedFromPercentageOffEntirePurchase"`
+		ExcludePromotionItemsFromPercentageOffEntirePurchase bool         `json:"excludePromotionItemsFromPercentageOffEntirePurchase"`
+		ReasonID                                             int          `json:"reasonID"`
+		SpecialUnitPrice                                     int          `json:"specialUnitPrice"`
+		MaxItemsWithSpecialUnitPrice                         int          `json:"maxItemsWithSpecialUnitPrice"`
+		RedemptionLimit                                      int          `json:"redemptionLimit"`
+		StoreGroup                                           string       `json:"storeGroup"`
+		CanBeAppliedManuallyMultipleTimes                    int          `json:"canBeAppliedManuallyMultipleTimes"`
+		PurchasedProductGroupID                              int          `json:"purchasedProductGroupID"`
+		PurchasedBrandID                                     int          `json:"purchasedBrandID"`
+		PurchasedAmount                                      int          `json:"purchasedAmount"`
+		PurchaseTotalValue                                   float64      `json:"purchaseTotalValue"`
+		RewardPoints                                         int          `json:"rewardPoints"`
+		PercentageOffEntirePurchase                          int          `json:"percentageOffEntirePurchase"`
+		SumOffEntirePurchase                                 float64      `json:"sumOffEntirePurchase"`
+		SpecialPrice                                         float64      `json:"specialPrice"`
+		Tiers                                                []Tier       `json:"tiers"`
+		ProductSets                                          []ProductSet `json:"productSets"`
+		BundlePrice                                          float64      `json:"bundlePrice"`
+		BundleSumOff                                         float64      `json:"bundleSumOff"`
+		BundlePercentageOff                                  float64      `json:"bundlePercentageOff"`
+		Schedules                                            []Schedule   `json:"schedules"`
+		Priority                                             int          `json:"priority"`
+		Exclusive                                            bool         `json:"exclusive"`
+		StackingGroup                                        string       `json:"stackingGroup"`
+		CombinableWith                                       []int        `json:"combinableWith"`
+		Added                                                int64        `json:"added"`
+		Addedby                                              string       `json:"addedby"`
+		Changed                                              int64        `json:"changed"`
+		Changedby                                            string       `json:"changedby"`
+	}
+	// Tier structure of a single threshold and award step of a tiered campaign
+	Tier struct {
+		PurchasedAmount    int     `json:"purchasedAmount"`
+		PurchaseTotalValue float64 `json:"purchaseTotalValue"`
+		PercentageOFF      float64 `json:"percentageOFF"`
+		SumOFF             float64 `json:"sumOFF"`
+		SpecialPrice       float64 `json:"specialPrice"`
+	}
+	// ProductSet structure of a named product set of a bundle or mix-and-match campaign
+	ProductSet struct {
+		Name              string   `json:"name"`
+		Amount            int      `json:"amount"`
+		Products          []string `json:"products"`
+		ProductGroupID    int      `json:"productGroupID"`
+		ProductCategoryID int      `json:"productCategoryID"`
 	}
 )
 
@@ -148,18 +206,35 @@ type (
 func New(configuration *config.Configuration) ICampaignHelper {
 
 	return &CampaignHelper{
-		Configuration:    configuration,
-		ConfigRepository: configurationRepo.New(configuration),
+		Configuration:      configuration,
+		CampaignRepository: campaign.New(configuration),
+		ConfigRepository:   configurationRepo.New(configuration),
+		SegmentRepository:  segment.New(configuration),
 	}
 }
 
-func (p *CampaignHelper) MapToArray(cs []campaign.Campaign, attrs map[int][]*attributes.Attribute) ([]RecordOutput, error) {
+// MapToArray maps database campaigns, their settings and child records to output records
+func (p *CampaignHelper) MapToArray(ctx context.Context, cs []campaign.Campaign, relations Relations) ([]RecordOutput, error) {
 	result := make([]RecordOutput, len(cs))
+	locations := make(map[int]*time.Location)
 	for idx, c := range cs {
+		loc, ok := locations[c.WarehouseID]
+		if !ok {
+			var err error
+			loc, err = p.GetLocation(ctx, c.WarehouseID)
+			if err != nil {
+				return nil, err
+			}
+			locations[c.WarehouseID] = loc
+		}
+
 		ro := RecordOutput{}
 		ro.CampaignID = c.ID
-		ro.StartDate = c.StartDate
-		ro.EndDate = c.EndDate
+		ro.StartDate = c.StartDate.UTC()
+		ro.EndDate = c.EndDate.UTC()
+		ro.StartDateLocal = c.StartDate.In(loc).Format(LocalTimeFormat)
+		ro.EndDateLocal = c.EndDate.In(loc).Format(LocalTimeFormat)
+		ro.Timezone = loc.String()
 		ro.Name = c.Name
 		ro.Type = c.Type
 		ro.WarehouseID = c.WarehouseID
@@ -183,38 +258,11 @@ func (p *CampaignHelper) MapToArray(cs []campaign.Campaign, attrs map[int][]*att
 		ro.Addedby = c.Addedby
 		ro.Changed = c.Changed
 		ro.Changedby = c.Changedby
-
-		vals := make(map[string]interface{})
-		for _, attr := range attrs[c.ID] {
-			switch attr.Type {
-			case "text":
-				vals[attr.Name] = attr.ValueText
-			case "int":
-				vals[attr.Name] = attr.ValueInt
-			case "double":
-				vals[attr.Name] = attr.ValueDouble
-			}
-		}
-
-		v := reflect.ValueOf(&ro)
-		v = reflect.Indirect(v)
-		t := v.Type()
-		for i := 0; i < v.NumField(); i++ {
-			field := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
-			val, ok := vals[field]
-			if !ok {
-				continue
-			}
-
-			value := v.Field(i)
-			switch e := val.(type) {
-			case int:
-				value.SetInt(int64(e))
-			case string:
-				value.SetString(e)
-			case float64:
-				value.SetFloat(e)
-			}
+		ro.Tiers = MapTiersToOutput(relations.Tiers[c.ID])
+		ro.ProductSets = MapProductSetsToOutput(relations.ProductSets[c.ID])
+		ro.Schedules = MapSchedulesToOutput(relations.Schedules[c.ID])
+		if cs, ok := relations.Settings[c.ID]; ok {
+			MapSettingsToOutput(&ro, cs)
 		}
 		result[idx] = ro
 	}
@@ -275,11 +323,11 @@ func (p *CampaignHelper) MapToOutput(records []Record) ([]RecordOutput, error) {
 	return output, nil
 }
 
-func (p *CampaignHelper) Validate(r *Record) error {
+func (p *CampaignHelper) Validate(ctx context.Context, r *Record) error {
 	if r == nil {
 		return errors.New("record is null")
 	}
-	c, err := p.ConfigRepository.GetConfigByName("vertical")
+	c, err := p.ConfigRepository.GetConfigByName(ctx, "vertical")
 	if err != nil {
 		log.Error(err)
 		return errors.New("1006")
@@ -346,6 +394,135 @@ func (p *CampaignHelper) Validate(r *Record) error {
 		return errors.New("1144")
 	} else if !IsRedemptionLimitAndMaxItemsWithSpecialUnitPrice(r) {
 		return errors.New("1145")
+	} else if !IsSubsidiesNumeric(r.PurchasedProductSubsidies) {
+		return errorcodes.New("purchasedProductSubsidies", 1014)
+	} else if !IsSubsidiesNumeric(r.AwardedProductSubsidies) {
+		return errorcodes.New("awardedProductSubsidies", 1014)
+	}
+
+	// tiered campaign requirements
+	if !IsTiersAndNoSingleThresholdOrAward(r) {
+		return errors.New("1150")
+	} else if !IsTiersSingleThresholdType(r) {
+		return errors.New("1151")
+	} else if !IsTiersThresholdsAscending(r) {
+		return errors.New("1152")
+	} else if !IsTiersSingleAward(r) {
+		return errors.New("1153")
+	} else if !IsQuantityTiersAndPurchasedProductOptions(r) {
+		return errors.New("1154")
+	}
+
+	// bundle and mix-and-match campaign requirements
+	if !IsProductSetsAndNoOtherPurchaseOrAwardOptions(r) {
+		return errors.New("1160")
+	} else if !IsProductSetsValid(r) {
+		return errors.New("1161")
+	} else if !IsProductSetNamesUnique(r) {
+		return errors.New("1162")
+	} else if !IsProductSetsAndSingleBundleAward(r) {
+		return errors.New("1163")
+	}
+
+	// recurring schedule requirements
+	if !IsSchedulesRestricting(r) {
+		return errors.New("1170")
+	} else if !IsSchedulesDaysValid(r) {
+		return errors.New("1171")
+	} else if !IsSchedulesWeeksOfMonthAndDaysOfWeek(r) {
+		return errors.New("1172")
+	} else if !IsSchedulesTimeWindowValid(r) {
+		return errors.New("1173")
+	}
+
+	// stacking requirements
+	if !IsExclusiveAndNotStackingGroupOrCombinableWith(r) {
+		return errors.New("1183")
+	} else if !IsCombinableWithNotSelf(r) {
+		return errors.New("1184")
+	} else if !IsStackingGroupValid(r) {
+		return errors.New("1186")
+	} else if !p.IsCombinableWithExisting(ctx, r) {
+		return errors.New("1185")
+	}
+
+	// customer segment requirements
+	if !p.IsCustomerSegmentIDsExisting(ctx, r) {
+		return errors.New("1194")
 	}
 	return nil
 }
+
+// MapTiersToOutput maps database tiers to output tiers
+func MapTiersToOutput(tiers []*tier.Tier) []Tier {
+	if len(tiers) == 0 {
+		return nil
+	}
+	output := make([]Tier, len(tiers))
+	for idx, t := range tiers {
+		output[idx] = Tier{
+			PurchasedAmount:    t.PurchasedAmount,
+			PurchaseTotalValue: t.PurchaseTotalValue,
+			PercentageOFF:      t.PercentageOff,
+			SumOFF:             t.SumOff,
+			SpecialPrice:       t.SpecialPrice,
+		}
+	}
+	return output
+}
+
+// MapProductSetsToOutput maps database product sets to output product sets
+func MapProductSetsToOutput(sets []*productset.ProductSet) []ProductSet {
+	if len(sets) == 0 {
+		return nil
+	}
+	output := make([]ProductSet, len(sets))
+	for idx, s := range sets {
+		var products []string
+		if len(s.Products) > 0 {
+			products = strings.Split(s.Products, ",")
+		}
+		output[idx] = ProductSet{
+			Name:              s.Name,
+			Amount:            s.Amount,
+			Products:          products,
+			ProductGroupID:    s.ProductGroupID,
+			ProductCategoryID: s.ProductCategoryID,
+		}
+	}
+	return output
+}
+
+// MapProductSetsToDatabase maps the record product sets to database product sets of the campaign
+func MapProductSetsToDatabase(r *Record, campaignID int) []*productset.ProductSet {
+	sets := make([]*productset.ProductSet, len(r.ProductSets))
+	for idx, s := range r.ProductSets {
+		sets[idx] = &productset.ProductSet{
+			CampaignID:        campaignID,
+			Position:          idx,
+			Name:              s.Name,
+			Amount:            s.Amount,
+			Products:          strings.Join(s.Products, ","),
+			ProductGroupID:    s.ProductGroupID,
+			ProductCategoryID: s.ProductCategoryID,
+		}
+	}
+	return sets
+}
+
+// MapTiersToDatabase maps the record tiers to database tiers of the campaign
+func MapTiersToDatabase(r *Record, campaignID int) []*tier.Tier {
+	tiers := make([]*tier.Tier, len(r.Tiers))
+	for idx, t := range r.Tiers {
+		tiers[idx] = &tier.Tier{
+			CampaignID:         campaignID,
+			Position:           idx,
+			PurchasedAmount:    t.PurchasedAmount,
+			PurchaseTotalValue: t.PurchaseTotalValue,
+			PercentageOff:      t.PercentageOFF,
+			SumOff:             t.SumOFF,
+			SpecialPrice:       t.SpecialPrice,
+		}
+	}
+	return tiers
+}
@@ -1,7 +1,9 @@
 package campaignhelper
 
 import (
+	"context"
 	"errors"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/repositories/config"
@@ -14,7 +16,7 @@ import (
 func TestCampaignHelper_Validate_NoError(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -25,7 +27,7 @@ func TestCampaignHelper_Validate_NoError(t *testing.T) {
 	r.PurchasedAmount = 66
 	r.WarehouseID = 1
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 
 	require.Nil(t, err)
 }
@@ -33,7 +35,7 @@ func TestCampaignHelper_Validate_NoError(t *testing.T) {
 func TestCampaignHelper_Validate_IsTypeValid(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -45,29 +47,29 @@ func TestCampaignHelper_Validate_IsTypeValid(t *testing.T) {
 
 	r.Type = "test"
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errorcodes.New("type", 1014))
 
 	r.Type = "auto"
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, nil)
 
 	r.Type = "coupon"
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, nil)
 
 	r.Type = "manual"
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, nil)
 }
 
 func TestCampaignHelper_Validate_IsStoreRegionIDsEnabled(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -79,14 +81,14 @@ func TestCampaignHelper_Validate_IsStoreRegionIDsEnabled(t *testing.T) {
 
 	r.StoreRegionIDs = []int{1, 2, 3}
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1028"))
 }
 
 func TestCampaignHelper_Validate_IsCustomerGroupIDsEnabled(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -98,14 +100,14 @@ func TestCampaignHelper_Validate_IsCustomerGroupIDsEnabled(t *testing.T) {
 
 	r.CustomerGroupIDs = []int{1, 2, 3}
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1028"))
 }
 
 func TestCampaignHelper_Validate_IsStartDateValid(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -115,19 +117,19 @@ func TestCampaignHelper_Validate_IsStartDateValid(t *testing.T) {
 	r.PurchasedProducts = []string{"milk", "cookie"}
 	r.WarehouseID = 1
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errorcodes.New("startDate", 1014))
 
 	r.StartDate = time.Now().Add(-1 * time.Hour)
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errorcodes.New("startDate", 1014))
 }
 
 func TestCampaignHelper_Validate_IsEndDateValid(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -137,19 +139,19 @@ func TestCampaignHelper_Validate_IsEndDateValid(t *testing.T) {
 	r.PurchasedProducts = []string{"milk", "cookie"}
 	r.WarehouseID = 1
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errorcodes.New("endDate", 1014))
 
 	r.EndDate = time.Now().Add(-1 * time.Hour)
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errorcodes.New("endDate", 1014))
 }
 
 func TestCampaignHelper_Validate_IsRequiresManagerOverrideAndNotAutomaticOrNotCoupon(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -161,20 +163,20 @@ func TestCampaignHelper_Validate_IsRequiresManagerOverrideAndNotAutomaticOrNotCo
 
 	r.Type = "auto"
 	r.RequiresManagerOverride = true
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1076"))
 
 	r.Type = "coupon"
 	r.RequiresManagerOverride = true
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1076"))
 }
 
 func TestCampaignHelper_Validate_IsMultipleSetting(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -187,14 +189,14 @@ func TestCampaignHelper_Validate_IsMultipleSetting(t *testing.T) {
 	r.WarehouseID = 1
 	r.StoreGroup = "test"
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1110"))
 }
 
 func TestCampaignHelper_Validate_IsPurchasedProductGroupIDOrPurchasedProductCategoryIDOrPurchasedProductsAndPurchasedAmount(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -208,7 +210,7 @@ func TestCampaignHelper_Validate_IsPurchasedProductGroupIDOrPurchasedProductCate
 	r.PurchasedProducts = []string{"milk", "cookie"}
 	r.PurchasedAmount = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1111"))
 
 	r.PurchasedProductGroupID = 1
@@ -216,7 +218,7 @@ func TestCampaignHelper_Validate_IsPurchasedProductGroupIDOrPurchasedProductCate
 	r.PurchasedProducts = []string{""}
 	r.PurchasedAmount = 0
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1111"))
 
 	r.PurchasedProductGroupID = 0
@@ -224,7 +226,7 @@ func TestCampaignHelper_Validate_IsPurchasedProductGroupIDOrPurchasedProductCate
 	r.PurchasedProducts = []string{""}
 	r.PurchasedAmount = 0
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1111"))
 
 }
@@ -232,7 +234,7 @@ func TestCampaignHelper_Validate_IsPurchasedProductGroupIDOrPurchasedProductCate
 func TestCampaignHelper_Validate_IsMultipleProductOptions(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -246,7 +248,7 @@ func TestCampaignHelper_Validate_IsMultipleProductOptions(t *testing.T) {
 	r.PurchasedProducts = []string{"milk", "cookie"}
 	r.PurchasedAmount = 12
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1112"))
 
 	r.PurchasedProductGroupID = 1
@@ -254,7 +256,7 @@ func TestCampaignHelper_Validate_IsMultipleProductOptions(t *testing.T) {
 	r.PurchasedProducts = []string{""}
 	r.PurchasedAmount = 12
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1112"))
 
 	r.PurchasedProductGroupID = 1
@@ -262,14 +264,14 @@ func TestCampaignHelper_Validate_IsMultipleProductOptions(t *testing.T) {
 	r.PurchasedProducts = []string{"milk", "cookie"}
 	r.PurchasedAmount = 12
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1112"))
 }
 
 func TestCampaignHelper_Validate_IsAwardedProductOptionsAndSumOffOrPercentageOff(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -287,7 +289,7 @@ func TestCampaignHelper_Validate_IsAwardedProductOptionsAndSumOffOrPercentageOff
 	r.SumOFF = 0
 	r.PercentageOFF = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1113"))
 
 	r.AwardedProductGroupID = 0
@@ -297,7 +299,7 @@ func TestCampaignHelper_Validate_IsAwardedProductOptionsAndSumOffOrPercentageOff
 	r.SumOFF = 0
 	r.PercentageOFF = 0
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1113"))
 
 	r.AwardedProductGroupID = 0
@@ -307,7 +309,7 @@ func TestCampaignHelper_Validate_IsAwardedProductOptionsAndSumOffOrPercentageOff
 	r.SumOFF = 0
 	r.PercentageOFF = 0
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1113"))
 
 	r.AwardedProductGroupID = 0
@@ -317,14 +319,14 @@ func TestCampaignHelper_Validate_IsAwardedProductOptionsAndSumOffOrPercentageOff
 	r.SumOFF = 0
 	r.PercentageOFF = 0
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1113"))
 }
 
 func TestCampaignHelper_Validate_IsMultipleAwardOptions(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -341,7 +343,7 @@ func TestCampaignHelper_Validate_IsMultipleAwardOptions(t *testing.T) {
 	r.AwardedAmount = 0
 	r.SumOFF = 12
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1114"))
 
 	r.AwardedProductGroupID = 1
@@ -350,7 +352,7 @@ func TestCampaignHelper_Validate_IsMultipleAwardOptions(t *testing.T) {
 	r.AwardedAmount = 0
 	r.SumOFF = 12
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1114"))
 
 	r.AwardedProductGroupID = 0
@@ -359,14 +361,14 @@ func TestCampaignHelper_Validate_IsMultipleAwardOptions(t *testing.T) {
 	r.AwardedAmount = 1
 	r.SumOFF = 12
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1114"))
 }
 
 func TestCampaignHelper_Validate_IsPercentageExclInclProductsAndPercentageOffEntirePurchase(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -381,14 +383,14 @@ func TestCampaignHelper_Validate_IsPercentageExclInclProductsAndPercentageOffEnt
 	r.PercentageOffIncludedProducts = []string{"12", "13"}
 	r.PercentageOffEntirePurchase = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1115"))
 }
 
 func TestCampaignHelper_Validate_IsSumExclInclProductsAndSumOffEntirePurchase(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -403,14 +405,14 @@ func TestCampaignHelper_Validate_IsSumExclInclProductsAndSumOffEntirePurchase(t
 	r.SumOffExcludedProducts = []string{"12", "13"}
 	r.SumOffEntirePurchase = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1116"))
 }
 
 func TestCampaignHelper_Validate_IsMaximumPointsDiscountAndRewardPointsAndSumOffEntirePurchase(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -425,28 +427,28 @@ func TestCampaignHelper_Validate_IsMaximumPointsDiscountAndRewardPointsAndSumOff
 	r.RewardPoints = 10
 	r.SumOffEntirePurchase = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1118"))
 
 	r.MaximumPointsDiscount = 10
 	r.RewardPoints = 0
 	r.SumOffEntirePurchase = 10
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1118"))
 
 	r.MaximumPointsDiscount = 0
 	r.RewardPoints = 10
 	r.SumOffEntirePurchase = 10
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1118"))
 }
 
 func TestCampaignHelper_Validate_IsLowestPriceItemIsAwardedAndSumOffOrPercentageOff(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -461,14 +463,14 @@ func TestCampaignHelper_Validate_IsLowestPriceItemIsAwardedAndSumOffOrPercentage
 	r.SumOFF = 0
 	r.PercentageOFF = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1119"))
 }
 
 func TestCampaignHelper_Validate_IsExcludeDiscountedFromPercentageOffEntirePurchaseAndPercentageOffEntirePurchase(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -482,14 +484,14 @@ func TestCampaignHelper_Validate_IsExcludeDiscountedFromPercentageOffEntirePurch
 	r.ExcludeDiscountedFromPercentageOffEntirePurchase = true
 	r.PercentageOffEntirePurchase = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1129"))
 }
 
 func TestCampaignHelper_Validate_IsExcludePromotionItemsFromPercentageOffEntirePurchaseAndPercentageOffEntirePurchase(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -503,14 +505,14 @@ func TestCampaignHelper_Validate_IsExcludePromotionItemsFromPercentageOffEntireP
 	r.ExcludePromotionItemsFromPercentageOffEntirePurchase = true
 	r.PercentageOffEntirePurchase = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1182"))
 }
 
 func TestCampaignHelper_Validate_IsPurchasedProductSubsidiesAndPurchasedProductsAndPercentageOffMatchingItemsOrSumOffMatchingItems(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -525,14 +527,14 @@ func TestCampaignHelper_Validate_IsPurchasedProductSubsidiesAndPurchasedProducts
 	r.PercentageOffMatchingItems = 0
 	r.SumOffMatchingItems = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1132"))
 }
 
 func TestCampaignHelper_Validate_IsPurchasedProductSubsidiesLenEqualsPurchasedProductsLen(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -547,14 +549,14 @@ func TestCampaignHelper_Validate_IsPurchasedProductSubsidiesLenEqualsPurchasedPr
 	r.PercentageOffMatchingItems = 12
 	r.SumOffMatchingItems = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1133"))
 }
 
 func TestCampaignHelper_Validate_IsAwardedProductSubsidiesLenEqualsAwardedProductsLen(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -568,14 +570,14 @@ func TestCampaignHelper_Validate_IsAwardedProductSubsidiesLenEqualsAwardedProduc
 	r.AwardedProducts = []string{"milk", "cookie", "cake"}
 	r.SumOFF = 12
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1134"))
 }
 
 func TestCampaignHelper_Validate_IsMaxItemsWithSpecialUnitPriceEqualsOrBiggerPurchasedAmount(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -588,14 +590,14 @@ func TestCampaignHelper_Validate_IsMaxItemsWithSpecialUnitPriceEqualsOrBiggerPur
 
 	r.MaxItemsWithSpecialUnitPrice = 5
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1140"))
 }
 
 func TestCampaignHelper_Validate_IsRedemptionLimitAndNotPercentageOfEntirePurchaseAndNotRewardPoints(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -612,7 +614,7 @@ func TestCampaignHelper_Validate_IsRedemptionLimitAndNotPercentageOfEntirePurcha
 	r.SumOffEntirePurchase = 1
 	r.MaximumPointsDiscount = 1
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1144"))
 
 	r.RedemptionLimit = 5
@@ -621,7 +623,7 @@ func TestCampaignHelper_Validate_IsRedemptionLimitAndNotPercentageOfEntirePurcha
 	r.SumOffEntirePurchase = 0
 	r.MaximumPointsDiscount = 0
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1144"))
 
 	r.RedemptionLimit = 5
@@ -630,14 +632,14 @@ func TestCampaignHelper_Validate_IsRedemptionLimitAndNotPercentageOfEntirePurcha
 	r.SumOffEntirePurchase = 1
 	r.MaximumPointsDiscount = 1
 
-	err = ch.Validate(r)
+	err = ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1144"))
 }
 
 func TestCampaignHelper_Validate_IsRedemptionLimitAndMaxItemsWithSpecialUnitPrice(t *testing.T) {
 	ch := new(CampaignHelper)
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
 	ch.ConfigRepository = cr
 
 	r := new(Record)
@@ -651,6 +653,348 @@ func TestCampaignHelper_Validate_IsRedemptionLimitAndMaxItemsWithSpecialUnitPric
 	r.RedemptionLimit = 5
 	r.MaxItemsWithSpecialUnitPrice = 0
 
-	err := ch.Validate(r)
+	err := ch.Validate(context.Background(), r)
 	assert.Equal(t, err, errors.New("1145"))
 }
+
+func TestCampaignHelper_Validate_Tiers_NoError(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.PurchasedProducts = []string{"milk", "cookie"}
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 2, PercentageOFF: 10},
+		{PurchasedAmount: 3, PercentageOFF: 20},
+		{PurchasedAmount: 5, PercentageOFF: 30},
+	}
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, nil)
+
+	r.PurchasedProducts = nil
+	r.Tiers = []Tier{
+		{PurchaseTotalValue: 50, SumOFF: 5},
+		{PurchaseTotalValue: 100, SumOFF: 15},
+	}
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, nil)
+}
+
+func TestCampaignHelper_Validate_IsTiersAndNoSingleThresholdOrAward(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.PurchasedProducts = []string{"milk", "cookie"}
+	r.PurchasedAmount = 2
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 2, PercentageOFF: 10},
+	}
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1150"))
+
+	r.PurchasedAmount = 0
+	r.AwardedProducts = []string{"milk"}
+	r.PercentageOFF = 10
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1150"))
+}
+
+func TestCampaignHelper_Validate_IsTiersSingleThresholdType(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.PurchasedProducts = []string{"milk", "cookie"}
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 2, PercentageOFF: 10},
+		{PurchaseTotalValue: 100, PercentageOFF: 20},
+	}
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1151"))
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 2, PurchaseTotalValue: 100, PercentageOFF: 10},
+	}
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1151"))
+
+	r.PurchasedProducts = nil
+	r.Tiers = []Tier{
+		{PercentageOFF: 10},
+	}
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1151"))
+}
+
+func TestCampaignHelper_Validate_IsTiersThresholdsAscending(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.PurchasedProducts = []string{"milk", "cookie"}
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 3, PercentageOFF: 20},
+		{PurchasedAmount: 2, PercentageOFF: 10},
+	}
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1152"))
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 2, PercentageOFF: 10},
+		{PurchasedAmount: 2, PercentageOFF: 20},
+	}
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1152"))
+}
+
+func TestCampaignHelper_Validate_IsTiersSingleAward(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.PurchasedProducts = []string{"milk", "cookie"}
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 2},
+	}
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1153"))
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 2, PercentageOFF: 10, SumOFF: 5},
+	}
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1153"))
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 2, PercentageOFF: 110},
+	}
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1153"))
+}
+
+func TestCampaignHelper_Validate_IsQuantityTiersAndPurchasedProductOptions(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+
+	r.Tiers = []Tier{
+		{PurchasedAmount: 2, PercentageOFF: 10},
+	}
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1154"))
+}
+
+func TestCampaignHelper_Validate_ProductSets_NoError(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+
+	r.ProductSets = []ProductSet{
+		{Name: "burger", Amount: 1, Products: []string{"1", "2"}},
+		{Name: "drink", Amount: 1, ProductGroupID: 3},
+		{Name: "fries", Amount: 1, ProductCategoryID: 4},
+	}
+	r.BundlePrice = 9.99
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, nil)
+}
+
+func TestCampaignHelper_Validate_IsProductSetsAndNoOtherPurchaseOrAwardOptions(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.ProductSets = []ProductSet{
+		{Name: "A", Amount: 3, Products: []string{"1", "2"}},
+	}
+	r.BundlePercentageOff = 10
+
+	r.PurchaseTotalValue = 10
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1160"))
+
+	r.PurchaseTotalValue = 0
+	r.PercentageOffEntirePurchase = 10
+	r.PurchasedProducts = []string{"1"}
+	r.PurchasedAmount = 1
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1160"))
+}
+
+func TestCampaignHelper_Validate_IsProductSetsValid(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.BundlePrice = 5
+
+	r.ProductSets = []ProductSet{
+		{Name: "", Amount: 3, Products: []string{"1", "2"}},
+	}
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1161"))
+
+	r.ProductSets = []ProductSet{
+		{Name: "A", Amount: 0, Products: []string{"1", "2"}},
+	}
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1161"))
+
+	r.ProductSets = []ProductSet{
+		{Name: "A", Amount: 1, Products: []string{"1", "2"}, ProductGroupID: 1},
+	}
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1161"))
+
+	r.ProductSets = []ProductSet{
+		{Name: "A", Amount: 1},
+	}
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1161"))
+}
+
+func TestCampaignHelper_Validate_IsProductSetNamesUnique(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.BundlePrice = 5
+
+	r.ProductSets = []ProductSet{
+		{Name: "A", Amount: 3, Products: []string{"1", "2"}},
+		{Name: "A", Amount: 1, ProductGroupID: 2},
+	}
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1162"))
+}
+
+func TestCampaignHelper_Validate_IsProductSetsAndSingleBundleAward(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.ProductSets = []ProductSet{
+		{Name: "A", Amount: 3, Products: []string{"1", "2"}},
+		{Name: "B", Amount: 1, ProductGroupID: 2},
+	}
+
+	err := ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1163"))
+
+	r.BundlePrice = 5
+	r.BundleSumOff = 1
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1163"))
+
+	r.BundlePrice = 0
+	r.BundleSumOff = 0
+	r.BundlePercentageOff = 120
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1163"))
+
+	r.ProductSets = nil
+	r.PurchasedProducts = []string{"1"}
+	r.PurchasedAmount = 1
+	r.BundlePercentageOff = 0
+	r.BundlePrice = 5
+
+	err = ch.Validate(context.Background(), r)
+	assert.Equal(t, err, errors.New("1163"))
+}
@@ -0,0 +1,61 @@
+package campaignhelper
+
+import (
+	"context"
+
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+// IsCustomerSegmentIDsExisting checks that all targeted customer segments exist
+func (p *CampaignHelper) IsCustomerSegmentIDsExisting(ctx context.Context, r *Record) bool {
+	for _, id := range r.CustomerSegmentIDs {
+		count, err := p.SegmentRepository.GetSegmentsCount(ctx, id)
+		if err != nil {
+			log.Error(err)
+			return false
+		}
+		if count == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// GetCustomerSegmentIDs returns the distinct customer segments targeted by the campaigns
+func GetCustomerSegmentIDs(records []RecordOutput) []int {
+	ids := make([]int, 0)
+	for _, r := range records {
+		for _, id := range splitInts(r.CustomerSegmentIDs) {
+			if !containsInt(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// IsCustomerEligible checks if the campaign targeting allows the customer of
+// the customer group, belonging to the segments, to use the campaign.
+// A campaign without targeting is available to every customer, a campaign
+// targeted at several groups or segments requires any of them
+func IsCustomerEligible(r RecordOutput, customerGroupID int, segmentIDs []int) bool {
+	if groups := splitInts(r.CustomerGroupIDs); len(groups) > 0 && !containsInt(groups, customerGroupID) {
+		return false
+	}
+	segments := splitInts(r.CustomerSegmentIDs)
+	if len(segments) == 0 {
+		return true
+	}
+	for _, id := range segmentIDs {
+		if containsInt(segments, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsCustomerAssigned checks if a campaign restricted to a customer allowlist
+// is assigned to the customer
+func IsCustomerAssigned(r RecordOutput, assignedCampaignIDs []int) bool {
+	return r.CustomerAllowlist == 0 || containsInt(assignedCampaignIDs, r.CampaignID)
+}
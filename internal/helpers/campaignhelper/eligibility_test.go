@@ -0,0 +1,52 @@
+package campaignhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	segmentMocks "github.com/zdarovich/promotion-api/internal/repositories/segment/mocks"
+)
+
+func TestCampaignHelper_Validate_IsCustomerSegmentIDsExisting(t *testing.T) {
+	ch, _ := newStackingHelper()
+	sr := new(segmentMocks.IRepository)
+	sr.On("GetSegmentsCount", mock.Anything, 1).Return(1, nil)
+	sr.On("GetSegmentsCount", mock.Anything, 2).Return(0, nil)
+	ch.SegmentRepository = sr
+
+	r := newStackingRecord()
+	r.CustomerSegmentIDs = []int{1}
+	require.Nil(t, ch.Validate(context.Background(), r))
+
+	r.CustomerSegmentIDs = []int{1, 2}
+	assert.Equal(t, errors.New("1194"), ch.Validate(context.Background(), r))
+}
+
+func TestIsCustomerEligible(t *testing.T) {
+	assert.True(t, IsCustomerEligible(RecordOutput{}, 0, nil))
+	assert.True(t, IsCustomerEligible(RecordOutput{CustomerGroupIDs: "1,2"}, 2, nil))
+	assert.False(t, IsCustomerEligible(RecordOutput{CustomerGroupIDs: "1,2"}, 3, nil))
+	assert.True(t, IsCustomerEligible(RecordOutput{CustomerSegmentIDs: "5,6"}, 0, []int{4, 6}))
+	assert.False(t, IsCustomerEligible(RecordOutput{CustomerSegmentIDs: "5,6"}, 0, []int{4}))
+	assert.False(t, IsCustomerEligible(RecordOutput{CustomerGroupIDs: "1", CustomerSegmentIDs: "5"}, 2, []int{5}))
+}
+
+func TestGetCustomerSegmentIDs(t *testing.T) {
+	ids := GetCustomerSegmentIDs([]RecordOutput{
+		{CustomerSegmentIDs: "1,2"},
+		{},
+		{CustomerSegmentIDs: "2,3"},
+	})
+
+	assert.Equal(t, []int{1, 2, 3}, ids)
+}
+
+func TestIsCustomerAssigned(t *testing.T) {
+	assert.True(t, IsCustomerAssigned(RecordOutput{CampaignID: 1}, nil))
+	assert.True(t, IsCustomerAssigned(RecordOutput{CampaignID: 1, CustomerAllowlist: 1}, []int{1, 2}))
+	assert.False(t, IsCustomerAssigned(RecordOutput{CampaignID: 3, CustomerAllowlist: 1}, []int{1, 2}))
+}
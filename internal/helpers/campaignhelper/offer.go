@@ -0,0 +1,36 @@
+package campaignhelper
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// MaxCustomerIDs maximum count of customers assigned to a campaign by a single request
+const MaxCustomerIDs = 100000
+
+// ParseCustomerIDs parses a list of customer ids separated by commas, spaces
+// or line breaks, as exported by spreadsheets. Duplicates are removed
+func ParseCustomerIDs(value string) ([]int, error) {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || unicode.IsSpace(r)
+	})
+	ids := make([]int, 0, len(fields))
+	seen := make(map[int]bool, len(fields))
+	for _, field := range fields {
+		id, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, err
+		}
+		if id <= 0 {
+			return nil, errors.New("invalid customer id " + field)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,20 @@
+package campaignhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCustomerIDs(t *testing.T) {
+	ids, err := ParseCustomerIDs("1,2, 3\n4\r\n5;2")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, ids)
+
+	_, err = ParseCustomerIDs("1,a")
+	assert.NotNil(t, err)
+
+	_, err = ParseCustomerIDs("1,0")
+	assert.NotNil(t, err)
+}
@@ -2,6 +2,7 @@ package campaignhelper
 
 import (
 	"github.com/zdarovich/promotion-api/internal/repositories/config"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -66,7 +67,7 @@ func IsPurchasedProductGroupIDOrPurchasedProductCategoryIDOrPurchasedProductsAnd
 	if c.PurchasedProductGroupID == 0 && c.PurchasedProductCategoryID == 0 && len(c.PurchasedProducts) == 0 {
 		return true
 	}
-	return (c.PurchasedProductGroupID != 0 || c.PurchasedProductCategoryID != 0 || len(c.PurchasedProducts) > 0) && c.PurchasedAmount > 0
+	return (c.PurchasedProductGroupID != 0 || c.PurchasedProductCategoryID != 0 || len(c.PurchasedProducts) > 0) && (c.PurchasedAmount > 0 || IsQuantityTiers(c))
 }
 
 func IsAwardedProductOptionsAndSumOffOrPercentageOff(c *Record) bool {
@@ -168,6 +169,15 @@ func IsCustomerGroupIDsEnabled(r *Record, conf config.Conf) bool {
 		strings.Contains(conf.Value, "promotion_regions")
 }
 
+func IsSubsidiesNumeric(subsidies []string) bool {
+	for _, subsidy := range subsidies {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(subsidy), 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
 func IsStartDateValid(c *Record) bool {
 	return !c.StartDate.IsZero() && c.StartDate.After(time.Now().Add(-1*time.Hour))
 }
@@ -213,3 +223,218 @@ func IsReasonIdEqualsPromotion(c *Record) bool {
 	// TODO
 	return true
 }
+
+func IsQuantityTiers(c *Record) bool {
+	return len(c.Tiers) > 0 && c.Tiers[0].PurchasedAmount > 0
+}
+
+func IsTiersAndNoSingleThresholdOrAward(c *Record) bool {
+	if len(c.Tiers) == 0 {
+		return true
+	}
+	return c.PurchasedAmount == 0 &&
+		c.PurchaseTotalValue == 0 &&
+		c.PercentageOFF == 0 &&
+		c.SumOFF == 0 &&
+		c.SpecialPrice == 0 &&
+		c.SpecialUnitPrice == 0 &&
+		c.PercentageOffEntirePurchase == 0 &&
+		c.SumOffEntirePurchase == 0
+}
+
+func IsTiersSingleThresholdType(c *Record) bool {
+	if len(c.Tiers) == 0 {
+		return true
+	}
+	quantity := IsQuantityTiers(c)
+	for _, t := range c.Tiers {
+		if (t.PurchasedAmount > 0) == (t.PurchaseTotalValue > 0) {
+			return false
+		}
+		if (t.PurchasedAmount > 0) != quantity {
+			return false
+		}
+	}
+	return true
+}
+
+func IsTiersThresholdsAscending(c *Record) bool {
+	for i := 1; i < len(c.Tiers); i++ {
+		if c.Tiers[i].PurchasedAmount <= c.Tiers[i-1].PurchasedAmount &&
+			c.Tiers[i].PurchaseTotalValue <= c.Tiers[i-1].PurchaseTotalValue {
+			return false
+		}
+	}
+	return true
+}
+
+func IsTiersSingleAward(c *Record) bool {
+	for _, t := range c.Tiers {
+		i := 0
+		if t.PercentageOFF > 0 {
+			i++
+		}
+		if t.SumOFF > 0 {
+			i++
+		}
+		if t.SpecialPrice > 0 {
+			i++
+		}
+		if i != 1 || t.PercentageOFF > 100 {
+			return false
+		}
+	}
+	return true
+}
+
+func IsQuantityTiersAndPurchasedProductOptions(c *Record) bool {
+	if !IsQuantityTiers(c) {
+		return true
+	}
+	return c.PurchasedProductGroupID != 0 || c.PurchasedProductCategoryID != 0 || len(c.PurchasedProducts) > 0
+}
+
+func IsProductSetsAndNoOtherPurchaseOrAwardOptions(c *Record) bool {
+	if len(c.ProductSets) == 0 {
+		return true
+	}
+	return c.PurchasedProductGroupID == 0 &&
+		c.PurchasedProductCategoryID == 0 &&
+		len(c.PurchasedProducts) == 0 &&
+		c.PurchasedAmount == 0 &&
+		c.PurchaseTotalValue == 0 &&
+		len(c.Tiers) == 0 &&
+		c.PercentageOFF == 0 &&
+		c.SumOFF == 0 &&
+		c.SpecialPrice == 0 &&
+		c.SpecialUnitPrice == 0 &&
+		c.PercentageOffEntirePurchase == 0 &&
+		c.SumOffEntirePurchase == 0
+}
+
+func IsProductSetsValid(c *Record) bool {
+	for _, s := range c.ProductSets {
+		if strings.TrimSpace(s.Name) == "" || s.Amount <= 0 {
+			return false
+		}
+		i := 0
+		if s.ProductGroupID != 0 {
+			i++
+		}
+		if s.ProductCategoryID != 0 {
+			i++
+		}
+		if len(s.Products) != 0 {
+			i++
+		}
+		if i != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func IsProductSetNamesUnique(c *Record) bool {
+	names := make(map[string]bool)
+	for _, s := range c.ProductSets {
+		if names[s.Name] {
+			return false
+		}
+		names[s.Name] = true
+	}
+	return true
+}
+
+func IsProductSetsAndSingleBundleAward(c *Record) bool {
+	i := 0
+	if c.BundlePrice > 0 {
+		i++
+	}
+	if c.BundleSumOff > 0 {
+		i++
+	}
+	if c.BundlePercentageOff > 0 {
+		i++
+	}
+	if len(c.ProductSets) == 0 {
+		return i == 0
+	}
+	return i == 1 && c.BundlePercentageOff <= 100
+}
+
+func IsSchedulesRestricting(c *Record) bool {
+	for _, s := range c.Schedules {
+		if len(s.DaysOfWeek) == 0 && len(s.DaysOfMonth) == 0 && len(s.WeeksOfMonth) == 0 && s.StartTime == "" && s.EndTime == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func IsSchedulesDaysValid(c *Record) bool {
+	for _, s := range c.Schedules {
+		for _, d := range s.DaysOfWeek {
+			if d < 1 || d > 7 {
+				return false
+			}
+		}
+		for _, d := range s.DaysOfMonth {
+			if d == 0 || d < -31 || d > 31 {
+				return false
+			}
+		}
+		for _, w := range s.WeeksOfMonth {
+			if w == 0 || w < -5 || w > 5 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func IsSchedulesWeeksOfMonthAndDaysOfWeek(c *Record) bool {
+	for _, s := range c.Schedules {
+		if len(s.WeeksOfMonth) > 0 && len(s.DaysOfWeek) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func IsSchedulesTimeWindowValid(c *Record) bool {
+	for _, s := range c.Schedules {
+		if s.StartTime == "" && s.EndTime == "" {
+			continue
+		}
+		start, err := parseClock(s.StartTime)
+		if err != nil {
+			return false
+		}
+		end, err := parseClock(s.EndTime)
+		if err != nil || start == end {
+			return false
+		}
+	}
+	return true
+}
+
+func IsExclusiveAndNotStackingGroupOrCombinableWith(c *Record) bool {
+	if !c.Exclusive {
+		return true
+	}
+	return c.StackingGroup == "" && len(c.CombinableWith) == 0
+}
+
+func IsCombinableWithNotSelf(c *Record) bool {
+	if c.CampaignID == 0 {
+		return true
+	}
+	return !containsInt(c.CombinableWith, c.CampaignID)
+}
+
+func IsStackingGroupValid(c *Record) bool {
+	if c.StackingGroup == "" {
+		return true
+	}
+	return strings.TrimSpace(c.StackingGroup) != "" && len(c.StackingGroup) <= 50
+}
@@ -0,0 +1,166 @@
+package campaignhelper
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+)
+
+// Schedule structure of a single recurrence rule of a campaign.
+// All set restrictions of a rule must match, a campaign with several rules
+// is active when any of them matches. Days of week are ISO numbers
+// (1 is Monday, 7 is Sunday), negative days and weeks of month count from
+// the end of the month (-1 is the last one). A time window whose end is
+// before its start spans midnight.
+type Schedule struct {
+	DaysOfWeek   []int  `json:"daysOfWeek"`
+	DaysOfMonth  []int  `json:"daysOfMonth"`
+	WeeksOfMonth []int  `json:"weeksOfMonth"`
+	StartTime    string `json:"startTime"`
+	EndTime      string `json:"endTime"`
+}
+
+// IsActiveAt checks if the campaign period and schedules allow the campaign
+// to be applied at the instant. Schedules are evaluated in the campaign timezone
+func IsActiveAt(r RecordOutput, t time.Time) bool {
+	if t.Before(r.StartDate) || t.After(r.EndDate) {
+		return false
+	}
+	if len(r.Schedules) == 0 {
+		return true
+	}
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	for _, s := range r.Schedules {
+		if s.Matches(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// Matches checks if the instant satisfies all restrictions of the rule. The
+// days of a time window spanning midnight are those of the day it started
+func (s Schedule) Matches(t time.Time) bool {
+	day := t
+	if s.StartTime != "" {
+		start, _ := parseClock(s.StartTime)
+		end, _ := parseClock(s.EndTime)
+		minute := t.Hour()*60 + t.Minute()
+		switch {
+		case start < end:
+			if minute < start || minute >= end {
+				return false
+			}
+		case minute < end:
+			// After midnight, the window started the day before
+			day = t.AddDate(0, 0, -1)
+		case minute < start:
+			return false
+		}
+	}
+
+	if len(s.DaysOfWeek) > 0 && !containsInt(s.DaysOfWeek, isoWeekday(day)) {
+		return false
+	}
+	if len(s.DaysOfMonth) > 0 && !containsInt(s.DaysOfMonth, day.Day()) && !containsInt(s.DaysOfMonth, day.Day()-daysIn(day)-1) {
+		return false
+	}
+	if len(s.WeeksOfMonth) > 0 {
+		week := (day.Day()-1)/7 + 1
+		lastWeek := -((daysIn(day)-day.Day())/7 + 1)
+		if !containsInt(s.WeeksOfMonth, week) && !containsInt(s.WeeksOfMonth, lastWeek) {
+			return false
+		}
+	}
+	return true
+}
+
+// MapSchedulesToOutput maps database schedules to output schedules
+func MapSchedulesToOutput(schedules []*schedule.Schedule) []Schedule {
+	if len(schedules) == 0 {
+		return nil
+	}
+	output := make([]Schedule, len(schedules))
+	for idx, s := range schedules {
+		output[idx] = Schedule{
+			DaysOfWeek:   splitInts(s.DaysOfWeek),
+			DaysOfMonth:  splitInts(s.DaysOfMonth),
+			WeeksOfMonth: splitInts(s.WeeksOfMonth),
+			StartTime:    s.StartTime,
+			EndTime:      s.EndTime,
+		}
+	}
+	return output
+}
+
+// MapSchedulesToDatabase maps the record schedules to database schedules of the campaign
+func MapSchedulesToDatabase(r *Record, campaignID int) []*schedule.Schedule {
+	schedules := make([]*schedule.Schedule, len(r.Schedules))
+	for idx, s := range r.Schedules {
+		schedules[idx] = &schedule.Schedule{
+			CampaignID:   campaignID,
+			Position:     idx,
+			DaysOfWeek:   joinInts(s.DaysOfWeek),
+			DaysOfMonth:  joinInts(s.DaysOfMonth),
+			WeeksOfMonth: joinInts(s.WeeksOfMonth),
+			StartTime:    s.StartTime,
+			EndTime:      s.EndTime,
+		}
+	}
+	return schedules
+}
+
+// parseClock converts HH:MM to minutes from midnight
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func splitInts(value string) []int {
+	if len(value) == 0 {
+		return nil
+	}
+	var result []int
+	for _, el := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(el)
+		if err != nil {
+			continue
+		}
+		result = append(result, i)
+	}
+	return result
+}
+
+func joinInts(values []int) string {
+	return strings.Trim(strings.Join(strings.Fields(fmt.Sprint(values)), ","), "[]")
+}
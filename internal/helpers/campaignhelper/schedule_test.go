@@ -0,0 +1,148 @@
+package campaignhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zdarovich/promotion-api/internal/repositories/config"
+	configMocks "github.com/zdarovich/promotion-api/internal/repositories/config/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+)
+
+func TestSchedule_IsActiveAt_DateRange(t *testing.T) {
+	r := RecordOutput{
+		StartDate: time.Date(2020, time.May, 4, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, time.May, 10, 23, 59, 59, 0, time.UTC),
+	}
+
+	assert.False(t, IsActiveAt(r, time.Date(2020, time.May, 3, 23, 59, 0, 0, time.UTC)))
+	assert.True(t, IsActiveAt(r, time.Date(2020, time.May, 4, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, IsActiveAt(r, time.Date(2020, time.May, 10, 23, 59, 0, 0, time.UTC)))
+	assert.False(t, IsActiveAt(r, time.Date(2020, time.May, 11, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestSchedule_IsActiveAt_Timezone(t *testing.T) {
+	r := RecordOutput{
+		StartDate: time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, time.May, 31, 0, 0, 0, 0, time.UTC),
+		Timezone:  "America/New_York",
+		Schedules: []Schedule{
+			{StartTime: "16:00", EndTime: "18:00"},
+		},
+	}
+
+	// 16:30 in New York
+	assert.True(t, IsActiveAt(r, time.Date(2020, time.May, 4, 20, 30, 0, 0, time.UTC)))
+	assert.False(t, IsActiveAt(r, time.Date(2020, time.May, 4, 16, 30, 0, 0, time.UTC)))
+}
+
+func TestSchedule_IsActiveAt_HappyHour(t *testing.T) {
+	r := RecordOutput{
+		StartDate: time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, time.May, 31, 0, 0, 0, 0, time.UTC),
+		Schedules: []Schedule{
+			{DaysOfWeek: []int{1, 2, 3, 4, 5}, StartTime: "16:00", EndTime: "18:00"},
+		},
+	}
+
+	// Monday
+	assert.True(t, IsActiveAt(r, time.Date(2020, time.May, 4, 16, 0, 0, 0, time.UTC)))
+	assert.True(t, IsActiveAt(r, time.Date(2020, time.May, 4, 17, 59, 0, 0, time.UTC)))
+	assert.False(t, IsActiveAt(r, time.Date(2020, time.May, 4, 18, 0, 0, 0, time.UTC)))
+	assert.False(t, IsActiveAt(r, time.Date(2020, time.May, 4, 15, 59, 0, 0, time.UTC)))
+	// Saturday
+	assert.False(t, IsActiveAt(r, time.Date(2020, time.May, 9, 17, 0, 0, 0, time.UTC)))
+}
+
+func TestSchedule_Matches(t *testing.T) {
+	// 2020-05-05 is the first Tuesday and 2020-05-26 the last Tuesday of May
+	firstTuesday := Schedule{DaysOfWeek: []int{2}, WeeksOfMonth: []int{1}}
+	assert.True(t, firstTuesday.Matches(time.Date(2020, time.May, 5, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, firstTuesday.Matches(time.Date(2020, time.May, 12, 12, 0, 0, 0, time.UTC)))
+
+	lastTuesday := Schedule{DaysOfWeek: []int{2}, WeeksOfMonth: []int{-1}}
+	assert.True(t, lastTuesday.Matches(time.Date(2020, time.May, 26, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, lastTuesday.Matches(time.Date(2020, time.May, 19, 12, 0, 0, 0, time.UTC)))
+
+	monthly := Schedule{DaysOfMonth: []int{1, -1}}
+	assert.True(t, monthly.Matches(time.Date(2020, time.February, 1, 12, 0, 0, 0, time.UTC)))
+	assert.True(t, monthly.Matches(time.Date(2020, time.February, 29, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, monthly.Matches(time.Date(2020, time.February, 28, 12, 0, 0, 0, time.UTC)))
+
+	overnight := Schedule{StartTime: "22:00", EndTime: "02:00"}
+	assert.True(t, overnight.Matches(time.Date(2020, time.May, 5, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, overnight.Matches(time.Date(2020, time.May, 5, 1, 0, 0, 0, time.UTC)))
+	assert.False(t, overnight.Matches(time.Date(2020, time.May, 5, 2, 0, 0, 0, time.UTC)))
+
+	// After midnight the days are those of the evening the window started,
+	// 2020-05-08 is a Friday and 2020-05-31 the last day of May
+	fridayNight := Schedule{DaysOfWeek: []int{5}, StartTime: "22:00", EndTime: "02:00"}
+	assert.True(t, fridayNight.Matches(time.Date(2020, time.May, 8, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, fridayNight.Matches(time.Date(2020, time.May, 9, 1, 59, 0, 0, time.UTC)))
+	assert.False(t, fridayNight.Matches(time.Date(2020, time.May, 8, 1, 0, 0, 0, time.UTC)))
+	assert.False(t, fridayNight.Matches(time.Date(2020, time.May, 9, 23, 0, 0, 0, time.UTC)))
+
+	lastNight := Schedule{DaysOfMonth: []int{-1}, StartTime: "22:00", EndTime: "02:00"}
+	assert.True(t, lastNight.Matches(time.Date(2020, time.June, 1, 1, 0, 0, 0, time.UTC)))
+	assert.False(t, lastNight.Matches(time.Date(2020, time.May, 31, 1, 0, 0, 0, time.UTC)))
+}
+
+func TestSchedule_MapSchedules(t *testing.T) {
+	r := &Record{
+		Schedules: []Schedule{
+			{DaysOfWeek: []int{1, 2}, StartTime: "16:00", EndTime: "18:00"},
+			{DaysOfMonth: []int{1, -1}},
+		},
+	}
+
+	db := MapSchedulesToDatabase(r, 5)
+
+	assert.Equal(t, []*schedule.Schedule{
+		{CampaignID: 5, Position: 0, DaysOfWeek: "1,2", StartTime: "16:00", EndTime: "18:00"},
+		{CampaignID: 5, Position: 1, DaysOfMonth: "1,-1"},
+	}, db)
+	assert.Equal(t, r.Schedules, MapSchedulesToOutput(db))
+}
+
+func TestCampaignHelper_Validate_Schedules(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	r := new(Record)
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(48 * time.Hour)
+	r.Type = "auto"
+	r.WarehouseID = 1
+	r.PurchasedAmount = 2
+	r.PurchasedProducts = []string{"milk", "cookie"}
+
+	r.Schedules = []Schedule{{DaysOfWeek: []int{1, 2, 3, 4, 5}, StartTime: "16:00", EndTime: "18:00"}}
+	assert.Equal(t, nil, ch.Validate(context.Background(), r))
+
+	r.Schedules = []Schedule{{}}
+	assert.Equal(t, errors.New("1170"), ch.Validate(context.Background(), r))
+
+	r.Schedules = []Schedule{{DaysOfWeek: []int{0}}}
+	assert.Equal(t, errors.New("1171"), ch.Validate(context.Background(), r))
+
+	r.Schedules = []Schedule{{DaysOfMonth: []int{32}}}
+	assert.Equal(t, errors.New("1171"), ch.Validate(context.Background(), r))
+
+	r.Schedules = []Schedule{{WeeksOfMonth: []int{1}}}
+	assert.Equal(t, errors.New("1172"), ch.Validate(context.Background(), r))
+
+	r.Schedules = []Schedule{{StartTime: "16:00"}}
+	assert.Equal(t, errors.New("1173"), ch.Validate(context.Background(), r))
+
+	r.Schedules = []Schedule{{StartTime: "25:00", EndTime: "18:00"}}
+	assert.Equal(t, errors.New("1173"), ch.Validate(context.Background(), r))
+
+	r.Schedules = []Schedule{{StartTime: "18:00", EndTime: "18:00"}}
+	assert.Equal(t, errors.New("1173"), ch.Validate(context.Background(), r))
+}
@@ -0,0 +1,166 @@
+package campaignhelper
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+)
+
+// MapSettingsToOutput sets the typed campaign settings on the output record
+func MapSettingsToOutput(ro *RecordOutput, s *settings.Settings) {
+	ro.AwardedBrandID = s.AwardedBrandID
+	ro.DiscountForOneLine = s.DiscountForOneLine
+	ro.RequiredCouponID = s.RequiredCouponID
+	ro.RequiredCouponCode = s.RequiredCouponCode
+	ro.AwardedAmount = s.AwardedAmount
+	ro.PurchasedProductCategoryID = s.PurchasedProductCategoryID
+	ro.AwardedProductCategoryID = s.AwardedProductCategoryID
+	ro.MaximumPointsDiscount = s.MaximumPointsDiscount
+	ro.CustomerCanUseOnlyOnce = s.CustomerCanUseOnlyOnce
+	ro.PriceAtLeast = s.PriceAtLeast
+	ro.PriceAtMost = s.PriceAtMost
+	ro.RequiresManagerOverride = s.RequiresManagerOverride
+	ro.SumOffMatchingItems = s.SumOffMatchingItems
+	ro.ExcludeDiscountedFromPercentageOffEntirePurchase = s.ExcludeDiscountedFromPercentageOffEntirePurchase
+	ro.ExcludePromotionItemsFromPercentageOffEntirePurchase = s.ExcludePromotionItemsFromPercentageOffEntirePurchase
+	ro.ReasonID = s.ReasonID
+	ro.SpecialUnitPrice = s.SpecialUnitPrice
+	ro.MaxItemsWithSpecialUnitPrice = s.MaxItemsWithSpecialUnitPrice
+	ro.RedemptionLimit = s.RedemptionLimit
+	ro.StoreGroup = s.StoreGroup
+	ro.CanBeAppliedManuallyMultipleTimes = s.CanBeAppliedManuallyMultipleTimes
+	ro.PurchasedBrandID = s.PurchasedBrandID
+	ro.BundlePrice = s.BundlePrice
+	ro.BundleSumOff = s.BundleSumOff
+	ro.BundlePercentageOff = s.BundlePercentageOff
+	ro.Priority = s.Priority
+	ro.Exclusive = s.Exclusive
+	ro.StackingGroup = s.StackingGroup
+	ro.CustomerAllowlist = s.CustomerAllowlist
+
+	// settings duplicated in the campaign table are only set when present
+	if s.AwardedProductGroupID != 0 {
+		ro.AwardedProductGroupID = s.AwardedProductGroupID
+	}
+	if s.LowestPriceItemIsAwarded != 0 {
+		ro.LowestPriceItemIsAwarded = s.LowestPriceItemIsAwarded
+	}
+	if s.PercentageOffMatchingItems != 0 {
+		ro.PercentageOffMatchingItems = s.PercentageOffMatchingItems
+	}
+	if s.PurchasedProductGroupID != 0 {
+		ro.PurchasedProductGroupID = s.PurchasedProductGroupID
+	}
+	if s.RewardPoints != 0 {
+		ro.RewardPoints = s.RewardPoints
+	}
+	if s.PercentageOffEntirePurchase != 0 {
+		ro.PercentageOffEntirePurchase = s.PercentageOffEntirePurchase
+	}
+
+	ro.PurchasedProducts = strings.Join(s.Products[settings.ListPurchasedProducts], ",")
+	ro.AwardedProducts = strings.Join(s.Products[settings.ListAwardedProducts], ",")
+	ro.ExcludedProducts = strings.Join(s.Products[settings.ListExcludedProducts], ",")
+	ro.PercentageOffExcludedProducts = strings.Join(s.Products[settings.ListPercentageOffExcludedProducts], ",")
+	ro.PercentageOffIncludedProducts = strings.Join(s.Products[settings.ListPercentageOffIncludedProducts], ",")
+	ro.SumOffExcludedProducts = strings.Join(s.Products[settings.ListSumOffExcludedProducts], ",")
+	ro.SumOffIncludedProducts = strings.Join(s.Products[settings.ListSumOffIncludedProducts], ",")
+	ro.PurchasedProductSubsidies = joinFloats(s.Subsidies[settings.ListPurchasedProductSubsidies])
+	ro.AwardedProductSubsidies = joinFloats(s.Subsidies[settings.ListAwardedProductSubsidies])
+	ro.StoreRegionIDs = joinInts(s.StoreRegionIDs)
+	ro.CustomerGroupIDs = joinInts(s.CustomerGroupIDs)
+	ro.CustomerSegmentIDs = joinInts(s.CustomerSegmentIDs)
+	ro.CombinableWith = joinInts(s.CombinableWith)
+}
+
+// MapSettingsToDatabase maps the record to the typed settings of the campaign
+func MapSettingsToDatabase(r *Record, campaignID int) *settings.Settings {
+	s := &settings.Settings{
+		CampaignID:                 campaignID,
+		AwardedBrandID:             r.AwardedBrandID,
+		DiscountForOneLine:         r.DiscountForOneLine,
+		RequiredCouponID:           r.RequiredCouponID,
+		RequiredCouponCode:         r.RequiredCouponCode,
+		AwardedAmount:              r.AwardedAmount,
+		PurchasedProductCategoryID: r.PurchasedProductCategoryID,
+		AwardedProductCategoryID:   r.AwardedProductCategoryID,
+		MaximumPointsDiscount:      r.MaximumPointsDiscount,
+		CustomerCanUseOnlyOnce:     boolToInt(r.CustomerCanUseOnlyOnce),
+		PriceAtLeast:               r.PriceAtLeast,
+		PriceAtMost:                r.PriceAtMost,
+		RequiresManagerOverride:    boolToInt(r.RequiresManagerOverride),
+		SumOffMatchingItems:        r.SumOffMatchingItems,
+		ExcludeDiscountedFromPercentageOffEntirePurchase:     boolToInt(r.ExcludeDiscountedFromPercentageOffEntirePurchase),
+		ExcludePromotionItemsFromPercentageOffEntirePurchase: boolToInt(r.ExcludePromotionItemsFromPercentageOffEntirePurchase),
+		ReasonID:                          r.ReasonID,
+		SpecialUnitPrice:                  r.SpecialUnitPrice,
+		MaxItemsWithSpecialUnitPrice:      r.MaxItemsWithSpecialUnitPrice,
+		RedemptionLimit:                   r.RedemptionLimit,
+		StoreGroup:                        r.StoreGroup,
+		CanBeAppliedManuallyMultipleTimes: r.CanBeAppliedManuallyMultipleTimes,
+		PurchasedBrandID:                  r.PurchasedBrandID,
+		AwardedProductGroupID:             r.AwardedProductGroupID,
+		LowestPriceItemIsAwarded:          boolToInt(r.LowestPriceItemIsAwarded),
+		PercentageOffMatchingItems:        r.PercentageOffMatchingItems,
+		PurchasedProductGroupID:           r.PurchasedProductGroupID,
+		RewardPoints:                      r.RewardPoints,
+		PercentageOffEntirePurchase:       r.PercentageOffEntirePurchase,
+		BundlePrice:                       r.BundlePrice,
+		BundleSumOff:                      r.BundleSumOff,
+		BundlePercentageOff:               r.BundlePercentageOff,
+		Priority:                          r.Priority,
+		Exclusive:                         boolToInt(r.Exclusive),
+		StackingGroup:                     r.StackingGroup,
+		CustomerAllowlist:                 boolToInt(r.CustomerAllowlist),
+		Products:                          make(map[string][]string),
+		Subsidies:                         make(map[string][]float64),
+		StoreRegionIDs:                    r.StoreRegionIDs,
+		CustomerGroupIDs:                  r.CustomerGroupIDs,
+		CustomerSegmentIDs:                r.CustomerSegmentIDs,
+		CombinableWith:                    r.CombinableWith,
+	}
+
+	lists := map[string][]string{
+		settings.ListPurchasedProducts:             r.PurchasedProducts,
+		settings.ListAwardedProducts:               r.AwardedProducts,
+		settings.ListExcludedProducts:              r.ExcludedProducts,
+		settings.ListPercentageOffExcludedProducts: r.PercentageOffExcludedProducts,
+		settings.ListPercentageOffIncludedProducts: r.PercentageOffIncludedProducts,
+		settings.ListSumOffExcludedProducts:        r.SumOffExcludedProducts,
+		settings.ListSumOffIncludedProducts:        r.SumOffIncludedProducts,
+	}
+	for list, products := range lists {
+		if len(products) > 0 {
+			s.Products[list] = products
+		}
+	}
+
+	subsidies := map[string][]string{
+		settings.ListPurchasedProductSubsidies: r.PurchasedProductSubsidies,
+		settings.ListAwardedProductSubsidies:   r.AwardedProductSubsidies,
+	}
+	for list, values := range subsidies {
+		for _, value := range values {
+			f, _ := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			s.Subsidies[list] = append(s.Subsidies[list], f)
+		}
+	}
+
+	return s
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func joinFloats(values []float64) string {
+	s := make([]string, len(values))
+	for idx, v := range values {
+		s[idx] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.Join(s, ",")
+}
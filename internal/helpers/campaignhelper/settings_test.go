@@ -0,0 +1,53 @@
+package campaignhelper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+)
+
+func TestMapSettingsToDatabase(t *testing.T) {
+	r := &Record{
+		Priority:                  3,
+		Exclusive:                 true,
+		StackingGroup:             "loyalty",
+		PurchasedProducts:         []string{"1", "2"},
+		PurchasedProductSubsidies: []string{"0.5", "1"},
+		CustomerGroupIDs:          []int{7},
+	}
+
+	s := MapSettingsToDatabase(r, 9)
+
+	assert.Equal(t, 9, s.CampaignID)
+	assert.Equal(t, 3, s.Priority)
+	assert.Equal(t, 1, s.Exclusive)
+	assert.Equal(t, "loyalty", s.StackingGroup)
+	assert.Equal(t, map[string][]string{settings.ListPurchasedProducts: {"1", "2"}}, s.Products)
+	assert.Equal(t, map[string][]float64{settings.ListPurchasedProductSubsidies: {0.5, 1}}, s.Subsidies)
+	assert.Equal(t, []int{7}, s.CustomerGroupIDs)
+}
+
+func TestMapSettingsToOutput(t *testing.T) {
+	ro := &RecordOutput{RewardPoints: 10, PurchasedProductGroupID: 4}
+	s := &settings.Settings{
+		CampaignID:       9,
+		Priority:         3,
+		RewardPoints:     0,
+		Products:         map[string][]string{settings.ListAwardedProducts: {"1", "2"}},
+		Subsidies:        map[string][]float64{settings.ListAwardedProductSubsidies: {0.5, 1}},
+		StoreRegionIDs:   []int{1, 2},
+		CustomerGroupIDs: []int{7},
+	}
+
+	MapSettingsToOutput(ro, s)
+
+	assert.Equal(t, 3, ro.Priority)
+	assert.Equal(t, 10, ro.RewardPoints)
+	assert.Equal(t, 4, ro.PurchasedProductGroupID)
+	assert.Equal(t, "1,2", ro.AwardedProducts)
+	assert.Equal(t, "0.5,1", ro.AwardedProductSubsidies)
+	assert.Equal(t, "1,2", ro.StoreRegionIDs)
+	assert.Equal(t, "7", ro.CustomerGroupIDs)
+	assert.Equal(t, "", ro.PurchasedProducts)
+}
@@ -0,0 +1,82 @@
+package campaignhelper
+
+import (
+	"context"
+	"sort"
+
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+// IsCombinableWithExisting checks that all campaigns of the combinable-with
+// list exist
+func (p *CampaignHelper) IsCombinableWithExisting(ctx context.Context, r *Record) bool {
+	for _, id := range r.CombinableWith {
+		count, err := p.CampaignRepository.GetCampaignsCount(ctx, id, "")
+		if err != nil {
+			log.Error(err)
+			return false
+		}
+		if count == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectCombinable picks the campaigns that may be applied together out of
+// the campaigns applicable to a cart. Campaigns are considered by descending
+// priority (ties by campaign id) and one is added when it combines with all
+// campaigns already picked:
+// - an exclusive campaign is only applied alone
+// - only one campaign of a stacking group is applied
+// - a campaign with a combinable-with list only combines with the listed campaigns
+func SelectCombinable(records []RecordOutput) []RecordOutput {
+
+	candidates := make([]RecordOutput, len(records))
+	copy(candidates, records)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Priority != candidates[j].Priority {
+			return candidates[i].Priority > candidates[j].Priority
+		}
+		return candidates[i].CampaignID < candidates[j].CampaignID
+	})
+
+	selected := make([]RecordOutput, 0)
+	for _, candidate := range candidates {
+		if len(selected) == 0 {
+			selected = append(selected, candidate)
+			if candidate.Exclusive == 1 {
+				break
+			}
+			continue
+		}
+		if isCombinable(candidate, selected) {
+			selected = append(selected, candidate)
+		}
+	}
+	return selected
+}
+
+// isCombinable checks if the candidate combines with every picked campaign
+func isCombinable(candidate RecordOutput, selected []RecordOutput) bool {
+	if candidate.Exclusive == 1 {
+		return false
+	}
+	candidateList := splitInts(candidate.CombinableWith)
+	for _, s := range selected {
+		if s.Exclusive == 1 {
+			return false
+		}
+		if candidate.StackingGroup != "" && candidate.StackingGroup == s.StackingGroup {
+			return false
+		}
+		if len(candidateList) > 0 && !containsInt(candidateList, s.CampaignID) {
+			return false
+		}
+		list := splitInts(s.CombinableWith)
+		if len(list) > 0 && !containsInt(list, candidate.CampaignID) {
+			return false
+		}
+	}
+	return true
+}
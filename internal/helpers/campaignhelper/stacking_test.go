@@ -0,0 +1,148 @@
+package campaignhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	campaignMocks "github.com/zdarovich/promotion-api/internal/repositories/campaign/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/config"
+	configMocks "github.com/zdarovich/promotion-api/internal/repositories/config/mocks"
+)
+
+func newStackingRecord() *Record {
+	r := new(Record)
+	r.CampaignID = 5
+	r.StartDate = time.Now().Add(1 * time.Hour)
+	r.EndDate = time.Now().Add(2 * time.Hour)
+	r.Type = "auto"
+	r.PurchasedProducts = []string{"milk", "cookie"}
+	r.PurchasedAmount = 66
+	r.WarehouseID = 1
+	return r
+}
+
+func newStackingHelper() (*CampaignHelper, *campaignMocks.IRepository) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+	campaigns := new(campaignMocks.IRepository)
+	ch.CampaignRepository = campaigns
+	return ch, campaigns
+}
+
+func TestCampaignHelper_Validate_Stacking_NoError(t *testing.T) {
+	ch, campaigns := newStackingHelper()
+	campaigns.On("GetCampaignsCount", mock.Anything, 1, "").Return(1, nil)
+	campaigns.On("GetCampaignsCount", mock.Anything, 2, "").Return(1, nil)
+
+	r := newStackingRecord()
+	r.Priority = 10
+	r.StackingGroup = "seasonal"
+	r.CombinableWith = []int{1, 2}
+
+	require.Nil(t, ch.Validate(context.Background(), r))
+}
+
+func TestCampaignHelper_Validate_IsExclusiveAndNotStackingGroupOrCombinableWith(t *testing.T) {
+	ch, _ := newStackingHelper()
+
+	r := newStackingRecord()
+	r.Exclusive = true
+	require.Nil(t, ch.Validate(context.Background(), r))
+
+	r.StackingGroup = "seasonal"
+	assert.Equal(t, errors.New("1183"), ch.Validate(context.Background(), r))
+
+	r.StackingGroup = ""
+	r.CombinableWith = []int{1}
+	assert.Equal(t, errors.New("1183"), ch.Validate(context.Background(), r))
+}
+
+func TestCampaignHelper_Validate_IsCombinableWithNotSelf(t *testing.T) {
+	ch, _ := newStackingHelper()
+
+	r := newStackingRecord()
+	r.CombinableWith = []int{1, 5}
+
+	assert.Equal(t, errors.New("1184"), ch.Validate(context.Background(), r))
+}
+
+func TestCampaignHelper_Validate_IsCombinableWithExisting(t *testing.T) {
+	ch, campaigns := newStackingHelper()
+	campaigns.On("GetCampaignsCount", mock.Anything, 1, "").Return(1, nil)
+	campaigns.On("GetCampaignsCount", mock.Anything, 2, "").Return(0, nil)
+
+	r := newStackingRecord()
+	r.CombinableWith = []int{1, 2}
+
+	assert.Equal(t, errors.New("1185"), ch.Validate(context.Background(), r))
+}
+
+func TestCampaignHelper_Validate_IsStackingGroupValid(t *testing.T) {
+	ch, _ := newStackingHelper()
+
+	r := newStackingRecord()
+	r.StackingGroup = "   "
+	assert.Equal(t, errors.New("1186"), ch.Validate(context.Background(), r))
+
+	r.StackingGroup = "a-very-long-stacking-group-name-that-does-not-fit-in"
+	assert.Equal(t, errors.New("1186"), ch.Validate(context.Background(), r))
+}
+
+func ids(records []RecordOutput) []int {
+	result := make([]int, len(records))
+	for idx, r := range records {
+		result[idx] = r.CampaignID
+	}
+	return result
+}
+
+func TestSelectCombinable_PriorityOrder(t *testing.T) {
+	records := []RecordOutput{
+		{CampaignID: 3, Priority: 1},
+		{CampaignID: 2, Priority: 5},
+		{CampaignID: 1, Priority: 1},
+	}
+
+	assert.Equal(t, []int{2, 1, 3}, ids(SelectCombinable(records)))
+}
+
+func TestSelectCombinable_Exclusive(t *testing.T) {
+	records := []RecordOutput{
+		{CampaignID: 1, Priority: 1},
+		{CampaignID: 2, Priority: 5, Exclusive: 1},
+		{CampaignID: 3, Priority: 1},
+	}
+	assert.Equal(t, []int{2}, ids(SelectCombinable(records)))
+
+	records[1].Priority = 0
+	assert.Equal(t, []int{1, 3}, ids(SelectCombinable(records)))
+}
+
+func TestSelectCombinable_StackingGroup(t *testing.T) {
+	records := []RecordOutput{
+		{CampaignID: 1, Priority: 1, StackingGroup: "seasonal"},
+		{CampaignID: 2, Priority: 2, StackingGroup: "seasonal"},
+		{CampaignID: 3, Priority: 1, StackingGroup: "loyalty"},
+	}
+
+	assert.Equal(t, []int{2, 3}, ids(SelectCombinable(records)))
+}
+
+func TestSelectCombinable_CombinableWith(t *testing.T) {
+	records := []RecordOutput{
+		{CampaignID: 1, Priority: 3, CombinableWith: "2"},
+		{CampaignID: 2, Priority: 2},
+		{CampaignID: 3, Priority: 1},
+	}
+	assert.Equal(t, []int{1, 2}, ids(SelectCombinable(records)))
+
+	records[1].CombinableWith = "3"
+	assert.Equal(t, []int{1}, ids(SelectCombinable(records)))
+}
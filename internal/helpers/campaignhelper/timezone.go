@@ -0,0 +1,72 @@
+package campaignhelper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// ConfTimezone tenant conf holding the IANA timezone of the stores
+	ConfTimezone = "timezone"
+	// ConfWarehouseTimezone tenant conf holding the IANA timezone of a single warehouse
+	ConfWarehouseTimezone = "timezone_warehouse_%d"
+	// LocalTimeFormat format of the local representation of campaign instants
+	LocalTimeFormat = time.RFC3339
+)
+
+// Accepted input formats of the campaign start and end, the date only
+// format is the last one
+var dateTimeFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// GetLocation returns the timezone of the warehouse. When the warehouse has
+// no timezone of its own the tenant timezone is used and when neither is
+// configured the timezone is UTC
+func (p *CampaignHelper) GetLocation(ctx context.Context, warehouseID int) (*time.Location, error) {
+
+	names := []string{ConfTimezone}
+	if warehouseID > 0 {
+		names = append([]string{fmt.Sprintf(ConfWarehouseTimezone, warehouseID)}, names...)
+	}
+
+	for _, name := range names {
+		c, err := p.ConfigRepository.GetConfigByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(c.Value) == "" {
+			continue
+		}
+		return time.LoadLocation(strings.TrimSpace(c.Value))
+	}
+
+	return time.UTC, nil
+}
+
+// ParseDateTime parses the campaign start or end in the location. Values
+// with an explicit offset keep it. Date only values mean the start of
+// the day or, when endOfDay is set, the last second of the day
+func ParseDateTime(value string, loc *time.Location, endOfDay bool) (time.Time, error) {
+
+	var err error
+	for idx, format := range dateTimeFormats {
+		var t time.Time
+		t, err = time.ParseInLocation(format, value, loc)
+		if err != nil {
+			continue
+		}
+		if idx == len(dateTimeFormats)-1 && endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-1 * time.Second)
+		}
+		return t, nil
+	}
+	return time.Time{}, err
+}
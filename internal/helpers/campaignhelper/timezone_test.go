@@ -0,0 +1,110 @@
+package campaignhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/config"
+	configMocks "github.com/zdarovich/promotion-api/internal/repositories/config/mocks"
+)
+
+func TestCampaignHelper_GetLocation(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "timezone_warehouse_1").Return(config.Conf{Value: "America/New_York"}, nil)
+	cr.On("GetConfigByName", mock.Anything, "timezone_warehouse_2").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{Value: "Europe/Tallinn"}, nil)
+	ch.ConfigRepository = cr
+
+	loc, err := ch.GetLocation(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "America/New_York", loc.String())
+
+	loc, err = ch.GetLocation(context.Background(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, "Europe/Tallinn", loc.String())
+
+	loc, err = ch.GetLocation(context.Background(), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "Europe/Tallinn", loc.String())
+}
+
+func TestCampaignHelper_GetLocation_Defaults(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{}, nil)
+	ch.ConfigRepository = cr
+
+	loc, err := ch.GetLocation(context.Background(), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, time.UTC, loc)
+}
+
+func TestCampaignHelper_GetLocation_Errors(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{Value: "Mars/Olympus"}, nil).Once()
+	cr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{}, errors.New("1003")).Once()
+	ch.ConfigRepository = cr
+
+	_, err := ch.GetLocation(context.Background(), 0)
+	assert.NotNil(t, err)
+
+	_, err = ch.GetLocation(context.Background(), 0)
+	assert.NotNil(t, err)
+}
+
+func TestParseDateTime(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Tallinn")
+
+	start, err := ParseDateTime("2020-05-04", loc, false)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, time.May, 3, 21, 0, 0, 0, time.UTC), start.UTC())
+
+	end, err := ParseDateTime("2020-05-04", loc, true)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, time.May, 4, 20, 59, 59, 0, time.UTC), end.UTC())
+
+	start, err = ParseDateTime("2020-05-04 16:30:00", loc, false)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, time.May, 4, 13, 30, 0, 0, time.UTC), start.UTC())
+
+	start, err = ParseDateTime("2020-05-04T16:30", loc, true)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, time.May, 4, 13, 30, 0, 0, time.UTC), start.UTC())
+
+	start, err = ParseDateTime("2020-05-04T16:30:00Z", loc, false)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, time.May, 4, 16, 30, 0, 0, time.UTC), start.UTC())
+
+	_, err = ParseDateTime("04.05.2020", loc, false)
+	assert.NotNil(t, err)
+}
+
+func TestCampaignHelper_MapToArray_LocalAndUTC(t *testing.T) {
+	ch := new(CampaignHelper)
+	cr := new(configMocks.IRepository)
+	cr.On("GetConfigByName", mock.Anything, "timezone_warehouse_1").Return(config.Conf{Value: "America/New_York"}, nil)
+	ch.ConfigRepository = cr
+
+	cs := []campaign.Campaign{{
+		ID:          1,
+		WarehouseID: 1,
+		StartDate:   time.Date(2020, time.May, 4, 4, 0, 0, 0, time.UTC),
+		EndDate:     time.Date(2020, time.May, 11, 3, 59, 59, 0, time.UTC),
+	}}
+
+	output, err := ch.MapToArray(context.Background(), cs, Relations{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, time.May, 4, 4, 0, 0, 0, time.UTC), output[0].StartDate)
+	assert.Equal(t, "2020-05-04T00:00:00-04:00", output[0].StartDateLocal)
+	assert.Equal(t, "2020-05-10T23:59:59-04:00", output[0].EndDateLocal)
+	assert.Equal(t, "America/New_York", output[0].Timezone)
+	cr.AssertNumberOfCalls(t, "GetConfigByName", 1)
+}
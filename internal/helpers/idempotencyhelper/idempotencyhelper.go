@@ -0,0 +1,147 @@
+package idempotencyhelper
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/cache/redis"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+const (
+	// CacheKey redis key of the record of an idempotency key of a client
+	// code, the key is hashed
+	CacheKey = "idempotency_%s_%x"
+	// DefaultWindow time the responses are kept when no window is configured
+	DefaultWindow = 24 * time.Hour
+	// PendingTimeout a request that has not completed within the timeout,
+	// e.g. of an instance that stopped, can be run again with its key
+	PendingTimeout = 5 * time.Minute
+)
+
+var (
+	// ErrKeyReused the idempotency key was used for another request
+	ErrKeyReused = errors.New("idempotency key was used for a different request")
+	// ErrInProgress the first request of the idempotency key has not
+	// completed yet
+	ErrInProgress = errors.New("request of the idempotency key is in progress")
+)
+
+type (
+	// IdempotencyHelper stores the response of the first request of an
+	// idempotency key in redis, per client code. A duplicate request, with
+	// the key and the same fingerprint, gets the stored response
+	IdempotencyHelper struct {
+		Configuration *config.Configuration
+		Cache         redis.IRedis
+	}
+	// IIdempotencyHelper interface
+	IIdempotencyHelper interface {
+		Begin(ctx context.Context, clientCode string, key string, fingerprint string) (Record, bool, error)
+		Complete(ctx context.Context, clientCode string, key string, record Record) error
+		Release(ctx context.Context, clientCode string, key string) error
+	}
+	// Record of an idempotency key, the fingerprint of its request and the
+	// response once the request has completed
+	Record struct {
+		Fingerprint string `json:"fingerprint"`
+		Completed   bool   `json:"completed"`
+		Status      int    `json:"status,omitempty"`
+		ContentType string `json:"contentType,omitempty"`
+		Body        []byte `json:"body,omitempty"`
+	}
+)
+
+// New returns new configured idempotency helper
+func New(configuration *config.Configuration) IIdempotencyHelper {
+
+	return &IdempotencyHelper{
+		Configuration: configuration,
+		Cache:         redis.New(configuration),
+	}
+}
+
+// Fingerprint returns the fingerprint of the parts of a request, the request
+// type and its payload
+func Fingerprint(parts ...string) string {
+
+	hash := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(hash, "%d:%s;", len(part), part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Begin reserves the key for the request. A duplicate of a completed request
+// gets its record and true. ErrKeyReused is returned when the key is
+// reserved with another fingerprint and ErrInProgress while the first
+// request is running
+func (idempotencyHelper *IdempotencyHelper) Begin(ctx context.Context, clientCode string, key string, fingerprint string) (Record, bool, error) {
+
+	redisKey := cacheKey(clientCode, key)
+	pending, err := json.Marshal(Record{Fingerprint: fingerprint})
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	reserved, err := idempotencyHelper.Cache.SetNX(ctx, redisKey, string(pending), PendingTimeout)
+	if err != nil || reserved {
+		return Record{}, false, err
+	}
+
+	value, err := idempotencyHelper.Cache.Get(ctx, redisKey)
+	if err == redis.Nil {
+		// Released or expired since, the client may retry
+		return Record{}, false, ErrInProgress
+	}
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	var record Record
+	data, _ := value.(string)
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		return Record{}, false, err
+	}
+
+	if record.Fingerprint != fingerprint {
+		return Record{}, false, ErrKeyReused
+	}
+	if !record.Completed {
+		return Record{}, false, ErrInProgress
+	}
+	return record, true, nil
+}
+
+// Complete stores the response of the request of the key for the window
+func (idempotencyHelper *IdempotencyHelper) Complete(ctx context.Context, clientCode string, key string, record Record) error {
+
+	record.Completed = true
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	window := time.Duration(idempotencyHelper.Configuration.Idempotency.Window) * time.Second
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return idempotencyHelper.Cache.SetX(ctx, cacheKey(clientCode, key), string(data), window)
+}
+
+// Release removes the reservation of the key, the request can be run again
+func (idempotencyHelper *IdempotencyHelper) Release(ctx context.Context, clientCode string, key string) error {
+
+	return idempotencyHelper.Cache.Del(ctx, cacheKey(clientCode, key))
+}
+
+// cacheKey returns the redis key of the idempotency key of the client code
+func cacheKey(clientCode string, key string) string {
+
+	return fmt.Sprintf(CacheKey, clientCode, sha256.Sum256([]byte(key)))
+}
@@ -0,0 +1,140 @@
+package idempotencyhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/cache/redis"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+// MockCache in-memory redis, failing every call when fail is set
+type MockCache struct {
+	values map[string]interface{}
+	ttls   map[string]time.Duration
+	fail   bool
+}
+
+func (m *MockCache) Exists(ctx context.Context, key string) (interface{}, error) {
+	_, ok := m.values[key]
+	return ok, nil
+}
+
+func (m *MockCache) Get(ctx context.Context, key string) (interface{}, error) {
+	value, ok := m.values[key]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return value, nil
+}
+
+func (m *MockCache) Set(ctx context.Context, key string, value interface{}) error {
+	return m.SetX(ctx, key, value, 0)
+}
+
+func (m *MockCache) SetX(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	m.values[key] = value
+	m.ttls[key] = ttl
+	return nil
+}
+
+func (m *MockCache) Del(ctx context.Context, key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func (m *MockCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	if m.fail {
+		return false, errors.New("connection refused")
+	}
+	if _, ok := m.values[key]; ok {
+		return false, nil
+	}
+	return true, m.SetX(ctx, key, value, ttl)
+}
+
+func newHelper() (*IdempotencyHelper, *MockCache) {
+
+	configuration := &config.Configuration{}
+	configuration.Idempotency.Enabled = true
+	configuration.Idempotency.Window = 3600
+
+	cache := &MockCache{values: map[string]interface{}{}, ttls: map[string]time.Duration{}}
+	return &IdempotencyHelper{Configuration: configuration, Cache: cache}, cache
+}
+
+func TestBegin_ReplaysCompletedRequest(t *testing.T) {
+	idempotencyHelper, cache := newHelper()
+	ctx := context.Background()
+	fingerprint := Fingerprint("saveCampaigns", "name=Spring")
+
+	_, replay, err := idempotencyHelper.Begin(ctx, "104235", "retry-1", fingerprint)
+	require.Nil(t, err)
+	assert.False(t, replay)
+	assert.Equal(t, PendingTimeout, cache.ttls[cacheKey("104235", "retry-1")])
+
+	// A duplicate while the first request runs
+	_, _, err = idempotencyHelper.Begin(ctx, "104235", "retry-1", fingerprint)
+	assert.Equal(t, ErrInProgress, err)
+
+	require.Nil(t, idempotencyHelper.Complete(ctx, "104235", "retry-1", Record{Fingerprint: fingerprint, Status: 200, ContentType: "application/json", Body: []byte(`{"id":1}`)}))
+	assert.Equal(t, time.Hour, cache.ttls[cacheKey("104235", "retry-1")])
+
+	record, replay, err := idempotencyHelper.Begin(ctx, "104235", "retry-1", fingerprint)
+	require.Nil(t, err)
+	assert.True(t, replay)
+	assert.Equal(t, Record{Fingerprint: fingerprint, Completed: true, Status: 200, ContentType: "application/json", Body: []byte(`{"id":1}`)}, record)
+
+	// The keys are per client code
+	_, replay, err = idempotencyHelper.Begin(ctx, "104236", "retry-1", fingerprint)
+	assert.Nil(t, err)
+	assert.False(t, replay)
+}
+
+func TestBegin_KeyReused(t *testing.T) {
+	idempotencyHelper, _ := newHelper()
+	ctx := context.Background()
+
+	_, _, err := idempotencyHelper.Begin(ctx, "104235", "retry-1", Fingerprint("saveCampaigns", "name=Spring"))
+	require.Nil(t, err)
+
+	_, _, err = idempotencyHelper.Begin(ctx, "104235", "retry-1", Fingerprint("saveCampaigns", "name=Summer"))
+	assert.Equal(t, ErrKeyReused, err)
+}
+
+func TestRelease(t *testing.T) {
+	idempotencyHelper, cache := newHelper()
+	ctx := context.Background()
+
+	_, _, err := idempotencyHelper.Begin(ctx, "104235", "retry-1", "fingerprint")
+	require.Nil(t, err)
+	require.Nil(t, idempotencyHelper.Release(ctx, "104235", "retry-1"))
+	assert.Empty(t, cache.values)
+
+	_, replay, err := idempotencyHelper.Begin(ctx, "104235", "retry-1", "fingerprint")
+	assert.Nil(t, err)
+	assert.False(t, replay)
+}
+
+func TestBegin_CacheFailure(t *testing.T) {
+	idempotencyHelper, cache := newHelper()
+	cache.fail = true
+
+	_, replay, err := idempotencyHelper.Begin(context.Background(), "104235", "retry-1", "fingerprint")
+
+	assert.NotNil(t, err)
+	assert.False(t, replay)
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, Fingerprint("a", "b"), Fingerprint("a", "b"))
+	assert.NotEqual(t, Fingerprint("ab", ""), Fingerprint("a", "b"))
+}
@@ -0,0 +1,339 @@
+package jwthelper
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+// ReloadInterval minimum time between two reloads of the keys, a token
+// signed with an unknown key does not reload them more often
+const ReloadInterval = time.Minute
+
+// curveSizes curve of the ECDSA algorithms
+var curveSizes = map[string]int{"ES256": 256, "ES384": 384, "ES512": 521}
+
+type (
+	// JWTHelper verifies the identity tokens with the public keys of the
+	// configuration. The keys are loaded once and reloaded when a token is
+	// signed with a key id that is not known yet
+	JWTHelper struct {
+		Configuration *config.Configuration
+		mutex         sync.Mutex
+		keys          []publicKey
+		loaded        time.Time
+		now           func() time.Time
+	}
+	// IJWTHelper interface
+	IJWTHelper interface {
+		Verify(token string) (Claims, error)
+	}
+	// Claims of a verified token
+	Claims struct {
+		ClientCode string
+		Username   string
+		Expires    time.Time
+	}
+	publicKey struct {
+		id  string
+		key crypto.PublicKey
+	}
+	header struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}
+)
+
+// New returns new configured jwt helper
+func New(configuration *config.Configuration) IJWTHelper {
+
+	return &JWTHelper{
+		Configuration: configuration,
+		now:           time.Now,
+	}
+}
+
+// Verify checks the signature and the claims of the token and returns its
+// claims
+func (jwtHelper *JWTHelper) Verify(token string) (Claims, error) {
+
+	var claims Claims
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, errors.New("token is not a JWS compact serialization")
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return claims, fmt.Errorf("token header: %s", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, fmt.Errorf("token signature: %s", err)
+	}
+
+	keys := jwtHelper.candidates(h.KeyID)
+	if len(keys) == 0 {
+		return claims, fmt.Errorf("no public key for kid %q", h.KeyID)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	err = errors.New("signature does not match any public key")
+	for _, key := range keys {
+		if err = verifySignature(h.Algorithm, key.key, signed, signature); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return claims, err
+	}
+
+	values := make(map[string]interface{})
+	if err = decodeSegment(parts[1], &values); err != nil {
+		return claims, fmt.Errorf("token claims: %s", err)
+	}
+	return jwtHelper.claims(values)
+}
+
+// candidates returns the keys a token signed with the key id may be
+// verified with. The keys of the PEM files have no id and are candidates of
+// every token
+func (jwtHelper *JWTHelper) candidates(keyID string) []publicKey {
+	jwtHelper.mutex.Lock()
+	defer jwtHelper.mutex.Unlock()
+
+	if jwtHelper.loaded.IsZero() {
+		jwtHelper.load()
+	}
+	keys := jwtHelper.match(keyID)
+	if keyID != "" && len(keys) == 0 && jwtHelper.now().Sub(jwtHelper.loaded) >= ReloadInterval {
+		// The identity service may have rotated its keys
+		jwtHelper.load()
+		keys = jwtHelper.match(keyID)
+	}
+	return keys
+}
+
+func (jwtHelper *JWTHelper) match(keyID string) []publicKey {
+
+	keys := make([]publicKey, 0)
+	for _, key := range jwtHelper.keys {
+		if keyID == "" || key.id == "" || key.id == keyID {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// load reads the public keys of the PEM files and the JWKS file. When a
+// file cannot be read the keys loaded before are kept
+func (jwtHelper *JWTHelper) load() {
+
+	jwtHelper.loaded = jwtHelper.now()
+	configuration := jwtHelper.Configuration.Identity.JWT
+	previousPEM, previousJWKS := make([]publicKey, 0), make([]publicKey, 0)
+	for _, key := range jwtHelper.keys {
+		if key.id == "" {
+			previousPEM = append(previousPEM, key)
+		} else {
+			previousJWKS = append(previousJWKS, key)
+		}
+	}
+
+	keys := make([]publicKey, 0)
+	for _, path := range configuration.PublicKeys {
+		pemKeys, err := LoadPEM(path)
+		if err != nil {
+			log.Errorf("jwt public key %s: %s", path, err)
+			keys = previousPEM
+			break
+		}
+		for _, key := range pemKeys {
+			keys = append(keys, publicKey{key: key})
+		}
+	}
+	if configuration.JWKS != "" {
+		jwks, err := LoadJWKS(configuration.JWKS)
+		if err != nil {
+			log.Errorf("jwt jwks %s: %s", configuration.JWKS, err)
+			keys = append(keys, previousJWKS...)
+		}
+		for id, key := range jwks {
+			keys = append(keys, publicKey{id: id, key: key})
+		}
+	}
+	jwtHelper.keys = keys
+}
+
+// claims validates the registered claims and returns the claims of the api
+func (jwtHelper *JWTHelper) claims(values map[string]interface{}) (Claims, error) {
+
+	var claims Claims
+	configuration := jwtHelper.Configuration.Identity.JWT
+	leeway := time.Duration(configuration.Leeway) * time.Second
+	now := jwtHelper.now()
+
+	exp, ok := numericDate(values["exp"])
+	if !ok {
+		return claims, errors.New("token has no exp claim")
+	}
+	if !now.Before(exp.Add(leeway)) {
+		return claims, fmt.Errorf("token expired at %s", exp.UTC().Format(time.RFC3339))
+	}
+	if nbf, ok := numericDate(values["nbf"]); ok && now.Add(leeway).Before(nbf) {
+		return claims, fmt.Errorf("token is not valid before %s", nbf.UTC().Format(time.RFC3339))
+	}
+	if configuration.Issuer != "" && values["iss"] != configuration.Issuer {
+		return claims, fmt.Errorf("token issuer %v is not %s", values["iss"], configuration.Issuer)
+	}
+	if configuration.Audience != "" && !hasAudience(values["aud"], configuration.Audience) {
+		return claims, fmt.Errorf("token audience %v has no %s", values["aud"], configuration.Audience)
+	}
+
+	claims.Expires = exp
+	claims.ClientCode = stringClaim(values["clientCode"])
+	claims.Username = stringClaim(values["username"])
+	if claims.ClientCode == "" || claims.Username == "" {
+		return claims, errors.New("token has no clientCode or username claim")
+	}
+	return claims, nil
+}
+
+// verifySignature verifies the signature of the algorithm with the key, the
+// type of the key has to be the one of the algorithm
+func verifySignature(algorithm string, key crypto.PublicKey, signed []byte, signature []byte) error {
+
+	if len(algorithm) != 5 {
+		return fmt.Errorf("algorithm %q is not supported", algorithm)
+	}
+	var hash crypto.Hash
+	switch algorithm[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		digest, err := digest(hash, signed)
+		if err != nil {
+			return err
+		}
+		switch algorithm[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(k, hash, digest, signature)
+		case "PS":
+			return rsa.VerifyPSS(k, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+	case *ecdsa.PublicKey:
+		if curveSizes[algorithm] != k.Curve.Params().BitSize {
+			break
+		}
+		digest, err := digest(hash, signed)
+		if err != nil {
+			return err
+		}
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return errors.New("ecdsa signature has a wrong length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("ecdsa verification error")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if algorithm != "EdDSA" {
+			break
+		}
+		if !ed25519.Verify(k, signed, signature) {
+			return errors.New("ed25519 verification error")
+		}
+		return nil
+	}
+	return fmt.Errorf("algorithm %q does not match the key", algorithm)
+}
+
+func digest(hash crypto.Hash, signed []byte) ([]byte, error) {
+
+	switch hash {
+	case crypto.SHA256:
+		sum := sha256.Sum256(signed)
+		return sum[:], nil
+	case crypto.SHA384:
+		sum := sha512.Sum384(signed)
+		return sum[:], nil
+	case crypto.SHA512:
+		sum := sha512.Sum512(signed)
+		return sum[:], nil
+	}
+	return nil, errors.New("unsupported algorithm")
+}
+
+func decodeSegment(segment string, v interface{}) error {
+
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func numericDate(value interface{}) (time.Time, bool) {
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+func hasAudience(value interface{}, audience string) bool {
+
+	switch v := value.(type) {
+	case string:
+		return v == audience
+	case []interface{}:
+		for _, a := range v {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func stringClaim(value interface{}) string {
+
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return ""
+}
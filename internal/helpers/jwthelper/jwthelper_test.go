@@ -0,0 +1,242 @@
+package jwthelper
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+var now = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func sign(t *testing.T, h map[string]interface{}, claims map[string]interface{}, key crypto.Signer) string {
+
+	encode := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.Nil(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(h) + "." + encode(claims)
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		s, err := rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.Nil(t, err)
+		signature = s
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.Nil(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, []byte(signed))
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"clientCode": 104235,
+		"username":   "service",
+		"exp":        now.Add(time.Hour).Unix(),
+		"iss":        "identity",
+		"aud":        []string{"promotion-api"},
+	}
+}
+
+func writePEM(t *testing.T, dir string, key crypto.PublicKey) string {
+
+	data, err := x509.MarshalPKIXPublicKey(key)
+	require.Nil(t, err)
+	path := filepath.Join(dir, "identity.pem")
+	require.Nil(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: data}), 0600))
+	return path
+}
+
+func writeJWKS(t *testing.T, path string, keys map[string]*ecdsa.PublicKey) {
+
+	set := map[string][]map[string]string{"keys": {}}
+	for id, key := range keys {
+		set["keys"] = append(set["keys"], map[string]string{
+			"kty": "EC",
+			"kid": id,
+			"use": "sig",
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+			"y":   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+		})
+	}
+	data, err := json.Marshal(set)
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(path, data, 0600))
+}
+
+func newHelper(publicKeys []string, jwks string) *JWTHelper {
+
+	configuration := &config.Configuration{}
+	configuration.Identity.JWT.Enabled = true
+	configuration.Identity.JWT.PublicKeys = publicKeys
+	configuration.Identity.JWT.JWKS = jwks
+	configuration.Identity.JWT.Issuer = "identity"
+	configuration.Identity.JWT.Audience = "promotion-api"
+	configuration.Identity.JWT.Leeway = 30
+
+	jwtHelper := New(configuration).(*JWTHelper)
+	jwtHelper.now = func() time.Time { return now }
+	return jwtHelper
+}
+
+func TestVerify_RS256_PEM(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	jwtHelper := newHelper([]string{writePEM(t, t.TempDir(), &key.PublicKey)}, "")
+
+	claims, err := jwtHelper.Verify(sign(t, map[string]interface{}{"alg": "RS256", "typ": "JWT"}, validClaims(), key))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "104235", claims.ClientCode)
+	assert.Equal(t, "service", claims.Username)
+	assert.True(t, now.Add(time.Hour).Equal(claims.Expires))
+}
+
+func TestVerify_EdDSA_PEM(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.Nil(t, err)
+	jwtHelper := newHelper([]string{writePEM(t, t.TempDir(), public)}, "")
+
+	_, err = jwtHelper.Verify(sign(t, map[string]interface{}{"alg": "EdDSA"}, validClaims(), private))
+
+	assert.Nil(t, err)
+}
+
+func TestVerify_ES256_JWKS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]*ecdsa.PublicKey{"2021-03": &key.PublicKey})
+	jwtHelper := newHelper(nil, path)
+
+	_, err = jwtHelper.Verify(sign(t, map[string]interface{}{"alg": "ES256", "kid": "2021-03"}, validClaims(), key))
+	assert.Nil(t, err)
+
+	_, err = jwtHelper.Verify(sign(t, map[string]interface{}{"alg": "ES256", "kid": "2020-01"}, validClaims(), key))
+	assert.NotNil(t, err)
+}
+
+func TestVerify_JWKS_ReloadsRotatedKeys(t *testing.T) {
+	old, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	rotated, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, map[string]*ecdsa.PublicKey{"old": &old.PublicKey})
+	jwtHelper := newHelper(nil, path)
+
+	_, err = jwtHelper.Verify(sign(t, map[string]interface{}{"alg": "ES256", "kid": "old"}, validClaims(), old))
+	require.Nil(t, err)
+
+	writeJWKS(t, path, map[string]*ecdsa.PublicKey{"old": &old.PublicKey, "rotated": &rotated.PublicKey})
+	token := sign(t, map[string]interface{}{"alg": "ES256", "kid": "rotated"}, validClaims(), rotated)
+
+	// Not reloaded more often than the reload interval
+	_, err = jwtHelper.Verify(token)
+	assert.NotNil(t, err)
+
+	now = now.Add(ReloadInterval)
+	defer func() { now = now.Add(-ReloadInterval) }()
+	_, err = jwtHelper.Verify(token)
+	assert.Nil(t, err)
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	jwtHelper := newHelper([]string{writePEM(t, t.TempDir(), &key.PublicKey)}, "")
+	rs256 := map[string]interface{}{"alg": "RS256"}
+
+	with := func(name string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+
+	tokens := map[string]string{
+		"malformed":          "abc.def",
+		"wrong key":          sign(t, rs256, validClaims(), other),
+		"none algorithm":     sign(t, map[string]interface{}{"alg": "none"}, validClaims(), key),
+		"wrong algorithm":    sign(t, map[string]interface{}{"alg": "ES256"}, validClaims(), key),
+		"expired":            sign(t, rs256, with("exp", now.Add(-time.Minute).Unix()), key),
+		"no expiry":          sign(t, rs256, with("exp", nil), key),
+		"not yet valid":      sign(t, rs256, with("nbf", now.Add(time.Minute).Unix()), key),
+		"wrong issuer":       sign(t, rs256, with("iss", "other"), key),
+		"wrong audience":     sign(t, rs256, with("aud", "other"), key),
+		"no client code":     sign(t, rs256, with("clientCode", nil), key),
+		"no username":        sign(t, rs256, with("username", nil), key),
+		"tampered signature": sign(t, rs256, validClaims(), key) + "A",
+	}
+	for name, token := range tokens {
+		_, err := jwtHelper.Verify(token)
+		assert.NotNil(t, err, name)
+	}
+
+	// Expired within the leeway
+	_, err = jwtHelper.Verify(sign(t, rs256, with("exp", now.Add(-10*time.Second).Unix()), key))
+	assert.Nil(t, err)
+}
+
+func TestVerify_NoKeys(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	jwtHelper := newHelper([]string{"/does/not/exist.pem"}, "")
+
+	_, err = jwtHelper.Verify(sign(t, map[string]interface{}{"alg": "RS256"}, validClaims(), key))
+
+	assert.NotNil(t, err)
+}
+
+func TestLoadJWKS_RSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.Nil(t, err)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	data, err := json.Marshal(map[string][]map[string]string{"keys": {
+		{
+			"kty": "RSA",
+			"kid": "rsa",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		},
+		{"kty": "RSA", "kid": "encryption", "use": "enc"},
+	}})
+	require.Nil(t, err)
+	require.Nil(t, os.WriteFile(path, data, 0600))
+
+	keys, err := LoadJWKS(path)
+
+	assert.Nil(t, err)
+	assert.Len(t, keys, 1)
+	assert.Equal(t, &key.PublicKey, keys["rsa"])
+}
@@ -0,0 +1,201 @@
+package ratelimithelper
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/cache/redis"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+const (
+	// CacheKey redis key of the counter of a limit in a window, the name of
+	// the limit and the index of the window
+	CacheKey = "ratelimit_%s_%d"
+	// DefaultWindow window of the limits when none is configured
+	DefaultWindow = time.Minute
+)
+
+type (
+	// RateLimitHelper counts the requests of the tenant, of the caller and of
+	// the caller per request type in redis, so that the limits hold across
+	// the instances. The limits are sliding windows, the count of the
+	// previous window is weighted by the part of it still in the window
+	RateLimitHelper struct {
+		Configuration *config.Configuration
+		Cache         redis.IRedis
+		now           func() time.Time
+	}
+	// IRateLimitHelper interface
+	IRateLimitHelper interface {
+		Allow(ctx context.Context, clientCode string, key string, request string) (Result, error)
+	}
+	// Result of the most restrictive limit of a request, Limit is 0 when the
+	// request has no limits. Reset is the time until the window ends
+	Result struct {
+		Allowed   bool
+		Limit     int
+		Remaining int
+		Reset     time.Duration
+	}
+	counter struct {
+		name  string
+		limit int
+	}
+)
+
+// New returns new configured rate limit helper
+func New(configuration *config.Configuration) IRateLimitHelper {
+
+	return &RateLimitHelper{
+		Configuration: configuration,
+		Cache:         redis.New(configuration),
+		now:           time.Now,
+	}
+}
+
+// Allow returns whether the request is within the limits of the tenant, of
+// the caller key and of the request type, and counts it when it is. A
+// rejected request is not counted. The request is allowed when the counters
+// cannot be read, with the error
+func (rateLimitHelper *RateLimitHelper) Allow(ctx context.Context, clientCode string, key string, request string) (Result, error) {
+
+	result := Result{Allowed: true}
+	if !rateLimitHelper.Configuration.RateLimit.Enabled {
+		return result, nil
+	}
+
+	window := time.Duration(rateLimitHelper.Configuration.RateLimit.Window) * time.Second
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	now := rateLimitHelper.now().UnixNano()
+	index := now / int64(window)
+	elapsed := time.Duration(now % int64(window))
+	result.Reset = window - elapsed
+
+	limits := rateLimitHelper.Limits(clientCode)
+	counters := []counter{
+		{name: clientCode, limit: limits.Tenant},
+		{name: clientCode + "_" + key, limit: limits.Caller},
+		{name: clientCode + "_" + key + "_" + request, limit: limits.Requests[request]},
+	}
+
+	for _, c := range counters {
+		if c.limit <= 0 {
+			continue
+		}
+
+		current, err := rateLimitHelper.count(ctx, fmt.Sprintf(CacheKey, c.name, index))
+		if err != nil {
+			return Result{Allowed: true}, err
+		}
+		previous, err := rateLimitHelper.count(ctx, fmt.Sprintf(CacheKey, c.name, index-1))
+		if err != nil {
+			return Result{Allowed: true}, err
+		}
+
+		// The count with the request
+		count := float64(previous)*float64(window-elapsed)/float64(window) + float64(current+1)
+		remaining := c.limit - int(math.Ceil(count))
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		if count > float64(c.limit) {
+			// The limit that rejects the request is the one reported
+			result.Allowed = false
+			result.Limit = c.limit
+			result.Remaining = 0
+			return result, nil
+		}
+		if result.Limit == 0 || remaining < result.Remaining {
+			result.Limit = c.limit
+			result.Remaining = remaining
+		}
+	}
+
+	// Concurrent requests checked before either is counted may both be
+	// allowed, the limits may be exceeded by the requests in flight
+	for _, c := range counters {
+		if c.limit <= 0 {
+			continue
+		}
+
+		_, err := rateLimitHelper.Cache.Incr(ctx, fmt.Sprintf(CacheKey, c.name, index), 2*window)
+		if err != nil {
+			return Result{Allowed: true}, err
+		}
+	}
+
+	return result, nil
+}
+
+// Limits returns the limits of the tenant, the default limits with the
+// overrides of the client code
+func (rateLimitHelper *RateLimitHelper) Limits(clientCode string) config.RateLimits {
+
+	defaults := rateLimitHelper.Configuration.RateLimit.RateLimits
+	override, ok := rateLimitHelper.Configuration.RateLimit.Tenants[clientCode]
+	if !ok {
+		return defaults
+	}
+
+	limits := config.RateLimits{
+		Tenant:   pick(override.Tenant, defaults.Tenant),
+		Caller:   pick(override.Caller, defaults.Caller),
+		Requests: make(map[string]int),
+	}
+	for request, limit := range defaults.Requests {
+		limits.Requests[request] = limit
+	}
+	for request, limit := range override.Requests {
+		limits.Requests[request] = pick(limit, limits.Requests[request])
+	}
+	return limits
+}
+
+// SetHeaders sets the rate limit headers of the result, and Retry-After when
+// the request is rejected
+func (result Result) SetHeaders(header http.Header) {
+
+	if result.Limit == 0 {
+		return
+	}
+
+	reset := strconv.Itoa(int(math.Ceil(result.Reset.Seconds())))
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", reset)
+	if !result.Allowed {
+		header.Set("Retry-After", reset)
+	}
+}
+
+// count returns the count of the window, 0 when it has none or has expired
+func (rateLimitHelper *RateLimitHelper) count(ctx context.Context, key string) (int64, error) {
+
+	value, err := rateLimitHelper.Cache.Get(ctx, key)
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	count, _ := strconv.ParseInt(fmt.Sprint(value), 10, 64)
+	return count, nil
+}
+
+// pick returns the override unless it is 0
+func pick(override int, limit int) int {
+
+	if override != 0 {
+		return override
+	}
+	return limit
+}
@@ -0,0 +1,209 @@
+package ratelimithelper
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/cache/redis"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+var now = time.Date(2021, 3, 1, 12, 0, 15, 0, time.UTC)
+
+// MockCache in-memory redis counters, failing every call when fail is set
+type MockCache struct {
+	counters map[string]int64
+	fail     bool
+}
+
+func (m *MockCache) Exists(ctx context.Context, key string) (interface{}, error) {
+	_, ok := m.counters[key]
+	return ok, nil
+}
+
+func (m *MockCache) Get(ctx context.Context, key string) (interface{}, error) {
+	if m.fail {
+		return nil, errors.New("connection refused")
+	}
+	count, ok := m.counters[key]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return strconv.FormatInt(count, 10), nil
+}
+
+func (m *MockCache) Set(ctx context.Context, key string, value interface{}) error {
+	return nil
+}
+
+func (m *MockCache) SetX(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	return nil
+}
+
+func (m *MockCache) Del(ctx context.Context, key string) error {
+	delete(m.counters, key)
+	return nil
+}
+
+func (m *MockCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	if m.fail {
+		return 0, errors.New("connection refused")
+	}
+	m.counters[key]++
+	return m.counters[key], nil
+}
+
+func (m *MockCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return false, nil
+}
+
+func newHelper() (*RateLimitHelper, *MockCache) {
+
+	configuration := &config.Configuration{}
+	configuration.RateLimit.Enabled = true
+	configuration.RateLimit.Window = 60
+	configuration.RateLimit.Tenant = 100
+	configuration.RateLimit.Caller = 10
+	configuration.RateLimit.Requests = map[string]int{"getCampaigns": 3}
+
+	cache := &MockCache{counters: map[string]int64{}}
+	return &RateLimitHelper{
+		Configuration: configuration,
+		Cache:         cache,
+		now:           func() time.Time { return now },
+	}, cache
+}
+
+func TestAllow_RequestLimit(t *testing.T) {
+	rateLimitHelper, _ := newHelper()
+
+	for i := 2; i >= 0; i-- {
+		result, err := rateLimitHelper.Allow(context.Background(), "104235", "key", "getCampaigns")
+		require.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, i, result.Remaining)
+		assert.Equal(t, 45*time.Second, result.Reset)
+	}
+
+	result, err := rateLimitHelper.Allow(context.Background(), "104235", "key", "getCampaigns")
+	require.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 3, result.Limit)
+
+	// Other request types and other callers have their own counters, the
+	// rejected request is not counted
+	result, _ = rateLimitHelper.Allow(context.Background(), "104235", "key", "getCampaign")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 10, result.Limit)
+	assert.Equal(t, 6, result.Remaining)
+	result, _ = rateLimitHelper.Allow(context.Background(), "104235", "other", "getCampaigns")
+	assert.True(t, result.Allowed)
+}
+
+func TestAllow_Rejected_NotCounted(t *testing.T) {
+	rateLimitHelper, cache := newHelper()
+	index := now.Unix() / 60
+
+	for i := 0; i < 3; i++ {
+		rateLimitHelper.Allow(context.Background(), "104235", "key", "getCampaigns")
+	}
+	for i := 0; i < 5; i++ {
+		result, err := rateLimitHelper.Allow(context.Background(), "104235", "key", "getCampaigns")
+		require.Nil(t, err)
+		assert.False(t, result.Allowed)
+	}
+
+	assert.Equal(t, int64(3), cache.counters[fmtKey("104235_key_getCampaigns", index)])
+	assert.Equal(t, int64(3), cache.counters[fmtKey("104235_key", index)])
+	assert.Equal(t, int64(3), cache.counters[fmtKey("104235", index)])
+}
+
+func TestAllow_TenantLimit(t *testing.T) {
+	rateLimitHelper, _ := newHelper()
+	rateLimitHelper.Configuration.RateLimit.Tenant = 2
+
+	rateLimitHelper.Allow(context.Background(), "104235", "a", "getCampaign")
+	rateLimitHelper.Allow(context.Background(), "104235", "b", "getCampaign")
+	result, _ := rateLimitHelper.Allow(context.Background(), "104235", "c", "getCampaign")
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 2, result.Limit)
+
+	result, _ = rateLimitHelper.Allow(context.Background(), "104236", "a", "getCampaign")
+	assert.True(t, result.Allowed)
+}
+
+func TestAllow_SlidingWindow(t *testing.T) {
+	rateLimitHelper, cache := newHelper()
+	index := now.Unix() / 60
+
+	// 4 requests in the previous window, 3/4 of it is still in the sliding window
+	cache.counters[fmtKey("104235_key_getCampaigns", index-1)] = 4
+
+	result, _ := rateLimitHelper.Allow(context.Background(), "104235", "key", "getCampaigns")
+	assert.False(t, result.Allowed)
+
+	cache.counters[fmtKey("104235_key_getCampaigns", index-1)] = 2
+	cache.counters[fmtKey("104235_key_getCampaigns", index)] = 0
+	result, _ = rateLimitHelper.Allow(context.Background(), "104235", "key", "getCampaigns")
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestAllow_Disabled(t *testing.T) {
+	rateLimitHelper, cache := newHelper()
+	rateLimitHelper.Configuration.RateLimit.Enabled = false
+
+	result, err := rateLimitHelper.Allow(context.Background(), "104235", "key", "getCampaigns")
+
+	assert.Nil(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Limit)
+	assert.Empty(t, cache.counters)
+}
+
+func TestAllow_CacheFailure(t *testing.T) {
+	rateLimitHelper, cache := newHelper()
+	cache.fail = true
+
+	result, err := rateLimitHelper.Allow(context.Background(), "104235", "key", "getCampaigns")
+
+	assert.NotNil(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestLimits_TenantOverride(t *testing.T) {
+	rateLimitHelper, _ := newHelper()
+	rateLimitHelper.Configuration.RateLimit.Tenants = map[string]config.RateLimits{
+		"104235": {Tenant: -1, Requests: map[string]int{"getCampaigns": 30, "getReports": 1}},
+	}
+
+	limits := rateLimitHelper.Limits("104235")
+
+	assert.Equal(t, config.RateLimits{Tenant: -1, Caller: 10, Requests: map[string]int{"getCampaigns": 30, "getReports": 1}}, limits)
+	assert.Equal(t, rateLimitHelper.Configuration.RateLimit.RateLimits, rateLimitHelper.Limits("104236"))
+}
+
+func TestResult_SetHeaders(t *testing.T) {
+	header := http.Header{}
+	Result{Allowed: false, Limit: 3, Remaining: 0, Reset: 44500 * time.Millisecond}.SetHeaders(header)
+
+	assert.Equal(t, "3", header.Get("RateLimit-Limit"))
+	assert.Equal(t, "0", header.Get("RateLimit-Remaining"))
+	assert.Equal(t, "45", header.Get("RateLimit-Reset"))
+	assert.Equal(t, "45", header.Get("Retry-After"))
+
+	header = http.Header{}
+	Result{Allowed: true}.SetHeaders(header)
+	assert.Empty(t, header)
+}
+
+func fmtKey(name string, index int64) string {
+	return "ratelimit_" + name + "_" + strconv.FormatInt(index, 10)
+}
@@ -0,0 +1,192 @@
+package segmenthelper
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat format of the customer dates and of the date rule values
+const DateFormat = "2006-01-02"
+
+// Customer structure of the customer profile segments are evaluated against
+type Customer struct {
+	CustomerID      int
+	CustomerGroupID int
+	Birthday        time.Time
+	LoyaltyTier     string
+	SignupDate      time.Time
+	TotalSpend      float64
+	Tags            []string
+}
+
+// Matches checks if the customer satisfies all rules. A segment without
+// rules contains no customers
+func Matches(rules []Rule, customer Customer) bool {
+	if len(rules) == 0 {
+		return false
+	}
+	for _, rule := range rules {
+		if !rule.Matches(customer) {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches checks if the customer satisfies the rule. Rules over an attribute
+// missing from the profile do not match
+func (r Rule) Matches(customer Customer) bool {
+	switch r.Attribute {
+	case AttributeBirthdayMonth:
+		if customer.Birthday.IsZero() {
+			return false
+		}
+		return compareNumbers(r.Operator, float64(customer.Birthday.Month()), r.Value)
+	case AttributeLoyaltyTier:
+		if customer.LoyaltyTier == "" {
+			return false
+		}
+		return compareStrings(r.Operator, customer.LoyaltyTier, r.Value)
+	case AttributeSignupDate:
+		if customer.SignupDate.IsZero() {
+			return false
+		}
+		return compareStrings(r.Operator, customer.SignupDate.Format(DateFormat), r.Value)
+	case AttributeTotalSpend:
+		return compareNumbers(r.Operator, customer.TotalSpend, r.Value)
+	case AttributeTags:
+		return compareTags(r.Operator, customer.Tags, r.Value)
+	}
+	return false
+}
+
+// IsRuleValueValid checks if the rule value can be compared with the attribute
+func IsRuleValueValid(r Rule) bool {
+	values := []string{strings.TrimSpace(r.Value)}
+	if r.Operator == "in" {
+		values = splitValues(r.Value)
+	}
+	if len(values) == 0 || values[0] == "" {
+		return false
+	}
+	for _, v := range values {
+		switch r.Attribute {
+		case AttributeBirthdayMonth:
+			month, err := strconv.Atoi(v)
+			if err != nil || month < 1 || month > 12 {
+				return false
+			}
+		case AttributeSignupDate:
+			if _, err := time.Parse(DateFormat, v); err != nil {
+				return false
+			}
+		case AttributeTotalSpend:
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func compareNumbers(operator string, actual float64, value string) bool {
+	if operator == "in" {
+		for _, v := range splitValues(value) {
+			if f, err := strconv.ParseFloat(v, 64); err == nil && f == actual {
+				return true
+			}
+		}
+		return false
+	}
+	expected, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return false
+	}
+	switch operator {
+	case "eq":
+		return actual == expected
+	case "neq":
+		return actual != expected
+	case "gt":
+		return actual > expected
+	case "gte":
+		return actual >= expected
+	case "lt":
+		return actual < expected
+	case "lte":
+		return actual <= expected
+	}
+	return false
+}
+
+// compareStrings compares case-insensitively, dates in DateFormat compare
+// in their natural order
+func compareStrings(operator string, actual string, value string) bool {
+	actual = strings.ToLower(actual)
+	expected := strings.ToLower(strings.TrimSpace(value))
+	switch operator {
+	case "eq":
+		return actual == expected
+	case "neq":
+		return actual != expected
+	case "gt":
+		return actual > expected
+	case "gte":
+		return actual >= expected
+	case "lt":
+		return actual < expected
+	case "lte":
+		return actual <= expected
+	case "in":
+		for _, v := range splitValues(value) {
+			if strings.ToLower(v) == actual {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func compareTags(operator string, tags []string, value string) bool {
+	has := func(tag string) bool {
+		for _, t := range tags {
+			if strings.EqualFold(t, tag) {
+				return true
+			}
+		}
+		return false
+	}
+	switch operator {
+	case "contains":
+		return has(strings.TrimSpace(value))
+	case "notContains":
+		return !has(strings.TrimSpace(value))
+	case "in":
+		for _, v := range splitValues(value) {
+			if has(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func splitValues(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package segmenthelper
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/segment"
+)
+
+const (
+	// AttributeBirthdayMonth month of the customer birthday, 1 to 12
+	AttributeBirthdayMonth = "birthdayMonth"
+	// AttributeLoyaltyTier loyalty tier name of the customer
+	AttributeLoyaltyTier = "loyaltyTier"
+	// AttributeSignupDate date the customer signed up, YYYY-MM-DD
+	AttributeSignupDate = "signupDate"
+	// AttributeTotalSpend lifetime spend of the customer
+	AttributeTotalSpend = "totalSpend"
+	// AttributeTags tags of the customer
+	AttributeTags = "tags"
+)
+
+// Operators supported by each segment attribute
+var attributeOperators = map[string][]string{
+	AttributeBirthdayMonth: {"eq", "neq", "gt", "gte", "lt", "lte", "in"},
+	AttributeLoyaltyTier:   {"eq", "neq", "in"},
+	AttributeSignupDate:    {"eq", "neq", "gt", "gte", "lt", "lte"},
+	AttributeTotalSpend:    {"eq", "neq", "gt", "gte", "lt", "lte"},
+	AttributeTags:          {"contains", "notContains", "in"},
+}
+
+type (
+	// SegmentHelper struct
+	SegmentHelper struct {
+		Configuration     *config.Configuration
+		SegmentRepository segment.IRepository
+	}
+	// ISegmentHelper interface
+	ISegmentHelper interface {
+		MapToArray(segments []segment.Segment, rules map[int][]*segment.Rule) []RecordOutput
+		MapRulesToDatabase(r *Record) []*segment.Rule
+		GetCustomerSegments(ctx context.Context, customer Customer, segmentIDs []int) ([]int, error)
+		Validate(r *Record) error
+	}
+	// RecordOutput structure of the output record
+	RecordOutput struct {
+		SegmentID   int    `json:"segmentID"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Rules       []Rule `json:"rules"`
+		Added       int64  `json:"added"`
+		Addedby     string `json:"addedby"`
+		Changed     int64  `json:"changed"`
+		Changedby   string `json:"changedby"`
+	}
+	// Record structure of the input record
+	Record struct {
+		SegmentID   int    `json:"segmentID"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Rules       []Rule `json:"rules"`
+	}
+	// Rule structure of a single condition over a customer attribute.
+	// List operators take comma-separated values
+	Rule struct {
+		Attribute string `json:"attribute"`
+		Operator  string `json:"operator"`
+		Value     string `json:"value"`
+	}
+)
+
+// New returns configured segment helper
+func New(configuration *config.Configuration) ISegmentHelper {
+
+	return &SegmentHelper{
+		Configuration:     configuration,
+		SegmentRepository: segment.New(configuration),
+	}
+}
+
+// MapToArray maps database segments and their rules to output records
+func (p *SegmentHelper) MapToArray(segments []segment.Segment, rules map[int][]*segment.Rule) []RecordOutput {
+	result := make([]RecordOutput, len(segments))
+	for idx, s := range segments {
+		result[idx] = RecordOutput{
+			SegmentID:   s.ID,
+			Name:        s.Name,
+			Description: s.Description,
+			Rules:       MapRulesToOutput(rules[s.ID]),
+			Added:       s.Added,
+			Addedby:     s.Addedby,
+			Changed:     s.Changed,
+			Changedby:   s.Changedby,
+		}
+	}
+	return result
+}
+
+// MapRulesToOutput maps database rules to output rules
+func MapRulesToOutput(rules []*segment.Rule) []Rule {
+	output := make([]Rule, len(rules))
+	for idx, r := range rules {
+		output[idx] = Rule{
+			Attribute: r.Attribute,
+			Operator:  r.Operator,
+			Value:     r.Value,
+		}
+	}
+	return output
+}
+
+// MapRulesToDatabase maps the record rules to database rules
+func (p *SegmentHelper) MapRulesToDatabase(r *Record) []*segment.Rule {
+	rules := make([]*segment.Rule, len(r.Rules))
+	for idx, rule := range r.Rules {
+		rules[idx] = &segment.Rule{
+			SegmentID: r.SegmentID,
+			Position:  idx,
+			Attribute: rule.Attribute,
+			Operator:  rule.Operator,
+			Value:     strings.TrimSpace(rule.Value),
+		}
+	}
+	return rules
+}
+
+// GetCustomerSegments returns the segments out of the given ones the customer belongs to
+func (p *SegmentHelper) GetCustomerSegments(ctx context.Context, customer Customer, segmentIDs []int) ([]int, error) {
+	result := make([]int, 0)
+	if len(segmentIDs) == 0 {
+		return result, nil
+	}
+	rules, err := p.SegmentRepository.GetRules(ctx, segmentIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range segmentIDs {
+		if Matches(MapRulesToOutput(rules[id]), customer) {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+// Validate checks the segment record
+func (p *SegmentHelper) Validate(r *Record) error {
+	if r == nil {
+		return errors.New("record is null")
+	}
+	if strings.TrimSpace(r.Name) == "" {
+		return errorcodes.New("name", errorcodes.CodeRequiredParameterMissing)
+	}
+	if len(r.Rules) == 0 {
+		return errors.New("1190")
+	}
+	for _, rule := range r.Rules {
+		operators, ok := attributeOperators[rule.Attribute]
+		if !ok {
+			return errors.New("1191")
+		} else if !containsString(operators, rule.Operator) {
+			return errors.New("1192")
+		} else if !IsRuleValueValid(rule) {
+			return errors.New("1193")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package segmenthelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/repositories/segment"
+	segmentMocks "github.com/zdarovich/promotion-api/internal/repositories/segment/mocks"
+)
+
+func TestSegmentHelper_Validate(t *testing.T) {
+	sh := new(SegmentHelper)
+
+	r := &Record{Name: "VIP", Rules: []Rule{{Attribute: "loyaltyTier", Operator: "in", Value: "gold,platinum"}}}
+	assert.Nil(t, sh.Validate(r))
+
+	r.Name = " "
+	assert.Equal(t, errorcodes.New("name", errorcodes.CodeRequiredParameterMissing), sh.Validate(r))
+
+	r.Name = "VIP"
+	r.Rules = nil
+	assert.Equal(t, errors.New("1190"), sh.Validate(r))
+
+	r.Rules = []Rule{{Attribute: "shoeSize", Operator: "eq", Value: "42"}}
+	assert.Equal(t, errors.New("1191"), sh.Validate(r))
+
+	r.Rules = []Rule{{Attribute: "tags", Operator: "gt", Value: "vip"}}
+	assert.Equal(t, errors.New("1192"), sh.Validate(r))
+
+	r.Rules = []Rule{{Attribute: "birthdayMonth", Operator: "eq", Value: "13"}}
+	assert.Equal(t, errors.New("1193"), sh.Validate(r))
+
+	r.Rules = []Rule{{Attribute: "signupDate", Operator: "lt", Value: "2020/01/01"}}
+	assert.Equal(t, errors.New("1193"), sh.Validate(r))
+
+	r.Rules = []Rule{{Attribute: "totalSpend", Operator: "gte", Value: ""}}
+	assert.Equal(t, errors.New("1193"), sh.Validate(r))
+}
+
+func TestSegment_Matches(t *testing.T) {
+	customer := Customer{
+		Birthday:    time.Date(1990, time.May, 4, 0, 0, 0, 0, time.UTC),
+		LoyaltyTier: "Gold",
+		SignupDate:  time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC),
+		TotalSpend:  1200.5,
+		Tags:        []string{"newsletter", "VIP"},
+	}
+
+	assert.True(t, Matches([]Rule{
+		{Attribute: "birthdayMonth", Operator: "in", Value: "4,5,6"},
+		{Attribute: "loyaltyTier", Operator: "eq", Value: "gold"},
+		{Attribute: "signupDate", Operator: "lt", Value: "2020-01-01"},
+		{Attribute: "totalSpend", Operator: "gte", Value: "1000"},
+		{Attribute: "tags", Operator: "contains", Value: "vip"},
+	}, customer))
+
+	assert.False(t, Matches([]Rule{
+		{Attribute: "totalSpend", Operator: "gte", Value: "1000"},
+		{Attribute: "tags", Operator: "notContains", Value: "newsletter"},
+	}, customer))
+
+	assert.False(t, Matches(nil, customer))
+	assert.False(t, Matches([]Rule{{Attribute: "birthdayMonth", Operator: "eq", Value: "5"}}, Customer{}))
+}
+
+func TestSegmentHelper_GetCustomerSegments(t *testing.T) {
+	sr := new(segmentMocks.IRepository)
+	sr.On("GetRules", mock.Anything, []int{1, 2, 3}).Return(map[int][]*segment.Rule{
+		1: {{SegmentID: 1, Attribute: "loyaltyTier", Operator: "eq", Value: "gold"}},
+		2: {{SegmentID: 2, Attribute: "totalSpend", Operator: "gt", Value: "5000"}},
+	}, nil)
+	sh := &SegmentHelper{SegmentRepository: sr}
+
+	ids, err := sh.GetCustomerSegments(context.Background(), Customer{LoyaltyTier: "gold", TotalSpend: 100}, []int{1, 2, 3})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1}, ids)
+}
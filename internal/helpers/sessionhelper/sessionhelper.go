@@ -0,0 +1,211 @@
+package sessionhelper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/cache/redis"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/log"
+	conf "github.com/zdarovich/promotion-api/internal/repositories/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/session"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+)
+
+const (
+	// CacheKey redis key of the resolved session of a client code and a
+	// session key, deleting it invalidates the cached session
+	CacheKey = "session_%s_%s"
+	// ConfSlidingExpiration tenant conf holding the seconds of inactivity
+	// after which a session expires, empty keeps the expiry of the session
+	ConfSlidingExpiration = "session_sliding_expiration"
+	// ExtendInterval the expiry of a session is moved forward at most once
+	// per interval
+	ExtendInterval = time.Minute
+)
+
+type (
+	// SessionHelper resolves the session key of a request to its session and
+	// user. The resolved session is cached in redis for the configured time
+	SessionHelper struct {
+		Configuration     *config.Configuration
+		SessionRepository session.IRepository
+		UserRepository    user.IRepository
+		ConfigRepository  conf.IRepository
+		Cache             redis.IRedis
+		now               func() time.Time
+	}
+	// ISessionHelper interface
+	ISessionHelper interface {
+		Resolve(ctx context.Context, clientCode string, sessionKey string) (Resolved, error)
+		Invalidate(ctx context.Context, clientCode string, sessionKey string) error
+	}
+	// Resolved session of a session key, its user and the sliding expiration
+	// of the tenant in seconds
+	Resolved struct {
+		Session           session.Session
+		User              user.User
+		SlidingExpiration int64
+	}
+)
+
+// New returns new configured session helper
+func New(configuration *config.Configuration) ISessionHelper {
+
+	return &SessionHelper{
+		Configuration:     configuration,
+		SessionRepository: session.New(configuration),
+		UserRepository:    user.New(configuration),
+		ConfigRepository:  conf.New(configuration),
+		Cache:             redis.New(configuration),
+		now:               time.Now,
+	}
+}
+
+// Resolve returns the session of the session key and its user, from the
+// cache when it is there. An expired session is read again from the
+// database as it may have been extended since it was cached. With sliding
+// expiration the expiry of an active session is moved forward
+func (sessionHelper *SessionHelper) Resolve(ctx context.Context, clientCode string, sessionKey string) (Resolved, error) {
+
+	resolved, cached := sessionHelper.cached(ctx, clientCode, sessionKey)
+	if cached && resolved.Session.Expires.Int64 < sessionHelper.now().Unix() {
+		sessionHelper.Invalidate(ctx, clientCode, sessionKey)
+		cached = false
+	}
+
+	if !cached {
+		var err error
+		resolved, err = sessionHelper.load(ctx, sessionKey)
+		if err != nil {
+			return resolved, err
+		}
+	}
+
+	if sessionHelper.slide(ctx, &resolved) || !cached {
+		sessionHelper.store(ctx, clientCode, sessionKey, resolved)
+	}
+
+	return resolved, nil
+}
+
+// Invalidate removes the cached session of the session key
+func (sessionHelper *SessionHelper) Invalidate(ctx context.Context, clientCode string, sessionKey string) error {
+
+	if sessionHelper.Configuration.Session.CacheTTL <= 0 {
+		return nil
+	}
+
+	err := sessionHelper.Cache.Del(ctx, fmt.Sprintf(CacheKey, clientCode, sessionKey))
+	if err != nil {
+		log.Warn(fmt.Sprintf("session cache invalidation failed: %s", err))
+	}
+	return err
+}
+
+// load reads the session, its user and the sliding expiration from the
+// database
+func (sessionHelper *SessionHelper) load(ctx context.Context, sessionKey string) (Resolved, error) {
+
+	var resolved Resolved
+	s, err := sessionHelper.SessionRepository.GetSessionByKey(ctx, sessionKey)
+	if err != nil {
+		return resolved, err
+	}
+
+	u, err := sessionHelper.UserRepository.GetUserByShortName(ctx, s.User)
+	if err != nil {
+		return resolved, err
+	}
+
+	resolved.Session = s
+	resolved.User = u
+
+	// Sliding expiration is optional, the session stays valid without it
+	c, err := sessionHelper.ConfigRepository.GetConfigByName(ctx, ConfSlidingExpiration)
+	if err != nil {
+		log.Warn(fmt.Sprintf("session sliding expiration not read: %s", err))
+		return resolved, nil
+	}
+	if value := strings.TrimSpace(c.Value); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || seconds < 0 {
+			log.Warn(fmt.Sprintf("invalid %s conf %q", ConfSlidingExpiration, c.Value))
+			return resolved, nil
+		}
+		resolved.SlidingExpiration = seconds
+	}
+
+	return resolved, nil
+}
+
+// slide moves the expiry of an active session to the sliding expiration
+// from now and returns whether it was moved. The expiry is not written
+// when it would move less than the extend interval
+func (sessionHelper *SessionHelper) slide(ctx context.Context, resolved *Resolved) bool {
+
+	now := sessionHelper.now().Unix()
+	expires := now + resolved.SlidingExpiration
+	current := resolved.Session.Expires.Int64
+
+	if resolved.SlidingExpiration <= 0 || current < now || expires-current < int64(ExtendInterval/time.Second) {
+		return false
+	}
+
+	err := sessionHelper.SessionRepository.ExtendSession(ctx, resolved.Session.Key, expires)
+	if err != nil {
+		log.Warn(fmt.Sprintf("session expiry not extended: %s", err))
+		return false
+	}
+
+	resolved.Session.Expires.Int64 = expires
+	resolved.Session.Expires.Valid = true
+	return true
+}
+
+// cached returns the cached session of the session key and whether it was
+// cached. Cache errors are logged and read from the database instead
+func (sessionHelper *SessionHelper) cached(ctx context.Context, clientCode string, sessionKey string) (Resolved, bool) {
+
+	var resolved Resolved
+	if sessionHelper.Configuration.Session.CacheTTL <= 0 {
+		return resolved, false
+	}
+
+	value, err := sessionHelper.Cache.Get(ctx, fmt.Sprintf(CacheKey, clientCode, sessionKey))
+	if err != nil {
+		if err != redis.Nil {
+			log.Warn(fmt.Sprintf("session cache read failed: %s", err))
+		}
+		return resolved, false
+	}
+
+	data, ok := value.(string)
+	if !ok || json.Unmarshal([]byte(data), &resolved) != nil {
+		return Resolved{}, false
+	}
+	return resolved, true
+}
+
+// store caches the resolved session for the configured time
+func (sessionHelper *SessionHelper) store(ctx context.Context, clientCode string, sessionKey string, resolved Resolved) {
+
+	ttl := sessionHelper.Configuration.Session.CacheTTL
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(resolved)
+	if err != nil {
+		return
+	}
+
+	err = sessionHelper.Cache.SetX(ctx, fmt.Sprintf(CacheKey, clientCode, sessionKey), string(data), time.Duration(ttl)*time.Second)
+	if err != nil {
+		log.Warn(fmt.Sprintf("session cache write failed: %s", err))
+	}
+}
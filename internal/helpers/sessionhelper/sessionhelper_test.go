@@ -0,0 +1,206 @@
+package sessionhelper
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/cache/redis"
+	"github.com/zdarovich/promotion-api/internal/config"
+	conf "github.com/zdarovich/promotion-api/internal/repositories/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/session"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+)
+
+var now = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// MockCache in-memory redis, failing every call when fail is set
+type MockCache struct {
+	values map[string]interface{}
+	ttls   map[string]time.Duration
+	fail   bool
+}
+
+func (m *MockCache) Exists(ctx context.Context, key string) (interface{}, error) {
+	_, ok := m.values[key]
+	return ok, nil
+}
+
+func (m *MockCache) Get(ctx context.Context, key string) (interface{}, error) {
+	if m.fail {
+		return nil, errors.New("connection refused")
+	}
+	value, ok := m.values[key]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return value, nil
+}
+
+func (m *MockCache) Set(ctx context.Context, key string, value interface{}) error {
+	return m.SetX(ctx, key, value, 0)
+}
+
+func (m *MockCache) SetX(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if m.fail {
+		return errors.New("connection refused")
+	}
+	m.values[key] = value
+	m.ttls[key] = ttl
+	return nil
+}
+
+func (m *MockCache) Del(ctx context.Context, key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func (m *MockCache) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockCache) SetNX(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	return false, nil
+}
+
+type fixture struct {
+	helper   *SessionHelper
+	cache    *MockCache
+	sessions *session.MemoryRepository
+	confs    *conf.MemoryRepository
+}
+
+func newFixture(cacheTTL int) fixture {
+
+	configuration := &config.Configuration{}
+	configuration.Session.CacheTTL = cacheTTL
+
+	sessions := session.NewMemory()
+	sessions.AddSession(session.Session{User: "admin", Key: "key", Expires: sql.NullInt64{Int64: now.Add(time.Hour).Unix(), Valid: true}})
+	users := user.NewMemory(sessions)
+	users.AddUser(user.User{Name: "Admin", ShortName: "admin"})
+	cache := &MockCache{values: map[string]interface{}{}, ttls: map[string]time.Duration{}}
+	confs := conf.NewMemory()
+
+	return fixture{
+		helper: &SessionHelper{
+			Configuration:     configuration,
+			SessionRepository: sessions,
+			UserRepository:    users,
+			ConfigRepository:  confs,
+			Cache:             cache,
+			now:               func() time.Time { return now },
+		},
+		cache:    cache,
+		sessions: sessions,
+		confs:    confs,
+	}
+}
+
+func TestResolve_CachesSessionAndUser(t *testing.T) {
+	f := newFixture(60)
+
+	resolved, err := f.helper.Resolve(context.Background(), "104235", "key")
+
+	require.Nil(t, err)
+	assert.Equal(t, "admin", resolved.Session.User)
+	assert.Equal(t, "Admin", resolved.User.Name)
+	assert.Contains(t, f.cache.values, "session_104235_key")
+	assert.Equal(t, time.Minute, f.cache.ttls["session_104235_key"])
+
+	// Served from the cache while the session is in it
+	f.sessions.AddSession(session.Session{User: "other", Key: "key", Expires: sql.NullInt64{Int64: now.Add(time.Hour).Unix(), Valid: true}})
+	cached, err := f.helper.Resolve(context.Background(), "104235", "key")
+	require.Nil(t, err)
+	assert.Equal(t, resolved, cached)
+
+	// Invalidated sessions are read again
+	require.Nil(t, f.helper.Invalidate(context.Background(), "104235", "key"))
+	_, err = f.helper.Resolve(context.Background(), "104235", "key")
+	assert.NotNil(t, err)
+}
+
+func TestResolve_ExpiredCachedSessionIsReadAgain(t *testing.T) {
+	f := newFixture(60)
+	_, err := f.helper.Resolve(context.Background(), "104235", "key")
+	require.Nil(t, err)
+
+	// Extended in the database after it was cached
+	f.sessions.AddSession(session.Session{User: "admin", Key: "key", Expires: sql.NullInt64{Int64: now.Add(3 * time.Hour).Unix(), Valid: true}})
+	now = now.Add(2 * time.Hour)
+	defer func() { now = now.Add(-2 * time.Hour) }()
+
+	resolved, err := f.helper.Resolve(context.Background(), "104235", "key")
+
+	require.Nil(t, err)
+	assert.Equal(t, now.Add(time.Hour).Unix(), resolved.Session.Expires.Int64)
+}
+
+func TestResolve_CacheDisabledOrFailing(t *testing.T) {
+	f := newFixture(0)
+	_, err := f.helper.Resolve(context.Background(), "104235", "key")
+	require.Nil(t, err)
+	assert.Empty(t, f.cache.values)
+
+	f = newFixture(60)
+	f.cache.fail = true
+	resolved, err := f.helper.Resolve(context.Background(), "104235", "key")
+	require.Nil(t, err)
+	assert.Equal(t, "admin", resolved.User.ShortName)
+}
+
+func TestResolve_UnknownSession(t *testing.T) {
+	f := newFixture(60)
+
+	_, err := f.helper.Resolve(context.Background(), "104235", "unknown")
+
+	assert.Equal(t, sql.ErrNoRows, err)
+	assert.Empty(t, f.cache.values)
+}
+
+func TestResolve_SlidingExpiration(t *testing.T) {
+	f := newFixture(60)
+	f.confs.SetConfig(conf.Conf{Name: ConfSlidingExpiration, Value: "7200"})
+
+	resolved, err := f.helper.Resolve(context.Background(), "104235", "key")
+
+	require.Nil(t, err)
+	assert.Equal(t, int64(7200), resolved.SlidingExpiration)
+	assert.Equal(t, now.Add(2*time.Hour).Unix(), resolved.Session.Expires.Int64)
+	s, _ := f.sessions.GetSessionByKey(context.Background(), "key")
+	assert.Equal(t, now.Add(2*time.Hour).Unix(), s.Expires.Int64)
+
+	// Not written again within the extend interval
+	f.sessions.AddSession(session.Session{User: "admin", Key: "key", Expires: sql.NullInt64{Int64: 1, Valid: true}})
+	now = now.Add(30 * time.Second)
+	resolved, err = f.helper.Resolve(context.Background(), "104235", "key")
+	now = now.Add(-30 * time.Second)
+	require.Nil(t, err)
+	s, _ = f.sessions.GetSessionByKey(context.Background(), "key")
+	assert.Equal(t, int64(1), s.Expires.Int64)
+}
+
+func TestResolve_SlidingExpirationKeepsExpiredSessions(t *testing.T) {
+	f := newFixture(0)
+	f.confs.SetConfig(conf.Conf{Name: ConfSlidingExpiration, Value: "7200"})
+	f.sessions.AddSession(session.Session{User: "admin", Key: "key", Expires: sql.NullInt64{Int64: now.Add(-time.Second).Unix(), Valid: true}})
+
+	resolved, err := f.helper.Resolve(context.Background(), "104235", "key")
+
+	require.Nil(t, err)
+	assert.Equal(t, now.Add(-time.Second).Unix(), resolved.Session.Expires.Int64)
+}
+
+func TestResolve_InvalidSlidingExpiration(t *testing.T) {
+	f := newFixture(0)
+	f.confs.SetConfig(conf.Conf{Name: ConfSlidingExpiration, Value: "1 hour"})
+
+	resolved, err := f.helper.Resolve(context.Background(), "104235", "key")
+
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), resolved.SlidingExpiration)
+}
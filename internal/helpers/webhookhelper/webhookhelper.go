@@ -0,0 +1,302 @@
+package webhookhelper
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/webhook"
+)
+
+// Events of the campaigns the webhooks subscribe to
+const (
+	EventCreated   string = campaign.EventCreated
+	EventUpdated   string = campaign.EventUpdated
+	EventDeleted   string = campaign.EventDeleted
+	EventActivated string = "campaign.activated"
+	EventExpired   string = "campaign.expired"
+)
+
+// Headers of the deliveries. The signature is the HMAC-SHA256, keyed with
+// the secret of the webhook, of the timestamp, a dot and the body
+const (
+	HeaderEvent     = "X-Promotion-Event"
+	HeaderDelivery  = "X-Promotion-Delivery"
+	HeaderTimestamp = "X-Promotion-Timestamp"
+	HeaderSignature = "X-Promotion-Signature"
+)
+
+const (
+	// SecretPrefix prefix of the generated secrets
+	SecretPrefix = "whsec_"
+	// MinSecretLength minimum length of a secret that is not generated
+	MinSecretLength = 16
+	// MaxSecretLength maximum length of a secret
+	MaxSecretLength = 100
+	// MaxURLLength maximum length of the url of a webhook
+	MaxURLLength = 255
+)
+
+// Events all the events, in the order they are documented
+var Events = []string{EventCreated, EventUpdated, EventDeleted, EventActivated, EventExpired}
+
+type (
+	// WebhookHelper records the deliveries of the campaign events to the
+	// webhooks subscribed to them, the deliveries are sent by the webhook
+	// delivery service
+	WebhookHelper struct {
+		Configuration     *config.Configuration
+		WebhookRepository webhook.IRepository
+		now               func() time.Time
+	}
+	// IWebhookHelper interface
+	IWebhookHelper interface {
+		Publish(ctx context.Context, clientCode string, event string, c campaign.Campaign) error
+		PublishTx(ctx context.Context, tx *sqlx.Tx, clientCode string, event string, c campaign.Campaign) error
+	}
+	// Payload body of a delivery
+	Payload struct {
+		Event      string `json:"event"`
+		ClientCode string `json:"clientCode,omitempty"`
+		Created    int64  `json:"created"`
+		Data       Data   `json:"data"`
+	}
+	// Data the campaign of the event, its details are read with getCampaigns
+	Data struct {
+		CampaignID int       `json:"campaignID"`
+		Name       string    `json:"name"`
+		Type       string    `json:"type"`
+		StartDate  time.Time `json:"startDate"`
+		EndDate    time.Time `json:"endDate"`
+	}
+	// Record structure of the output record of a webhook, the secret is
+	// output only when it is set
+	Record struct {
+		WebhookID int      `json:"webhookID"`
+		URL       string   `json:"url"`
+		Secret    string   `json:"secret,omitempty"`
+		Events    []string `json:"events"`
+		Active    bool     `json:"active"`
+		Added     int64    `json:"added"`
+		Addedby   string   `json:"addedby"`
+		Changed   int64    `json:"changed"`
+		Changedby string   `json:"changedby"`
+	}
+	// DeliveryRecord structure of the output record of a delivery
+	DeliveryRecord struct {
+		DeliveryID  int             `json:"deliveryID"`
+		WebhookID   int             `json:"webhookID"`
+		Event       string          `json:"event"`
+		Payload     json.RawMessage `json:"payload"`
+		Status      string          `json:"status"`
+		Attempts    int             `json:"attempts"`
+		NextAttempt int64           `json:"nextAttempt"`
+		LastStatus  int             `json:"lastStatus"`
+		LastError   string          `json:"lastError"`
+		Added       int64           `json:"added"`
+		Delivered   int64           `json:"delivered"`
+	}
+)
+
+// New returns new configured webhook helper
+func New(configuration *config.Configuration) IWebhookHelper {
+
+	return &WebhookHelper{
+		Configuration:     configuration,
+		WebhookRepository: webhook.New(configuration),
+		now:               time.Now,
+	}
+}
+
+// Publish records a delivery of the event of the campaign for every active
+// webhook subscribed to it. Nothing is recorded when webhooks are disabled
+func (webhookHelper *WebhookHelper) Publish(ctx context.Context, clientCode string, event string, c campaign.Campaign) error {
+
+	deliveries, err := webhookHelper.deliveries(ctx, clientCode, event, c)
+	if err != nil {
+		return err
+	}
+
+	return webhookHelper.WebhookRepository.SaveDeliveries(ctx, deliveries)
+}
+
+// PublishTx records the deliveries like Publish in the transaction of the
+// change of the campaign
+func (webhookHelper *WebhookHelper) PublishTx(ctx context.Context, tx *sqlx.Tx, clientCode string, event string, c campaign.Campaign) error {
+
+	deliveries, err := webhookHelper.deliveries(ctx, clientCode, event, c)
+	if err != nil {
+		return err
+	}
+
+	return webhookHelper.WebhookRepository.SaveDeliveriesTx(ctx, tx, deliveries)
+}
+
+// deliveries returns the deliveries of the event, none when webhooks are
+// disabled
+func (webhookHelper *WebhookHelper) deliveries(ctx context.Context, clientCode string, event string, c campaign.Campaign) ([]*webhook.Delivery, error) {
+
+	if !webhookHelper.Configuration.Webhooks.Enabled {
+		return nil, nil
+	}
+
+	webhooks, err := webhookHelper.WebhookRepository.GetWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return Deliveries(webhooks, clientCode, event, c, webhookHelper.now().Unix())
+}
+
+// Deliveries returns the pending deliveries of the event of the campaign to
+// the active webhooks subscribed to it, created at now
+func Deliveries(webhooks []webhook.Webhook, clientCode string, event string, c campaign.Campaign, now int64) ([]*webhook.Delivery, error) {
+
+	var deliveries []*webhook.Delivery
+	var payload []byte
+	for _, w := range webhooks {
+		if !w.Subscribed(event) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			payload, err = json.Marshal(Payload{
+				Event:      event,
+				ClientCode: clientCode,
+				Created:    now,
+				Data: Data{
+					CampaignID: c.ID,
+					Name:       c.Name,
+					Type:       c.Type,
+					StartDate:  c.StartDate.UTC(),
+					EndDate:    c.EndDate.UTC(),
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		deliveries = append(deliveries, &webhook.Delivery{
+			WebhookID:   w.ID,
+			Event:       event,
+			Payload:     string(payload),
+			Status:      webhook.StatusPending,
+			NextAttempt: now,
+			Added:       now,
+		})
+	}
+	return deliveries, nil
+}
+
+// Sign returns the signature header of the body sent at the timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret returns a new random secret of a webhook
+func GenerateSecret() (string, error) {
+
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return SecretPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ValidEvent returns whether the event is known
+func ValidEvent(event string) bool {
+
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseEvents returns the events of the comma-separated list without
+// duplicates and whether they are all known
+func ParseEvents(list string) ([]string, bool) {
+
+	events := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, e := range strings.Split(list, ",") {
+		e = strings.TrimSpace(e)
+		if !ValidEvent(e) {
+			return nil, false
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+	return events, true
+}
+
+// ValidURL returns whether the url is an absolute http or https url
+func ValidURL(rawURL string) bool {
+
+	if len(rawURL) > MaxURLLength {
+		return false
+	}
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// MapToOutput returns the output record of the webhook, with its secret
+// when withSecret is set
+func MapToOutput(w webhook.Webhook, withSecret bool) Record {
+
+	r := Record{
+		WebhookID: w.ID,
+		URL:       w.URL,
+		Events:    strings.Split(w.Events, ","),
+		Active:    w.Active,
+		Added:     w.Added,
+		Addedby:   w.Addedby,
+		Changed:   w.Changed,
+		Changedby: w.Changedby,
+	}
+	if withSecret {
+		r.Secret = w.Secret
+	}
+	return r
+}
+
+// MapDeliveriesToOutput returns the output records of the deliveries
+func MapDeliveriesToOutput(deliveries []webhook.Delivery) []DeliveryRecord {
+
+	records := make([]DeliveryRecord, len(deliveries))
+	for idx, d := range deliveries {
+		records[idx] = DeliveryRecord{
+			DeliveryID:  d.ID,
+			WebhookID:   d.WebhookID,
+			Event:       d.Event,
+			Payload:     json.RawMessage(d.Payload),
+			Status:      d.Status,
+			Attempts:    d.Attempts,
+			NextAttempt: d.NextAttempt,
+			LastStatus:  d.LastStatus,
+			LastError:   d.LastError,
+			Added:       d.Added,
+			Delivered:   d.Delivered,
+		}
+	}
+	return records
+}
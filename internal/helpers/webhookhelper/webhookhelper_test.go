@@ -0,0 +1,118 @@
+package webhookhelper
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/webhook"
+)
+
+var now = time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newHelper(enabled bool, webhooks ...webhook.Webhook) (IWebhookHelper, *webhook.MemoryRepository) {
+
+	configuration := &config.Configuration{}
+	configuration.Webhooks.Enabled = enabled
+	repository := webhook.NewMemory()
+	for _, w := range webhooks {
+		repository.SaveWebhook(context.Background(), &w)
+	}
+	return &WebhookHelper{
+		Configuration:     configuration,
+		WebhookRepository: repository,
+		now:               func() time.Time { return now },
+	}, repository
+}
+
+func TestPublish(t *testing.T) {
+	helper, repository := newHelper(true,
+		webhook.Webhook{URL: "https://pos.example.com", Events: EventCreated + "," + EventDeleted, Active: true},
+		webhook.Webhook{URL: "https://crm.example.com", Events: EventDeleted, Active: true},
+		webhook.Webhook{URL: "https://old.example.com", Events: EventCreated, Active: false},
+	)
+	c := campaign.Campaign{ID: 4, Name: "Spring", Type: "auto", StartDate: now, EndDate: now.Add(24 * time.Hour)}
+
+	require.Nil(t, helper.Publish(context.Background(), "104235", EventCreated, c))
+
+	deliveries, err := repository.GetDeliveries(context.Background(), 0, "", 20, 0)
+	require.Nil(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 1, deliveries[0].WebhookID)
+	assert.Equal(t, webhook.StatusPending, deliveries[0].Status)
+	assert.Equal(t, now.Unix(), deliveries[0].NextAttempt)
+
+	var payload Payload
+	require.Nil(t, json.Unmarshal([]byte(deliveries[0].Payload), &payload))
+	assert.Equal(t, EventCreated, payload.Event)
+	assert.Equal(t, "104235", payload.ClientCode)
+	assert.Equal(t, 4, payload.Data.CampaignID)
+	assert.Equal(t, "Spring", payload.Data.Name)
+	assert.True(t, now.Equal(payload.Data.StartDate))
+
+	require.Nil(t, helper.Publish(context.Background(), "104235", EventDeleted, c))
+	count, _ := repository.GetDeliveriesCount(context.Background(), 0, "")
+	assert.Equal(t, 3, count)
+}
+
+func TestPublish_Disabled(t *testing.T) {
+	helper, repository := newHelper(false, webhook.Webhook{URL: "https://pos.example.com", Events: EventCreated, Active: true})
+
+	require.Nil(t, helper.Publish(context.Background(), "104235", EventCreated, campaign.Campaign{ID: 1}))
+
+	count, _ := repository.GetDeliveriesCount(context.Background(), 0, "")
+	assert.Equal(t, 0, count)
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '1614600000.{"event":"campaign.created"}' | openssl dgst -sha256 -hmac whsec_test
+	signature := Sign("whsec_test", 1614600000, []byte(`{"event":"campaign.created"}`))
+
+	assert.Equal(t, "sha256=532be990ae6b5648804405aa76ad7799e1860ead482ef5588b409fdf12e8179b", signature)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	require.Nil(t, err)
+	second, err := GenerateSecret()
+	require.Nil(t, err)
+
+	assert.True(t, strings.HasPrefix(first, SecretPrefix))
+	assert.NotEqual(t, first, second)
+}
+
+func TestValidEvent(t *testing.T) {
+	assert.True(t, ValidEvent(EventExpired))
+	assert.False(t, ValidEvent("campaign.approved"))
+}
+
+func TestParseEvents(t *testing.T) {
+	events, ok := ParseEvents("campaign.created, campaign.deleted,campaign.created")
+	assert.True(t, ok)
+	assert.Equal(t, []string{EventCreated, EventDeleted}, events)
+
+	_, ok = ParseEvents("campaign.created,")
+	assert.False(t, ok)
+}
+
+func TestValidURL(t *testing.T) {
+	assert.True(t, ValidURL("https://pos.example.com/hooks?tenant=1"))
+	assert.True(t, ValidURL("http://127.0.0.1:8080"))
+	assert.False(t, ValidURL("ftp://pos.example.com"))
+	assert.False(t, ValidURL("/hooks"))
+	assert.False(t, ValidURL("https://"+strings.Repeat("a", MaxURLLength)))
+}
+
+func TestMapToOutput(t *testing.T) {
+	w := webhook.Webhook{ID: 2, URL: "https://pos.example.com", Secret: "secret", Events: "campaign.created,campaign.deleted", Active: true}
+
+	assert.Equal(t, []string{EventCreated, EventDeleted}, MapToOutput(w, false).Events)
+	assert.Empty(t, MapToOutput(w, false).Secret)
+	assert.Equal(t, "secret", MapToOutput(w, true).Secret)
+}
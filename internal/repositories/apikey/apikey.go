@@ -0,0 +1,200 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	"github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/rights"
+)
+
+// Scopes of the api keys
+const (
+	// ScopeRead allows the requests needing view rights only
+	ScopeRead string = "read"
+	// ScopeWrite allows the requests of the read scope and the requests
+	// creating, changing or deleting records
+	ScopeWrite string = "write"
+)
+
+// KeyPrefix prefix of the generated keys, makes them recognizable in
+// configuration files and logs
+const KeyPrefix = "pak_"
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration *config.Configuration
+		Database      sqlx.IDB
+	}
+	// IRepository interface
+	IRepository interface {
+		GetAPIKeys(ctx context.Context) ([]APIKey, error)
+		GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error)
+		SaveAPIKey(ctx context.Context, k *APIKey) error
+		RevokeAPIKey(ctx context.Context, id int, revoked int64, revokedby string) error
+	}
+	// APIKey structure of the api_key table, only the hash of the key is
+	// stored
+	APIKey struct {
+		ID        int    `json:"id"`
+		Name      string `json:"name"`
+		KeyHash   string `json:"key_hash"`
+		Scope     string `json:"scope"`
+		Expires   int64  `json:"expires"`
+		Added     int64  `json:"added"`
+		Addedby   string `json:"addedby"`
+		Revoked   int64  `json:"revoked"`
+		Revokedby string `json:"revokedby"`
+	}
+)
+
+// New returns new configured api key repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration: configuration,
+		Database:      sqlx.New(configuration),
+	}
+}
+
+// GetAPIKeys returns all the api keys, the revoked ones included
+func (repository *Repository) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM api_key ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	keys := make([]APIKey, 0)
+	for result.Next() {
+		var k APIKey
+		err := result.StructScan(&k)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, result.Err()
+}
+
+// GetAPIKeyByHash returns the api key of the hash, sql.ErrNoRows when it
+// does not exist
+func (repository *Repository) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+
+	var k APIKey
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM api_key WHERE key_hash = ?", keyHash)
+	if err != nil {
+		return k, err
+	}
+	defer result.Close()
+
+	if !result.Next() {
+		if err = result.Err(); err != nil {
+			return k, err
+		}
+		return k, sql.ErrNoRows
+	}
+	err = result.StructScan(&k)
+
+	return k, err
+}
+
+// SaveAPIKey creates the api key and sets its id
+func (repository *Repository) SaveAPIKey(ctx context.Context, k *APIKey) error {
+
+	vals := map[string]interface{}{
+		"name":     k.Name,
+		"key_hash": k.KeyHash,
+		"scope":    k.Scope,
+		"expires":  k.Expires,
+		"added":    k.Added,
+		"addedby":  k.Addedby,
+	}
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+	id, err := dialect.For(repository.Configuration).InsertID(ctx, tx, "INSERT INTO api_key (name, key_hash, scope, expires, added, addedby) VALUES "+
+		"(:name, :key_hash, :scope, :expires, :added, :addedby)", vals)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+	k.ID = int(id)
+
+	return tx.Commit()
+}
+
+// RevokeAPIKey revokes the api key, a revoked key is kept with the time and
+// the name of who revoked it
+func (repository *Repository) RevokeAPIKey(ctx context.Context, id int, revoked int64, revokedby string) error {
+
+	vals := map[string]interface{}{
+		"id":        id,
+		"revoked":   revoked,
+		"revokedby": revokedby,
+	}
+	_, err := repository.Database.NamedExecContext(ctx, "UPDATE api_key SET revoked=:revoked, revokedby=:revokedby WHERE id=:id AND revoked=0", vals)
+
+	return err
+}
+
+// Active returns whether the key may authenticate requests at the time
+func (k APIKey) Active(now int64) bool {
+
+	return k.ID != 0 && k.Revoked == 0 && (k.Expires == 0 || now < k.Expires)
+}
+
+// Generate returns a new random key, it is shown once and only its hash is
+// stored
+func Generate() (string, error) {
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return KeyPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Hash returns the hash the key is stored and looked up by
+func Hash(key string) string {
+
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidScope returns whether the scope is known
+func ValidScope(scope string) bool {
+
+	return scope == ScopeRead || scope == ScopeWrite
+}
+
+// ScopeRights returns the rights of the scope. The read scope has the view
+// rights, the write scope has the rights to create, edit and delete as well.
+// No scope has the rights to approve campaigns or to manage the api keys
+func ScopeRights(scope string) rights.Rights {
+
+	r := rights.Rights{}
+	switch scope {
+	case ScopeWrite:
+		r.CreateCampaigns = true
+		r.EditCampaigns = true
+		r.DeleteCampaigns = true
+		fallthrough
+	case ScopeRead:
+		r.ViewCampaigns = true
+		r.ViewReports = true
+	}
+	return r
+}
@@ -0,0 +1,115 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/rights"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	databaseMock struct{}
+)
+
+func (d *databaseMock) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return nil, errors.New("1003")
+}
+
+var queryXq string
+var queryXa []interface{}
+
+func (d *databaseMock) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	queryXq = query
+	queryXa = args
+	return nil, errors.New("1003")
+}
+
+func (d *databaseMock) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
+	return nil, nil
+}
+
+var namedExecQ string
+var namedExecA interface{}
+
+func (d *databaseMock) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	namedExecQ = query
+	namedExecA = arg
+	return nil, errors.New("1003")
+}
+func (d *databaseMock) Close() error { return nil }
+
+func TestAPIKey_New(t *testing.T) {
+	r := New(&config.Configuration{})
+	assert.IsType(t, &Repository{}, r)
+}
+
+func TestAPIKey_GetAPIKeyByHash(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	_, err := r.GetAPIKeyByHash(context.Background(), "hash")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM api_key WHERE key_hash = ?", queryXq)
+	assert.Equal(t, []interface{}{"hash"}, queryXa)
+}
+
+func TestAPIKey_SaveAPIKey(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	k := &APIKey{Name: "webshop", KeyHash: "hash", Scope: ScopeRead, Added: 10, Addedby: "admin"}
+	err := r.SaveAPIKey(context.Background(), k)
+
+	assert.EqualError(t, err, "1003")
+	assert.Equal(t, 0, k.ID)
+}
+
+func TestAPIKey_RevokeAPIKey(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	err := r.RevokeAPIKey(context.Background(), 3, 20, "admin")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "UPDATE api_key SET revoked=:revoked, revokedby=:revokedby WHERE id=:id AND revoked=0", namedExecQ)
+	assert.Equal(t, map[string]interface{}{"id": 3, "revoked": int64(20), "revokedby": "admin"}, namedExecA)
+}
+
+func TestAPIKey_Active(t *testing.T) {
+	assert.True(t, APIKey{ID: 1}.Active(100))
+	assert.True(t, APIKey{ID: 1, Expires: 101}.Active(100))
+	assert.False(t, APIKey{ID: 1, Expires: 100}.Active(100))
+	assert.False(t, APIKey{ID: 1, Revoked: 50}.Active(100))
+	assert.False(t, APIKey{}.Active(100))
+}
+
+func TestGenerate(t *testing.T) {
+	first, err := Generate()
+	assert.Nil(t, err)
+	second, err := Generate()
+	assert.Nil(t, err)
+
+	assert.True(t, strings.HasPrefix(first, KeyPrefix))
+	assert.NotEqual(t, first, second)
+	assert.Len(t, Hash(first), 64)
+	assert.Equal(t, Hash(first), Hash(first))
+	assert.NotEqual(t, Hash(first), Hash(second))
+}
+
+func TestScopeRights(t *testing.T) {
+	read := ScopeRights(ScopeRead)
+	assert.True(t, read.Has(rights.ViewCampaigns))
+	assert.False(t, read.Has(rights.CreateCampaigns))
+
+	write := ScopeRights(ScopeWrite)
+	assert.Empty(t, write.Missing([]rights.Right{rights.ViewCampaigns, rights.CreateCampaigns, rights.EditCampaigns, rights.DeleteCampaigns}))
+	assert.False(t, write.Has(rights.ApproveCampaigns))
+	assert.False(t, write.Has(rights.ManageAPIKeys))
+
+	assert.Equal(t, rights.Rights{}, ScopeRights("admin"))
+}
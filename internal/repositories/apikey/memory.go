@@ -0,0 +1,70 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// MemoryRepository in-memory api key repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex  sync.RWMutex
+	keys   []APIKey
+	lastID int
+}
+
+// NewMemory returns new empty in-memory api key repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{}
+}
+
+// GetAPIKeys returns all the api keys ordered by id, the revoked ones
+// included
+func (repository *MemoryRepository) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	keys := make([]APIKey, len(repository.keys))
+	copy(keys, repository.keys)
+	return keys, nil
+}
+
+// GetAPIKeyByHash returns the api key of the hash, sql.ErrNoRows when it
+// does not exist
+func (repository *MemoryRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	for _, k := range repository.keys {
+		if k.KeyHash == keyHash {
+			return k, nil
+		}
+	}
+	return APIKey{}, sql.ErrNoRows
+}
+
+// SaveAPIKey creates the api key and sets its id
+func (repository *MemoryRepository) SaveAPIKey(ctx context.Context, k *APIKey) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.lastID++
+	k.ID = repository.lastID
+	repository.keys = append(repository.keys, *k)
+	return nil
+}
+
+// RevokeAPIKey revokes the api key, a key revoked before is left as it is
+func (repository *MemoryRepository) RevokeAPIKey(ctx context.Context, id int, revoked int64, revokedby string) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for idx, k := range repository.keys {
+		if k.ID == id && k.Revoked == 0 {
+			repository.keys[idx].Revoked = revoked
+			repository.keys[idx].Revokedby = revokedby
+		}
+	}
+	return nil
+}
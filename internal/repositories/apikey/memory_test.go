@@ -0,0 +1,32 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	var repository IRepository = NewMemory()
+	webshop := &APIKey{Name: "webshop", KeyHash: Hash("first"), Scope: ScopeWrite, Added: 10, Addedby: "admin"}
+	assert.Nil(t, repository.SaveAPIKey(context.Background(), webshop))
+	assert.Nil(t, repository.SaveAPIKey(context.Background(), &APIKey{Name: "reports", KeyHash: Hash("second"), Scope: ScopeRead}))
+	assert.Equal(t, 1, webshop.ID)
+
+	k, err := repository.GetAPIKeyByHash(context.Background(), Hash("first"))
+	assert.Nil(t, err)
+	assert.Equal(t, *webshop, k)
+	_, err = repository.GetAPIKeyByHash(context.Background(), Hash("unknown"))
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	assert.Nil(t, repository.RevokeAPIKey(context.Background(), 1, 20, "admin"))
+	assert.Nil(t, repository.RevokeAPIKey(context.Background(), 1, 30, "other"))
+	keys, err := repository.GetAPIKeys(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, keys, 2)
+	assert.Equal(t, int64(20), keys[0].Revoked)
+	assert.Equal(t, "admin", keys[0].Revokedby)
+	assert.Equal(t, int64(0), keys[1].Revoked)
+}
@@ -0,0 +1,220 @@
+package assignment
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+// BatchSize count of assignments inserted by a single statement
+const BatchSize = 1000
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration *config.Configuration
+		Database      sqlx2.IDB
+	}
+	// IRepository interface
+	IRepository interface {
+		GetAssignments(
+			ctx context.Context,
+			campaignID int,
+			records int,
+			page int,
+		) ([]*Assignment, error)
+		GetAssignmentsCount(
+			ctx context.Context,
+			campaignID int,
+		) (int, error)
+		GetCustomerAssignments(
+			ctx context.Context,
+			customerID int,
+			at time.Time,
+		) ([]*Assignment, error)
+		SaveAssignments(
+			ctx context.Context,
+			a []*Assignment,
+		) error
+		DeleteAssignments(
+			ctx context.Context,
+			campaignID int,
+			customerIDs []int,
+		) error
+	}
+	// Assignment structure of a customer allowed to use a personalized campaign.
+	// Expires is a unix timestamp, 0 if the assignment does not expire
+	Assignment struct {
+		ID         int   `json:"id"`
+		CampaignID int   `json:"campaign_id"`
+		CustomerID int   `json:"customer_id"`
+		Expires    int64 `json:"expires"`
+		Added      int64 `json:"added"`
+	}
+)
+
+// New returns new configured assignment repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration: configuration,
+		Database:      sqlx2.New(configuration),
+	}
+}
+
+// GetAssignments returns a page of the customers assigned to the campaign
+func (repository *Repository) GetAssignments(
+	ctx context.Context,
+	campaignID int,
+	records int,
+	page int,
+) ([]*Assignment, error) {
+
+	pagination, limits := dialect.For(repository.Configuration).Paginate(records, page)
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM campaign_customer WHERE campaign_id = ? ORDER BY customer_id"+pagination,
+		append([]interface{}{campaignID}, limits...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scan(result)
+}
+
+// GetAssignmentsCount returns the count of customers assigned to the campaign
+func (repository *Repository) GetAssignmentsCount(
+	ctx context.Context,
+	campaignID int,
+) (int, error) {
+
+	result, err := repository.Database.QueryRowxContext(ctx, "SELECT COUNT(*) FROM campaign_customer WHERE campaign_id = ?", campaignID)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = result.Scan(&count)
+	return count, err
+}
+
+// GetCustomerAssignments returns the assignments of the customer that have
+// not expired at the instant
+func (repository *Repository) GetCustomerAssignments(
+	ctx context.Context,
+	customerID int,
+	at time.Time,
+) ([]*Assignment, error) {
+
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM campaign_customer WHERE customer_id = ? AND (expires = 0 OR expires > ?) ORDER BY campaign_id",
+		customerID, at.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	return scan(result)
+}
+
+// SaveAssignments saves the assignments in batches of multi-row inserts
+// within a single transaction. Assigning a customer again updates the expiry
+func (repository *Repository) SaveAssignments(
+	ctx context.Context,
+	assignments []*Assignment,
+) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+	d := dialect.For(repository.Configuration)
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+
+	for from := 0; from < len(assignments); from += BatchSize {
+		to := from + BatchSize
+		if to > len(assignments) {
+			to = len(assignments)
+		}
+		query, args := insertQuery(d, assignments[from:to])
+		_, err := tx.ExecContext(ctx, tx.Rebind(query), args...)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DeleteAssignments deletes the assignments of the customers to the campaign,
+// all assignments of the campaign when no customers are given
+func (repository *Repository) DeleteAssignments(
+	ctx context.Context,
+	campaignID int,
+	customerIDs []int,
+) error {
+
+	if len(customerIDs) == 0 {
+		_, err := repository.Database.NamedExecContext(ctx, "DELETE FROM campaign_customer WHERE campaign_id=:campaign_id",
+			map[string]interface{}{
+				"campaign_id": campaignID,
+			})
+		return err
+	}
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+
+	for from := 0; from < len(customerIDs); from += BatchSize {
+		to := from + BatchSize
+		if to > len(customerIDs) {
+			to = len(customerIDs)
+		}
+		query, args, err := sqlx.In("DELETE FROM campaign_customer WHERE campaign_id = ? AND customer_id IN (?)", campaignID, customerIDs[from:to])
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+		_, err = tx.ExecContext(ctx, tx.Rebind(query), args...)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertQuery(d dialect.IDialect, assignments []*Assignment) (string, []interface{}) {
+	rows := make([]string, len(assignments))
+	args := make([]interface{}, 0, len(assignments)*4)
+	for idx, a := range assignments {
+		rows[idx] = "(?, ?, ?, ?)"
+		args = append(args, a.CampaignID, a.CustomerID, a.Expires, a.Added)
+	}
+	query := "INSERT INTO campaign_customer (campaign_id, customer_id, expires, added) VALUES " + strings.Join(rows, ", ")
+	return d.Upsert(query, []string{"campaign_id", "customer_id"}, []string{"expires"}), args
+}
+
+func scan(result *sqlx.Rows) ([]*Assignment, error) {
+	defer result.Close()
+	assignments := make([]*Assignment, 0)
+	for result.Next() {
+		var a Assignment
+		err := result.StructScan(&a)
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, &a)
+	}
+	return assignments, result.Err()
+}
@@ -0,0 +1,104 @@
+package assignment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	databaseMock struct{}
+)
+
+func (d *databaseMock) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return nil, errors.New("1003")
+}
+
+var queryXq string
+var queryXa []interface{}
+
+func (d *databaseMock) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	queryXq = query
+	queryXa = args
+	return nil, errors.New("1003")
+}
+
+func (d *databaseMock) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
+	return nil, nil
+}
+
+var namedExecQ string
+var namedExecA interface{}
+
+func (d *databaseMock) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	namedExecQ = query
+	namedExecA = arg
+	r := new(sql.Result)
+
+	return *r, nil
+}
+func (d *databaseMock) Close() error { return nil }
+
+func TestAssignment_New(t *testing.T) {
+	r := New(&config.Configuration{})
+	assert.IsType(t, &Repository{}, r)
+}
+
+func TestAssignment_GetCustomerAssignments(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+	at := time.Unix(1588600000, 0)
+
+	_, err := r.GetCustomerAssignments(context.Background(), 5, at)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM campaign_customer WHERE customer_id = ? AND (expires = 0 OR expires > ?) ORDER BY campaign_id", queryXq)
+	assert.Equal(t, []interface{}{5, int64(1588600000)}, queryXa)
+}
+
+func TestAssignment_SaveAssignments_Empty(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	assert.Nil(t, r.SaveAssignments(context.Background(), nil))
+}
+
+func TestAssignment_insertQuery(t *testing.T) {
+	query, args := insertQuery(dialect.For(nil), []*Assignment{
+		{CampaignID: 1, CustomerID: 2, Expires: 0, Added: 10},
+		{CampaignID: 1, CustomerID: 3, Expires: 20, Added: 10},
+	})
+
+	assert.Equal(t, "INSERT INTO campaign_customer (campaign_id, customer_id, expires, added) VALUES (?, ?, ?, ?), (?, ?, ?, ?) "+
+		"ON DUPLICATE KEY UPDATE expires = VALUES(expires)", query)
+	assert.Equal(t, []interface{}{1, 2, int64(0), int64(10), 1, 3, int64(20), int64(10)}, args)
+}
+
+func TestAssignment_insertQuery_Batch(t *testing.T) {
+	assignments := make([]*Assignment, BatchSize)
+	for idx := range assignments {
+		assignments[idx] = &Assignment{CampaignID: 1, CustomerID: idx}
+	}
+
+	query, args := insertQuery(dialect.For(nil), assignments)
+
+	assert.Equal(t, BatchSize, strings.Count(query, "(?, ?, ?, ?)"))
+	assert.Len(t, args, BatchSize*4)
+}
+
+func TestAssignment_DeleteAssignments_Campaign(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	err := r.DeleteAssignments(context.Background(), 7, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETE FROM campaign_customer WHERE campaign_id=:campaign_id", namedExecQ)
+	assert.Equal(t, map[string]interface{}{"campaign_id": 7}, namedExecA)
+}
@@ -0,0 +1,142 @@
+package assignment
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryRepository in-memory assignment repository, safe for concurrent use.
+// A customer is assigned to a campaign at most once
+type MemoryRepository struct {
+	mutex       sync.RWMutex
+	assignments map[key]Assignment
+	lastID      int
+}
+
+type key struct {
+	campaignID int
+	customerID int
+}
+
+// NewMemory returns new empty in-memory assignment repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		assignments: make(map[key]Assignment),
+	}
+}
+
+// GetAssignments returns a page of the customers assigned to the campaign
+// ordered by customer id
+func (repository *MemoryRepository) GetAssignments(
+	ctx context.Context,
+	campaignID int,
+	records int,
+	page int,
+) ([]*Assignment, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	assignments := repository.filter(func(a Assignment) bool { return a.CampaignID == campaignID })
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].CustomerID < assignments[j].CustomerID })
+
+	from := records * page
+	if records <= 0 || from < 0 || from >= len(assignments) {
+		return make([]*Assignment, 0), nil
+	}
+	to := from + records
+	if to > len(assignments) {
+		to = len(assignments)
+	}
+	return assignments[from:to], nil
+}
+
+// GetAssignmentsCount returns the count of customers assigned to the campaign
+func (repository *MemoryRepository) GetAssignmentsCount(
+	ctx context.Context,
+	campaignID int,
+) (int, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return len(repository.filter(func(a Assignment) bool { return a.CampaignID == campaignID })), nil
+}
+
+// GetCustomerAssignments returns the assignments of the customer that have
+// not expired at the instant ordered by campaign id
+func (repository *MemoryRepository) GetCustomerAssignments(
+	ctx context.Context,
+	customerID int,
+	at time.Time,
+) ([]*Assignment, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	assignments := repository.filter(func(a Assignment) bool {
+		return a.CustomerID == customerID && (a.Expires == 0 || a.Expires > at.Unix())
+	})
+	sort.Slice(assignments, func(i, j int) bool { return assignments[i].CampaignID < assignments[j].CampaignID })
+	return assignments, nil
+}
+
+// SaveAssignments stores the assignments. Assigning a customer again
+// updates the expiry
+func (repository *MemoryRepository) SaveAssignments(
+	ctx context.Context,
+	assignments []*Assignment,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, a := range assignments {
+		k := key{a.CampaignID, a.CustomerID}
+		if stored, ok := repository.assignments[k]; ok {
+			stored.Expires = a.Expires
+			repository.assignments[k] = stored
+			continue
+		}
+		repository.lastID++
+		stored := *a
+		stored.ID = repository.lastID
+		repository.assignments[k] = stored
+	}
+	return nil
+}
+
+// DeleteAssignments deletes the assignments of the customers to the campaign,
+// all assignments of the campaign when no customers are given
+func (repository *MemoryRepository) DeleteAssignments(
+	ctx context.Context,
+	campaignID int,
+	customerIDs []int,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if len(customerIDs) == 0 {
+		for k := range repository.assignments {
+			if k.campaignID == campaignID {
+				delete(repository.assignments, k)
+			}
+		}
+		return nil
+	}
+	for _, customerID := range customerIDs {
+		delete(repository.assignments, key{campaignID, customerID})
+	}
+	return nil
+}
+
+// filter returns copies of the matching assignments
+func (repository *MemoryRepository) filter(match func(a Assignment) bool) []*Assignment {
+	assignments := make([]*Assignment, 0)
+	for _, a := range repository.assignments {
+		if match(a) {
+			a := a
+			assignments = append(assignments, &a)
+		}
+	}
+	return assignments
+}
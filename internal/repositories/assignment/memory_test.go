@@ -0,0 +1,47 @@
+package assignment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	var repository IRepository = NewMemory()
+	assert.Nil(t, repository.SaveAssignments(context.Background(), []*Assignment{
+		{CampaignID: 1, CustomerID: 12, Added: 1},
+		{CampaignID: 1, CustomerID: 10, Expires: 50, Added: 1},
+		{CampaignID: 2, CustomerID: 10, Added: 1},
+	}))
+	assert.Nil(t, repository.SaveAssignments(context.Background(), []*Assignment{{CampaignID: 1, CustomerID: 12, Expires: 200, Added: 2}}))
+
+	assignments, err := repository.GetAssignments(context.Background(), 1, 20, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []*Assignment{
+		{ID: 2, CampaignID: 1, CustomerID: 10, Expires: 50, Added: 1},
+		{ID: 1, CampaignID: 1, CustomerID: 12, Expires: 200, Added: 1},
+	}, assignments)
+
+	assignments, _ = repository.GetAssignments(context.Background(), 1, 1, 1)
+	assert.Len(t, assignments, 1)
+	assert.Equal(t, 12, assignments[0].CustomerID)
+	count, err := repository.GetAssignmentsCount(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	assignments, err = repository.GetCustomerAssignments(context.Background(), 10, time.Unix(100, 0))
+	assert.Nil(t, err)
+	assert.Len(t, assignments, 1)
+	assert.Equal(t, 2, assignments[0].CampaignID)
+
+	assert.Nil(t, repository.DeleteAssignments(context.Background(), 1, []int{10}))
+	count, _ = repository.GetAssignmentsCount(context.Background(), 1)
+	assert.Equal(t, 1, count)
+	assert.Nil(t, repository.DeleteAssignments(context.Background(), 1, nil))
+	count, _ = repository.GetAssignmentsCount(context.Background(), 1)
+	assert.Equal(t, 0, count)
+	count, _ = repository.GetAssignmentsCount(context.Background(), 2)
+	assert.Equal(t, 1, count)
+}
@@ -1,8 +1,12 @@
 package attributes
 
 import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
 	"github.com/zdarovich/promotion-api/internal/config"
-	"github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
 	"github.com/zdarovich/promotion-api/internal/log"
 )
 
@@ -10,29 +14,39 @@ const (
 	TEXT   = "text"
 	INT    = "int"
 	DOUBLE = "double"
+	// ValueTextLength size of the value_text column
+	ValueTextLength = 255
 )
 
+// ChunkSize maximum number of campaigns whose attributes are loaded in one query
+var ChunkSize = 1000
+
 type (
 	// Repository struct
 	Repository struct {
 		Configuration *config.Configuration
-		Database      sqlx.IDB
+		Database      sqlx2.IDB
 	}
 	// IRepository interface
 	IRepository interface {
 		GetAttribute(
+			ctx context.Context,
 			campaignID int,
 		) ([]Attribute, error)
 		GetAttributes(
+			ctx context.Context,
 			campaignIDs []int,
 		) (map[int][]*Attribute, error)
 		SaveAttributes(
+			ctx context.Context,
 			c []*Attribute,
 		) error
 		UpdateAttribute(
+			ctx context.Context,
 			c Attribute,
 		) error
 		DeleteAttributesByCampaignID(
+			ctx context.Context,
 			campaignID int,
 		) error
 	}
@@ -54,52 +68,68 @@ func New(configuration *config.Configuration) IRepository {
 
 	return &Repository{
 		Configuration: configuration,
-		Database:      sqlx.New(configuration),
+		Database:      sqlx2.New(configuration),
 	}
 }
 
+// GetAttributes returns the attributes of the campaigns grouped by campaign ID.
+// The attributes are loaded with one query per ChunkSize campaigns
 func (repository *Repository) GetAttributes(
+	ctx context.Context,
 	campaignIDs []int,
 ) (map[int][]*Attribute, error) {
-	trx, err := repository.Database.Beginx()
-	defer repository.Database.Close()
-	if err != nil {
-		return nil, err
-	}
-	var query = "SELECT * FROM attributes WHERE obj_id=? AND obj_table=?"
-	campaignsAttrs := make(map[int][]*Attribute)
+	campaignsAttrs := make(map[int][]*Attribute, len(campaignIDs))
 	for _, id := range campaignIDs {
-		result, err := trx.Queryx(query, id, "campaign")
+		campaignsAttrs[id] = make([]*Attribute, 0)
+	}
 
+	for start := 0; start < len(campaignIDs); start += ChunkSize {
+		end := start + ChunkSize
+		if end > len(campaignIDs) {
+			end = len(campaignIDs)
+		}
+		err := repository.getAttributesChunk(ctx, campaignIDs[start:end], campaignsAttrs)
 		if err != nil {
 			return nil, err
 		}
+	}
+	return campaignsAttrs, nil
+}
 
-		attrs := make([]*Attribute, 0)
-		for result.Next() {
-			var attr Attribute
-			err := result.StructScan(&attr)
-			if err != nil {
-				return nil, err
-			}
-			attrs = append(attrs, &attr)
-		}
-		campaignsAttrs[id] = attrs
+func (repository *Repository) getAttributesChunk(
+	ctx context.Context,
+	campaignIDs []int,
+	campaignsAttrs map[int][]*Attribute,
+) error {
+	query, args, err := sqlx.In("SELECT * FROM attributes WHERE obj_table=? AND obj_id IN (?) ORDER BY obj_id, id", "campaign", campaignIDs)
+	if err != nil {
+		return err
 	}
-	err = trx.Commit()
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return campaignsAttrs, nil
+	defer result.Close()
+
+	for result.Next() {
+		var attr Attribute
+		err := result.StructScan(&attr)
+		if err != nil {
+			return err
+		}
+		campaignsAttrs[attr.ObjID] = append(campaignsAttrs[attr.ObjID], &attr)
+	}
+	return result.Err()
 }
 
 func (repository *Repository) GetAttribute(
+	ctx context.Context,
 	campaignID int,
 ) ([]Attribute, error) {
 
 	var query = "SELECT * FROM attributes WHERE obj_id=? AND obj_table=?"
 
-	result, err := repository.Database.Queryx(query, campaignID, "campaign")
+	result, err := repository.Database.QueryxContext(ctx, query, campaignID, "campaign")
 
 	if err != nil {
 		return nil, err
@@ -121,14 +151,16 @@ func (repository *Repository) GetAttribute(
 }
 
 func (repository *Repository) SaveAttributes(
+	ctx context.Context,
 	attrs []*Attribute,
 ) error {
-	tx, err := repository.Database.Beginx()
+	tx, err := repository.Database.BeginTxx(ctx, nil)
 	defer repository.Database.Close()
 	if err != nil {
 		return err
 	}
 
+	d := dialect.For(repository.Configuration)
 	for _, attr := range attrs {
 		vals := map[string]interface{}{
 			"obj_id":       attr.ObjID,
@@ -139,16 +171,12 @@ func (repository *Repository) SaveAttributes(
 			"value_int":    attr.ValueInt,
 			"value_double": attr.ValueDouble,
 		}
-		res, err := tx.NamedExec("INSERT INTO attributes (obj_id, obj_table, name, type, value_text, value_int, value_double) VALUES "+
+		id, err := d.InsertID(ctx, tx, "INSERT INTO attributes (obj_id, obj_table, name, type, value_text, value_int, value_double) VALUES "+
 			"(:obj_id, :obj_table, :name, :type, :value_text, :value_int, :value_double)", vals)
 		if err != nil {
 			log.Error(tx.Rollback())
 			return err
 		}
-		id, err := res.LastInsertId()
-		if err != nil {
-			return err
-		}
 		attr.ID = int(id)
 	}
 	err = tx.Commit()
@@ -159,17 +187,19 @@ func (repository *Repository) SaveAttributes(
 }
 
 func (repository *Repository) UpdateAttribute(
+	ctx context.Context,
 	c Attribute,
 ) error {
 	panic("implement me")
 }
 
 func (repository *Repository) DeleteAttributesByCampaignID(
+	ctx context.Context,
 	campaignID int,
 ) error {
-	var query = "DELETE FROM campaign WHERE obj_id=:obj_id AND obj_table=:obj_table"
+	var query = "DELETE FROM attributes WHERE obj_id=:obj_id AND obj_table=:obj_table"
 
-	_, err := repository.Database.NamedExec(query,
+	_, err := repository.Database.NamedExecContext(ctx, query,
 		map[string]interface{}{
 			"obj_id":    campaignID,
 			"obj_table": "campaign",
@@ -1 +1,208 @@
 package attributes
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/stretchr/testify/assert"
+	"github.com/zdarovich/promotion-api/internal/config"
+)
+
+// attributesPerCampaign number of attributes the fake database returns per campaign
+const attributesPerCampaign = 10
+
+// roundTrip simulated network latency of a query
+var roundTrip = 200 * time.Microsecond
+
+// queries number of queries run against the fake database
+var queries int64
+
+func init() {
+	sql.Register("attributesfake", fakeDriver{})
+}
+
+type (
+	fakeDriver struct{}
+	fakeConn   struct{}
+	fakeStmt   struct{}
+	fakeTx     struct{}
+	fakeRows   struct {
+		ids []int64
+		row int
+	}
+	// databaseMock runs the queries against the fake driver
+	databaseMock struct {
+		db *sqlx.DB
+	}
+)
+
+func (fakeDriver) Open(name string) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+// Query returns attributesPerCampaign rows for every integer argument
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	atomic.AddInt64(&queries, 1)
+	time.Sleep(roundTrip)
+	rows := &fakeRows{}
+	for _, arg := range args {
+		if id, ok := arg.(int64); ok {
+			rows.ids = append(rows.ids, id)
+		}
+	}
+	return rows, nil
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "obj_id", "obj_table", "name", "type", "value_text", "value_int", "value_double"}
+}
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.row >= len(r.ids)*attributesPerCampaign {
+		return io.EOF
+	}
+	id := r.ids[r.row/attributesPerCampaign]
+	dest[0] = int64(r.row)
+	dest[1] = id
+	dest[2] = "campaign"
+	dest[3] = "attribute" + strconv.Itoa(r.row%attributesPerCampaign)
+	dest[4] = TEXT
+	dest[5] = "value"
+	dest[6] = int64(0)
+	dest[7] = float64(0)
+	r.row++
+	return nil
+}
+
+func newDatabaseMock() *databaseMock {
+	db := sqlx.MustOpen("attributesfake", "")
+	db.Mapper = reflectx.NewMapper("json")
+	return &databaseMock{db: db}
+}
+
+func (d *databaseMock) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return d.db.Beginx()
+}
+func (d *databaseMock) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return d.db.Queryx(query, args...)
+}
+func (d *databaseMock) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
+	return d.db.QueryRowx(query, args...), nil
+}
+func (d *databaseMock) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	return d.db.NamedExec(query, arg)
+}
+func (d *databaseMock) Close() error { return nil }
+
+// getAttributesPerCampaign loads the attributes with one query per campaign,
+// the way GetAttributes used to. Kept as the baseline of the benchmarks
+func (repository *Repository) getAttributesPerCampaign(
+	campaignIDs []int,
+) (map[int][]*Attribute, error) {
+	trx, err := repository.Database.BeginTxx(context.Background(), nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return nil, err
+	}
+	campaignsAttrs := make(map[int][]*Attribute)
+	for _, id := range campaignIDs {
+		result, err := trx.Queryx("SELECT * FROM attributes WHERE obj_id=? AND obj_table=?", id, "campaign")
+		if err != nil {
+			return nil, err
+		}
+		attrs := make([]*Attribute, 0)
+		for result.Next() {
+			var attr Attribute
+			err := result.StructScan(&attr)
+			if err != nil {
+				return nil, err
+			}
+			attrs = append(attrs, &attr)
+		}
+		campaignsAttrs[id] = attrs
+	}
+	return campaignsAttrs, trx.Commit()
+}
+
+func campaignIDs(n int) []int {
+	ids := make([]int, n)
+	for idx := range ids {
+		ids[idx] = idx + 1
+	}
+	return ids
+}
+
+func TestAttributes_New(t *testing.T) {
+	r := New(&config.Configuration{})
+	assert.IsType(t, &Repository{}, r)
+}
+
+func TestAttributes_GetAttributes(t *testing.T) {
+	r := &Repository{Database: newDatabaseMock()}
+	atomic.StoreInt64(&queries, 0)
+
+	attrs, err := r.GetAttributes(context.Background(), campaignIDs(2500))
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&queries))
+	assert.Len(t, attrs, 2500)
+	assert.Len(t, attrs[1], attributesPerCampaign)
+	assert.Len(t, attrs[2500], attributesPerCampaign)
+	assert.Equal(t, 2500, attrs[2500][0].ObjID)
+	assert.Equal(t, "attribute0", attrs[2500][0].Name)
+}
+
+func TestAttributes_GetAttributes_NoCampaigns(t *testing.T) {
+	r := &Repository{Database: newDatabaseMock()}
+	atomic.StoreInt64(&queries, 0)
+
+	attrs, err := r.GetAttributes(context.Background(), nil)
+
+	assert.Nil(t, err)
+	assert.Empty(t, attrs)
+	assert.Equal(t, int64(0), atomic.LoadInt64(&queries))
+}
+
+func BenchmarkGetAttributes(b *testing.B) {
+	for _, size := range []int{20, 100, 1000} {
+		ids := campaignIDs(size)
+		r := &Repository{Database: newDatabaseMock()}
+
+		b.Run(fmt.Sprintf("batched/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := r.GetAttributes(context.Background(), ids)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("perCampaign/%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, err := r.getAttributesPerCampaign(ids)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
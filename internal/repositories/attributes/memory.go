@@ -0,0 +1,113 @@
+package attributes
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryRepository in-memory attributes repository, safe for concurrent use.
+// Only the attributes of the campaign table are returned
+type MemoryRepository struct {
+	mutex      sync.RWMutex
+	attributes map[int]Attribute
+	lastID     int
+}
+
+// NewMemory returns new empty in-memory attributes repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		attributes: make(map[int]Attribute),
+	}
+}
+
+// GetAttribute returns the attributes of the campaign
+func (repository *MemoryRepository) GetAttribute(
+	ctx context.Context,
+	campaignID int,
+) ([]Attribute, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return repository.campaignAttributes(campaignID), nil
+}
+
+// GetAttributes returns the attributes of the campaigns grouped by campaign ID,
+// every campaign has an entry
+func (repository *MemoryRepository) GetAttributes(
+	ctx context.Context,
+	campaignIDs []int,
+) (map[int][]*Attribute, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	campaignsAttrs := make(map[int][]*Attribute, len(campaignIDs))
+	for _, id := range campaignIDs {
+		attrs := repository.campaignAttributes(id)
+		campaignsAttrs[id] = make([]*Attribute, len(attrs))
+		for idx := range attrs {
+			campaignsAttrs[id][idx] = &attrs[idx]
+		}
+	}
+	return campaignsAttrs, nil
+}
+
+// SaveAttributes stores the attributes with new ids and sets the ids
+func (repository *MemoryRepository) SaveAttributes(
+	ctx context.Context,
+	attrs []*Attribute,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, attr := range attrs {
+		repository.lastID++
+		attr.ID = repository.lastID
+		repository.attributes[attr.ID] = *attr
+	}
+	return nil
+}
+
+// UpdateAttribute replaces the attribute with the same id, unknown attributes
+// are ignored
+func (repository *MemoryRepository) UpdateAttribute(
+	ctx context.Context,
+	c Attribute,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.attributes[c.ID]; ok {
+		repository.attributes[c.ID] = c
+	}
+	return nil
+}
+
+// DeleteAttributesByCampaignID deletes the attributes of the campaign
+func (repository *MemoryRepository) DeleteAttributesByCampaignID(
+	ctx context.Context,
+	campaignID int,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for id, attr := range repository.attributes {
+		if attr.ObjTable == "campaign" && attr.ObjID == campaignID {
+			delete(repository.attributes, id)
+		}
+	}
+	return nil
+}
+
+// campaignAttributes returns the attributes of the campaign ordered by id
+func (repository *MemoryRepository) campaignAttributes(campaignID int) []Attribute {
+	attrs := make([]Attribute, 0)
+	for _, attr := range repository.attributes {
+		if attr.ObjTable == "campaign" && attr.ObjID == campaignID {
+			attrs = append(attrs, attr)
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].ID < attrs[j].ID })
+	return attrs
+}
@@ -0,0 +1,38 @@
+package attributes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	var repository IRepository = NewMemory()
+	attrs := []*Attribute{
+		{ObjID: 1, ObjTable: "campaign", Name: "priority", Type: INT, ValueInt: 5},
+		{ObjID: 2, ObjTable: "campaign", Name: "storeGroup", Type: TEXT, ValueText: "north"},
+		{ObjID: 1, ObjTable: "product", Name: "priority", Type: INT, ValueInt: 7},
+		{ObjID: 1, ObjTable: "campaign", Name: "bundlePrice", Type: DOUBLE, ValueDouble: 9.5},
+	}
+	assert.Nil(t, repository.SaveAttributes(context.Background(), attrs))
+	assert.Equal(t, []int{1, 2, 3, 4}, []int{attrs[0].ID, attrs[1].ID, attrs[2].ID, attrs[3].ID})
+
+	campaignAttrs, err := repository.GetAttribute(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []Attribute{*attrs[0], *attrs[3]}, campaignAttrs)
+
+	grouped, err := repository.GetAttributes(context.Background(), []int{1, 2, 3})
+	assert.Nil(t, err)
+	assert.Equal(t, map[int][]*Attribute{1: {attrs[0], attrs[3]}, 2: {attrs[1]}, 3: {}}, grouped)
+
+	assert.Nil(t, repository.UpdateAttribute(context.Background(), Attribute{ID: 2, ObjID: 2, ObjTable: "campaign", Name: "storeGroup", Type: TEXT, ValueText: "south"}))
+	campaignAttrs, _ = repository.GetAttribute(context.Background(), 2)
+	assert.Equal(t, "south", campaignAttrs[0].ValueText)
+
+	assert.Nil(t, repository.DeleteAttributesByCampaignID(context.Background(), 1))
+	campaignAttrs, _ = repository.GetAttribute(context.Background(), 1)
+	assert.Empty(t, campaignAttrs)
+	grouped, _ = repository.GetAttributes(context.Background(), []int{2})
+	assert.Len(t, grouped[2], 1)
+}
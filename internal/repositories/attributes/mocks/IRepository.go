@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	attributes "github.com/zdarovich/promotion-api/internal/repositories/attributes"
 )
@@ -12,13 +14,13 @@ type IRepository struct {
 	mock.Mock
 }
 
-// DeleteAttributesByCampaignID provides a mock function with given fields: campaignID
-func (_m *IRepository) DeleteAttributesByCampaignID(campaignID int) error {
-	ret := _m.Called(campaignID)
+// DeleteAttributesByCampaignID provides a mock function with given fields: ctx, campaignID
+func (_m *IRepository) DeleteAttributesByCampaignID(ctx context.Context, campaignID int) error {
+	ret := _m.Called(ctx, campaignID)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int) error); ok {
-		r0 = rf(campaignID)
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, campaignID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -26,13 +28,13 @@ func (_m *IRepository) DeleteAttributesByCampaignID(campaignID int) error {
 	return r0
 }
 
-// GetAttribute provides a mock function with given fields: campaignID
-func (_m *IRepository) GetAttribute(campaignID int) ([]attributes.Attribute, error) {
-	ret := _m.Called(campaignID)
+// GetAttribute provides a mock function with given fields: ctx, campaignID
+func (_m *IRepository) GetAttribute(ctx context.Context, campaignID int) ([]attributes.Attribute, error) {
+	ret := _m.Called(ctx, campaignID)
 
 	var r0 []attributes.Attribute
-	if rf, ok := ret.Get(0).(func(int) []attributes.Attribute); ok {
-		r0 = rf(campaignID)
+	if rf, ok := ret.Get(0).(func(context.Context, int) []attributes.Attribute); ok {
+		r0 = rf(ctx, campaignID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]attributes.Attribute)
@@ -40,8 +42,8 @@ func (_m *IRepository) GetAttribute(campaignID int) ([]attributes.Attribute, err
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(campaignID)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, campaignID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -49,13 +51,13 @@ func (_m *IRepository) GetAttribute(campaignID int) ([]attributes.Attribute, err
 	return r0, r1
 }
 
-// GetAttributes provides a mock function with given fields: campaignIDs
-func (_m *IRepository) GetAttributes(campaignIDs []int) (map[int][]*attributes.Attribute, error) {
-	ret := _m.Called(campaignIDs)
+// GetAttributes provides a mock function with given fields: ctx, campaignIDs
+func (_m *IRepository) GetAttributes(ctx context.Context, campaignIDs []int) (map[int][]*attributes.Attribute, error) {
+	ret := _m.Called(ctx, campaignIDs)
 
 	var r0 map[int][]*attributes.Attribute
-	if rf, ok := ret.Get(0).(func([]int) map[int][]*attributes.Attribute); ok {
-		r0 = rf(campaignIDs)
+	if rf, ok := ret.Get(0).(func(context.Context, []int) map[int][]*attributes.Attribute); ok {
+		r0 = rf(ctx, campaignIDs)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(map[int][]*attributes.Attribute)
@@ -63,8 +65,8 @@ func (_m *IRepository) GetAttributes(campaignIDs []int) (map[int][]*attributes.A
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func([]int) error); ok {
-		r1 = rf(campaignIDs)
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, campaignIDs)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -72,13 +74,13 @@ func (_m *IRepository) GetAttributes(campaignIDs []int) (map[int][]*attributes.A
 	return r0, r1
 }
 
-// SaveAttributes provides a mock function with given fields: c
-func (_m *IRepository) SaveAttributes(c []*attributes.Attribute) error {
-	ret := _m.Called(c)
+// SaveAttributes provides a mock function with given fields: ctx, c
+func (_m *IRepository) SaveAttributes(ctx context.Context, c []*attributes.Attribute) error {
+	ret := _m.Called(ctx, c)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func([]*attributes.Attribute) error); ok {
-		r0 = rf(c)
+	if rf, ok := ret.Get(0).(func(context.Context, []*attributes.Attribute) error); ok {
+		r0 = rf(ctx, c)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -86,13 +88,13 @@ func (_m *IRepository) SaveAttributes(c []*attributes.Attribute) error {
 	return r0
 }
 
-// UpdateAttribute provides a mock function with given fields: c
-func (_m *IRepository) UpdateAttribute(c attributes.Attribute) error {
-	ret := _m.Called(c)
+// UpdateAttribute provides a mock function with given fields: ctx, c
+func (_m *IRepository) UpdateAttribute(ctx context.Context, c attributes.Attribute) error {
+	ret := _m.Called(ctx, c)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(attributes.Attribute) error); ok {
-		r0 = rf(c)
+	if rf, ok := ret.Get(0).(func(context.Context, attributes.Attribute) error); ok {
+		r0 = rf(ctx, c)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -1,14 +1,31 @@
 package campaign
 
 import (
+	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	sqlx2 "github.com/jmoiron/sqlx"
 	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
 	"github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/outbox"
 	"reflect"
 	"strings"
 	"time"
 )
 
+// Events of the campaigns recorded in the outbox
+const (
+	EventCreated string = "campaign.created"
+	EventUpdated string = "campaign.updated"
+	EventDeleted string = "campaign.deleted"
+)
+
+// Aggregate of the campaign messages of the outbox
+const Aggregate = "campaign"
+
 type (
 	// Repository struct
 	Repository struct {
@@ -18,25 +35,63 @@ type (
 	// IRepository interface
 	IRepository interface {
 		GetCampaigns(
+			ctx context.Context,
 			campaignID int,
 			campaignType string,
 			records int,
 			page int,
 		) ([]Campaign, error)
 		GetCampaignsCount(
+			ctx context.Context,
 			campaignID int,
 			campaignType string,
 		) (int, error)
+		GetActiveCampaigns(
+			ctx context.Context,
+			at time.Time,
+			campaignID int,
+			campaignType string,
+		) ([]Campaign, error)
+		GetCampaignsByIDs(
+			ctx context.Context,
+			campaignIDs []int,
+		) ([]Campaign, error)
+		GetCampaignsStartingOrEnding(
+			ctx context.Context,
+			from time.Time,
+			to time.Time,
+		) ([]Campaign, error)
 		SaveCampaigns(
+			ctx context.Context,
 			c *Campaign,
 		) error
+		SaveCampaignsWithRecords(
+			ctx context.Context,
+			c *Campaign,
+			records Records,
+		) error
 		UpdateCampaigns(
+			ctx context.Context,
 			c Campaign,
 		) error
 		DeleteCampaigns(
+			ctx context.Context,
+			campaignID int,
+		) error
+		RecordChange(
+			ctx context.Context,
 			campaignID int,
 		) error
+		GetChanges(
+			ctx context.Context,
+			after int64,
+			tombstones bool,
+			limit int,
+		) ([]Change, error)
 	}
+	// Records saves the child records of the new campaign in its
+	// transaction, the transaction is nil in the memory repository
+	Records func(ctx context.Context, tx *sqlx2.Tx, campaignID int) error
 	// Promotion structure of the promotion
 	Campaign struct {
 		ID                      int       `json:"id"`
@@ -62,48 +117,262 @@ type (
 		Changed                 int64     `json:"changed"`
 		Changedby               string    `json:"changedby"`
 	}
+	// Change structure of the campaign_change table, the latest change of a
+	// campaign. Seq orders the changes, a deleted campaign keeps its change
+	// as a tombstone
+	Change struct {
+		CampaignID int   `json:"campaign_id"`
+		Seq        int64 `json:"seq"`
+		Deleted    bool  `json:"deleted"`
+		Changed    int64 `json:"changed"`
+	}
+	// EventPayload payload of the campaign messages of the outbox
+	EventPayload struct {
+		Event       string    `json:"event"`
+		CampaignID  int       `json:"campaignID"`
+		Name        string    `json:"name"`
+		Type        string    `json:"type"`
+		WarehouseID int       `json:"warehouseID"`
+		StartDate   time.Time `json:"startDate"`
+		EndDate     time.Time `json:"endDate"`
+		Occurred    int64     `json:"occurred"`
+	}
 )
 
 func (repository *Repository) DeleteCampaigns(
+	ctx context.Context,
 	campaignID int,
 ) error {
 
 	var query = "DELETE FROM campaign WHERE id IN (:id)"
+	vals := map[string]interface{}{
+		"id": campaignID,
+	}
 
-	_, err := repository.Database.NamedExec(query,
-		map[string]interface{}{
-			"id": campaignID,
-		})
+	// The deleted campaign is read for its event, its tombstone and event
+	// are recorded in the transaction of the delete
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+	var c Campaign
+	err = tx.GetContext(ctx, &c, tx.Rebind("SELECT * FROM campaign WHERE id = ?"), campaignID)
+	if err == sql.ErrNoRows {
+		return tx.Rollback()
+	}
+	if err == nil {
+		_, err = tx.NamedExecContext(ctx, query, vals)
+	}
+	if err == nil {
+		err = repository.recordChange(ctx, tx, campaignID, true)
+	}
+	if err == nil && repository.eventsEnabled() {
+		err = repository.recordEvent(ctx, tx, EventDeleted, c)
+	}
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
 
-	return err
+	return tx.Commit()
 }
 
 func (repository *Repository) UpdateCampaigns(
+	ctx context.Context,
 	c Campaign,
 ) error {
 	panic("implement me")
 }
 
 func (repository *Repository) SaveCampaigns(
+	ctx context.Context,
 	c *Campaign,
+) error {
+	return repository.SaveCampaignsWithRecords(ctx, c, nil)
+}
+
+// SaveCampaignsWithRecords saves the campaign and, with the id set, its
+// child records in one transaction. A campaign whose records fail is not
+// saved
+func (repository *Repository) SaveCampaignsWithRecords(
+	ctx context.Context,
+	c *Campaign,
+	records Records,
 ) error {
 	conditionsString, values := repository.getSaveConditions(*c)
 
 	var query = "INSERT INTO campaign" + conditionsString
 
-	r, err := repository.Database.NamedExec(query, values)
-
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+	id, err := dialect.For(repository.Configuration).InsertID(ctx, tx, query, values)
 	if err != nil {
+		log.Error(tx.Rollback())
 		return err
 	}
-	id, err := r.LastInsertId()
 
 	c.ID = int(id)
 
-	return nil
+	if records != nil {
+		err = records(ctx, tx, c.ID)
+	}
+	if err == nil {
+		err = repository.recordChange(ctx, tx, c.ID, false)
+	}
+	if err == nil && repository.eventsEnabled() {
+		err = repository.recordEvent(ctx, tx, EventCreated, *c)
+	}
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RecordChange records a change of the campaign in the change feed, for the
+// changes of its settings and child records saved after the campaign
+func (repository *Repository) RecordChange(
+	ctx context.Context,
+	campaignID int,
+) error {
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+	err = repository.recordChange(ctx, tx, campaignID, false)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetChanges returns the latest changes of the campaigns after the sequence
+// number in the order of their sequence numbers, the tombstones of the
+// deleted campaigns only with tombstones
+func (repository *Repository) GetChanges(
+	ctx context.Context,
+	after int64,
+	tombstones bool,
+	limit int,
+) ([]Change, error) {
+
+	query := "SELECT * FROM campaign_change WHERE seq > ?"
+	values := []interface{}{after}
+	if !tombstones {
+		query += " AND deleted = ?"
+		values = append(values, false)
+	}
+	pagination, limits := dialect.For(repository.Configuration).Paginate(limit, 0)
+	values = append(values, limits...)
+
+	result, err := repository.Database.QueryxContext(ctx, query+" ORDER BY seq"+pagination, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	changes := make([]Change, 0)
+	for result.Next() {
+		var c Change
+		err := result.StructScan(&c)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+
+	return changes, result.Err()
+}
+
+// recordChange takes the next sequence number and sets it as the latest
+// change of the campaign. The sequence row stays locked until the
+// transaction ends, the changes are committed in the order of their
+// sequence numbers and a reader never sees a number after one that is not
+// committed yet
+func (repository *Repository) recordChange(ctx context.Context, tx *sqlx2.Tx, campaignID int, deleted bool) error {
+
+	_, err := tx.ExecContext(ctx, "UPDATE campaign_change_sequence SET seq = seq + 1 WHERE id = 1")
+	if err != nil {
+		return err
+	}
+	var seq int64
+	err = tx.GetContext(ctx, &seq, "SELECT seq FROM campaign_change_sequence WHERE id = 1")
+	if err != nil {
+		return err
+	}
+
+	query := dialect.For(repository.Configuration).Upsert(
+		"INSERT INTO campaign_change (campaign_id, seq, deleted, changed) VALUES (:campaign_id, :seq, :deleted, :changed)",
+		[]string{"campaign_id"},
+		[]string{"seq", "deleted", "changed"},
+	)
+	_, err = tx.NamedExecContext(ctx, query, map[string]interface{}{
+		"campaign_id": campaignID,
+		"seq":         seq,
+		"deleted":     deleted,
+		"changed":     time.Now().Unix(),
+	})
+
+	return err
+}
+
+// OutboxMessage returns the outbox message of the event of the campaign
+func OutboxMessage(event string, c Campaign, now int64) (*outbox.Message, error) {
+
+	payload, err := json.Marshal(EventPayload{
+		Event:       event,
+		CampaignID:  c.ID,
+		Name:        c.Name,
+		Type:        c.Type,
+		WarehouseID: c.WarehouseID,
+		StartDate:   c.StartDate.UTC(),
+		EndDate:     c.EndDate.UTC(),
+		Occurred:    now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &outbox.Message{
+		Aggregate:   Aggregate,
+		AggregateID: c.ID,
+		Event:       event,
+		Payload:     string(payload),
+		Added:       now,
+	}, nil
+}
+
+// recordEvent records the event of the campaign in the outbox, in the
+// transaction of the change. It follows recordChange: the sequence row is
+// locked and the outbox ids are taken in the order of the commits, a reader
+// of the outbox after an id misses no event
+func (repository *Repository) recordEvent(ctx context.Context, tx *sqlx2.Tx, event string, c Campaign) error {
+
+	m, err := OutboxMessage(event, c, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+
+	return outbox.Insert(ctx, repository.Configuration, tx, m)
+}
+
+// eventsEnabled returns whether the campaign changes are recorded in the
+// outbox
+func (repository *Repository) eventsEnabled() bool {
+	return repository.Configuration != nil && repository.Configuration.Events.Enabled
 }
 
 func (repository *Repository) GetCampaignsCount(
+	ctx context.Context,
 	campaignID int,
 	campaignType string,
 ) (int, error) {
@@ -118,7 +387,7 @@ func (repository *Repository) GetCampaignsCount(
 	} else {
 		query = "SELECT COUNT(*) FROM campaign WHERE " + conditionsString
 	}
-	result, err := repository.Database.QueryRowx(query, values...)
+	result, err := repository.Database.QueryRowxContext(ctx, query, values...)
 
 	var count int
 
@@ -141,6 +410,7 @@ func New(configuration *config.Configuration) IRepository {
 
 // GetCampaigns returns campaign
 func (repository *Repository) GetCampaigns(
+	ctx context.Context,
 	campaignID int,
 	campaignType string,
 	records int,
@@ -152,19 +422,135 @@ func (repository *Repository) GetCampaigns(
 		campaignType,
 	)
 
-	values = append(values, records*page)
-	values = append(values, records*page+records)
+	pagination, limits := dialect.For(repository.Configuration).Paginate(records, page)
+	values = append(values, limits...)
+	var query string
+	if len(conditionsString) == 0 {
+		query = "SELECT * FROM campaign ORDER BY id" + pagination
+	} else {
+		query = "SELECT * FROM campaign WHERE " + conditionsString + " ORDER BY id" + pagination
+	}
+	result, err := repository.Database.QueryxContext(ctx, query, values...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	campaigns := make([]Campaign, 0)
+	for result.Next() {
+		var campaign Campaign
+		err := result.StructScan(&campaign)
+
+		if err != nil {
+			return nil, err
+		}
+
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, result.Err()
+}
+
+// GetActiveCampaigns returns all campaigns whose period contains the instant
+func (repository *Repository) GetActiveCampaigns(
+	ctx context.Context,
+	at time.Time,
+	campaignID int,
+	campaignType string,
+) ([]Campaign, error) {
+
+	conditionsString, values := repository.getConditions(
+		campaignID,
+		campaignType,
+	)
+
+	instant := at.UTC().Format("2006-01-02 15:04:05")
+	values = append(values, instant, instant)
 	var query string
 	if len(conditionsString) == 0 {
-		query = "SELECT * FROM campaign LIMIT ?, ?"
+		query = "SELECT * FROM campaign WHERE start_date <= ? AND end_date >= ?"
 	} else {
-		query = "SELECT * FROM campaign WHERE " + conditionsString + " LIMIT ?, ?"
+		query = "SELECT * FROM campaign WHERE " + conditionsString + " AND start_date <= ? AND end_date >= ?"
 	}
-	result, err := repository.Database.Queryx(query, values...)
+	result, err := repository.Database.QueryxContext(ctx, query, values...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	campaigns := make([]Campaign, 0)
+	for result.Next() {
+		var campaign Campaign
+		err := result.StructScan(&campaign)
+
+		if err != nil {
+			return nil, err
+		}
+
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, result.Err()
+}
+
+// GetCampaignsByIDs returns the campaigns with the ids
+func (repository *Repository) GetCampaignsByIDs(
+	ctx context.Context,
+	campaignIDs []int,
+) ([]Campaign, error) {
+
+	campaigns := make([]Campaign, 0)
+	if len(campaignIDs) == 0 {
+		return campaigns, nil
+	}
+
+	query, args, err := sqlx2.In("SELECT * FROM campaign WHERE id IN (?)", campaignIDs)
+	if err != nil {
+		return nil, err
+	}
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	for result.Next() {
+		var campaign Campaign
+		err := result.StructScan(&campaign)
+
+		if err != nil {
+			return nil, err
+		}
+
+		campaigns = append(campaigns, campaign)
+	}
+
+	return campaigns, result.Err()
+}
+
+// GetCampaignsStartingOrEnding returns the campaigns that start after from
+// and at the latest at to, or that end at from or later and before to. A
+// campaign is active up to and including its end date
+func (repository *Repository) GetCampaignsStartingOrEnding(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) ([]Campaign, error) {
+
+	start := from.UTC().Format("2006-01-02 15:04:05")
+	end := to.UTC().Format("2006-01-02 15:04:05")
+	result, err := repository.Database.QueryxContext(ctx,
+		"SELECT * FROM campaign WHERE (start_date > ? AND start_date <= ?) OR (end_date >= ? AND end_date < ?) ORDER BY id",
+		start, end, start, end,
+	)
 
 	if err != nil {
 		return nil, err
 	}
+	defer result.Close()
 
 	campaigns := make([]Campaign, 0)
 	for result.Next() {
@@ -178,7 +564,7 @@ func (repository *Repository) GetCampaigns(
 		campaigns = append(campaigns, campaign)
 	}
 
-	return campaigns, nil
+	return campaigns, result.Err()
 }
 
 func (repository *Repository) getConditions(
@@ -0,0 +1,278 @@
+package campaign
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/repositories/outbox"
+)
+
+// MemoryRepository in-memory campaign repository, safe for concurrent use.
+// Campaigns are ordered by id and ids are assigned like an auto increment
+// column, they are not reused after a delete. The changes are recorded in
+// the Outbox when it is set and in the change feed
+type MemoryRepository struct {
+	Outbox    *outbox.MemoryRepository
+	mutex     sync.RWMutex
+	campaigns map[int]Campaign
+	changes   map[int]Change
+	lastID    int
+	lastSeq   int64
+}
+
+// NewMemory returns new empty in-memory campaign repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		campaigns: make(map[int]Campaign),
+		changes:   make(map[int]Change),
+	}
+}
+
+// GetCampaigns returns a page of the campaigns with the id and type, an empty
+// filter matches all campaigns
+func (repository *MemoryRepository) GetCampaigns(
+	ctx context.Context,
+	campaignID int,
+	campaignType string,
+	records int,
+	page int,
+) ([]Campaign, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	campaigns := repository.filter(func(c Campaign) bool {
+		return matches(c, campaignID, campaignType)
+	})
+	return paginate(campaigns, records, page), nil
+}
+
+// GetCampaignsCount returns the count of the campaigns with the id and type
+func (repository *MemoryRepository) GetCampaignsCount(
+	ctx context.Context,
+	campaignID int,
+	campaignType string,
+) (int, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	campaigns := repository.filter(func(c Campaign) bool {
+		return matches(c, campaignID, campaignType)
+	})
+	return len(campaigns), nil
+}
+
+// GetActiveCampaigns returns all campaigns whose period contains the instant
+func (repository *MemoryRepository) GetActiveCampaigns(
+	ctx context.Context,
+	at time.Time,
+	campaignID int,
+	campaignType string,
+) ([]Campaign, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return repository.filter(func(c Campaign) bool {
+		return matches(c, campaignID, campaignType) && !c.StartDate.After(at) && !c.EndDate.Before(at)
+	}), nil
+}
+
+// GetCampaignsByIDs returns the campaigns with the ids
+func (repository *MemoryRepository) GetCampaignsByIDs(
+	ctx context.Context,
+	campaignIDs []int,
+) ([]Campaign, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	ids := make(map[int]bool, len(campaignIDs))
+	for _, id := range campaignIDs {
+		ids[id] = true
+	}
+	return repository.filter(func(c Campaign) bool {
+		return ids[c.ID]
+	}), nil
+}
+
+// GetCampaignsStartingOrEnding returns the campaigns that start after from
+// and at the latest at to, or that end at from or later and before to
+func (repository *MemoryRepository) GetCampaignsStartingOrEnding(
+	ctx context.Context,
+	from time.Time,
+	to time.Time,
+) ([]Campaign, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return repository.filter(func(c Campaign) bool {
+		starts := c.StartDate.After(from) && !c.StartDate.After(to)
+		ends := !c.EndDate.Before(from) && c.EndDate.Before(to)
+		return starts || ends
+	}), nil
+}
+
+// SaveCampaigns stores the campaign with a new id and sets the id
+func (repository *MemoryRepository) SaveCampaigns(
+	ctx context.Context,
+	c *Campaign,
+) error {
+	return repository.SaveCampaignsWithRecords(ctx, c, nil)
+}
+
+// SaveCampaignsWithRecords stores the campaign with a new id and saves its
+// child records, the campaign is not stored when they fail
+func (repository *MemoryRepository) SaveCampaignsWithRecords(
+	ctx context.Context,
+	c *Campaign,
+	records Records,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.lastID++
+	c.ID = repository.lastID
+	if records != nil {
+		err := records(ctx, nil, c.ID)
+		if err != nil {
+			return err
+		}
+	}
+	repository.campaigns[c.ID] = stored(*c)
+	repository.recordChange(c.ID, false)
+	return repository.recordEvent(EventCreated, *c)
+}
+
+// UpdateCampaigns replaces the campaign with the same id, unknown campaigns
+// are ignored
+func (repository *MemoryRepository) UpdateCampaigns(
+	ctx context.Context,
+	c Campaign,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.campaigns[c.ID]; !ok {
+		return nil
+	}
+	repository.campaigns[c.ID] = stored(c)
+	repository.recordChange(c.ID, false)
+	return repository.recordEvent(EventUpdated, c)
+}
+
+// DeleteCampaigns deletes the campaign
+func (repository *MemoryRepository) DeleteCampaigns(
+	ctx context.Context,
+	campaignID int,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	c, ok := repository.campaigns[campaignID]
+	if !ok {
+		return nil
+	}
+	delete(repository.campaigns, campaignID)
+	repository.recordChange(campaignID, true)
+	return repository.recordEvent(EventDeleted, c)
+}
+
+// RecordChange records a change of the campaign in the change feed
+func (repository *MemoryRepository) RecordChange(
+	ctx context.Context,
+	campaignID int,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.recordChange(campaignID, false)
+	return nil
+}
+
+// GetChanges returns the latest changes of the campaigns after the sequence
+// number ordered by sequence number, tombstones only with tombstones
+func (repository *MemoryRepository) GetChanges(
+	ctx context.Context,
+	after int64,
+	tombstones bool,
+	limit int,
+) ([]Change, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	changes := make([]Change, 0)
+	for _, c := range repository.changes {
+		if c.Seq > after && (tombstones || !c.Deleted) {
+			changes = append(changes, c)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Seq < changes[j].Seq })
+	if limit >= 0 && len(changes) > limit {
+		changes = changes[:limit]
+	}
+	return changes, nil
+}
+
+// recordChange sets the next sequence number as the latest change of the
+// campaign
+func (repository *MemoryRepository) recordChange(campaignID int, deleted bool) {
+
+	repository.lastSeq++
+	repository.changes[campaignID] = Change{
+		CampaignID: campaignID,
+		Seq:        repository.lastSeq,
+		Deleted:    deleted,
+		Changed:    time.Now().Unix(),
+	}
+}
+
+// recordEvent records the event of the campaign in the outbox when it is set
+func (repository *MemoryRepository) recordEvent(event string, c Campaign) error {
+
+	if repository.Outbox == nil {
+		return nil
+	}
+	m, err := OutboxMessage(event, c, time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	repository.Outbox.Add(m)
+	return nil
+}
+
+// filter returns the matching campaigns ordered by id
+func (repository *MemoryRepository) filter(match func(c Campaign) bool) []Campaign {
+	campaigns := make([]Campaign, 0)
+	for _, c := range repository.campaigns {
+		if match(c) {
+			campaigns = append(campaigns, c)
+		}
+	}
+	sort.Slice(campaigns, func(i, j int) bool { return campaigns[i].ID < campaigns[j].ID })
+	return campaigns
+}
+
+func matches(c Campaign, campaignID int, campaignType string) bool {
+	return (campaignID <= 0 || c.ID == campaignID) && (campaignType == "" || c.Type == campaignType)
+}
+
+// stored returns the campaign as the database returns it, with UTC periods
+func stored(c Campaign) Campaign {
+	c.StartDate = c.StartDate.UTC()
+	c.EndDate = c.EndDate.UTC()
+	return c
+}
+
+// paginate returns the page like LIMIT records OFFSET records*page
+func paginate(campaigns []Campaign, records int, page int) []Campaign {
+	from := records * page
+	if records <= 0 || from < 0 || from >= len(campaigns) {
+		return make([]Campaign, 0)
+	}
+	to := from + records
+	if to > len(campaigns) {
+		to = len(campaigns)
+	}
+	return campaigns[from:to]
+}
@@ -0,0 +1,189 @@
+package campaign
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zdarovich/promotion-api/internal/repositories/outbox"
+)
+
+func TestMemoryRepository_GetCampaigns(t *testing.T) {
+	var repository IRepository = NewMemory()
+	for _, campaignType := range []string{"auto", "manual", "auto", "auto"} {
+		c := &Campaign{Type: campaignType}
+		assert.Nil(t, repository.SaveCampaigns(context.Background(), c))
+	}
+
+	campaigns, err := repository.GetCampaigns(context.Background(), 0, "auto", 2, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 3}, GetIds(campaigns))
+
+	campaigns, err = repository.GetCampaigns(context.Background(), 0, "auto", 2, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{4}, GetIds(campaigns))
+
+	campaigns, err = repository.GetCampaigns(context.Background(), 0, "auto", 2, 2)
+	assert.Nil(t, err)
+	assert.Empty(t, campaigns)
+
+	campaigns, err = repository.GetCampaigns(context.Background(), 2, "", 20, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2}, GetIds(campaigns))
+
+	count, err := repository.GetCampaignsCount(context.Background(), 0, "auto")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	campaigns, err = repository.GetCampaignsByIDs(context.Background(), []int{4, 2, 9})
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2, 4}, GetIds(campaigns))
+}
+
+func TestMemoryRepository_GetActiveCampaigns(t *testing.T) {
+	repository := NewMemory()
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	may := &Campaign{
+		StartDate: time.Date(2020, 5, 1, 3, 0, 0, 0, loc),
+		EndDate:   time.Date(2020, 5, 31, 23, 59, 59, 0, time.UTC),
+	}
+	june := &Campaign{
+		StartDate: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 6, 30, 23, 59, 59, 0, time.UTC),
+	}
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), may))
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), june))
+
+	campaigns, err := repository.GetActiveCampaigns(context.Background(), time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC), 0, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []int{may.ID}, GetIds(campaigns))
+	assert.Equal(t, time.UTC, campaigns[0].StartDate.Location())
+
+	campaigns, err = repository.GetActiveCampaigns(context.Background(), time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC), 0, "")
+	assert.Nil(t, err)
+	assert.Empty(t, campaigns)
+}
+
+func TestMemoryRepository_GetCampaignsStartingOrEnding(t *testing.T) {
+	repository := NewMemory()
+	may := &Campaign{
+		StartDate: time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 5, 31, 23, 59, 59, 0, time.UTC),
+	}
+	june := &Campaign{
+		StartDate: time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2020, 6, 30, 23, 59, 59, 0, time.UTC),
+	}
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), may))
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), june))
+
+	campaigns, err := repository.GetCampaignsStartingOrEnding(context.Background(),
+		time.Date(2020, 5, 31, 23, 0, 0, 0, time.UTC), time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, []int{may.ID, june.ID}, GetIds(campaigns))
+
+	// Neither starts nor ends within the period
+	campaigns, err = repository.GetCampaignsStartingOrEnding(context.Background(),
+		time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 6, 2, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Empty(t, campaigns)
+}
+
+func TestMemoryRepository_UpdateAndDelete(t *testing.T) {
+	repository := NewMemory()
+	c := &Campaign{Name: "old"}
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), c))
+
+	assert.Nil(t, repository.UpdateCampaigns(context.Background(), Campaign{ID: c.ID, Name: "new"}))
+	assert.Nil(t, repository.UpdateCampaigns(context.Background(), Campaign{ID: 99, Name: "unknown"}))
+	campaigns, _ := repository.GetCampaigns(context.Background(), 0, "", 20, 0)
+	assert.Len(t, campaigns, 1)
+	assert.Equal(t, "new", campaigns[0].Name)
+
+	assert.Nil(t, repository.DeleteCampaigns(context.Background(), c.ID))
+	next := &Campaign{}
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), next))
+	assert.Equal(t, c.ID+1, next.ID)
+	count, _ := repository.GetCampaignsCount(context.Background(), c.ID, "")
+	assert.Equal(t, 0, count)
+}
+
+func TestMemoryRepository_Outbox(t *testing.T) {
+	repository := NewMemory()
+	repository.Outbox = outbox.NewMemory()
+
+	c := &Campaign{Name: "spring"}
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), c))
+	assert.Nil(t, repository.UpdateCampaigns(context.Background(), Campaign{ID: c.ID, Name: "summer"}))
+	// Saved with a campaign id it is a new campaign
+	replaced := &Campaign{ID: c.ID, Name: "autumn"}
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), replaced))
+	assert.Nil(t, repository.DeleteCampaigns(context.Background(), c.ID))
+	assert.Nil(t, repository.DeleteCampaigns(context.Background(), 99))
+
+	messages := repository.Outbox.GetMessages()
+	events := make([]string, len(messages))
+	for idx, m := range messages {
+		assert.Equal(t, Aggregate, m.Aggregate)
+		events[idx] = m.Event
+	}
+	assert.Equal(t, []string{EventCreated, EventUpdated, EventCreated, EventDeleted}, events)
+	assert.Equal(t, replaced.ID, messages[2].AggregateID)
+
+	var payload EventPayload
+	assert.Nil(t, json.Unmarshal([]byte(messages[3].Payload), &payload))
+	assert.Equal(t, EventDeleted, payload.Event)
+	assert.Equal(t, "summer", payload.Name)
+}
+
+func TestMemoryRepository_Changes(t *testing.T) {
+	repository := NewMemory()
+	first := &Campaign{Name: "first"}
+	second := &Campaign{Name: "second"}
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), first))
+	assert.Nil(t, repository.SaveCampaigns(context.Background(), second))
+	assert.Nil(t, repository.RecordChange(context.Background(), first.ID))
+	assert.Nil(t, repository.DeleteCampaigns(context.Background(), second.ID))
+
+	changes, err := repository.GetChanges(context.Background(), 0, true, 100)
+	assert.Nil(t, err)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, Change{CampaignID: first.ID, Seq: 3, Changed: changes[0].Changed}, changes[0])
+	assert.Equal(t, second.ID, changes[1].CampaignID)
+	assert.Equal(t, int64(4), changes[1].Seq)
+	assert.True(t, changes[1].Deleted)
+
+	changes, _ = repository.GetChanges(context.Background(), 0, false, 100)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, first.ID, changes[0].CampaignID)
+
+	changes, _ = repository.GetChanges(context.Background(), 3, true, 100)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, second.ID, changes[0].CampaignID)
+
+	changes, _ = repository.GetChanges(context.Background(), 0, true, 1)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, int64(3), changes[0].Seq)
+}
+
+func TestMemoryRepository_Concurrent(t *testing.T) {
+	repository := NewMemory()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, repository.SaveCampaigns(context.Background(), &Campaign{}))
+			_, err := repository.GetCampaigns(context.Background(), 0, "", 20, 0)
+			assert.Nil(t, err)
+		}()
+	}
+	wg.Wait()
+
+	count, _ := repository.GetCampaignsCount(context.Background(), 0, "")
+	assert.Equal(t, 50, count)
+}
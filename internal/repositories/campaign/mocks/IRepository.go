@@ -3,8 +3,12 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	campaign "github.com/zdarovich/promotion-api/internal/repositories/campaign"
+
+	time "time"
 )
 
 // IRepository is an autogenerated mock type for the IRepository type
@@ -12,13 +16,13 @@ type IRepository struct {
 	mock.Mock
 }
 
-// DeleteCampaigns provides a mock function with given fields: campaignID
-func (_m *IRepository) DeleteCampaigns(campaignID int) error {
-	ret := _m.Called(campaignID)
+// DeleteCampaigns provides a mock function with given fields: ctx, campaignID
+func (_m *IRepository) DeleteCampaigns(ctx context.Context, campaignID int) error {
+	ret := _m.Called(ctx, campaignID)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(int) error); ok {
-		r0 = rf(campaignID)
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, campaignID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -26,13 +30,13 @@ func (_m *IRepository) DeleteCampaigns(campaignID int) error {
 	return r0
 }
 
-// GetCampaigns provides a mock function with given fields: campaignID, campaignType, records, page
-func (_m *IRepository) GetCampaigns(campaignID int, campaignType string, records int, page int) ([]campaign.Campaign, error) {
-	ret := _m.Called(campaignID, campaignType, records, page)
+// GetActiveCampaigns provides a mock function with given fields: ctx, at, campaignID, campaignType
+func (_m *IRepository) GetActiveCampaigns(ctx context.Context, at time.Time, campaignID int, campaignType string) ([]campaign.Campaign, error) {
+	ret := _m.Called(ctx, at, campaignID, campaignType)
 
 	var r0 []campaign.Campaign
-	if rf, ok := ret.Get(0).(func(int, string, int, int) []campaign.Campaign); ok {
-		r0 = rf(campaignID, campaignType, records, page)
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, string) []campaign.Campaign); ok {
+		r0 = rf(ctx, at, campaignID, campaignType)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]campaign.Campaign)
@@ -40,8 +44,8 @@ func (_m *IRepository) GetCampaigns(campaignID int, campaignType string, records
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int, string, int, int) error); ok {
-		r1 = rf(campaignID, campaignType, records, page)
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int, string) error); ok {
+		r1 = rf(ctx, at, campaignID, campaignType)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -49,20 +53,89 @@ func (_m *IRepository) GetCampaigns(campaignID int, campaignType string, records
 	return r0, r1
 }
 
-// GetCampaignsCount provides a mock function with given fields: campaignID, campaignType
-func (_m *IRepository) GetCampaignsCount(campaignID int, campaignType string) (int, error) {
-	ret := _m.Called(campaignID, campaignType)
+// GetCampaigns provides a mock function with given fields: ctx, campaignID, campaignType, records, page
+func (_m *IRepository) GetCampaigns(ctx context.Context, campaignID int, campaignType string, records int, page int) ([]campaign.Campaign, error) {
+	ret := _m.Called(ctx, campaignID, campaignType, records, page)
+
+	var r0 []campaign.Campaign
+	if rf, ok := ret.Get(0).(func(context.Context, int, string, int, int) []campaign.Campaign); ok {
+		r0 = rf(ctx, campaignID, campaignType, records, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]campaign.Campaign)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, string, int, int) error); ok {
+		r1 = rf(ctx, campaignID, campaignType, records, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCampaignsByIDs provides a mock function with given fields: ctx, campaignIDs
+func (_m *IRepository) GetCampaignsByIDs(ctx context.Context, campaignIDs []int) ([]campaign.Campaign, error) {
+	ret := _m.Called(ctx, campaignIDs)
+
+	var r0 []campaign.Campaign
+	if rf, ok := ret.Get(0).(func(context.Context, []int) []campaign.Campaign); ok {
+		r0 = rf(ctx, campaignIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]campaign.Campaign)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, campaignIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCampaignsCount provides a mock function with given fields: ctx, campaignID, campaignType
+func (_m *IRepository) GetCampaignsCount(ctx context.Context, campaignID int, campaignType string) (int, error) {
+	ret := _m.Called(ctx, campaignID, campaignType)
 
 	var r0 int
-	if rf, ok := ret.Get(0).(func(int, string) int); ok {
-		r0 = rf(campaignID, campaignType)
+	if rf, ok := ret.Get(0).(func(context.Context, int, string) int); ok {
+		r0 = rf(ctx, campaignID, campaignType)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int, string) error); ok {
-		r1 = rf(campaignID, campaignType)
+	if rf, ok := ret.Get(1).(func(context.Context, int, string) error); ok {
+		r1 = rf(ctx, campaignID, campaignType)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCampaignsStartingOrEnding provides a mock function with given fields: ctx, from, to
+func (_m *IRepository) GetCampaignsStartingOrEnding(ctx context.Context, from time.Time, to time.Time) ([]campaign.Campaign, error) {
+	ret := _m.Called(ctx, from, to)
+
+	var r0 []campaign.Campaign
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time) []campaign.Campaign); ok {
+		r0 = rf(ctx, from, to)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]campaign.Campaign)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time) error); ok {
+		r1 = rf(ctx, from, to)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -70,13 +143,64 @@ func (_m *IRepository) GetCampaignsCount(campaignID int, campaignType string) (i
 	return r0, r1
 }
 
-// SaveCampaigns provides a mock function with given fields: c
-func (_m *IRepository) SaveCampaigns(c *campaign.Campaign) error {
-	ret := _m.Called(c)
+// GetChanges provides a mock function with given fields: ctx, after, tombstones, limit
+func (_m *IRepository) GetChanges(ctx context.Context, after int64, tombstones bool, limit int) ([]campaign.Change, error) {
+	ret := _m.Called(ctx, after, tombstones, limit)
+
+	var r0 []campaign.Change
+	if rf, ok := ret.Get(0).(func(context.Context, int64, bool, int) []campaign.Change); ok {
+		r0 = rf(ctx, after, tombstones, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]campaign.Change)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int64, bool, int) error); ok {
+		r1 = rf(ctx, after, tombstones, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordChange provides a mock function with given fields: ctx, campaignID
+func (_m *IRepository) RecordChange(ctx context.Context, campaignID int) error {
+	ret := _m.Called(ctx, campaignID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, campaignID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveCampaigns provides a mock function with given fields: ctx, c
+func (_m *IRepository) SaveCampaigns(ctx context.Context, c *campaign.Campaign) error {
+	ret := _m.Called(ctx, c)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *campaign.Campaign) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveCampaignsWithRecords provides a mock function with given fields: ctx, c, records
+func (_m *IRepository) SaveCampaignsWithRecords(ctx context.Context, c *campaign.Campaign, records campaign.Records) error {
+	ret := _m.Called(ctx, c, records)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*campaign.Campaign) error); ok {
-		r0 = rf(c)
+	if rf, ok := ret.Get(0).(func(context.Context, *campaign.Campaign, campaign.Records) error); ok {
+		r0 = rf(ctx, c, records)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -84,13 +208,13 @@ func (_m *IRepository) SaveCampaigns(c *campaign.Campaign) error {
 	return r0
 }
 
-// UpdateCampaigns provides a mock function with given fields: c
-func (_m *IRepository) UpdateCampaigns(c campaign.Campaign) error {
-	ret := _m.Called(c)
+// UpdateCampaigns provides a mock function with given fields: ctx, c
+func (_m *IRepository) UpdateCampaigns(ctx context.Context, c campaign.Campaign) error {
+	ret := _m.Called(ctx, c)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(campaign.Campaign) error); ok {
-		r0 = rf(c)
+	if rf, ok := ret.Get(0).(func(context.Context, campaign.Campaign) error); ok {
+		r0 = rf(ctx, c)
 	} else {
 		r0 = ret.Error(0)
 	}
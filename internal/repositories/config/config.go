@@ -1,6 +1,8 @@
 package config
 
 import (
+	"context"
+
 	"github.com/zdarovich/promotion-api/internal/config"
 	"github.com/zdarovich/promotion-api/internal/database/sqlx"
 )
@@ -13,7 +15,7 @@ type (
 	}
 	// IRepository interface
 	IRepository interface {
-		GetConfigByName(name string) (Conf, error)
+		GetConfigByName(ctx context.Context, name string) (Conf, error)
 	}
 	// Conf structure of the user
 	Conf struct {
@@ -38,15 +40,16 @@ func New(configuration *config.Configuration) IRepository {
 }
 
 // GetConfigByName returns user by session key
-func (repository *Repository) GetConfigByName(name string) (Conf, error) {
+func (repository *Repository) GetConfigByName(ctx context.Context, name string) (Conf, error) {
 
 	query := "SELECT * FROM conf WHERE name=?"
 
-	result, err := repository.Database.Queryx(query, name)
+	result, err := repository.Database.QueryxContext(ctx, query, name)
 	var conf Conf
 	if err != nil {
 		return conf, err
 	}
+	defer result.Close()
 	if result.Next() {
 		err = result.StructScan(&conf)
 	}
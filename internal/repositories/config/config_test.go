@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"database/sql"
 	"testing"
 
@@ -14,21 +15,23 @@ type (
 	databaseMock struct{}
 )
 
-func (d *databaseMock) Beginx() (*sqlx.Tx, error) { return nil, nil }
+func (d *databaseMock) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return nil, nil
+}
 
 var queryXq string
 var queryXa []interface{}
 
-func (d *databaseMock) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+func (d *databaseMock) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
 	queryXq = query
 	queryXa = args
 	return new(sqlx.Rows), nil
 }
 
-func (d *databaseMock) QueryRowx(query string, args ...interface{}) (*sqlx.Row, error) {
+func (d *databaseMock) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
 	return nil, nil
 }
-func (d *databaseMock) NamedExec(query string, arg interface{}) (sql.Result, error) {
+func (d *databaseMock) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
 	r := new(sql.Result)
 
 	return *r, nil
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryRepository in-memory conf repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex  sync.RWMutex
+	confs  map[string]Conf
+	lastID int
+}
+
+// NewMemory returns new empty in-memory conf repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		confs: make(map[string]Conf),
+	}
+}
+
+// SetConfig stores the conf, replacing the conf with the same name, and
+// returns it with its id
+func (repository *MemoryRepository) SetConfig(c Conf) Conf {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if stored, ok := repository.confs[c.Name]; ok {
+		c.ID = stored.ID
+	} else {
+		repository.lastID++
+		c.ID = repository.lastID
+	}
+	repository.confs[c.Name] = c
+	return c
+}
+
+// GetConfigByName returns the conf with the name, an empty conf when it
+// does not exist
+func (repository *MemoryRepository) GetConfigByName(ctx context.Context, name string) (Conf, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return repository.confs[name], nil
+}
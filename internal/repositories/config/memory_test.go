@@ -0,0 +1,25 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	repository := NewMemory()
+	timezone := repository.SetConfig(Conf{Name: "timezone", Value: "UTC"})
+	assert.Equal(t, 1, timezone.ID)
+	timezone = repository.SetConfig(Conf{Name: "timezone", Value: "Europe/Tallinn"})
+	assert.Equal(t, 1, timezone.ID)
+
+	var confs IRepository = repository
+	c, err := confs.GetConfigByName(context.Background(), "timezone")
+	assert.Nil(t, err)
+	assert.Equal(t, timezone, c)
+
+	c, err = confs.GetConfigByName(context.Background(), "vertical")
+	assert.Nil(t, err)
+	assert.Equal(t, Conf{}, c)
+}
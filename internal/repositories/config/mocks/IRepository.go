@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	config "github.com/zdarovich/promotion-api/internal/repositories/config"
 )
@@ -12,20 +14,20 @@ type IRepository struct {
 	mock.Mock
 }
 
-// GetConfigByName provides a mock function with given fields: name
-func (_m *IRepository) GetConfigByName(name string) (config.Conf, error) {
-	ret := _m.Called(name)
+// GetConfigByName provides a mock function with given fields: ctx, name
+func (_m *IRepository) GetConfigByName(ctx context.Context, name string) (config.Conf, error) {
+	ret := _m.Called(ctx, name)
 
 	var r0 config.Conf
-	if rf, ok := ret.Get(0).(func(string) config.Conf); ok {
-		r0 = rf(name)
+	if rf, ok := ret.Get(0).(func(context.Context, string) config.Conf); ok {
+		r0 = rf(ctx, name)
 	} else {
 		r0 = ret.Get(0).(config.Conf)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(name)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, name)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -1,6 +1,7 @@
 package identity
 
 import (
+	"context"
 	"fmt"
 	"github.com/zdarovich/promotion-api/internal/cache/redis"
 	"github.com/zdarovich/promotion-api/internal/config"
@@ -15,7 +16,7 @@ type (
 	}
 	// IIdentity interaface
 	IIdentity interface {
-		GetJWT(clientCode string, sessionKey string) (string, error)
+		GetJWT(ctx context.Context, clientCode string, sessionKey string) (string, error)
 	}
 )
 
@@ -29,15 +30,15 @@ func New(configuration *config.Configuration) IIdentity {
 }
 
 // GetJWT returns the identity JWT token
-func (identity *Identity) GetJWT(clientCode string, sessionKey string) (string, error) {
+func (identity *Identity) GetJWT(ctx context.Context, clientCode string, sessionKey string) (string, error) {
 
 	r := redis.New(identity.Configuration)
 	key := fmt.Sprintf("identity_token_%s_%s", clientCode, sessionKey)
 	var res interface{} = ""
 
-	exists, _ := r.Exists(key)
+	exists, _ := r.Exists(ctx, key)
 	if exists == 1 {
-		res, err := r.Get(key)
+		res, err := r.Get(ctx, key)
 
 		if err != nil {
 			return res.(string), err
@@ -45,13 +46,13 @@ func (identity *Identity) GetJWT(clientCode string, sessionKey string) (string,
 	} else {
 		// Request new JWT from identity integration.
 		// Save it to redis and return.
-		jwt, err := identity.IdentityService.GenerateNewJWT(clientCode, sessionKey)
+		jwt, err := identity.IdentityService.GenerateNewJWT(ctx, clientCode, sessionKey)
 
 		if err != nil {
 			return res.(string), err
 		}
 
-		err = r.Set(key, jwt)
+		err = r.Set(ctx, key, jwt)
 
 		if err != nil {
 			return res.(string), err
@@ -0,0 +1,139 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryRepository in-memory outbox repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex       sync.RWMutex
+	messages    []Message
+	lockedUntil int64
+	lastID      int
+}
+
+// NewMemory returns new empty in-memory outbox repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{}
+}
+
+// Add records the message and sets its id, the in-memory counterpart of
+// Insert
+func (repository *MemoryRepository) Add(m *Message) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.lastID++
+	m.ID = repository.lastID
+	repository.messages = append(repository.messages, *m)
+}
+
+// GetMessages returns all the messages in the order they were recorded
+func (repository *MemoryRepository) GetMessages() []Message {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	messages := make([]Message, len(repository.messages))
+	copy(messages, repository.messages)
+	return messages
+}
+
+// GetPending returns the messages not published yet in the order they were
+// recorded
+func (repository *MemoryRepository) GetPending(ctx context.Context, limit int) ([]Message, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	messages := make([]Message, 0)
+	for _, m := range repository.messages {
+		if len(messages) == limit {
+			break
+		}
+		if m.Published == 0 {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+// GetAfter returns the messages recorded after the message with the id in
+// the order they were recorded, published or not
+func (repository *MemoryRepository) GetAfter(ctx context.Context, after int, limit int) ([]Message, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	messages := make([]Message, 0)
+	for _, m := range repository.messages {
+		if len(messages) == limit {
+			break
+		}
+		if m.ID > after {
+			messages = append(messages, m)
+		}
+	}
+	return messages, nil
+}
+
+// GetLastID returns the id of the last message kept, 0 without messages
+func (repository *MemoryRepository) GetLastID(ctx context.Context) (int, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	if len(repository.messages) == 0 {
+		return 0, nil
+	}
+	return repository.messages[len(repository.messages)-1].ID, nil
+}
+
+// MarkPublished sets the time the message was published
+func (repository *MemoryRepository) MarkPublished(ctx context.Context, id int, published int64) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for idx := range repository.messages {
+		if repository.messages[idx].ID == id {
+			repository.messages[idx].Published = published
+		}
+	}
+	return nil
+}
+
+// DeletePublished deletes the messages published before the time
+func (repository *MemoryRepository) DeletePublished(ctx context.Context, before int64) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	kept := repository.messages[:0]
+	for _, m := range repository.messages {
+		if m.Published == 0 || m.Published >= before {
+			kept = append(kept, m)
+		}
+	}
+	repository.messages = kept
+	return nil
+}
+
+// ClaimRelay locks the relay until the time and returns whether it did
+func (repository *MemoryRepository) ClaimRelay(ctx context.Context, now int64, until int64) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if repository.lockedUntil > now {
+		return false, nil
+	}
+	repository.lockedUntil = until
+	return true, nil
+}
+
+// ReleaseRelay unlocks the relay claimed until the time
+func (repository *MemoryRepository) ReleaseRelay(ctx context.Context, until int64) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if repository.lockedUntil == until {
+		repository.lockedUntil = 0
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository_Messages(t *testing.T) {
+	repository := NewMemory()
+	for _, event := range []string{"campaign.created", "campaign.updated", "campaign.deleted"} {
+		repository.Add(&Message{Aggregate: "campaign", AggregateID: 4, Event: event})
+	}
+
+	pending, err := repository.GetPending(context.Background(), 2)
+	assert.Nil(t, err)
+	assert.Len(t, pending, 2)
+	assert.Equal(t, "campaign.created", pending[0].Event)
+
+	assert.Nil(t, repository.MarkPublished(context.Background(), 1, 100))
+	assert.Nil(t, repository.MarkPublished(context.Background(), 2, 200))
+	pending, _ = repository.GetPending(context.Background(), 10)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, 3, pending[0].ID)
+
+	after, _ := repository.GetAfter(context.Background(), 1, 10)
+	assert.Len(t, after, 2)
+	assert.Equal(t, 2, after[0].ID)
+	assert.Equal(t, int64(200), after[0].Published)
+
+	assert.Nil(t, repository.DeletePublished(context.Background(), 150))
+	assert.Len(t, repository.GetMessages(), 2)
+	last, _ := repository.GetLastID(context.Background())
+	assert.Equal(t, 3, last)
+}
+
+func TestMemoryRepository_Relay(t *testing.T) {
+	var repository IRepository = NewMemory()
+
+	claimed, _ := repository.ClaimRelay(context.Background(), 100, 160)
+	assert.True(t, claimed)
+	claimed, _ = repository.ClaimRelay(context.Background(), 120, 180)
+	assert.False(t, claimed)
+
+	// A release of an expired claim keeps the later claim
+	claimed, _ = repository.ClaimRelay(context.Background(), 160, 220)
+	assert.True(t, claimed)
+	assert.Nil(t, repository.ReleaseRelay(context.Background(), 160))
+	claimed, _ = repository.ClaimRelay(context.Background(), 170, 230)
+	assert.False(t, claimed)
+
+	assert.Nil(t, repository.ReleaseRelay(context.Background(), 220))
+	claimed, _ = repository.ClaimRelay(context.Background(), 170, 230)
+	assert.True(t, claimed)
+}
@@ -0,0 +1,180 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	"github.com/zdarovich/promotion-api/internal/database/sqlx"
+)
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration *config.Configuration
+		Database      sqlx.IDB
+	}
+	// IRepository interface
+	IRepository interface {
+		GetPending(ctx context.Context, limit int) ([]Message, error)
+		GetAfter(ctx context.Context, after int, limit int) ([]Message, error)
+		GetLastID(ctx context.Context) (int, error)
+		MarkPublished(ctx context.Context, id int, published int64) error
+		DeletePublished(ctx context.Context, before int64) error
+		ClaimRelay(ctx context.Context, now int64, until int64) (bool, error)
+		ReleaseRelay(ctx context.Context, until int64) error
+	}
+	// Message structure of the outbox table, an event of an aggregate
+	// recorded in the transaction of the change. Published is 0 until the
+	// relay has published it
+	Message struct {
+		ID          int    `json:"id"`
+		Aggregate   string `json:"aggregate"`
+		AggregateID int    `json:"aggregate_id"`
+		Event       string `json:"event"`
+		Payload     string `json:"payload"`
+		Added       int64  `json:"added"`
+		Published   int64  `json:"published"`
+	}
+)
+
+// New returns new configured outbox repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration: configuration,
+		Database:      sqlx.New(configuration),
+	}
+}
+
+// Insert records the message in the transaction of the change it describes,
+// the message is relayed only once the transaction is committed
+func Insert(ctx context.Context, configuration *config.Configuration, tx dialect.NamedExecQueryer, m *Message) error {
+
+	id, err := dialect.For(configuration).InsertID(ctx, tx, "INSERT INTO outbox (aggregate, aggregate_id, event, payload, added) VALUES "+
+		"(:aggregate, :aggregate_id, :event, :payload, :added)", map[string]interface{}{
+		"aggregate":    m.Aggregate,
+		"aggregate_id": m.AggregateID,
+		"event":        m.Event,
+		"payload":      m.Payload,
+		"added":        m.Added,
+	})
+	if err != nil {
+		return err
+	}
+	m.ID = int(id)
+
+	return nil
+}
+
+// GetPending returns the messages not published yet in the order they were
+// recorded
+func (repository *Repository) GetPending(ctx context.Context, limit int) ([]Message, error) {
+
+	pagination, limits := dialect.For(repository.Configuration).Paginate(limit, 0)
+	values := append([]interface{}{0}, limits...)
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM outbox WHERE published = ? ORDER BY id"+pagination, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	messages := make([]Message, 0)
+	for result.Next() {
+		var m Message
+		err := result.StructScan(&m)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, result.Err()
+}
+
+// GetAfter returns the messages recorded after the message with the id in
+// the order they were recorded, published or not
+func (repository *Repository) GetAfter(ctx context.Context, after int, limit int) ([]Message, error) {
+
+	pagination, limits := dialect.For(repository.Configuration).Paginate(limit, 0)
+	values := append([]interface{}{after}, limits...)
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM outbox WHERE id > ? ORDER BY id"+pagination, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	messages := make([]Message, 0)
+	for result.Next() {
+		var m Message
+		err := result.StructScan(&m)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, result.Err()
+}
+
+// GetLastID returns the id of the last message kept, 0 without messages
+func (repository *Repository) GetLastID(ctx context.Context) (int, error) {
+
+	result, err := repository.Database.QueryRowxContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM outbox")
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	err = result.Scan(&id)
+
+	return id, err
+}
+
+// MarkPublished sets the time the message was published
+func (repository *Repository) MarkPublished(ctx context.Context, id int, published int64) error {
+
+	_, err := repository.Database.NamedExecContext(ctx, "UPDATE outbox SET published=:published WHERE id=:id", map[string]interface{}{
+		"id":        id,
+		"published": published,
+	})
+
+	return err
+}
+
+// DeletePublished deletes the messages published before the time
+func (repository *Repository) DeletePublished(ctx context.Context, before int64) error {
+
+	_, err := repository.Database.NamedExecContext(ctx, "DELETE FROM outbox WHERE published > 0 AND published < :before", map[string]interface{}{
+		"before": before,
+	})
+
+	return err
+}
+
+// ClaimRelay locks the relay of the outbox until the time and returns
+// whether it did. The messages are relayed by one instance at a time so
+// that they are published in order
+func (repository *Repository) ClaimRelay(ctx context.Context, now int64, until int64) (bool, error) {
+
+	res, err := repository.Database.NamedExecContext(ctx, "UPDATE outbox_relay SET locked_until=:until WHERE id=1 AND locked_until<=:now", map[string]interface{}{
+		"now":   now,
+		"until": until,
+	})
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+
+	return affected == 1, err
+}
+
+// ReleaseRelay unlocks the relay claimed until the time
+func (repository *Repository) ReleaseRelay(ctx context.Context, until int64) error {
+
+	_, err := repository.Database.NamedExecContext(ctx, "UPDATE outbox_relay SET locked_until=0 WHERE id=1 AND locked_until=:until", map[string]interface{}{
+		"until": until,
+	})
+
+	return err
+}
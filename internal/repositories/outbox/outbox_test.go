@@ -0,0 +1,89 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	databaseMock struct{}
+)
+
+func (d *databaseMock) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return nil, errors.New("1003")
+}
+
+var queryXq string
+var queryXa []interface{}
+
+func (d *databaseMock) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	queryXq = query
+	queryXa = args
+	return nil, errors.New("1003")
+}
+
+func (d *databaseMock) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
+	return nil, errors.New("1003")
+}
+
+var namedExecQ string
+var namedExecA interface{}
+
+func (d *databaseMock) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	namedExecQ = query
+	namedExecA = arg
+	return nil, errors.New("1003")
+}
+func (d *databaseMock) Close() error { return nil }
+
+func TestOutbox_New(t *testing.T) {
+	r := New(&config.Configuration{})
+	assert.IsType(t, &Repository{}, r)
+}
+
+func TestOutbox_GetPending(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	_, err := r.GetPending(context.Background(), 100)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM outbox WHERE published = ? ORDER BY id LIMIT ? OFFSET ?", queryXq)
+	assert.Equal(t, []interface{}{0, 100, 0}, queryXa)
+}
+
+func TestOutbox_GetAfter(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	_, err := r.GetAfter(context.Background(), 12, 50)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM outbox WHERE id > ? ORDER BY id LIMIT ? OFFSET ?", queryXq)
+	assert.Equal(t, []interface{}{12, 50, 0}, queryXa)
+}
+
+func TestOutbox_ClaimRelay(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	claimed, err := r.ClaimRelay(context.Background(), 100, 160)
+
+	assert.NotNil(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, "UPDATE outbox_relay SET locked_until=:until WHERE id=1 AND locked_until<=:now", namedExecQ)
+	assert.Equal(t, map[string]interface{}{"now": int64(100), "until": int64(160)}, namedExecA)
+}
+
+func TestOutbox_DeletePublished(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	err := r.DeletePublished(context.Background(), 100)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "DELETE FROM outbox WHERE published > 0 AND published < :before", namedExecQ)
+}
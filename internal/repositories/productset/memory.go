@@ -0,0 +1,99 @@
+package productset
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MemoryRepository in-memory product set repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex  sync.RWMutex
+	sets   map[int]ProductSet
+	lastID int
+}
+
+// NewMemory returns new empty in-memory product set repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		sets: make(map[int]ProductSet),
+	}
+}
+
+// GetProductSets returns the product sets of the campaigns grouped by campaign id
+// and ordered by their position
+func (repository *MemoryRepository) GetProductSets(
+	ctx context.Context,
+	campaignIDs []int,
+) (map[int][]*ProductSet, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	ids := make(map[int]bool, len(campaignIDs))
+	for _, id := range campaignIDs {
+		ids[id] = true
+	}
+	sets := make([]ProductSet, 0)
+	for _, set := range repository.sets {
+		if ids[set.CampaignID] {
+			sets = append(sets, set)
+		}
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		if sets[i].Position != sets[j].Position {
+			return sets[i].Position < sets[j].Position
+		}
+		return sets[i].ID < sets[j].ID
+	})
+
+	campaignsSets := make(map[int][]*ProductSet)
+	for idx := range sets {
+		set := &sets[idx]
+		campaignsSets[set.CampaignID] = append(campaignsSets[set.CampaignID], set)
+	}
+	return campaignsSets, nil
+}
+
+// SaveProductSets stores the product sets with new ids and sets the ids
+func (repository *MemoryRepository) SaveProductSets(
+	ctx context.Context,
+	sets []*ProductSet,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, set := range sets {
+		repository.lastID++
+		set.ID = repository.lastID
+		repository.sets[set.ID] = *set
+	}
+	return nil
+}
+
+// SaveProductSetsTx stores the product sets like SaveProductSets, there is no transaction
+func (repository *MemoryRepository) SaveProductSetsTx(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	sets []*ProductSet,
+) error {
+	return repository.SaveProductSets(ctx, sets)
+}
+
+// DeleteProductSetsByCampaignID deletes all product sets of the campaign
+func (repository *MemoryRepository) DeleteProductSetsByCampaignID(
+	ctx context.Context,
+	campaignID int,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for id, set := range repository.sets {
+		if set.CampaignID == campaignID {
+			delete(repository.sets, id)
+		}
+	}
+	return nil
+}
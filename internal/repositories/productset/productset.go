@@ -0,0 +1,160 @@
+package productset
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration *config.Configuration
+		Database      sqlx2.IDB
+	}
+	// IRepository interface
+	IRepository interface {
+		GetProductSets(
+			ctx context.Context,
+			campaignIDs []int,
+		) (map[int][]*ProductSet, error)
+		SaveProductSets(
+			ctx context.Context,
+			p []*ProductSet,
+		) error
+		SaveProductSetsTx(
+			ctx context.Context,
+			tx *sqlx.Tx,
+			p []*ProductSet,
+		) error
+		DeleteProductSetsByCampaignID(
+			ctx context.Context,
+			campaignID int,
+		) error
+	}
+	// ProductSet structure of a named product set of a bundle campaign
+	ProductSet struct {
+		ID                int    `json:"id"`
+		CampaignID        int    `json:"campaign_id"`
+		Position          int    `json:"position"`
+		Name              string `json:"name"`
+		Amount            int    `json:"amount"`
+		Products          string `json:"products"`
+		ProductGroupID    int    `json:"prodgroup_id"`
+		ProductCategoryID int    `json:"prodcategory_id"`
+	}
+)
+
+// New returns new configured product set repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration: configuration,
+		Database:      sqlx2.New(configuration),
+	}
+}
+
+// GetProductSets returns the product sets of the campaigns grouped by
+// campaign id and ordered by their position
+func (repository *Repository) GetProductSets(
+	ctx context.Context,
+	campaignIDs []int,
+) (map[int][]*ProductSet, error) {
+
+	campaignsSets := make(map[int][]*ProductSet)
+	if len(campaignIDs) == 0 {
+		return campaignsSets, nil
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM campaign_product_set WHERE campaign_id IN (?) ORDER BY campaign_id, position", campaignIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	for result.Next() {
+		var set ProductSet
+		err := result.StructScan(&set)
+		if err != nil {
+			return nil, err
+		}
+		campaignsSets[set.CampaignID] = append(campaignsSets[set.CampaignID], &set)
+	}
+
+	return campaignsSets, result.Err()
+}
+
+// SaveProductSets saves the product sets in a single transaction
+func (repository *Repository) SaveProductSets(
+	ctx context.Context,
+	sets []*ProductSet,
+) error {
+	if len(sets) == 0 {
+		return nil
+	}
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+
+	err = repository.SaveProductSetsTx(ctx, tx, sets)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveProductSetsTx saves the product sets in the transaction
+func (repository *Repository) SaveProductSetsTx(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	sets []*ProductSet,
+) error {
+	for _, set := range sets {
+		vals := map[string]interface{}{
+			"campaign_id":     set.CampaignID,
+			"position":        set.Position,
+			"name":            set.Name,
+			"amount":          set.Amount,
+			"products":        set.Products,
+			"prodgroup_id":    set.ProductGroupID,
+			"prodcategory_id": set.ProductCategoryID,
+		}
+		id, err := dialect.For(repository.Configuration).InsertID(ctx, tx, "INSERT INTO campaign_product_set (campaign_id, position, name, amount, products, prodgroup_id, prodcategory_id) VALUES "+
+			"(:campaign_id, :position, :name, :amount, :products, :prodgroup_id, :prodcategory_id)", vals)
+		if err != nil {
+			return err
+		}
+		set.ID = int(id)
+	}
+
+	return nil
+}
+
+// DeleteProductSetsByCampaignID deletes all product sets of the campaign
+func (repository *Repository) DeleteProductSetsByCampaignID(
+	ctx context.Context,
+	campaignID int,
+) error {
+	var query = "DELETE FROM campaign_product_set WHERE campaign_id=:campaign_id"
+
+	_, err := repository.Database.NamedExecContext(ctx, query,
+		map[string]interface{}{
+			"campaign_id": campaignID,
+		})
+
+	return err
+}
@@ -0,0 +1,88 @@
+package productset
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	databaseMock struct{}
+)
+
+func (d *databaseMock) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return nil, nil
+}
+
+var queryXq string
+var queryXa []interface{}
+
+func (d *databaseMock) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	queryXq = query
+	queryXa = args
+	return nil, errors.New("1003")
+}
+
+func (d *databaseMock) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
+	return nil, nil
+}
+
+var namedExecQ string
+var namedExecA interface{}
+
+func (d *databaseMock) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	namedExecQ = query
+	namedExecA = arg
+	r := new(sql.Result)
+
+	return *r, nil
+}
+func (d *databaseMock) Close() error { return nil }
+
+func TestProductSet_New(t *testing.T) {
+	r := New(&config.Configuration{})
+	assert.IsType(t, &Repository{}, r)
+}
+
+func TestProductSet_GetProductSets_NoCampaigns(t *testing.T) {
+	queryXq = ""
+	r := &Repository{Database: &databaseMock{}}
+
+	sets, err := r.GetProductSets(context.Background(), []int{})
+
+	assert.Nil(t, err)
+	assert.Empty(t, sets)
+	assert.Equal(t, "", queryXq)
+}
+
+func TestProductSet_GetProductSets_SingleQuery(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	_, err := r.GetProductSets(context.Background(), []int{1, 2, 3})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM campaign_product_set WHERE campaign_id IN (?, ?, ?) ORDER BY campaign_id, position", queryXq)
+	assert.Equal(t, []interface{}{1, 2, 3}, queryXa)
+}
+
+func TestProductSet_SaveProductSets_Empty(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	assert.Nil(t, r.SaveProductSets(context.Background(), nil))
+}
+
+func TestProductSet_DeleteProductSetsByCampaignID(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	err := r.DeleteProductSetsByCampaignID(context.Background(), 7)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "DELETE FROM campaign_product_set WHERE campaign_id=:campaign_id", namedExecQ)
+	assert.Equal(t, map[string]interface{}{"campaign_id": 7}, namedExecA)
+}
@@ -0,0 +1,40 @@
+package rights
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryRepository in-memory rights repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex  sync.RWMutex
+	rights map[int]Rights
+}
+
+// NewMemory returns new empty in-memory rights repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		rights: make(map[int]Rights),
+	}
+}
+
+// SetRights stores the rights of the group, replacing its previous rights
+func (repository *MemoryRepository) SetRights(r Rights) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.rights[r.GroupID] = r
+}
+
+// GetRights returns the rights of the user group, no rights when the group
+// has none stored
+func (repository *MemoryRepository) GetRights(ctx context.Context, groupID int) (Rights, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	if r, ok := repository.rights[groupID]; ok {
+		return r, nil
+	}
+	return Rights{GroupID: groupID}, nil
+}
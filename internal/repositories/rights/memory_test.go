@@ -0,0 +1,23 @@
+package rights
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	repository := NewMemory()
+	repository.SetRights(Rights{GroupID: 1, ViewCampaigns: true})
+	repository.SetRights(Rights{GroupID: 1, ViewCampaigns: true, EditCampaigns: true})
+
+	var rights IRepository = repository
+	r, err := rights.GetRights(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, Rights{GroupID: 1, ViewCampaigns: true, EditCampaigns: true}, r)
+
+	r, err = rights.GetRights(context.Background(), 2)
+	assert.Nil(t, err)
+	assert.Equal(t, Rights{GroupID: 2}, r)
+}
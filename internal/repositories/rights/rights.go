@@ -0,0 +1,114 @@
+package rights
+
+import (
+	"context"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/sqlx"
+)
+
+// Rights of the user groups
+const (
+	ViewCampaigns    Right = "viewCampaigns"
+	CreateCampaigns  Right = "createCampaigns"
+	EditCampaigns    Right = "editCampaigns"
+	DeleteCampaigns  Right = "deleteCampaigns"
+	ApproveCampaigns Right = "approveCampaigns"
+	ViewReports      Right = "viewReports"
+	ManageAPIKeys    Right = "manageAPIKeys"
+	ManageWebhooks   Right = "manageWebhooks"
+)
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration *config.Configuration
+		Database      sqlx.IDB
+	}
+	// IRepository interface
+	IRepository interface {
+		GetRights(ctx context.Context, groupID int) (Rights, error)
+	}
+	// Right name of a right of a user group
+	Right string
+	// Rights structure of the user_group_right table, a group without a row
+	// has no rights
+	Rights struct {
+		GroupID          int  `json:"group_id"`
+		ViewCampaigns    bool `json:"view_campaigns"`
+		CreateCampaigns  bool `json:"create_campaigns"`
+		EditCampaigns    bool `json:"edit_campaigns"`
+		DeleteCampaigns  bool `json:"delete_campaigns"`
+		ApproveCampaigns bool `json:"approve_campaigns"`
+		ViewReports      bool `json:"view_reports"`
+		ManageAPIKeys    bool `json:"manage_api_keys"`
+		ManageWebhooks   bool `json:"manage_webhooks"`
+	}
+)
+
+// New returns new configured rights repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration: configuration,
+		Database:      sqlx.New(configuration),
+	}
+}
+
+// GetRights returns the rights of the user group
+func (repository *Repository) GetRights(ctx context.Context, groupID int) (Rights, error) {
+
+	query := "SELECT * FROM user_group_right WHERE group_id=?"
+
+	rights := Rights{GroupID: groupID}
+	result, err := repository.Database.QueryxContext(ctx, query, groupID)
+	if err != nil {
+		return rights, err
+	}
+	defer result.Close()
+	if result.Next() {
+		err = result.StructScan(&rights)
+	}
+
+	if err != nil {
+		return rights, err
+	}
+
+	return rights, result.Err()
+}
+
+// Has returns whether the group has the right
+func (rights Rights) Has(right Right) bool {
+
+	switch right {
+	case ViewCampaigns:
+		return rights.ViewCampaigns
+	case CreateCampaigns:
+		return rights.CreateCampaigns
+	case EditCampaigns:
+		return rights.EditCampaigns
+	case DeleteCampaigns:
+		return rights.DeleteCampaigns
+	case ApproveCampaigns:
+		return rights.ApproveCampaigns
+	case ViewReports:
+		return rights.ViewReports
+	case ManageAPIKeys:
+		return rights.ManageAPIKeys
+	case ManageWebhooks:
+		return rights.ManageWebhooks
+	}
+	return false
+}
+
+// Missing returns the rights the group does not have
+func (rights Rights) Missing(required []Right) []Right {
+
+	missing := make([]Right, 0)
+	for _, right := range required {
+		if !rights.Has(right) {
+			missing = append(missing, right)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,34 @@
+package rights
+
+import (
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRights_New(t *testing.T) {
+	r := New(&config.Configuration{})
+	assert.IsType(t, &Repository{}, r)
+}
+
+func TestRights_Has(t *testing.T) {
+	r := Rights{ViewCampaigns: true, DeleteCampaigns: true}
+
+	assert.True(t, r.Has(ViewCampaigns))
+	assert.True(t, r.Has(DeleteCampaigns))
+	assert.False(t, r.Has(CreateCampaigns))
+	assert.False(t, r.Has(EditCampaigns))
+	assert.False(t, r.Has(ApproveCampaigns))
+	assert.False(t, r.Has(ViewReports))
+	assert.False(t, r.Has(Right("unknown")))
+}
+
+func TestRights_Missing(t *testing.T) {
+	r := Rights{ViewCampaigns: true}
+
+	assert.Empty(t, r.Missing([]Right{ViewCampaigns}))
+	assert.Empty(t, r.Missing(nil))
+	assert.Equal(t, []Right{EditCampaigns, ApproveCampaigns}, r.Missing([]Right{ViewCampaigns, EditCampaigns, ApproveCampaigns}))
+}
@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MemoryRepository in-memory schedule repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex     sync.RWMutex
+	schedules map[int]Schedule
+	lastID    int
+}
+
+// NewMemory returns new empty in-memory schedule repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		schedules: make(map[int]Schedule),
+	}
+}
+
+// GetSchedules returns the schedules of the campaigns grouped by campaign id
+// and ordered by their position
+func (repository *MemoryRepository) GetSchedules(
+	ctx context.Context,
+	campaignIDs []int,
+) (map[int][]*Schedule, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	ids := make(map[int]bool, len(campaignIDs))
+	for _, id := range campaignIDs {
+		ids[id] = true
+	}
+	schedules := make([]Schedule, 0)
+	for _, schedule := range repository.schedules {
+		if ids[schedule.CampaignID] {
+			schedules = append(schedules, schedule)
+		}
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		if schedules[i].Position != schedules[j].Position {
+			return schedules[i].Position < schedules[j].Position
+		}
+		return schedules[i].ID < schedules[j].ID
+	})
+
+	campaignsSchedules := make(map[int][]*Schedule)
+	for idx := range schedules {
+		schedule := &schedules[idx]
+		campaignsSchedules[schedule.CampaignID] = append(campaignsSchedules[schedule.CampaignID], schedule)
+	}
+	return campaignsSchedules, nil
+}
+
+// SaveSchedules stores the schedules with new ids and sets the ids
+func (repository *MemoryRepository) SaveSchedules(
+	ctx context.Context,
+	schedules []*Schedule,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, schedule := range schedules {
+		repository.lastID++
+		schedule.ID = repository.lastID
+		repository.schedules[schedule.ID] = *schedule
+	}
+	return nil
+}
+
+// SaveSchedulesTx stores the schedules like SaveSchedules, there is no transaction
+func (repository *MemoryRepository) SaveSchedulesTx(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	schedules []*Schedule,
+) error {
+	return repository.SaveSchedules(ctx, schedules)
+}
+
+// DeleteSchedulesByCampaignID deletes all schedules of the campaign
+func (repository *MemoryRepository) DeleteSchedulesByCampaignID(
+	ctx context.Context,
+	campaignID int,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for id, schedule := range repository.schedules {
+		if schedule.CampaignID == campaignID {
+			delete(repository.schedules, id)
+		}
+	}
+	return nil
+}
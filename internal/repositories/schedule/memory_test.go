@@ -0,0 +1,29 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	var repository IRepository = NewMemory()
+	rows := []*Schedule{
+		{CampaignID: 1, Position: 1},
+		{CampaignID: 2, Position: 0},
+		{CampaignID: 1, Position: 0},
+	}
+	assert.Nil(t, repository.SaveSchedules(context.Background(), rows))
+	assert.Nil(t, repository.SaveSchedules(context.Background(), nil))
+	assert.Equal(t, []int{1, 2, 3}, []int{rows[0].ID, rows[1].ID, rows[2].ID})
+
+	grouped, err := repository.GetSchedules(context.Background(), []int{1, 2, 3})
+	assert.Nil(t, err)
+	assert.Equal(t, map[int][]*Schedule{1: {rows[2], rows[0]}, 2: {rows[1]}}, grouped)
+
+	assert.Nil(t, repository.DeleteSchedulesByCampaignID(context.Background(), 1))
+	grouped, err = repository.GetSchedules(context.Background(), []int{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, map[int][]*Schedule{2: {rows[1]}}, grouped)
+}
@@ -0,0 +1,161 @@
+package schedule
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration *config.Configuration
+		Database      sqlx2.IDB
+	}
+	// IRepository interface
+	IRepository interface {
+		GetSchedules(
+			ctx context.Context,
+			campaignIDs []int,
+		) (map[int][]*Schedule, error)
+		SaveSchedules(
+			ctx context.Context,
+			s []*Schedule,
+		) error
+		SaveSchedulesTx(
+			ctx context.Context,
+			tx *sqlx.Tx,
+			s []*Schedule,
+		) error
+		DeleteSchedulesByCampaignID(
+			ctx context.Context,
+			campaignID int,
+		) error
+	}
+	// Schedule structure of a single recurrence rule of a campaign.
+	// Day lists are stored as comma-separated values, times as HH:MM
+	Schedule struct {
+		ID           int    `json:"id"`
+		CampaignID   int    `json:"campaign_id"`
+		Position     int    `json:"position"`
+		DaysOfWeek   string `json:"days_of_week"`
+		DaysOfMonth  string `json:"days_of_month"`
+		WeeksOfMonth string `json:"weeks_of_month"`
+		StartTime    string `json:"start_time"`
+		EndTime      string `json:"end_time"`
+	}
+)
+
+// New returns new configured schedule repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration: configuration,
+		Database:      sqlx2.New(configuration),
+	}
+}
+
+// GetSchedules returns the schedules of the campaigns grouped by campaign id
+// and ordered by their position
+func (repository *Repository) GetSchedules(
+	ctx context.Context,
+	campaignIDs []int,
+) (map[int][]*Schedule, error) {
+
+	campaignsSchedules := make(map[int][]*Schedule)
+	if len(campaignIDs) == 0 {
+		return campaignsSchedules, nil
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM campaign_schedule WHERE campaign_id IN (?) ORDER BY campaign_id, position", campaignIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	for result.Next() {
+		var schedule Schedule
+		err := result.StructScan(&schedule)
+		if err != nil {
+			return nil, err
+		}
+		campaignsSchedules[schedule.CampaignID] = append(campaignsSchedules[schedule.CampaignID], &schedule)
+	}
+
+	return campaignsSchedules, result.Err()
+}
+
+// SaveSchedules saves the schedules in a single transaction
+func (repository *Repository) SaveSchedules(
+	ctx context.Context,
+	schedules []*Schedule,
+) error {
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+
+	err = repository.SaveSchedulesTx(ctx, tx, schedules)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveSchedulesTx saves the schedules in the transaction
+func (repository *Repository) SaveSchedulesTx(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	schedules []*Schedule,
+) error {
+	for _, schedule := range schedules {
+		vals := map[string]interface{}{
+			"campaign_id":    schedule.CampaignID,
+			"position":       schedule.Position,
+			"days_of_week":   schedule.DaysOfWeek,
+			"days_of_month":  schedule.DaysOfMonth,
+			"weeks_of_month": schedule.WeeksOfMonth,
+			"start_time":     schedule.StartTime,
+			"end_time":       schedule.EndTime,
+		}
+		id, err := dialect.For(repository.Configuration).InsertID(ctx, tx, "INSERT INTO campaign_schedule (campaign_id, position, days_of_week, days_of_month, weeks_of_month, start_time, end_time) VALUES "+
+			"(:campaign_id, :position, :days_of_week, :days_of_month, :weeks_of_month, :start_time, :end_time)", vals)
+		if err != nil {
+			return err
+		}
+		schedule.ID = int(id)
+	}
+
+	return nil
+}
+
+// DeleteSchedulesByCampaignID deletes all schedules of the campaign
+func (repository *Repository) DeleteSchedulesByCampaignID(
+	ctx context.Context,
+	campaignID int,
+) error {
+	var query = "DELETE FROM campaign_schedule WHERE campaign_id=:campaign_id"
+
+	_, err := repository.Database.NamedExecContext(ctx, query,
+		map[string]interface{}{
+			"campaign_id": campaignID,
+		})
+
+	return err
+}
@@ -0,0 +1,144 @@
+package segment
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryRepository in-memory segment repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex      sync.RWMutex
+	segments   map[int]Segment
+	rules      map[int][]Rule
+	lastID     int
+	lastRuleID int
+}
+
+// NewMemory returns new empty in-memory segment repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		segments: make(map[int]Segment),
+		rules:    make(map[int][]Rule),
+	}
+}
+
+// GetSegments returns a page of segments ordered by id, all of them when the
+// segment id is not set
+func (repository *MemoryRepository) GetSegments(
+	ctx context.Context,
+	segmentID int,
+	records int,
+	page int,
+) ([]Segment, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	segments := repository.filter(segmentID)
+	from := records * page
+	if records <= 0 || from < 0 || from >= len(segments) {
+		return make([]Segment, 0), nil
+	}
+	to := from + records
+	if to > len(segments) {
+		to = len(segments)
+	}
+	return segments[from:to], nil
+}
+
+// GetSegmentsCount returns the count of segments
+func (repository *MemoryRepository) GetSegmentsCount(
+	ctx context.Context,
+	segmentID int,
+) (int, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return len(repository.filter(segmentID)), nil
+}
+
+// GetRules returns the rules of the segments grouped by segment id and
+// ordered by their position
+func (repository *MemoryRepository) GetRules(
+	ctx context.Context,
+	segmentIDs []int,
+) (map[int][]*Rule, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	segmentsRules := make(map[int][]*Rule)
+	for _, id := range segmentIDs {
+		rules := repository.rules[id]
+		if len(rules) == 0 {
+			continue
+		}
+		segmentRules := make([]*Rule, len(rules))
+		for idx := range rules {
+			rule := rules[idx]
+			segmentRules[idx] = &rule
+		}
+		sort.SliceStable(segmentRules, func(i, j int) bool { return segmentRules[i].Position < segmentRules[j].Position })
+		segmentsRules[id] = segmentRules
+	}
+	return segmentsRules, nil
+}
+
+// SaveSegment creates or, when the id is set, updates the segment and
+// replaces its rules
+func (repository *MemoryRepository) SaveSegment(
+	ctx context.Context,
+	s *Segment,
+	rules []*Rule,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if s.ID == 0 {
+		repository.lastID++
+		s.ID = repository.lastID
+		repository.segments[s.ID] = *s
+	} else if stored, ok := repository.segments[s.ID]; ok {
+		stored.Name = s.Name
+		stored.Description = s.Description
+		stored.Changed = s.Changed
+		stored.Changedby = s.Changedby
+		repository.segments[s.ID] = stored
+	}
+
+	segmentRules := make([]Rule, len(rules))
+	for idx, rule := range rules {
+		repository.lastRuleID++
+		rule.ID = repository.lastRuleID
+		rule.SegmentID = s.ID
+		segmentRules[idx] = *rule
+	}
+	repository.rules[s.ID] = segmentRules
+	return nil
+}
+
+// DeleteSegment deletes the segment and its rules
+func (repository *MemoryRepository) DeleteSegment(
+	ctx context.Context,
+	segmentID int,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	delete(repository.segments, segmentID)
+	delete(repository.rules, segmentID)
+	return nil
+}
+
+// filter returns the segments ordered by id, only the segment with the id
+// when it is set
+func (repository *MemoryRepository) filter(segmentID int) []Segment {
+	segments := make([]Segment, 0)
+	for _, s := range repository.segments {
+		if segmentID <= 0 || s.ID == segmentID {
+			segments = append(segments, s)
+		}
+	}
+	sort.Slice(segments, func(i, j int) bool { return segments[i].ID < segments[j].ID })
+	return segments
+}
@@ -0,0 +1,47 @@
+package segment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	var repository IRepository = NewMemory()
+	first := &Segment{Name: "first", Added: 10, Addedby: "admin"}
+	rules := []*Rule{
+		{Position: 1, Attribute: "country", Operator: "eq", Value: "EE"},
+		{Position: 0, Attribute: "age", Operator: "gte", Value: "18"},
+	}
+	assert.Nil(t, repository.SaveSegment(context.Background(), first, rules))
+	assert.Nil(t, repository.SaveSegment(context.Background(), &Segment{Name: "second"}, nil))
+	assert.Equal(t, 1, first.ID)
+	assert.Equal(t, 1, rules[0].SegmentID)
+
+	segments, err := repository.GetSegments(context.Background(), 0, 1, 1)
+	assert.Nil(t, err)
+	assert.Len(t, segments, 1)
+	assert.Equal(t, "second", segments[0].Name)
+	count, err := repository.GetSegmentsCount(context.Background(), 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, count)
+
+	segmentsRules, err := repository.GetRules(context.Background(), []int{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, map[int][]*Rule{1: {rules[1], rules[0]}}, segmentsRules)
+
+	update := &Segment{ID: 1, Name: "renamed", Changed: 20, Changedby: "editor"}
+	assert.Nil(t, repository.SaveSegment(context.Background(), update, []*Rule{{Attribute: "vip", Operator: "eq", Value: "1"}}))
+	segments, _ = repository.GetSegments(context.Background(), 1, 20, 0)
+	assert.Equal(t, []Segment{{ID: 1, Name: "renamed", Added: 10, Addedby: "admin", Changed: 20, Changedby: "editor"}}, segments)
+	segmentsRules, _ = repository.GetRules(context.Background(), []int{1})
+	assert.Len(t, segmentsRules[1], 1)
+	assert.Equal(t, 3, segmentsRules[1][0].ID)
+
+	assert.Nil(t, repository.DeleteSegment(context.Background(), 1))
+	count, _ = repository.GetSegmentsCount(context.Background(), 1)
+	assert.Equal(t, 0, count)
+	segmentsRules, _ = repository.GetRules(context.Background(), []int{1})
+	assert.Empty(t, segmentsRules)
+}
@@ -0,0 +1,253 @@
+package segment
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration *config.Configuration
+		Database      sqlx2.IDB
+	}
+	// IRepository interface
+	IRepository interface {
+		GetSegments(
+			ctx context.Context,
+			segmentID int,
+			records int,
+			page int,
+		) ([]Segment, error)
+		GetSegmentsCount(
+			ctx context.Context,
+			segmentID int,
+		) (int, error)
+		GetRules(
+			ctx context.Context,
+			segmentIDs []int,
+		) (map[int][]*Rule, error)
+		SaveSegment(
+			ctx context.Context,
+			s *Segment,
+			rules []*Rule,
+		) error
+		DeleteSegment(
+			ctx context.Context,
+			segmentID int,
+		) error
+	}
+	// Segment structure of a customer segment
+	Segment struct {
+		ID          int    `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Added       int64  `json:"added"`
+		Addedby     string `json:"addedby"`
+		Changed     int64  `json:"changed"`
+		Changedby   string `json:"changedby"`
+	}
+	// Rule structure of a single condition over a customer attribute.
+	// A customer belongs to the segment when all its rules match
+	Rule struct {
+		ID        int    `json:"id"`
+		SegmentID int    `json:"segment_id"`
+		Position  int    `json:"position"`
+		Attribute string `json:"attribute"`
+		Operator  string `json:"operator"`
+		Value     string `json:"value"`
+	}
+)
+
+// New returns new configured segment repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration: configuration,
+		Database:      sqlx2.New(configuration),
+	}
+}
+
+// GetSegments returns a page of segments, all of them when the segment id is not set
+func (repository *Repository) GetSegments(
+	ctx context.Context,
+	segmentID int,
+	records int,
+	page int,
+) ([]Segment, error) {
+
+	var query string
+	var values []interface{}
+	if segmentID > 0 {
+		query = "SELECT * FROM customer_segment WHERE id = ?"
+		values = append(values, segmentID)
+	} else {
+		query = "SELECT * FROM customer_segment"
+	}
+	pagination, limits := dialect.For(repository.Configuration).Paginate(records, page)
+	values = append(values, limits...)
+
+	result, err := repository.Database.QueryxContext(ctx, query+" ORDER BY id"+pagination, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	segments := make([]Segment, 0)
+	for result.Next() {
+		var segment Segment
+		err := result.StructScan(&segment)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, result.Err()
+}
+
+// GetSegmentsCount returns the count of segments
+func (repository *Repository) GetSegmentsCount(
+	ctx context.Context,
+	segmentID int,
+) (int, error) {
+
+	var query = "SELECT COUNT(*) FROM customer_segment"
+	var values []interface{}
+	if segmentID > 0 {
+		query += " WHERE id = ?"
+		values = append(values, segmentID)
+	}
+
+	result, err := repository.Database.QueryRowxContext(ctx, query, values...)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = result.Scan(&count)
+	return count, err
+}
+
+// GetRules returns the rules of the segments grouped by segment id and
+// ordered by their position
+func (repository *Repository) GetRules(
+	ctx context.Context,
+	segmentIDs []int,
+) (map[int][]*Rule, error) {
+
+	segmentsRules := make(map[int][]*Rule)
+	if len(segmentIDs) == 0 {
+		return segmentsRules, nil
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM customer_segment_rule WHERE segment_id IN (?) ORDER BY segment_id, position", segmentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	for result.Next() {
+		var rule Rule
+		err := result.StructScan(&rule)
+		if err != nil {
+			return nil, err
+		}
+		segmentsRules[rule.SegmentID] = append(segmentsRules[rule.SegmentID], &rule)
+	}
+
+	return segmentsRules, result.Err()
+}
+
+// SaveSegment creates or, when the id is set, updates the segment and
+// replaces its rules in a single transaction
+func (repository *Repository) SaveSegment(
+	ctx context.Context,
+	s *Segment,
+	rules []*Rule,
+) error {
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+
+	vals := map[string]interface{}{
+		"id":          s.ID,
+		"name":        s.Name,
+		"description": s.Description,
+		"added":       s.Added,
+		"addedby":     s.Addedby,
+		"changed":     s.Changed,
+		"changedby":   s.Changedby,
+	}
+	if s.ID == 0 {
+		id, err := dialect.For(repository.Configuration).InsertID(ctx, tx, "INSERT INTO customer_segment (name, description, added, addedby, changed, changedby) VALUES "+
+			"(:name, :description, :added, :addedby, :changed, :changedby)", vals)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+		s.ID = int(id)
+	} else {
+		_, err := tx.NamedExecContext(ctx, "UPDATE customer_segment SET name=:name, description=:description, changed=:changed, changedby=:changedby WHERE id=:id", vals)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+		_, err = tx.NamedExecContext(ctx, "DELETE FROM customer_segment_rule WHERE segment_id=:id", vals)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+	}
+
+	for _, rule := range rules {
+		rule.SegmentID = s.ID
+		vals := map[string]interface{}{
+			"segment_id": rule.SegmentID,
+			"position":   rule.Position,
+			"attribute":  rule.Attribute,
+			"operator":   rule.Operator,
+			"value":      rule.Value,
+		}
+		id, err := dialect.For(repository.Configuration).InsertID(ctx, tx, "INSERT INTO customer_segment_rule (segment_id, position, attribute, operator, value) VALUES "+
+			"(:segment_id, :position, :attribute, :operator, :value)", vals)
+		if err != nil {
+			log.Error(tx.Rollback())
+			return err
+		}
+		rule.ID = int(id)
+	}
+
+	return tx.Commit()
+}
+
+// DeleteSegment deletes the segment and its rules
+func (repository *Repository) DeleteSegment(
+	ctx context.Context,
+	segmentID int,
+) error {
+
+	vals := map[string]interface{}{
+		"id": segmentID,
+	}
+	_, err := repository.Database.NamedExecContext(ctx, "DELETE FROM customer_segment_rule WHERE segment_id=:id", vals)
+	if err != nil {
+		return err
+	}
+	_, err = repository.Database.NamedExecContext(ctx, "DELETE FROM customer_segment WHERE id=:id", vals)
+
+	return err
+}
@@ -0,0 +1,102 @@
+package segment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	databaseMock struct{}
+)
+
+func (d *databaseMock) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return nil, nil
+}
+
+var queryXq string
+var queryXa []interface{}
+
+func (d *databaseMock) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	queryXq = query
+	queryXa = args
+	return nil, errors.New("1003")
+}
+
+func (d *databaseMock) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
+	return nil, nil
+}
+
+var namedExecQ []string
+var namedExecA interface{}
+
+func (d *databaseMock) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	namedExecQ = append(namedExecQ, query)
+	namedExecA = arg
+	r := new(sql.Result)
+
+	return *r, nil
+}
+func (d *databaseMock) Close() error { return nil }
+
+func TestSegment_New(t *testing.T) {
+	r := New(&config.Configuration{})
+	assert.IsType(t, &Repository{}, r)
+}
+
+func TestSegment_GetSegments(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	_, err := r.GetSegments(context.Background(), 0, 20, 1)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM customer_segment ORDER BY id LIMIT ? OFFSET ?", queryXq)
+	assert.Equal(t, []interface{}{20, 20}, queryXa)
+
+	_, err = r.GetSegments(context.Background(), 3, 20, 0)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM customer_segment WHERE id = ? ORDER BY id LIMIT ? OFFSET ?", queryXq)
+	assert.Equal(t, []interface{}{3, 20, 0}, queryXa)
+}
+
+func TestSegment_GetRules_NoSegments(t *testing.T) {
+	queryXq = ""
+	r := &Repository{Database: &databaseMock{}}
+
+	rules, err := r.GetRules(context.Background(), []int{})
+
+	assert.Nil(t, err)
+	assert.Empty(t, rules)
+	assert.Equal(t, "", queryXq)
+}
+
+func TestSegment_GetRules_SingleQuery(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	_, err := r.GetRules(context.Background(), []int{1, 2})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM customer_segment_rule WHERE segment_id IN (?, ?) ORDER BY segment_id, position", queryXq)
+	assert.Equal(t, []interface{}{1, 2}, queryXa)
+}
+
+func TestSegment_DeleteSegment(t *testing.T) {
+	namedExecQ = nil
+	r := &Repository{Database: &databaseMock{}}
+
+	err := r.DeleteSegment(context.Background(), 7)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"DELETE FROM customer_segment_rule WHERE segment_id=:id",
+		"DELETE FROM customer_segment WHERE id=:id",
+	}, namedExecQ)
+	assert.Equal(t, map[string]interface{}{"id": 7}, namedExecA)
+}
@@ -0,0 +1,64 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+)
+
+// MemoryRepository in-memory session repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex    sync.RWMutex
+	sessions map[string]Session
+	lastID   int
+}
+
+// NewMemory returns new empty in-memory session repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		sessions: make(map[string]Session),
+	}
+}
+
+// AddSession stores the session, replacing the session with the same key,
+// and returns it with its id
+func (repository *MemoryRepository) AddSession(s Session) Session {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if stored, ok := repository.sessions[s.Key]; ok {
+		s.ID = stored.ID
+	} else {
+		repository.lastID++
+		s.ID = repository.lastID
+	}
+	repository.sessions[s.Key] = s
+	return s
+}
+
+// GetSessionByKey returns the session if it exists, sql.ErrNoRows otherwise
+func (repository *MemoryRepository) GetSessionByKey(ctx context.Context, sessionKey string) (Session, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	s, ok := repository.sessions[sessionKey]
+	if !ok {
+		return Session{}, sql.ErrNoRows
+	}
+	return s, nil
+}
+
+// ExtendSession moves the expiry of the session forward, a session already
+// expiring later keeps its expiry
+func (repository *MemoryRepository) ExtendSession(ctx context.Context, sessionKey string, expires int64) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	s, ok := repository.sessions[sessionKey]
+	if ok && s.Expires.Int64 < expires {
+		s.Expires = sql.NullInt64{Int64: expires, Valid: true}
+		repository.sessions[sessionKey] = s
+	}
+	return nil
+}
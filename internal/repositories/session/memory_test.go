@@ -0,0 +1,33 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	repository := NewMemory()
+	first := repository.AddSession(Session{User: "admin", Key: "key", Expires: sql.NullInt64{Int64: 10, Valid: true}})
+	assert.Equal(t, 1, first.ID)
+	replaced := repository.AddSession(Session{User: "admin", Key: "key", Expires: sql.NullInt64{Int64: 20, Valid: true}})
+	assert.Equal(t, 1, replaced.ID)
+
+	var sessions IRepository = repository
+	s, err := sessions.GetSessionByKey(context.Background(), "key")
+	assert.Nil(t, err)
+	assert.Equal(t, replaced, s)
+
+	assert.Nil(t, sessions.ExtendSession(context.Background(), "key", 30))
+	s, _ = sessions.GetSessionByKey(context.Background(), "key")
+	assert.Equal(t, int64(30), s.Expires.Int64)
+	assert.Nil(t, sessions.ExtendSession(context.Background(), "key", 25))
+	s, _ = sessions.GetSessionByKey(context.Background(), "key")
+	assert.Equal(t, int64(30), s.Expires.Int64)
+
+	s, err = sessions.GetSessionByKey(context.Background(), "unknown")
+	assert.Equal(t, sql.ErrNoRows, err)
+	assert.Equal(t, 0, s.ID)
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	session "github.com/zdarovich/promotion-api/internal/repositories/session"
 )
@@ -12,20 +14,34 @@ type IRepository struct {
 	mock.Mock
 }
 
-// GetSessionByKey provides a mock function with given fields: sessionKey
-func (_m *IRepository) GetSessionByKey(sessionKey string) (session.Session, error) {
-	ret := _m.Called(sessionKey)
+// ExtendSession provides a mock function with given fields: ctx, sessionKey, expires
+func (_m *IRepository) ExtendSession(ctx context.Context, sessionKey string, expires int64) error {
+	ret := _m.Called(ctx, sessionKey, expires)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64) error); ok {
+		r0 = rf(ctx, sessionKey, expires)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetSessionByKey provides a mock function with given fields: ctx, sessionKey
+func (_m *IRepository) GetSessionByKey(ctx context.Context, sessionKey string) (session.Session, error) {
+	ret := _m.Called(ctx, sessionKey)
 
 	var r0 session.Session
-	if rf, ok := ret.Get(0).(func(string) session.Session); ok {
-		r0 = rf(sessionKey)
+	if rf, ok := ret.Get(0).(func(context.Context, string) session.Session); ok {
+		r0 = rf(ctx, sessionKey)
 	} else {
 		r0 = ret.Get(0).(session.Session)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(sessionKey)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, sessionKey)
 	} else {
 		r1 = ret.Error(1)
 	}
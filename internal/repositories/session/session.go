@@ -1,6 +1,7 @@
 package session
 
 import (
+	"context"
 	"database/sql"
 	"github.com/zdarovich/promotion-api/internal/config"
 	"github.com/zdarovich/promotion-api/internal/database/mysql"
@@ -14,7 +15,8 @@ type (
 	}
 	// IRepository interface
 	IRepository interface {
-		GetSessionByKey(sessionKey string) (Session, error)
+		GetSessionByKey(ctx context.Context, sessionKey string) (Session, error)
+		ExtendSession(ctx context.Context, sessionKey string, expires int64) error
 	}
 	// Session structure of the session
 	Session struct {
@@ -37,10 +39,10 @@ func New(configuration *config.Configuration) IRepository {
 }
 
 // GetSessionByKey returns the session if it exists
-func (repository *Repository) GetSessionByKey(sessionKey string) (Session, error) {
+func (repository *Repository) GetSessionByKey(ctx context.Context, sessionKey string) (Session, error) {
 
 	query := "SELECT `id`, `user`, `key`, `device`, `started`, `expires` FROM `session` WHERE `key` = ?"
-	result, err := repository.Database.QueryRow(query, sessionKey)
+	result, err := repository.Database.QueryRowContext(ctx, query, sessionKey)
 
 	var session Session
 
@@ -63,3 +65,13 @@ func (repository *Repository) GetSessionByKey(sessionKey string) (Session, error
 
 	return session, nil
 }
+
+// ExtendSession moves the expiry of the session forward, a session already
+// expiring later keeps its expiry
+func (repository *Repository) ExtendSession(ctx context.Context, sessionKey string, expires int64) error {
+
+	query := "UPDATE `session` SET `expires` = ? WHERE `key` = ? AND `expires` < ?"
+	_, err := repository.Database.ExecContext(ctx, query, expires, sessionKey, expires)
+
+	return err
+}
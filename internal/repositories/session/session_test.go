@@ -1,6 +1,8 @@
 package session
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/config"
@@ -31,7 +33,7 @@ var queryCalled bool = false
 var failQuery bool = false
 var queryResult mysql.IROWS
 
-func (m *MockDB) Query(query string, args ...interface{}) (mysql.IROWS, error) {
+func (m *MockDB) QueryContext(ctx context.Context, query string, args ...interface{}) (mysql.IROWS, error) {
 	queryCalled = true
 	if failQuery {
 		return nil, errors.New(errorcodes.CodeDatabase)
@@ -43,7 +45,7 @@ var queryRowCalled bool = false
 var failQueryRow bool = false
 var queryRowResult mysql.IROW
 
-func (m *MockDB) QueryRow(query string, args ...interface{}) (mysql.IROW, error) {
+func (m *MockDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) (mysql.IROW, error) {
 	queryRowCalled = true
 	if failQueryRow {
 		return nil, errors.New(errorcodes.CodeDatabase)
@@ -51,6 +53,19 @@ func (m *MockDB) QueryRow(query string, args ...interface{}) (mysql.IROW, error)
 	return queryRowResult, nil
 }
 
+var execCalled bool = false
+var failExec bool = false
+var execArgs []interface{}
+
+func (m *MockDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	execCalled = true
+	execArgs = args
+	if failExec {
+		return nil, errors.New(errorcodes.CodeDatabase)
+	}
+	return nil, nil
+}
+
 var nextCalled bool = false
 
 func (m *MockRows) Next() bool {
@@ -107,7 +122,7 @@ func Test_GetSessionByKey(t *testing.T) {
 		Database:      new(MockDB),
 	}
 
-	result, err := session.GetSessionByKey("123")
+	result, err := session.GetSessionByKey(context.Background(), "123")
 
 	assert.True(t, queryRowCalled)
 	assert.True(t, rowScanCalled)
@@ -130,7 +145,7 @@ func Test_GetSessionByKeyConnectionFail(t *testing.T) {
 		Database:      new(MockDB),
 	}
 
-	_, err := session.GetSessionByKey("123")
+	_, err := session.GetSessionByKey(context.Background(), "123")
 
 	assert.True(t, queryRowCalled)
 	assert.False(t, rowScanCalled)
@@ -152,9 +167,31 @@ func Test_GetSessionByKeyScanFail(t *testing.T) {
 		Database:      new(MockDB),
 	}
 
-	_, err := session.GetSessionByKey("123")
+	_, err := session.GetSessionByKey(context.Background(), "123")
 
 	assert.True(t, queryRowCalled)
 	assert.True(t, rowScanCalled)
 	assert.NotNil(t, err)
 }
+
+func Test_ExtendSession(t *testing.T) {
+
+	execCalled = false
+	failExec = false
+
+	session := &Repository{
+		Configuration: &config.Configuration{},
+		Database:      new(MockDB),
+	}
+
+	err := session.ExtendSession(context.Background(), "123", 1614600000)
+
+	assert.True(t, execCalled)
+	assert.Equal(t, []interface{}{int64(1614600000), "123", int64(1614600000)}, execArgs)
+	assert.Nil(t, err)
+
+	failExec = true
+	err = session.ExtendSession(context.Background(), "123", 1614600000)
+
+	assert.NotNil(t, err)
+}
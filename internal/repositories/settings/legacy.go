@@ -0,0 +1,73 @@
+package settings
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+)
+
+// FromAttributes converts the legacy attributes of a campaign to settings
+func FromAttributes(campaignID int, attrs []*attributes.Attribute) *Settings {
+	s := &Settings{CampaignID: campaignID}
+
+	byName := make(map[string]*attributes.Attribute, len(attrs))
+	for _, attr := range attrs {
+		byName[attr.Name] = attr
+	}
+
+	v := reflect.ValueOf(s).Elem()
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		attr, ok := byName[t.Field(i).Tag.Get("attr")]
+		if !ok {
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Int:
+			field.SetInt(int64(attr.ValueInt))
+		case reflect.Float64:
+			field.SetFloat(attr.ValueDouble)
+		case reflect.String:
+			field.SetString(attr.ValueText)
+		case reflect.Slice:
+			field.Set(reflect.ValueOf(splitInts(attr.ValueText)))
+		}
+	}
+
+	for _, list := range ProductLists {
+		if attr, ok := byName[list]; ok && attr.ValueText != "" {
+			if s.Products == nil {
+				s.Products = make(map[string][]string)
+			}
+			s.Products[list] = strings.Split(attr.ValueText, ",")
+		}
+	}
+	for _, list := range SubsidyLists {
+		if attr, ok := byName[list]; ok && attr.ValueText != "" {
+			if s.Subsidies == nil {
+				s.Subsidies = make(map[string][]float64)
+			}
+			for _, el := range strings.Split(attr.ValueText, ",") {
+				f, _ := strconv.ParseFloat(strings.TrimSpace(el), 64)
+				s.Subsidies[list] = append(s.Subsidies[list], f)
+			}
+		}
+	}
+
+	return s
+}
+
+func splitInts(value string) []int {
+	var result []int
+	for _, el := range strings.Split(value, ",") {
+		i, err := strconv.Atoi(strings.TrimSpace(el))
+		if err != nil {
+			continue
+		}
+		result = append(result, i)
+	}
+	return result
+}
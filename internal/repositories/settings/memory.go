@@ -0,0 +1,150 @@
+package settings
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+)
+
+// MemoryRepository in-memory settings repository, safe for concurrent use.
+// Campaigns without typed settings are read from the attributes repository
+type MemoryRepository struct {
+	mutex               sync.RWMutex
+	settings            map[int]Settings
+	AttributeRepository attributes.IRepository
+}
+
+// NewMemory returns new empty in-memory settings repository reading the
+// legacy settings from the attributes repository
+func NewMemory(attributeRepository attributes.IRepository) *MemoryRepository {
+
+	return &MemoryRepository{
+		settings:            make(map[int]Settings),
+		AttributeRepository: attributeRepository,
+	}
+}
+
+// GetSettings returns the settings of the campaigns. Campaigns that have not
+// been migrated to the typed schema yet are read from their attributes
+func (repository *MemoryRepository) GetSettings(
+	ctx context.Context,
+	campaignIDs []int,
+) (map[int]*Settings, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	campaignsSettings := make(map[int]*Settings)
+	legacy := make([]int, 0)
+	for _, id := range campaignIDs {
+		s, ok := repository.settings[id]
+		if !ok {
+			legacy = append(legacy, id)
+			continue
+		}
+		campaignsSettings[id] = copySettings(s)
+	}
+
+	if len(legacy) == 0 {
+		return campaignsSettings, nil
+	}
+	attrs, err := repository.AttributeRepository.GetAttributes(ctx, legacy)
+	if err != nil {
+		return nil, err
+	}
+	for id, campaignAttrs := range attrs {
+		if len(campaignAttrs) == 0 {
+			continue
+		}
+		campaignsSettings[id] = FromAttributes(id, campaignAttrs)
+	}
+
+	return campaignsSettings, nil
+}
+
+// SaveSettings replaces the settings of the campaign
+func (repository *MemoryRepository) SaveSettings(
+	ctx context.Context,
+	s *Settings,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	repository.settings[s.CampaignID] = *copySettings(*s)
+	return nil
+}
+
+// SaveSettingsTx replaces the settings like SaveSettings, there is no transaction
+func (repository *MemoryRepository) SaveSettingsTx(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	s *Settings,
+) error {
+	return repository.SaveSettings(ctx, s)
+}
+
+// MigrateSettings saves the settings of a campaign unless the campaign
+// already has typed settings. Returns whether the settings were saved
+func (repository *MemoryRepository) MigrateSettings(
+	ctx context.Context,
+	s *Settings,
+) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if _, ok := repository.settings[s.CampaignID]; ok {
+		return false, nil
+	}
+	repository.settings[s.CampaignID] = *copySettings(*s)
+	return true, nil
+}
+
+// DeleteSettingsByCampaignID deletes the typed and the legacy settings of the campaign
+func (repository *MemoryRepository) DeleteSettingsByCampaignID(
+	ctx context.Context,
+	campaignID int,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	delete(repository.settings, campaignID)
+	return repository.AttributeRepository.DeleteAttributesByCampaignID(ctx, campaignID)
+}
+
+// copySettings returns a copy of the settings as they are read from the
+// database: only the known non-empty lists and the id lists ordered by id
+func copySettings(s Settings) *Settings {
+	c := s
+	c.Products = nil
+	for _, list := range ProductLists {
+		if len(s.Products[list]) == 0 {
+			continue
+		}
+		if c.Products == nil {
+			c.Products = make(map[string][]string)
+		}
+		c.Products[list] = append([]string(nil), s.Products[list]...)
+	}
+	c.Subsidies = nil
+	for _, list := range SubsidyLists {
+		if len(s.Subsidies[list]) == 0 {
+			continue
+		}
+		if c.Subsidies == nil {
+			c.Subsidies = make(map[string][]float64)
+		}
+		c.Subsidies[list] = append([]float64(nil), s.Subsidies[list]...)
+	}
+	for _, table := range idTables {
+		ids := table.ids(&c)
+		if len(*ids) == 0 {
+			*ids = nil
+			continue
+		}
+		*ids = append([]int(nil), *ids...)
+		sort.Ints(*ids)
+	}
+	return &c
+}
@@ -0,0 +1,47 @@
+package settings
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	attrs := attributes.NewMemory()
+	var repository IRepository = NewMemory(attrs)
+	assert.Nil(t, attrs.SaveAttributes(context.Background(), []*attributes.Attribute{
+		{ObjID: 2, ObjTable: "campaign", Name: "priority", Type: attributes.INT, ValueInt: 3},
+	}))
+
+	s := &Settings{
+		CampaignID:     1,
+		Priority:       5,
+		Products:       map[string][]string{ListPurchasedProducts: {"milk", "cookie"}, ListAwardedProducts: {}},
+		CombinableWith: []int{3, 2},
+	}
+	assert.Nil(t, repository.SaveSettings(context.Background(), s))
+	s.Products[ListPurchasedProducts][0] = "changed"
+
+	campaignsSettings, err := repository.GetSettings(context.Background(), []int{1, 2, 3})
+	assert.Nil(t, err)
+	assert.Len(t, campaignsSettings, 2)
+	assert.Equal(t, map[string][]string{ListPurchasedProducts: {"milk", "cookie"}}, campaignsSettings[1].Products)
+	assert.Nil(t, campaignsSettings[1].Subsidies)
+	assert.Equal(t, []int{2, 3}, campaignsSettings[1].CombinableWith)
+	assert.Equal(t, 3, campaignsSettings[2].Priority)
+
+	migrated, err := repository.MigrateSettings(context.Background(), &Settings{CampaignID: 1})
+	assert.Nil(t, err)
+	assert.False(t, migrated)
+	migrated, err = repository.MigrateSettings(context.Background(), &Settings{CampaignID: 2, Priority: 4})
+	assert.Nil(t, err)
+	assert.True(t, migrated)
+	campaignsSettings, _ = repository.GetSettings(context.Background(), []int{2})
+	assert.Equal(t, 4, campaignsSettings[2].Priority)
+
+	assert.Nil(t, repository.DeleteSettingsByCampaignID(context.Background(), 2))
+	campaignsSettings, _ = repository.GetSettings(context.Background(), []int{2})
+	assert.Empty(t, campaignsSettings)
+}
@@ -0,0 +1,513 @@
+package settings
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+)
+
+// Names of the product and subsidy lists, equal to the legacy attribute names
+const (
+	ListPurchasedProducts             = "purchasedProducts"
+	ListAwardedProducts               = "awardedProducts"
+	ListExcludedProducts              = "excludedProducts"
+	ListPercentageOffExcludedProducts = "percentageOffExcludedProducts"
+	ListPercentageOffIncludedProducts = "percentageOffIncludedProducts"
+	ListSumOffExcludedProducts        = "sumOffExcludedProducts"
+	ListSumOffIncludedProducts        = "sumOffIncludedProducts"
+	ListPurchasedProductSubsidies     = "purchasedProductSubsidies"
+	ListAwardedProductSubsidies       = "awardedProductSubsidies"
+)
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration       *config.Configuration
+		Database            sqlx2.IDB
+		AttributeRepository attributes.IRepository
+	}
+	// IRepository interface
+	IRepository interface {
+		GetSettings(
+			ctx context.Context,
+			campaignIDs []int,
+		) (map[int]*Settings, error)
+		SaveSettings(
+			ctx context.Context,
+			s *Settings,
+		) error
+		SaveSettingsTx(
+			ctx context.Context,
+			tx *sqlx.Tx,
+			s *Settings,
+		) error
+		MigrateSettings(
+			ctx context.Context,
+			s *Settings,
+		) (bool, error)
+		DeleteSettingsByCampaignID(
+			ctx context.Context,
+			campaignID int,
+		) error
+	}
+	// Settings structure of the typed campaign settings. Scalar settings are
+	// columns of campaign_settings, lists are stored in child tables. The attr
+	// tag holds the name of the legacy attribute the setting was stored in
+	Settings struct {
+		CampaignID                                           int                  `json:"campaign_id"`
+		AwardedBrandID                                       int                  `json:"awarded_brand_id" attr:"awardedBrandID"`
+		DiscountForOneLine                                   int                  `json:"discount_for_one_line" attr:"discountForOneLine"`
+		RequiredCouponID                                     string               `json:"required_coupon_id" attr:"requiredCouponID"`
+		RequiredCouponCode                                   string               `json:"required_coupon_code" attr:"requiredCouponCode"`
+		AwardedAmount                                        int                  `json:"awarded_amount" attr:"awardedAmount"`
+		PurchasedProductCategoryID                           int                  `json:"purchased_product_category_id" attr:"purchasedProductCategoryID"`
+		AwardedProductCategoryID                             int                  `json:"awarded_product_category_id" attr:"awardedProductCategoryID"`
+		MaximumPointsDiscount                                int                  `json:"maximum_points_discount" attr:"maximumPointsDiscount"`
+		CustomerCanUseOnlyOnce                               int                  `json:"customer_can_use_only_once" attr:"customerCanUseOnlyOnce"`
+		PriceAtLeast                                         int                  `json:"price_at_least" attr:"priceAtLeast"`
+		PriceAtMost                                          int                  `json:"price_at_most" attr:"priceAtMost"`
+		RequiresManagerOverride                              int                  `json:"requires_manager_override" attr:"requiresManagerOverride"`
+		SumOffMatchingItems                                  int                  `json:"sum_off_matching_items" attr:"sumOffMatchingItems"`
+		ExcludeDiscountedFromPercentageOffEntirePurchase     int                  `json:"exclude_discounted_from_percentage_off_entire_purchase" attr:"excludeDiscountedFromPercentageOffEntirePurchase"`
+		ExcludePromotionItemsFromPercentageOffEntirePurchase int                  `json:"exclude_promotion_items_from_percentage_off_entire_purchase" attr:"excludePromotionItemsFromPercentageOffEntirePurchase"`
+		ReasonID                                             int                  `json:"reason_id" attr:"reasonID"`
+		SpecialUnitPrice                                     int                  `json:"special_unit_price" attr:"specialUnitPrice"`
+		MaxItemsWithSpecialUnitPrice                         int                  `json:"max_items_with_special_unit_price" attr:"maxItemsWithSpecialUnitPrice"`
+		RedemptionLimit                                      int                  `json:"redemption_limit" attr:"redemptionLimit"`
+		StoreGroup                                           string               `json:"store_group" attr:"storeGroup"`
+		CanBeAppliedManuallyMultipleTimes                    int                  `json:"can_be_applied_manually_multiple_times" attr:"canBeAppliedManuallyMultipleTimes"`
+		PurchasedBrandID                                     int                  `json:"purchased_brand_id" attr:"purchasedBrandID"`
+		AwardedProductGroupID                                int                  `json:"awarded_product_group_id" attr:"awardedProductGroupID"`
+		LowestPriceItemIsAwarded                             int                  `json:"lowest_price_item_is_awarded" attr:"lowestPriceItemIsAwarded"`
+		PercentageOffMatchingItems                           int                  `json:"percentage_off_matching_items" attr:"percentageOffMatchingItems"`
+		PurchasedProductGroupID                              int                  `json:"purchased_product_group_id" attr:"purchasedProductGroupID"`
+		RewardPoints                                         int                  `json:"reward_points" attr:"rewardPoints"`
+		PercentageOffEntirePurchase                          int                  `json:"percentage_off_entire_purchase" attr:"percentageOffEntirePurchase"`
+		BundlePrice                                          float64              `json:"bundle_price" attr:"bundlePrice"`
+		BundleSumOff                                         float64              `json:"bundle_sum_off" attr:"bundleSumOff"`
+		BundlePercentageOff                                  float64              `json:"bundle_percentage_off" attr:"bundlePercentageOff"`
+		Priority                                             int                  `json:"priority" attr:"priority"`
+		Exclusive                                            int                  `json:"exclusive" attr:"exclusive"`
+		StackingGroup                                        string               `json:"stacking_group" attr:"stackingGroup"`
+		CustomerAllowlist                                    int                  `json:"customer_allowlist" attr:"customerAllowlist"`
+		Products                                             map[string][]string  `json:"-"`
+		Subsidies                                            map[string][]float64 `json:"-"`
+		StoreRegionIDs                                       []int                `json:"-" attr:"storeRegionIDs"`
+		CustomerGroupIDs                                     []int                `json:"-" attr:"customerGroupIDs"`
+		CustomerSegmentIDs                                   []int                `json:"-" attr:"customerSegmentIDs"`
+		CombinableWith                                       []int                `json:"-" attr:"combinableWith"`
+	}
+	// product structure of a product of a campaign product list
+	product struct {
+		CampaignID int    `json:"campaign_id"`
+		List       string `json:"list"`
+		Position   int    `json:"position"`
+		Product    string `json:"product"`
+	}
+	// subsidy structure of a subsidy of a campaign subsidy list
+	subsidy struct {
+		CampaignID int     `json:"campaign_id"`
+		List       string  `json:"list"`
+		Position   int     `json:"position"`
+		Subsidy    float64 `json:"subsidy"`
+	}
+	// idTable child table of a campaign id list
+	idTable struct {
+		name   string
+		column string
+		ids    func(s *Settings) *[]int
+	}
+)
+
+// Child tables of the id lists
+var idTables = []idTable{
+	{"campaign_store_region", "store_region_id", func(s *Settings) *[]int { return &s.StoreRegionIDs }},
+	{"campaign_customer_group", "customer_group_id", func(s *Settings) *[]int { return &s.CustomerGroupIDs }},
+	{"campaign_customer_segment", "customer_segment_id", func(s *Settings) *[]int { return &s.CustomerSegmentIDs }},
+	{"campaign_combinable", "combinable_campaign_id", func(s *Settings) *[]int { return &s.CombinableWith }},
+}
+
+// ProductLists names of the product lists
+var ProductLists = []string{
+	ListPurchasedProducts,
+	ListAwardedProducts,
+	ListExcludedProducts,
+	ListPercentageOffExcludedProducts,
+	ListPercentageOffIncludedProducts,
+	ListSumOffExcludedProducts,
+	ListSumOffIncludedProducts,
+}
+
+// SubsidyLists names of the subsidy lists
+var SubsidyLists = []string{
+	ListPurchasedProductSubsidies,
+	ListAwardedProductSubsidies,
+}
+
+// New returns new configured settings repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration:       configuration,
+		Database:            sqlx2.New(configuration),
+		AttributeRepository: attributes.New(configuration),
+	}
+}
+
+// GetSettings returns the settings of the campaigns. Campaigns that have not
+// been migrated to the typed schema yet are read from their attributes
+func (repository *Repository) GetSettings(
+	ctx context.Context,
+	campaignIDs []int,
+) (map[int]*Settings, error) {
+
+	campaignsSettings := make(map[int]*Settings)
+	if len(campaignIDs) == 0 {
+		return campaignsSettings, nil
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM campaign_settings WHERE campaign_id IN (?)", campaignIDs)
+	if err != nil {
+		return nil, err
+	}
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+	for result.Next() {
+		var s Settings
+		err := result.StructScan(&s)
+		if err != nil {
+			return nil, err
+		}
+		campaignsSettings[s.CampaignID] = &s
+	}
+	err = result.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	typed := make([]int, 0, len(campaignsSettings))
+	legacy := make([]int, 0)
+	for _, id := range campaignIDs {
+		if _, ok := campaignsSettings[id]; ok {
+			typed = append(typed, id)
+		} else {
+			legacy = append(legacy, id)
+		}
+	}
+
+	err = repository.getLists(ctx, typed, campaignsSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(legacy) == 0 {
+		return campaignsSettings, nil
+	}
+	attrs, err := repository.AttributeRepository.GetAttributes(ctx, legacy)
+	if err != nil {
+		return nil, err
+	}
+	for id, campaignAttrs := range attrs {
+		if len(campaignAttrs) == 0 {
+			continue
+		}
+		campaignsSettings[id] = FromAttributes(id, campaignAttrs)
+	}
+
+	return campaignsSettings, nil
+}
+
+// getLists loads the lists of the typed settings from the child tables
+func (repository *Repository) getLists(
+	ctx context.Context,
+	campaignIDs []int,
+	campaignsSettings map[int]*Settings,
+) error {
+	if len(campaignIDs) == 0 {
+		return nil
+	}
+
+	err := repository.getProducts(ctx, campaignIDs, campaignsSettings)
+	if err != nil {
+		return err
+	}
+	err = repository.getSubsidies(ctx, campaignIDs, campaignsSettings)
+	if err != nil {
+		return err
+	}
+	for _, table := range idTables {
+		err = repository.getIDs(ctx, table, campaignIDs, campaignsSettings)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getProducts loads the product lists of the settings
+func (repository *Repository) getProducts(ctx context.Context, campaignIDs []int, campaignsSettings map[int]*Settings) error {
+
+	query, args, err := sqlx.In("SELECT * FROM campaign_product WHERE campaign_id IN (?) ORDER BY campaign_id, list, position", campaignIDs)
+	if err != nil {
+		return err
+	}
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+	for result.Next() {
+		var p product
+		err := result.StructScan(&p)
+		if err != nil {
+			return err
+		}
+		s := campaignsSettings[p.CampaignID]
+		if s.Products == nil {
+			s.Products = make(map[string][]string)
+		}
+		s.Products[p.List] = append(s.Products[p.List], p.Product)
+	}
+
+	return result.Err()
+}
+
+// getSubsidies loads the subsidy lists of the settings
+func (repository *Repository) getSubsidies(ctx context.Context, campaignIDs []int, campaignsSettings map[int]*Settings) error {
+
+	query, args, err := sqlx.In("SELECT * FROM campaign_subsidy WHERE campaign_id IN (?) ORDER BY campaign_id, list, position", campaignIDs)
+	if err != nil {
+		return err
+	}
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+	for result.Next() {
+		var sub subsidy
+		err := result.StructScan(&sub)
+		if err != nil {
+			return err
+		}
+		s := campaignsSettings[sub.CampaignID]
+		if s.Subsidies == nil {
+			s.Subsidies = make(map[string][]float64)
+		}
+		s.Subsidies[sub.List] = append(s.Subsidies[sub.List], sub.Subsidy)
+	}
+
+	return result.Err()
+}
+
+// getIDs loads the id list of the table of the settings
+func (repository *Repository) getIDs(ctx context.Context, table idTable, campaignIDs []int, campaignsSettings map[int]*Settings) error {
+
+	query, args, err := sqlx.In("SELECT campaign_id, "+table.column+" FROM "+table.name+" WHERE campaign_id IN (?) ORDER BY campaign_id, "+table.column, campaignIDs)
+	if err != nil {
+		return err
+	}
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+	for result.Next() {
+		var campaignID, id int
+		err := result.Scan(&campaignID, &id)
+		if err != nil {
+			return err
+		}
+		ids := table.ids(campaignsSettings[campaignID])
+		*ids = append(*ids, id)
+	}
+
+	return result.Err()
+}
+
+// SaveSettings replaces the settings of the campaign in a single transaction
+func (repository *Repository) SaveSettings(
+	ctx context.Context,
+	s *Settings,
+) error {
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+
+	err = repository.SaveSettingsTx(ctx, tx, s)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveSettingsTx replaces the settings of the campaign in the transaction
+func (repository *Repository) SaveSettingsTx(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	s *Settings,
+) error {
+	err := deleteSettings(ctx, tx, s.CampaignID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.NamedExecContext(ctx, "INSERT INTO campaign_settings "+insertColumns(), s)
+	if err != nil {
+		return err
+	}
+
+	return insertLists(ctx, tx, s)
+}
+
+// MigrateSettings saves the settings of a campaign unless the campaign
+// already has typed settings, e.g. saved while the migration was running.
+// Returns whether the settings were saved
+func (repository *Repository) MigrateSettings(
+	ctx context.Context,
+	s *Settings,
+) (bool, error) {
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return false, err
+	}
+
+	query := dialect.For(repository.Configuration).InsertIgnore("INSERT INTO campaign_settings " + insertColumns())
+	res, err := tx.NamedExecContext(ctx, query, s)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		log.Error(tx.Rollback())
+		return false, err
+	}
+	err = insertLists(ctx, tx, s)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// DeleteSettingsByCampaignID deletes the typed and the legacy settings of the campaign
+func (repository *Repository) DeleteSettingsByCampaignID(
+	ctx context.Context,
+	campaignID int,
+) error {
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+
+	err = deleteSettings(ctx, tx, campaignID)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+	_, err = tx.ExecContext(ctx, tx.Rebind("DELETE FROM attributes WHERE obj_id = ? AND obj_table = ?"), campaignID, "campaign")
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func deleteSettings(ctx context.Context, tx *sqlx.Tx, campaignID int) error {
+	tables := []string{"campaign_settings", "campaign_product", "campaign_subsidy"}
+	for _, table := range idTables {
+		tables = append(tables, table.name)
+	}
+	for _, table := range tables {
+		_, err := tx.ExecContext(ctx, tx.Rebind("DELETE FROM "+table+" WHERE campaign_id = ?"), campaignID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func insertLists(ctx context.Context, tx *sqlx.Tx, s *Settings) error {
+	var rows []string
+	var args []interface{}
+	for _, list := range ProductLists {
+		for idx, p := range s.Products[list] {
+			rows = append(rows, "(?, ?, ?, ?)")
+			args = append(args, s.CampaignID, list, idx, p)
+		}
+	}
+	if len(rows) > 0 {
+		_, err := tx.ExecContext(ctx, tx.Rebind("INSERT INTO campaign_product (campaign_id, list, position, product) VALUES "+strings.Join(rows, ", ")), args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, args = nil, nil
+	for _, list := range SubsidyLists {
+		for idx, sub := range s.Subsidies[list] {
+			rows = append(rows, "(?, ?, ?, ?)")
+			args = append(args, s.CampaignID, list, idx, sub)
+		}
+	}
+	if len(rows) > 0 {
+		_, err := tx.ExecContext(ctx, tx.Rebind("INSERT INTO campaign_subsidy (campaign_id, list, position, subsidy) VALUES "+strings.Join(rows, ", ")), args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, table := range idTables {
+		rows, args = nil, nil
+		for _, id := range *table.ids(s) {
+			rows = append(rows, "(?, ?)")
+			args = append(args, s.CampaignID, id)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		_, err := tx.ExecContext(ctx, tx.Rebind("INSERT INTO "+table.name+" (campaign_id, "+table.column+") VALUES "+strings.Join(rows, ", ")), args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertColumns returns the column and named value lists of the settings columns
+func insertColumns() string {
+	t := reflect.TypeOf(Settings{})
+	var fields []string
+	var values []string
+	for i := 0; i < t.NumField(); i++ {
+		field := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if field == "-" {
+			continue
+		}
+		fields = append(fields, field)
+		values = append(values, ":"+field)
+	}
+	return "(" + strings.Join(fields, ", ") + ") VALUES (" + strings.Join(values, ", ") + ")"
+}
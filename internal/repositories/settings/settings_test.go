@@ -0,0 +1,102 @@
+package settings
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	databaseMock struct{}
+)
+
+func (d *databaseMock) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return nil, errors.New("1003")
+}
+
+var queryXq string
+var queryXa []interface{}
+
+func (d *databaseMock) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	queryXq = query
+	queryXa = args
+	return nil, errors.New("1003")
+}
+
+func (d *databaseMock) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
+	return nil, nil
+}
+
+func (d *databaseMock) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	r := new(sql.Result)
+	return *r, nil
+}
+func (d *databaseMock) Close() error { return nil }
+
+func TestSettings_New(t *testing.T) {
+	r := New(&config.Configuration{})
+	assert.IsType(t, &Repository{}, r)
+}
+
+func TestSettings_GetSettings(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	s, err := r.GetSettings(context.Background(), nil)
+	assert.Nil(t, err)
+	assert.Empty(t, s)
+
+	_, err = r.GetSettings(context.Background(), []int{1, 2})
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM campaign_settings WHERE campaign_id IN (?, ?)", queryXq)
+	assert.Equal(t, []interface{}{1, 2}, queryXa)
+}
+
+func TestSettings_SaveSettings_BeginError(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	err := r.SaveSettings(context.Background(), &Settings{CampaignID: 1})
+	assert.Equal(t, errors.New("1003"), err)
+
+	migrated, err := r.MigrateSettings(context.Background(), &Settings{CampaignID: 1})
+	assert.False(t, migrated)
+	assert.Equal(t, errors.New("1003"), err)
+}
+
+func TestSettings_FromAttributes(t *testing.T) {
+	s := FromAttributes(4, []*attributes.Attribute{
+		{ObjID: 4, Name: "priority", Type: attributes.INT, ValueInt: 7},
+		{ObjID: 4, Name: "stackingGroup", Type: attributes.TEXT, ValueText: "loyalty"},
+		{ObjID: 4, Name: "bundlePrice", Type: attributes.DOUBLE, ValueDouble: 9.5},
+		{ObjID: 4, Name: "customerGroupIDs", Type: attributes.TEXT, ValueText: "3, 5,x"},
+		{ObjID: 4, Name: "purchasedProducts", Type: attributes.TEXT, ValueText: "10,11"},
+		{ObjID: 4, Name: "purchasedProductSubsidies", Type: attributes.TEXT, ValueText: "1.5,2"},
+		{ObjID: 4, Name: "unknown", Type: attributes.INT, ValueInt: 1},
+	})
+
+	assert.Equal(t, &Settings{
+		CampaignID:       4,
+		Priority:         7,
+		StackingGroup:    "loyalty",
+		BundlePrice:      9.5,
+		CustomerGroupIDs: []int{3, 5},
+		Products:         map[string][]string{ListPurchasedProducts: {"10", "11"}},
+		Subsidies:        map[string][]float64{ListPurchasedProductSubsidies: {1.5, 2}},
+	}, s)
+}
+
+func TestSettings_insertColumns(t *testing.T) {
+	columns := insertColumns()
+
+	assert.True(t, strings.HasPrefix(columns, "(campaign_id, awarded_brand_id, "))
+	assert.Contains(t, columns, ":customer_allowlist)")
+	assert.NotContains(t, columns, "products")
+	assert.NotContains(t, columns, "customer_group_ids")
+}
@@ -0,0 +1,99 @@
+package tier
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MemoryRepository in-memory tier repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex  sync.RWMutex
+	tiers  map[int]Tier
+	lastID int
+}
+
+// NewMemory returns new empty in-memory tier repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{
+		tiers: make(map[int]Tier),
+	}
+}
+
+// GetTiers returns the tiers of the campaigns grouped by campaign id
+// and ordered by their position
+func (repository *MemoryRepository) GetTiers(
+	ctx context.Context,
+	campaignIDs []int,
+) (map[int][]*Tier, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	ids := make(map[int]bool, len(campaignIDs))
+	for _, id := range campaignIDs {
+		ids[id] = true
+	}
+	tiers := make([]Tier, 0)
+	for _, tier := range repository.tiers {
+		if ids[tier.CampaignID] {
+			tiers = append(tiers, tier)
+		}
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		if tiers[i].Position != tiers[j].Position {
+			return tiers[i].Position < tiers[j].Position
+		}
+		return tiers[i].ID < tiers[j].ID
+	})
+
+	campaignsTiers := make(map[int][]*Tier)
+	for idx := range tiers {
+		tier := &tiers[idx]
+		campaignsTiers[tier.CampaignID] = append(campaignsTiers[tier.CampaignID], tier)
+	}
+	return campaignsTiers, nil
+}
+
+// SaveTiers stores the tiers with new ids and sets the ids
+func (repository *MemoryRepository) SaveTiers(
+	ctx context.Context,
+	tiers []*Tier,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, tier := range tiers {
+		repository.lastID++
+		tier.ID = repository.lastID
+		repository.tiers[tier.ID] = *tier
+	}
+	return nil
+}
+
+// SaveTiersTx stores the tiers like SaveTiers, there is no transaction
+func (repository *MemoryRepository) SaveTiersTx(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	tiers []*Tier,
+) error {
+	return repository.SaveTiers(ctx, tiers)
+}
+
+// DeleteTiersByCampaignID deletes all tiers of the campaign
+func (repository *MemoryRepository) DeleteTiersByCampaignID(
+	ctx context.Context,
+	campaignID int,
+) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for id, tier := range repository.tiers {
+		if tier.CampaignID == campaignID {
+			delete(repository.tiers, id)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package tier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	var repository IRepository = NewMemory()
+	rows := []*Tier{
+		{CampaignID: 1, Position: 1},
+		{CampaignID: 2, Position: 0},
+		{CampaignID: 1, Position: 0},
+	}
+	assert.Nil(t, repository.SaveTiers(context.Background(), rows))
+	assert.Nil(t, repository.SaveTiers(context.Background(), nil))
+	assert.Equal(t, []int{1, 2, 3}, []int{rows[0].ID, rows[1].ID, rows[2].ID})
+
+	grouped, err := repository.GetTiers(context.Background(), []int{1, 2, 3})
+	assert.Nil(t, err)
+	assert.Equal(t, map[int][]*Tier{1: {rows[2], rows[0]}, 2: {rows[1]}}, grouped)
+
+	assert.Nil(t, repository.DeleteTiersByCampaignID(context.Background(), 1))
+	grouped, err = repository.GetTiers(context.Background(), []int{1, 2})
+	assert.Nil(t, err)
+	assert.Equal(t, map[int][]*Tier{2: {rows[1]}}, grouped)
+}
@@ -0,0 +1,160 @@
+package tier
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration *config.Configuration
+		Database      sqlx2.IDB
+	}
+	// IRepository interface
+	IRepository interface {
+		GetTiers(
+			ctx context.Context,
+			campaignIDs []int,
+		) (map[int][]*Tier, error)
+		SaveTiers(
+			ctx context.Context,
+			t []*Tier,
+		) error
+		SaveTiersTx(
+			ctx context.Context,
+			tx *sqlx.Tx,
+			t []*Tier,
+		) error
+		DeleteTiersByCampaignID(
+			ctx context.Context,
+			campaignID int,
+		) error
+	}
+	// Tier structure of a single campaign tier
+	Tier struct {
+		ID                 int     `json:"id"`
+		CampaignID         int     `json:"campaign_id"`
+		Position           int     `json:"position"`
+		PurchasedAmount    int     `json:"purchased_amount"`
+		PurchaseTotalValue float64 `json:"purchase_total_value"`
+		PercentageOff      float64 `json:"percentage_off"`
+		SumOff             float64 `json:"sum_off"`
+		SpecialPrice       float64 `json:"special_price"`
+	}
+)
+
+// New returns new configured tier repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration: configuration,
+		Database:      sqlx2.New(configuration),
+	}
+}
+
+// GetTiers returns the tiers of the campaigns grouped by campaign id
+// and ordered by their position
+func (repository *Repository) GetTiers(
+	ctx context.Context,
+	campaignIDs []int,
+) (map[int][]*Tier, error) {
+
+	campaignsTiers := make(map[int][]*Tier)
+	if len(campaignIDs) == 0 {
+		return campaignsTiers, nil
+	}
+
+	query, args, err := sqlx.In("SELECT * FROM campaign_tier WHERE campaign_id IN (?) ORDER BY campaign_id, position", campaignIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := repository.Database.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	for result.Next() {
+		var tier Tier
+		err := result.StructScan(&tier)
+		if err != nil {
+			return nil, err
+		}
+		campaignsTiers[tier.CampaignID] = append(campaignsTiers[tier.CampaignID], &tier)
+	}
+
+	return campaignsTiers, result.Err()
+}
+
+// SaveTiers saves the tiers in a single transaction
+func (repository *Repository) SaveTiers(
+	ctx context.Context,
+	tiers []*Tier,
+) error {
+	if len(tiers) == 0 {
+		return nil
+	}
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+
+	err = repository.SaveTiersTx(ctx, tx, tiers)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveTiersTx saves the tiers in the transaction
+func (repository *Repository) SaveTiersTx(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	tiers []*Tier,
+) error {
+	for _, tier := range tiers {
+		vals := map[string]interface{}{
+			"campaign_id":          tier.CampaignID,
+			"position":             tier.Position,
+			"purchased_amount":     tier.PurchasedAmount,
+			"purchase_total_value": tier.PurchaseTotalValue,
+			"percentage_off":       tier.PercentageOff,
+			"sum_off":              tier.SumOff,
+			"special_price":        tier.SpecialPrice,
+		}
+		id, err := dialect.For(repository.Configuration).InsertID(ctx, tx, "INSERT INTO campaign_tier (campaign_id, position, purchased_amount, purchase_total_value, percentage_off, sum_off, special_price) VALUES "+
+			"(:campaign_id, :position, :purchased_amount, :purchase_total_value, :percentage_off, :sum_off, :special_price)", vals)
+		if err != nil {
+			return err
+		}
+		tier.ID = int(id)
+	}
+
+	return nil
+}
+
+// DeleteTiersByCampaignID deletes all tiers of the campaign
+func (repository *Repository) DeleteTiersByCampaignID(
+	ctx context.Context,
+	campaignID int,
+) error {
+	var query = "DELETE FROM campaign_tier WHERE campaign_id=:campaign_id"
+
+	_, err := repository.Database.NamedExecContext(ctx, query,
+		map[string]interface{}{
+			"campaign_id": campaignID,
+		})
+
+	return err
+}
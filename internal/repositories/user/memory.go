@@ -0,0 +1,79 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/zdarovich/promotion-api/internal/repositories/session"
+)
+
+// MemoryRepository in-memory user repository, safe for concurrent use. The
+// users of the sessions are looked up by their short name
+type MemoryRepository struct {
+	mutex             sync.RWMutex
+	users             []User
+	lastID            int
+	SessionRepository session.IRepository
+}
+
+// NewMemory returns new empty in-memory user repository reading the sessions
+// from the session repository
+func NewMemory(sessionRepository session.IRepository) *MemoryRepository {
+
+	return &MemoryRepository{
+		SessionRepository: sessionRepository,
+	}
+}
+
+// AddUser stores the user and returns it with its id, the id is assigned
+// when it is not set
+func (repository *MemoryRepository) AddUser(u User) User {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if u.ID == 0 {
+		repository.lastID++
+		u.ID = repository.lastID
+	} else if u.ID > repository.lastID {
+		repository.lastID = u.ID
+	}
+	repository.users = append(repository.users, u)
+	return u
+}
+
+// GetUser returns the user if it exists, sql.ErrNoRows otherwise
+func (repository *MemoryRepository) GetUser(ctx context.Context, username string) (User, error) {
+
+	return repository.find(func(u User) bool { return u.Name == username })
+}
+
+// GetUserBySessionKey returns user by session key, sql.ErrNoRows when the
+// session or its user does not exist
+func (repository *MemoryRepository) GetUserBySessionKey(ctx context.Context, sessionKey string) (User, error) {
+
+	s, err := repository.SessionRepository.GetSessionByKey(ctx, sessionKey)
+	if err != nil {
+		return User{}, err
+	}
+	return repository.find(func(u User) bool { return u.ShortName == s.User })
+}
+
+// GetUserByShortName returns the user by its short name, sql.ErrNoRows when
+// it does not exist
+func (repository *MemoryRepository) GetUserByShortName(ctx context.Context, shortName string) (User, error) {
+
+	return repository.find(func(u User) bool { return u.ShortName == shortName })
+}
+
+func (repository *MemoryRepository) find(match func(u User) bool) (User, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	for _, u := range repository.users {
+		if match(u) {
+			return u, nil
+		}
+	}
+	return User{}, sql.ErrNoRows
+}
@@ -0,0 +1,43 @@
+package user
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zdarovich/promotion-api/internal/repositories/session"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	sessions := session.NewMemory()
+	sessions.AddSession(session.Session{User: "admin", Key: "key"})
+	sessions.AddSession(session.Session{User: "removed", Key: "orphan"})
+	repository := NewMemory(sessions)
+	admin := repository.AddUser(User{Name: "Administrator", ShortName: "admin"})
+	assert.Equal(t, 1, admin.ID)
+	assert.Equal(t, 7, repository.AddUser(User{ID: 7, Name: "Seller", ShortName: "seller"}).ID)
+	assert.Equal(t, 8, repository.AddUser(User{Name: "Manager", ShortName: "manager"}).ID)
+
+	var users IRepository = repository
+	u, err := users.GetUser(context.Background(), "Administrator")
+	assert.Nil(t, err)
+	assert.Equal(t, admin, u)
+
+	u, err = users.GetUserBySessionKey(context.Background(), "key")
+	assert.Nil(t, err)
+	assert.Equal(t, admin, u)
+
+	u, err = users.GetUserByShortName(context.Background(), "seller")
+	assert.Nil(t, err)
+	assert.Equal(t, 7, u.ID)
+
+	_, err = users.GetUserBySessionKey(context.Background(), "orphan")
+	assert.Equal(t, sql.ErrNoRows, err)
+	_, err = users.GetUserBySessionKey(context.Background(), "unknown")
+	assert.Equal(t, sql.ErrNoRows, err)
+	_, err = users.GetUser(context.Background(), "unknown")
+	assert.Equal(t, sql.ErrNoRows, err)
+	_, err = users.GetUserByShortName(context.Background(), "unknown")
+	assert.Equal(t, sql.ErrNoRows, err)
+}
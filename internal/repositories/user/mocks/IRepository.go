@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	user "github.com/zdarovich/promotion-api/internal/repositories/user"
 )
@@ -12,20 +14,41 @@ type IRepository struct {
 	mock.Mock
 }
 
-// GetUser provides a mock function with given fields: username
-func (_m *IRepository) GetUser(username string) (user.User, error) {
-	ret := _m.Called(username)
+// GetUser provides a mock function with given fields: ctx, username
+func (_m *IRepository) GetUser(ctx context.Context, username string) (user.User, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 user.User
+	if rf, ok := ret.Get(0).(func(context.Context, string) user.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Get(0).(user.User)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserBySessionKey provides a mock function with given fields: ctx, sessionKey
+func (_m *IRepository) GetUserBySessionKey(ctx context.Context, sessionKey string) (user.User, error) {
+	ret := _m.Called(ctx, sessionKey)
 
 	var r0 user.User
-	if rf, ok := ret.Get(0).(func(string) user.User); ok {
-		r0 = rf(username)
+	if rf, ok := ret.Get(0).(func(context.Context, string) user.User); ok {
+		r0 = rf(ctx, sessionKey)
 	} else {
 		r0 = ret.Get(0).(user.User)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(username)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, sessionKey)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -33,20 +56,20 @@ func (_m *IRepository) GetUser(username string) (user.User, error) {
 	return r0, r1
 }
 
-// GetUserBySessionKey provides a mock function with given fields: sessionKey
-func (_m *IRepository) GetUserBySessionKey(sessionKey string) (user.User, error) {
-	ret := _m.Called(sessionKey)
+// GetUserByShortName provides a mock function with given fields: ctx, shortName
+func (_m *IRepository) GetUserByShortName(ctx context.Context, shortName string) (user.User, error) {
+	ret := _m.Called(ctx, shortName)
 
 	var r0 user.User
-	if rf, ok := ret.Get(0).(func(string) user.User); ok {
-		r0 = rf(sessionKey)
+	if rf, ok := ret.Get(0).(func(context.Context, string) user.User); ok {
+		r0 = rf(ctx, shortName)
 	} else {
 		r0 = ret.Get(0).(user.User)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(sessionKey)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, shortName)
 	} else {
 		r1 = ret.Error(1)
 	}
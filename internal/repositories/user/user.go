@@ -1,6 +1,8 @@
 package user
 
 import (
+	"context"
+
 	"github.com/zdarovich/promotion-api/internal/config"
 	"github.com/zdarovich/promotion-api/internal/database/mysql"
 )
@@ -13,8 +15,9 @@ type (
 	}
 	// IRepository interface
 	IRepository interface {
-		GetUser(username string) (User, error)
-		GetUserBySessionKey(sessionKey string) (User, error)
+		GetUser(ctx context.Context, username string) (User, error)
+		GetUserBySessionKey(ctx context.Context, sessionKey string) (User, error)
+		GetUserByShortName(ctx context.Context, shortName string) (User, error)
 	}
 	// User structure of the session
 	User struct {
@@ -36,10 +39,10 @@ func New(configuration *config.Configuration) IRepository {
 }
 
 // GetUser returns the user if it exists
-func (repository *Repository) GetUser(username string) (User, error) {
+func (repository *Repository) GetUser(ctx context.Context, username string) (User, error) {
 
 	query := "SELECT id, orgper_idDat, group_id, name, shortname FROM user WHERE name = ?"
-	result, err := repository.Database.QueryRow(query, username)
+	result, err := repository.Database.QueryRowContext(ctx, query, username)
 
 	var user User
 
@@ -63,13 +66,41 @@ func (repository *Repository) GetUser(username string) (User, error) {
 }
 
 // GetUserBySessionKey returns user by session key
-func (repository *Repository) GetUserBySessionKey(sessionKey string) (User, error) {
+func (repository *Repository) GetUserBySessionKey(ctx context.Context, sessionKey string) (User, error) {
 
 	query := "SELECT u.id, u.orgper_idDat, u.group_id, u.name, u.shortname " +
 		"FROM session s " +
 		"JOIN user u ON s.user = u.shortname " +
 		"WHERE s.key = ?"
-	result, err := repository.Database.QueryRow(query, sessionKey)
+	result, err := repository.Database.QueryRowContext(ctx, query, sessionKey)
+
+	var user User
+
+	if err != nil {
+		return user, err
+	}
+
+	err = result.Scan(
+		&user.ID,
+		&user.OrgPerIDDat,
+		&user.GroupID,
+		&user.Name,
+		&user.ShortName,
+	)
+
+	if err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// GetUserByShortName returns the user by its short name, the user name of
+// the identity tokens
+func (repository *Repository) GetUserByShortName(ctx context.Context, shortName string) (User, error) {
+
+	query := "SELECT id, orgper_idDat, group_id, name, shortname FROM user WHERE shortname = ?"
+	result, err := repository.Database.QueryRowContext(ctx, query, shortName)
 
 	var user User
 
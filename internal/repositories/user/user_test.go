@@ -1,6 +1,8 @@
 package user
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/config"
@@ -31,7 +33,7 @@ var queryCalled bool = false
 var failQuery bool = false
 var queryResult mysql.IROWS
 
-func (m *MockDB) Query(query string, args ...interface{}) (mysql.IROWS, error) {
+func (m *MockDB) QueryContext(ctx context.Context, query string, args ...interface{}) (mysql.IROWS, error) {
 	queryCalled = true
 	if failQuery {
 		return nil, errors.New(errorcodes.CodeDatabase)
@@ -43,7 +45,7 @@ var queryRowCalled bool = false
 var failQueryRow bool = false
 var queryRowResult mysql.IROW
 
-func (m *MockDB) QueryRow(query string, args ...interface{}) (mysql.IROW, error) {
+func (m *MockDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) (mysql.IROW, error) {
 	queryRowCalled = true
 	if failQueryRow {
 		return nil, errors.New(errorcodes.CodeDatabase)
@@ -51,6 +53,10 @@ func (m *MockDB) QueryRow(query string, args ...interface{}) (mysql.IROW, error)
 	return queryRowResult, nil
 }
 
+func (m *MockDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, nil
+}
+
 var nextCalled bool = false
 
 func (m *MockRows) Next() bool {
@@ -107,7 +113,7 @@ func Test_GetUser(t *testing.T) {
 		Database:      new(MockDB),
 	}
 
-	result, err := user.GetUser("user")
+	result, err := user.GetUser(context.Background(), "user")
 
 	assert.True(t, queryRowCalled)
 	assert.True(t, rowScanCalled)
@@ -130,7 +136,7 @@ func Test_GetUserConnectionFail(t *testing.T) {
 		Database:      new(MockDB),
 	}
 
-	_, err := user.GetUser("user")
+	_, err := user.GetUser(context.Background(), "user")
 
 	assert.True(t, queryRowCalled)
 	assert.False(t, rowScanCalled)
@@ -152,9 +158,32 @@ func Test_GetUserScanFail(t *testing.T) {
 		Database:      new(MockDB),
 	}
 
-	_, err := user.GetUser("user")
+	_, err := user.GetUser(context.Background(), "user")
 
 	assert.True(t, queryRowCalled)
 	assert.True(t, rowScanCalled)
 	assert.NotNil(t, err)
 }
+
+func Test_GetUserByShortName(t *testing.T) {
+
+	failQueryRow = false
+	queryRowCalled = false
+	queryRowResult = new(MockRow)
+	rowScanCalled = false
+	failRowScan = false
+
+	configuration := &config.Configuration{}
+
+	user := &Repository{
+		Configuration: configuration,
+		Database:      new(MockDB),
+	}
+
+	result, err := user.GetUserByShortName(context.Background(), "user")
+
+	assert.True(t, queryRowCalled)
+	assert.True(t, rowScanCalled)
+	assert.NotNil(t, result)
+	assert.Nil(t, err)
+}
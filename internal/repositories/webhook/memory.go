@@ -0,0 +1,239 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// MemoryRepository in-memory webhook repository, safe for concurrent use
+type MemoryRepository struct {
+	mutex          sync.RWMutex
+	webhooks       []Webhook
+	deliveries     []Delivery
+	swept          int64
+	lastID         int
+	lastDeliveryID int
+}
+
+// NewMemory returns new empty in-memory webhook repository
+func NewMemory() *MemoryRepository {
+
+	return &MemoryRepository{}
+}
+
+// GetWebhooks returns all the webhooks ordered by id, the inactive ones
+// included
+func (repository *MemoryRepository) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	webhooks := make([]Webhook, len(repository.webhooks))
+	copy(webhooks, repository.webhooks)
+	return webhooks, nil
+}
+
+// GetWebhook returns the webhook, sql.ErrNoRows when it does not exist
+func (repository *MemoryRepository) GetWebhook(ctx context.Context, id int) (Webhook, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	for _, w := range repository.webhooks {
+		if w.ID == id {
+			return w, nil
+		}
+	}
+	return Webhook{}, sql.ErrNoRows
+}
+
+// SaveWebhook creates or, when the id is set, updates the webhook. The time
+// and the name of who added it are kept on update
+func (repository *MemoryRepository) SaveWebhook(ctx context.Context, w *Webhook) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if w.ID == 0 {
+		repository.lastID++
+		w.ID = repository.lastID
+		repository.webhooks = append(repository.webhooks, *w)
+		return nil
+	}
+	for idx, stored := range repository.webhooks {
+		if stored.ID == w.ID {
+			updated := *w
+			updated.Added = stored.Added
+			updated.Addedby = stored.Addedby
+			repository.webhooks[idx] = updated
+		}
+	}
+	return nil
+}
+
+// DeleteWebhook deletes the webhook and its deliveries
+func (repository *MemoryRepository) DeleteWebhook(ctx context.Context, id int) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	webhooks := repository.webhooks[:0]
+	for _, w := range repository.webhooks {
+		if w.ID != id {
+			webhooks = append(webhooks, w)
+		}
+	}
+	repository.webhooks = webhooks
+
+	deliveries := repository.deliveries[:0]
+	for _, d := range repository.deliveries {
+		if d.WebhookID != id {
+			deliveries = append(deliveries, d)
+		}
+	}
+	repository.deliveries = deliveries
+	return nil
+}
+
+// SaveDeliveries creates the deliveries and sets their ids
+func (repository *MemoryRepository) SaveDeliveries(ctx context.Context, deliveries []*Delivery) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for _, d := range deliveries {
+		repository.lastDeliveryID++
+		d.ID = repository.lastDeliveryID
+		repository.deliveries = append(repository.deliveries, *d)
+	}
+	return nil
+}
+
+// SaveDeliveriesTx creates the deliveries like SaveDeliveries, there is no
+// transaction
+func (repository *MemoryRepository) SaveDeliveriesTx(ctx context.Context, tx *sqlx.Tx, deliveries []*Delivery) error {
+	return repository.SaveDeliveries(ctx, deliveries)
+}
+
+// GetDelivery returns the delivery, sql.ErrNoRows when it does not exist
+func (repository *MemoryRepository) GetDelivery(ctx context.Context, id int) (Delivery, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	for _, d := range repository.deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return Delivery{}, sql.ErrNoRows
+}
+
+// GetDeliveries returns a page of the deliveries of the webhook with the
+// status, the latest first. An empty filter matches all deliveries
+func (repository *MemoryRepository) GetDeliveries(ctx context.Context, webhookID int, status string, records int, page int) ([]Delivery, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	deliveries := repository.filter(webhookID, status)
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+
+	from := records * page
+	if records <= 0 || from < 0 || from >= len(deliveries) {
+		return make([]Delivery, 0), nil
+	}
+	to := from + records
+	if to > len(deliveries) {
+		to = len(deliveries)
+	}
+	return deliveries[from:to], nil
+}
+
+// GetDeliveriesCount returns the count of the deliveries of the webhook with
+// the status
+func (repository *MemoryRepository) GetDeliveriesCount(ctx context.Context, webhookID int, status string) (int, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return len(repository.filter(webhookID, status)), nil
+}
+
+// GetDueDeliveries returns the pending deliveries whose next attempt is due,
+// the oldest first
+func (repository *MemoryRepository) GetDueDeliveries(ctx context.Context, now int64, limit int) ([]Delivery, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	deliveries := make([]Delivery, 0)
+	for _, d := range repository.deliveries {
+		if d.Status == StatusPending && d.NextAttempt <= now && len(deliveries) < limit {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery moves the next attempt of the pending delivery to until and
+// returns whether it did
+func (repository *MemoryRepository) ClaimDelivery(ctx context.Context, d Delivery, until int64) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for idx, stored := range repository.deliveries {
+		if stored.ID == d.ID && stored.Status == StatusPending && stored.NextAttempt == d.NextAttempt {
+			repository.deliveries[idx].NextAttempt = until
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// UpdateDelivery saves the status and the attempts of the delivery
+func (repository *MemoryRepository) UpdateDelivery(ctx context.Context, d Delivery) error {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	for idx, stored := range repository.deliveries {
+		if stored.ID == d.ID {
+			stored.Status = d.Status
+			stored.Attempts = d.Attempts
+			stored.NextAttempt = d.NextAttempt
+			stored.LastStatus = d.LastStatus
+			stored.LastError = d.LastError
+			stored.Delivered = d.Delivered
+			repository.deliveries[idx] = stored
+		}
+	}
+	return nil
+}
+
+// GetSwept returns the time up to which the campaigns were checked for
+// activation and expiry
+func (repository *MemoryRepository) GetSwept(ctx context.Context) (int64, error) {
+	repository.mutex.RLock()
+	defer repository.mutex.RUnlock()
+
+	return repository.swept, nil
+}
+
+// ClaimSweep moves the swept time from swept to to and returns whether it
+// did
+func (repository *MemoryRepository) ClaimSweep(ctx context.Context, swept int64, to int64) (bool, error) {
+	repository.mutex.Lock()
+	defer repository.mutex.Unlock()
+
+	if repository.swept != swept || swept == to {
+		return false, nil
+	}
+	repository.swept = to
+	return true, nil
+}
+
+// filter returns the deliveries of the webhook with the status ordered by id
+func (repository *MemoryRepository) filter(webhookID int, status string) []Delivery {
+	deliveries := make([]Delivery, 0)
+	for _, d := range repository.deliveries {
+		if (webhookID <= 0 || d.WebhookID == webhookID) && (status == "" || d.Status == status) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries
+}
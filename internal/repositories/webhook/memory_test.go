@@ -0,0 +1,103 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository_Webhooks(t *testing.T) {
+	var repository IRepository = NewMemory()
+	pos := &Webhook{URL: "https://pos.example.com/hooks", Secret: "secret", Events: "campaign.created", Active: true, Added: 10, Addedby: "admin"}
+	assert.Nil(t, repository.SaveWebhook(context.Background(), pos))
+	assert.Nil(t, repository.SaveWebhook(context.Background(), &Webhook{URL: "https://crm.example.com", Events: "campaign.deleted"}))
+	assert.Equal(t, 1, pos.ID)
+
+	assert.Nil(t, repository.SaveWebhook(context.Background(), &Webhook{ID: 1, URL: "https://pos.example.com/v2", Events: "campaign.updated", Changed: 20, Changedby: "other"}))
+	w, err := repository.GetWebhook(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "https://pos.example.com/v2", w.URL)
+	assert.Equal(t, "admin", w.Addedby)
+	assert.Equal(t, "other", w.Changedby)
+	_, err = repository.GetWebhook(context.Background(), 3)
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	assert.Nil(t, repository.SaveDeliveries(context.Background(), []*Delivery{
+		{WebhookID: 1, Event: "campaign.updated", Status: StatusPending},
+		{WebhookID: 2, Event: "campaign.deleted", Status: StatusPending},
+	}))
+	assert.Nil(t, repository.DeleteWebhook(context.Background(), 1))
+	webhooks, err := repository.GetWebhooks(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, webhooks, 1)
+	count, err := repository.GetDeliveriesCount(context.Background(), 0, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestMemoryRepository_Deliveries(t *testing.T) {
+	var repository IRepository = NewMemory()
+	deliveries := []*Delivery{
+		{WebhookID: 1, Event: "campaign.created", Status: StatusPending, NextAttempt: 10},
+		{WebhookID: 1, Event: "campaign.updated", Status: StatusPending, NextAttempt: 30},
+		{WebhookID: 2, Event: "campaign.created", Status: StatusDead, NextAttempt: 0},
+	}
+	assert.Nil(t, repository.SaveDeliveries(context.Background(), deliveries))
+	assert.Equal(t, 3, deliveries[2].ID)
+
+	due, err := repository.GetDueDeliveries(context.Background(), 20, 10)
+	assert.Nil(t, err)
+	assert.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].ID)
+
+	// Claimed once only
+	claimed, err := repository.ClaimDelivery(context.Background(), due[0], 50)
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	claimed, err = repository.ClaimDelivery(context.Background(), due[0], 50)
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+
+	due[0].Status = StatusDelivered
+	due[0].Attempts = 1
+	due[0].LastStatus = 204
+	assert.Nil(t, repository.UpdateDelivery(context.Background(), due[0]))
+	d, err := repository.GetDelivery(context.Background(), 1)
+	assert.Nil(t, err)
+	assert.Equal(t, StatusDelivered, d.Status)
+	assert.Equal(t, "campaign.created", d.Event)
+
+	page, err := repository.GetDeliveries(context.Background(), 1, "", 1, 0)
+	assert.Nil(t, err)
+	assert.Len(t, page, 1)
+	assert.Equal(t, 2, page[0].ID)
+	dead, err := repository.GetDeliveries(context.Background(), 0, StatusDead, 20, 0)
+	assert.Nil(t, err)
+	assert.Len(t, dead, 1)
+}
+
+func TestMemoryRepository_Sweep(t *testing.T) {
+	var repository IRepository = NewMemory()
+
+	claimed, err := repository.ClaimSweep(context.Background(), 0, 100)
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	claimed, err = repository.ClaimSweep(context.Background(), 0, 200)
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+
+	swept, err := repository.GetSwept(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), swept)
+}
+
+func TestWebhook_Subscribed(t *testing.T) {
+	w := Webhook{Events: "campaign.created, campaign.deleted", Active: true}
+
+	assert.True(t, w.Subscribed("campaign.deleted"))
+	assert.False(t, w.Subscribed("campaign.updated"))
+	w.Active = false
+	assert.False(t, w.Subscribed("campaign.created"))
+}
@@ -0,0 +1,419 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	sqlx2 "github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/database/dialect"
+	"github.com/zdarovich/promotion-api/internal/database/sqlx"
+	"github.com/zdarovich/promotion-api/internal/log"
+)
+
+// Statuses of the deliveries
+const (
+	// StatusPending the delivery is due at its next attempt
+	StatusPending string = "pending"
+	// StatusDelivered the endpoint accepted the delivery
+	StatusDelivered string = "delivered"
+	// StatusDead the delivery failed all its attempts, the dead deliveries
+	// are the dead-letter list of the webhooks
+	StatusDead string = "dead"
+)
+
+type (
+	// Repository struct
+	Repository struct {
+		Configuration *config.Configuration
+		Database      sqlx.IDB
+	}
+	// IRepository interface
+	IRepository interface {
+		GetWebhooks(ctx context.Context) ([]Webhook, error)
+		GetWebhook(ctx context.Context, id int) (Webhook, error)
+		SaveWebhook(ctx context.Context, w *Webhook) error
+		DeleteWebhook(ctx context.Context, id int) error
+		SaveDeliveries(ctx context.Context, deliveries []*Delivery) error
+		SaveDeliveriesTx(ctx context.Context, tx *sqlx2.Tx, deliveries []*Delivery) error
+		GetDelivery(ctx context.Context, id int) (Delivery, error)
+		GetDeliveries(ctx context.Context, webhookID int, status string, records int, page int) ([]Delivery, error)
+		GetDeliveriesCount(ctx context.Context, webhookID int, status string) (int, error)
+		GetDueDeliveries(ctx context.Context, now int64, limit int) ([]Delivery, error)
+		ClaimDelivery(ctx context.Context, d Delivery, until int64) (bool, error)
+		UpdateDelivery(ctx context.Context, d Delivery) error
+		GetSwept(ctx context.Context) (int64, error)
+		ClaimSweep(ctx context.Context, swept int64, to int64) (bool, error)
+	}
+	// Webhook structure of the webhook table, events is the comma-separated
+	// list of the events the endpoint is subscribed to
+	Webhook struct {
+		ID        int    `json:"id"`
+		URL       string `json:"url"`
+		Secret    string `json:"secret"`
+		Events    string `json:"events"`
+		Active    bool   `json:"active"`
+		Added     int64  `json:"added"`
+		Addedby   string `json:"addedby"`
+		Changed   int64  `json:"changed"`
+		Changedby string `json:"changedby"`
+	}
+	// Delivery structure of the webhook_delivery table, an event sent to a
+	// webhook. LastStatus is the HTTP status of the last attempt, 0 when the
+	// endpoint could not be reached
+	Delivery struct {
+		ID          int    `json:"id"`
+		WebhookID   int    `json:"webhook_id"`
+		Event       string `json:"event"`
+		Payload     string `json:"payload"`
+		Status      string `json:"status"`
+		Attempts    int    `json:"attempts"`
+		NextAttempt int64  `json:"next_attempt"`
+		LastStatus  int    `json:"last_status"`
+		LastError   string `json:"last_error"`
+		Added       int64  `json:"added"`
+		Delivered   int64  `json:"delivered"`
+	}
+)
+
+// New returns new configured webhook repository
+func New(configuration *config.Configuration) IRepository {
+
+	return &Repository{
+		Configuration: configuration,
+		Database:      sqlx.New(configuration),
+	}
+}
+
+// GetWebhooks returns all the webhooks, the inactive ones included
+func (repository *Repository) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM webhook ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	webhooks := make([]Webhook, 0)
+	for result.Next() {
+		var w Webhook
+		err := result.StructScan(&w)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks, result.Err()
+}
+
+// GetWebhook returns the webhook, sql.ErrNoRows when it does not exist
+func (repository *Repository) GetWebhook(ctx context.Context, id int) (Webhook, error) {
+
+	var w Webhook
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM webhook WHERE id = ?", id)
+	if err != nil {
+		return w, err
+	}
+	defer result.Close()
+
+	if !result.Next() {
+		if err = result.Err(); err != nil {
+			return w, err
+		}
+		return w, sql.ErrNoRows
+	}
+	err = result.StructScan(&w)
+
+	return w, err
+}
+
+// SaveWebhook creates or, when the id is set, updates the webhook
+func (repository *Repository) SaveWebhook(ctx context.Context, w *Webhook) error {
+
+	vals := map[string]interface{}{
+		"id":        w.ID,
+		"url":       w.URL,
+		"secret":    w.Secret,
+		"events":    w.Events,
+		"active":    w.Active,
+		"added":     w.Added,
+		"addedby":   w.Addedby,
+		"changed":   w.Changed,
+		"changedby": w.Changedby,
+	}
+	if w.ID != 0 {
+		_, err := repository.Database.NamedExecContext(ctx, "UPDATE webhook SET url=:url, secret=:secret, events=:events, active=:active, "+
+			"changed=:changed, changedby=:changedby WHERE id=:id", vals)
+		return err
+	}
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+	id, err := dialect.For(repository.Configuration).InsertID(ctx, tx, "INSERT INTO webhook (url, secret, events, active, added, addedby, changed, changedby) VALUES "+
+		"(:url, :secret, :events, :active, :added, :addedby, :changed, :changedby)", vals)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+	w.ID = int(id)
+
+	return tx.Commit()
+}
+
+// DeleteWebhook deletes the webhook and its deliveries
+func (repository *Repository) DeleteWebhook(ctx context.Context, id int) error {
+
+	vals := map[string]interface{}{
+		"id": id,
+	}
+	_, err := repository.Database.NamedExecContext(ctx, "DELETE FROM webhook_delivery WHERE webhook_id=:id", vals)
+	if err != nil {
+		return err
+	}
+	_, err = repository.Database.NamedExecContext(ctx, "DELETE FROM webhook WHERE id=:id", vals)
+
+	return err
+}
+
+// SaveDeliveries creates the deliveries in a single transaction and sets
+// their ids
+func (repository *Repository) SaveDeliveries(ctx context.Context, deliveries []*Delivery) error {
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	tx, err := repository.Database.BeginTxx(ctx, nil)
+	defer repository.Database.Close()
+	if err != nil {
+		return err
+	}
+
+	err = repository.SaveDeliveriesTx(ctx, tx, deliveries)
+	if err != nil {
+		log.Error(tx.Rollback())
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SaveDeliveriesTx creates the deliveries in the transaction and sets their
+// ids
+func (repository *Repository) SaveDeliveriesTx(ctx context.Context, tx *sqlx2.Tx, deliveries []*Delivery) error {
+
+	for _, d := range deliveries {
+		vals := map[string]interface{}{
+			"webhook_id":   d.WebhookID,
+			"event":        d.Event,
+			"payload":      d.Payload,
+			"status":       d.Status,
+			"attempts":     d.Attempts,
+			"next_attempt": d.NextAttempt,
+			"added":        d.Added,
+		}
+		id, err := dialect.For(repository.Configuration).InsertID(ctx, tx, "INSERT INTO webhook_delivery (webhook_id, event, payload, status, attempts, next_attempt, added) VALUES "+
+			"(:webhook_id, :event, :payload, :status, :attempts, :next_attempt, :added)", vals)
+		if err != nil {
+			return err
+		}
+		d.ID = int(id)
+	}
+
+	return nil
+}
+
+// GetDelivery returns the delivery, sql.ErrNoRows when it does not exist
+func (repository *Repository) GetDelivery(ctx context.Context, id int) (Delivery, error) {
+
+	var d Delivery
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM webhook_delivery WHERE id = ?", id)
+	if err != nil {
+		return d, err
+	}
+	defer result.Close()
+
+	if !result.Next() {
+		if err = result.Err(); err != nil {
+			return d, err
+		}
+		return d, sql.ErrNoRows
+	}
+	err = result.StructScan(&d)
+
+	return d, err
+}
+
+// GetDeliveries returns a page of the deliveries of the webhook with the
+// status, the latest first. An empty filter matches all deliveries
+func (repository *Repository) GetDeliveries(ctx context.Context, webhookID int, status string, records int, page int) ([]Delivery, error) {
+
+	conditionsString, values := getDeliveryConditions(webhookID, status)
+
+	pagination, limits := dialect.For(repository.Configuration).Paginate(records, page)
+	values = append(values, limits...)
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM webhook_delivery"+conditionsString+" ORDER BY id DESC"+pagination, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return scanDeliveries(result)
+}
+
+// GetDeliveriesCount returns the count of the deliveries of the webhook with
+// the status
+func (repository *Repository) GetDeliveriesCount(ctx context.Context, webhookID int, status string) (int, error) {
+
+	conditionsString, values := getDeliveryConditions(webhookID, status)
+
+	result, err := repository.Database.QueryRowxContext(ctx, "SELECT COUNT(*) FROM webhook_delivery"+conditionsString, values...)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = result.Scan(&count)
+	return count, err
+}
+
+// GetDueDeliveries returns the pending deliveries whose next attempt is due,
+// the oldest first
+func (repository *Repository) GetDueDeliveries(ctx context.Context, now int64, limit int) ([]Delivery, error) {
+
+	pagination, limits := dialect.For(repository.Configuration).Paginate(limit, 0)
+	values := append([]interface{}{StatusPending, now}, limits...)
+	result, err := repository.Database.QueryxContext(ctx, "SELECT * FROM webhook_delivery WHERE status = ? AND next_attempt <= ? ORDER BY id"+pagination, values...)
+	if err != nil {
+		return nil, err
+	}
+	defer result.Close()
+
+	return scanDeliveries(result)
+}
+
+// ClaimDelivery moves the next attempt of the pending delivery to until and
+// returns whether it did. A delivery is attempted by the instance that
+// claimed it, another instance that read it before has no longer the same
+// next attempt
+func (repository *Repository) ClaimDelivery(ctx context.Context, d Delivery, until int64) (bool, error) {
+
+	vals := map[string]interface{}{
+		"id":           d.ID,
+		"status":       StatusPending,
+		"next_attempt": d.NextAttempt,
+		"until":        until,
+	}
+	res, err := repository.Database.NamedExecContext(ctx, "UPDATE webhook_delivery SET next_attempt=:until "+
+		"WHERE id=:id AND status=:status AND next_attempt=:next_attempt", vals)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+
+	return affected == 1, err
+}
+
+// UpdateDelivery saves the status and the attempts of the delivery
+func (repository *Repository) UpdateDelivery(ctx context.Context, d Delivery) error {
+
+	vals := map[string]interface{}{
+		"id":           d.ID,
+		"status":       d.Status,
+		"attempts":     d.Attempts,
+		"next_attempt": d.NextAttempt,
+		"last_status":  d.LastStatus,
+		"last_error":   d.LastError,
+		"delivered":    d.Delivered,
+	}
+	_, err := repository.Database.NamedExecContext(ctx, "UPDATE webhook_delivery SET status=:status, attempts=:attempts, next_attempt=:next_attempt, "+
+		"last_status=:last_status, last_error=:last_error, delivered=:delivered WHERE id=:id", vals)
+
+	return err
+}
+
+// GetSwept returns the time up to which the campaigns were checked for
+// activation and expiry, 0 when they have never been
+func (repository *Repository) GetSwept(ctx context.Context) (int64, error) {
+
+	result, err := repository.Database.QueryRowxContext(ctx, "SELECT swept FROM webhook_sweep WHERE id = 1")
+	if err != nil {
+		return 0, err
+	}
+
+	var swept int64
+	err = result.Scan(&swept)
+	return swept, err
+}
+
+// ClaimSweep moves the swept time from swept to to and returns whether it
+// did, the instance that moved it checks the campaigns of the period
+func (repository *Repository) ClaimSweep(ctx context.Context, swept int64, to int64) (bool, error) {
+
+	vals := map[string]interface{}{
+		"swept": swept,
+		"to":    to,
+	}
+	res, err := repository.Database.NamedExecContext(ctx, "UPDATE webhook_sweep SET swept=:to WHERE id=1 AND swept=:swept", vals)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+
+	return affected == 1, err
+}
+
+// Subscribed returns whether the webhook is active and subscribed to the
+// event
+func (w Webhook) Subscribed(event string) bool {
+
+	if !w.Active {
+		return false
+	}
+	for _, e := range strings.Split(w.Events, ",") {
+		if strings.TrimSpace(e) == event {
+			return true
+		}
+	}
+	return false
+}
+
+func getDeliveryConditions(webhookID int, status string) (string, []interface{}) {
+
+	var conditions []string
+	var values []interface{}
+
+	if webhookID > 0 {
+		conditions = append(conditions, "webhook_id = ?")
+		values = append(values, webhookID)
+	}
+	if status != "" {
+		conditions = append(conditions, "status = ?")
+		values = append(values, status)
+	}
+
+	if len(conditions) == 0 {
+		return "", values
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), values
+}
+
+func scanDeliveries(result *sqlx2.Rows) ([]Delivery, error) {
+
+	deliveries := make([]Delivery, 0)
+	for result.Next() {
+		var d Delivery
+		err := result.StructScan(&d)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, result.Err()
+}
@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	databaseMock struct{}
+)
+
+func (d *databaseMock) BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error) {
+	return nil, errors.New("1003")
+}
+
+var queryXq string
+var queryXa []interface{}
+
+func (d *databaseMock) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	queryXq = query
+	queryXa = args
+	return nil, errors.New("1003")
+}
+
+var queryRowXq string
+
+func (d *databaseMock) QueryRowxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Row, error) {
+	queryRowXq = query
+	return nil, errors.New("1003")
+}
+
+var namedExecQ []string
+var namedExecA interface{}
+
+func (d *databaseMock) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	namedExecQ = append(namedExecQ, query)
+	namedExecA = arg
+	return nil, errors.New("1003")
+}
+func (d *databaseMock) Close() error { return nil }
+
+func TestWebhook_New(t *testing.T) {
+	r := New(&config.Configuration{})
+	assert.IsType(t, &Repository{}, r)
+}
+
+func TestWebhook_UpdateWebhook(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+	namedExecQ = nil
+
+	err := r.SaveWebhook(context.Background(), &Webhook{ID: 2, URL: "https://pos.example.com", Events: "campaign.created", Active: true})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"UPDATE webhook SET url=:url, secret=:secret, events=:events, active=:active, changed=:changed, changedby=:changedby WHERE id=:id"}, namedExecQ)
+	assert.Equal(t, "https://pos.example.com", namedExecA.(map[string]interface{})["url"])
+}
+
+func TestWebhook_DeleteWebhook(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+	namedExecQ = nil
+
+	err := r.DeleteWebhook(context.Background(), 2)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"DELETE FROM webhook_delivery WHERE webhook_id=:id"}, namedExecQ)
+}
+
+func TestWebhook_GetDeliveries(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	_, err := r.GetDeliveries(context.Background(), 2, StatusDead, 20, 1)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM webhook_delivery WHERE webhook_id = ? AND status = ? ORDER BY id DESC LIMIT ? OFFSET ?", queryXq)
+	assert.Equal(t, []interface{}{2, StatusDead, 20, 20}, queryXa)
+
+	_, err = r.GetDeliveriesCount(context.Background(), 0, "")
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM webhook_delivery", queryRowXq)
+}
+
+func TestWebhook_GetDueDeliveries(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+
+	_, err := r.GetDueDeliveries(context.Background(), 100, 50)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, "SELECT * FROM webhook_delivery WHERE status = ? AND next_attempt <= ? ORDER BY id LIMIT ? OFFSET ?", queryXq)
+	assert.Equal(t, []interface{}{StatusPending, int64(100), 50, 0}, queryXa)
+}
+
+func TestWebhook_ClaimDelivery(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+	namedExecQ = nil
+
+	claimed, err := r.ClaimDelivery(context.Background(), Delivery{ID: 3, NextAttempt: 100}, 160)
+
+	assert.NotNil(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, []string{"UPDATE webhook_delivery SET next_attempt=:until WHERE id=:id AND status=:status AND next_attempt=:next_attempt"}, namedExecQ)
+	assert.Equal(t, map[string]interface{}{"id": 3, "status": StatusPending, "next_attempt": int64(100), "until": int64(160)}, namedExecA)
+}
+
+func TestWebhook_ClaimSweep(t *testing.T) {
+	r := &Repository{Database: &databaseMock{}}
+	namedExecQ = nil
+
+	claimed, err := r.ClaimSweep(context.Background(), 100, 160)
+
+	assert.NotNil(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, []string{"UPDATE webhook_sweep SET swept=:to WHERE id=1 AND swept=:swept"}, namedExecQ)
+}
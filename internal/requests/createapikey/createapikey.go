@@ -0,0 +1,134 @@
+package createapikey
+
+import (
+	"context"
+	"errors"
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+	"strings"
+	"time"
+)
+
+// MaxNameLength maximum length of the name of a key
+const MaxNameLength = 50
+
+type (
+	// CreateAPIKey struct
+	CreateAPIKey struct {
+		APIKeyRepository apikey.IRepository
+		UserRepository   user.IRepository
+		Configuration    *config.Configuration
+	}
+	// record structure of the output record, the key is output only once
+	record struct {
+		APIKeyID int    `json:"apiKeyID"`
+		Name     string `json:"name"`
+		Key      string `json:"key"`
+		Scope    string `json:"scope"`
+		Expires  int64  `json:"expires"`
+		Added    int64  `json:"added"`
+		Addedby  string `json:"addedby"`
+	}
+)
+
+// @Summary Create API key
+// @Description  Creates an API key of the account. The key is sent as apiKey in place of the sessionKey and is output only once, only its hash is stored. The name of the key is recorded as addedby and changedby of the records it saves.
+// @Tags apikey
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "createAPIKey"
+// @Param name formData string true "webshop"
+// @Description  scope - "read" allows the requests needing view rights, "write" allows creating, changing and deleting campaigns and segments as well.
+// @Param scope formData string true "read"
+// @Description  expires - The date and time in UTC the key expires. The key does not expire when not set.
+// @Param expires formData string false "2021-12-31"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /createAPIKey [POST]
+func (createAPIKey *CreateAPIKey) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	userEntity, err := caller.User(ctx, createAPIKey.UserRepository, context.PostForm("sessionKey"))
+	if err != nil || userEntity.ID == 0 {
+		return nil, errors.New("userEntity not found")
+	}
+
+	now := time.Now()
+	k, err := validate(context, now)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := apikey.Generate()
+	if err != nil {
+		return nil, err
+	}
+	k.KeyHash = apikey.Hash(key)
+	k.Added = now.Unix()
+	k.Addedby = userEntity.ShortName
+	err = createAPIKey.APIKeyRepository.SaveAPIKey(ctx, k)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	return &response.Data{
+		Total:           1,
+		TotalInResponse: 1,
+		Records: []record{{
+			APIKeyID: k.ID,
+			Name:     k.Name,
+			Key:      key,
+			Scope:    k.Scope,
+			Expires:  k.Expires,
+			Added:    k.Added,
+			Addedby:  k.Addedby,
+		}},
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &CreateAPIKey{
+		APIKeyRepository: apikey.New(configuration),
+		UserRepository:   user.New(configuration),
+		Configuration:    configuration,
+	}
+}
+
+// validate returns the key of the input parameters
+func validate(context root.IGinContext, now time.Time) (*apikey.APIKey, error) {
+
+	k := apikey.APIKey{
+		Name:  strings.TrimSpace(context.PostForm("name")),
+		Scope: context.PostForm("scope"),
+	}
+	if k.Name == "" {
+		return nil, errorcodes.New("name", errorcodes.CodeRequiredParameterMissing)
+	}
+	if len(k.Name) > MaxNameLength {
+		return nil, errorcodes.New("name", 1014)
+	}
+	if k.Scope == "" {
+		return nil, errorcodes.New("scope", errorcodes.CodeRequiredParameterMissing)
+	}
+	if !apikey.ValidScope(k.Scope) {
+		return nil, errorcodes.New("scope", 1014)
+	}
+	if formVal := context.PostForm("expires"); formVal != "" {
+		t, err := campaignhelper.ParseDateTime(formVal, time.UTC, true)
+		if err != nil || !t.After(now) {
+			return nil, errorcodes.New("expires", 1014)
+		}
+		k.Expires = t.Unix()
+	}
+	return &k, nil
+}
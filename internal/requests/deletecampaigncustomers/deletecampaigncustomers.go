@@ -0,0 +1,103 @@
+package deletecampaigncustomers
+
+import (
+	"context"
+	"errors"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/assignment"
+	"strconv"
+)
+
+type (
+	// DeleteCampaignCustomers struct
+	DeleteCampaignCustomers struct {
+		AssignmentRepository assignment.IRepository
+		Configuration        *config.Configuration
+		InputParameters      inputParameters
+	}
+	// requestParams the parameters that can be used for deleting
+	inputParameters struct {
+		CampaignID  int
+		CustomerIDs []int
+	}
+)
+
+// @Summary Remove customers from a personalized campaign
+// @Description  Removes the customers from the allowlist of the campaign, all customers when no customers are given
+// @Tags campaign
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "deleteCampaignCustomers"
+// @Param campaignID formData string true "1"
+// @Param customerIDs formData string false "1,2,3"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /deleteCampaignCustomers [POST]
+func (deleteCampaignCustomers *DeleteCampaignCustomers) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	err := deleteCampaignCustomers.validate(context)
+	if err != nil {
+		return nil, err
+	}
+
+	err = deleteCampaignCustomers.AssignmentRepository.DeleteAssignments(ctx,
+		deleteCampaignCustomers.InputParameters.CampaignID,
+		deleteCampaignCustomers.InputParameters.CustomerIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	totalRecordsCount, err := deleteCampaignCustomers.AssignmentRepository.GetAssignmentsCount(ctx,
+		deleteCampaignCustomers.InputParameters.CampaignID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Data{
+		Total:           totalRecordsCount,
+		TotalInResponse: 0,
+		Records:         []interface{}{},
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &DeleteCampaignCustomers{
+		AssignmentRepository: assignment.New(configuration),
+		Configuration:        configuration,
+	}
+}
+
+// validate checks if the required parameters have been set
+func (deleteCampaignCustomers *DeleteCampaignCustomers) validate(context root.IGinContext) error {
+
+	var err error
+	inputParameters := inputParameters{}
+	inputParameters.CampaignID, _ = strconv.Atoi(context.PostForm("campaignID"))
+
+	// Required parameters
+	if inputParameters.CampaignID == 0 {
+
+		return errorcodes.New("campaignID", errorcodes.CodeRequiredParameterMissing)
+	}
+
+	inputParameters.CustomerIDs, err = campaignhelper.ParseCustomerIDs(context.PostForm("customerIDs"))
+	if err != nil {
+		return errorcodes.New("customerIDs", 1014)
+	}
+	if len(inputParameters.CustomerIDs) > campaignhelper.MaxCustomerIDs {
+		return errors.New("1195")
+	}
+
+	deleteCampaignCustomers.InputParameters = inputParameters
+	return nil
+}
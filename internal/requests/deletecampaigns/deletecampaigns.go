@@ -1,22 +1,38 @@
 package deletecampaigns
 
 import (
+	"context"
+	"fmt"
+	"github.com/zdarovich/promotion-api/internal/api/caller"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/api/requests/root"
 	"github.com/zdarovich/promotion-api/internal/api/response"
 	"github.com/zdarovich/promotion-api/internal/config"
 	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/assignment"
 	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
 	"strconv"
 )
 
 type (
 	// DeleteCampaigns struct
 	DeleteCampaigns struct {
-		CampaignRepository campaign.IRepository
-		CampaignHelper     campaignhelper.ICampaignHelper
-		Configuration      *config.Configuration
-		InputParameters    inputParameters
+		CampaignRepository   campaign.IRepository
+		AssignmentRepository assignment.IRepository
+		SettingsRepository   settings.IRepository
+		TierRepository       tier.IRepository
+		ProductSetRepository productset.IRepository
+		ScheduleRepository   schedule.IRepository
+		CampaignHelper       campaignhelper.ICampaignHelper
+		WebhookHelper        webhookhelper.IWebhookHelper
+		Configuration        *config.Configuration
+		InputParameters      inputParameters
 	}
 	// requestParams the parameters that can be used for searching
 	inputParameters struct {
@@ -38,7 +54,7 @@ type (
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Router /deleteCampaigns [POST]
-func (deleteCampaigns *DeleteCampaigns) Handle(context root.IGinContext) (*response.Data, error) {
+func (deleteCampaigns *DeleteCampaigns) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
 
 	err := deleteCampaigns.validate(context)
 
@@ -52,13 +68,60 @@ func (deleteCampaigns *DeleteCampaigns) Handle(context root.IGinContext) (*respo
 
 	var campaigns []campaign.Campaign
 
-	err = deleteCampaigns.CampaignRepository.DeleteCampaigns(
+	// Read before it is deleted for the event
+	deleted, err := deleteCampaigns.CampaignRepository.GetCampaignsByIDs(ctx,
+		[]int{deleteCampaigns.InputParameters.CampaignID},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = deleteCampaigns.CampaignRepository.DeleteCampaigns(ctx,
+		deleteCampaigns.InputParameters.CampaignID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = deleteCampaigns.SettingsRepository.DeleteSettingsByCampaignID(ctx,
+		deleteCampaigns.InputParameters.CampaignID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = deleteCampaigns.TierRepository.DeleteTiersByCampaignID(ctx,
 		deleteCampaigns.InputParameters.CampaignID,
 	)
 	if err != nil {
 		return nil, err
 	}
-	totalRecordsCount, err = deleteCampaigns.CampaignRepository.GetCampaignsCount(
+	err = deleteCampaigns.ProductSetRepository.DeleteProductSetsByCampaignID(ctx,
+		deleteCampaigns.InputParameters.CampaignID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = deleteCampaigns.ScheduleRepository.DeleteSchedulesByCampaignID(ctx,
+		deleteCampaigns.InputParameters.CampaignID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	err = deleteCampaigns.AssignmentRepository.DeleteAssignments(ctx,
+		deleteCampaigns.InputParameters.CampaignID,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	// The campaign is deleted, a failed event is logged only
+	authenticated, _ := caller.FromContext(ctx)
+	for _, c := range deleted {
+		err = deleteCampaigns.WebhookHelper.Publish(ctx, authenticated.ClientCode, webhookhelper.EventDeleted, c)
+		if err != nil {
+			log.Error(fmt.Sprintf("%s event of campaign %d not published: %s", webhookhelper.EventDeleted, c.ID, err))
+		}
+	}
+	totalRecordsCount, err = deleteCampaigns.CampaignRepository.GetCampaignsCount(ctx,
 		deleteCampaigns.InputParameters.CampaignID,
 		"",
 	)
@@ -78,9 +141,15 @@ func (deleteCampaigns *DeleteCampaigns) Handle(context root.IGinContext) (*respo
 func New(configuration *config.Configuration) root.IRoot {
 
 	return &DeleteCampaigns{
-		CampaignRepository: campaign.New(configuration),
-		CampaignHelper:     campaignhelper.New(configuration),
-		Configuration:      configuration,
+		CampaignRepository:   campaign.New(configuration),
+		AssignmentRepository: assignment.New(configuration),
+		SettingsRepository:   settings.New(configuration),
+		TierRepository:       tier.New(configuration),
+		ProductSetRepository: productset.New(configuration),
+		ScheduleRepository:   schedule.New(configuration),
+		CampaignHelper:       campaignhelper.New(configuration),
+		WebhookHelper:        webhookhelper.New(configuration),
+		Configuration:        configuration,
 	}
 }
 
@@ -0,0 +1,87 @@
+package deletesegments
+
+import (
+	"context"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/segment"
+	"strconv"
+)
+
+type (
+	// DeleteSegments struct
+	DeleteSegments struct {
+		SegmentRepository segment.IRepository
+		Configuration     *config.Configuration
+		InputParameters   inputParameters
+	}
+	// requestParams the parameters that can be used for searching
+	inputParameters struct {
+		SegmentID int
+	}
+)
+
+// @Summary Delete customer segment
+// @Description  Delete customer segment
+// @Tags segment
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "deleteSegments"
+// @Param segmentID formData string true "1"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /deleteSegments [POST]
+func (deleteSegments *DeleteSegments) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	err := deleteSegments.validate(context)
+	if err != nil {
+		return nil, err
+	}
+
+	err = deleteSegments.SegmentRepository.DeleteSegment(ctx,
+		deleteSegments.InputParameters.SegmentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	totalRecordsCount, err := deleteSegments.SegmentRepository.GetSegmentsCount(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Data{
+		Total:           totalRecordsCount,
+		TotalInResponse: 0,
+		Records:         []interface{}{},
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &DeleteSegments{
+		SegmentRepository: segment.New(configuration),
+		Configuration:     configuration,
+	}
+}
+
+// validate checks if the required parameters have been set
+func (deleteSegments *DeleteSegments) validate(context root.IGinContext) error {
+
+	inputParameters := inputParameters{}
+	inputParameters.SegmentID, _ = strconv.Atoi(context.PostForm("segmentID"))
+
+	// Required parameters
+	if inputParameters.SegmentID == 0 {
+
+		return errorcodes.New("segmentID", errorcodes.CodeRequiredParameterMissing)
+	}
+
+	deleteSegments.InputParameters = inputParameters
+	return nil
+}
@@ -0,0 +1,64 @@
+package deletewebhook
+
+import (
+	"context"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/webhook"
+	"strconv"
+)
+
+type (
+	// DeleteWebhook struct
+	DeleteWebhook struct {
+		WebhookRepository webhook.IRepository
+		Configuration     *config.Configuration
+	}
+)
+
+// @Summary Delete webhook
+// @Description  Deletes a webhook and its deliveries, the pending ones are not sent
+// @Tags webhook
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "deleteWebhook"
+// @Param webhookID formData string true "1"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /deleteWebhook [POST]
+func (deleteWebhook *DeleteWebhook) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	webhookID, _ := strconv.Atoi(context.PostForm("webhookID"))
+	if webhookID == 0 {
+		return nil, errorcodes.New("webhookID", errorcodes.CodeRequiredParameterMissing)
+	}
+
+	err := deleteWebhook.WebhookRepository.DeleteWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+	webhooks, err := deleteWebhook.WebhookRepository.GetWebhooks(ctx)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	return &response.Data{
+		Total:           len(webhooks),
+		TotalInResponse: 0,
+		Records:         []interface{}{},
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &DeleteWebhook{
+		WebhookRepository: webhook.New(configuration),
+		Configuration:     configuration,
+	}
+}
@@ -0,0 +1,79 @@
+package getapikeys
+
+import (
+	"context"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
+)
+
+type (
+	// GetAPIKeys struct
+	GetAPIKeys struct {
+		APIKeyRepository apikey.IRepository
+		Configuration    *config.Configuration
+	}
+	// record structure of the output record, the keys themselves are not
+	// stored and cannot be output
+	record struct {
+		APIKeyID  int    `json:"apiKeyID"`
+		Name      string `json:"name"`
+		Scope     string `json:"scope"`
+		Expires   int64  `json:"expires"`
+		Added     int64  `json:"added"`
+		Addedby   string `json:"addedby"`
+		Revoked   int64  `json:"revoked"`
+		Revokedby string `json:"revokedby"`
+	}
+)
+
+// @Summary Get API keys
+// @Description  Get the API keys of the account, the revoked ones included
+// @Tags apikey
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "getAPIKeys"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /getAPIKeys [POST]
+func (getAPIKeys *GetAPIKeys) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	keys, err := getAPIKeys.APIKeyRepository.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	records := make([]record, len(keys))
+	for idx, k := range keys {
+		records[idx] = record{
+			APIKeyID:  k.ID,
+			Name:      k.Name,
+			Scope:     k.Scope,
+			Expires:   k.Expires,
+			Added:     k.Added,
+			Addedby:   k.Addedby,
+			Revoked:   k.Revoked,
+			Revokedby: k.Revokedby,
+		}
+	}
+
+	return &response.Data{
+		Total:           len(records),
+		TotalInResponse: len(records),
+		Records:         records,
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &GetAPIKeys{
+		APIKeyRepository: apikey.New(configuration),
+		Configuration:    configuration,
+	}
+}
@@ -0,0 +1,223 @@
+package getcampaignchanges
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
+	"strconv"
+)
+
+const (
+	// DefaultRecordsOnPage changes returned without recordsOnPage
+	DefaultRecordsOnPage = 100
+	// MaxRecordsOnPage changes returned at most
+	MaxRecordsOnPage = 1000
+)
+
+type (
+	// GetCampaignChanges struct
+	GetCampaignChanges struct {
+		CampaignRepository   campaign.IRepository
+		SettingsRepository   settings.IRepository
+		TierRepository       tier.IRepository
+		ProductSetRepository productset.IRepository
+		ScheduleRepository   schedule.IRepository
+		CampaignHelper       campaignhelper.ICampaignHelper
+		Configuration        *config.Configuration
+		InputParameters      inputParameters
+	}
+	inputParameters struct {
+		// Seq of the last change the caller has, 0 without a sync token
+		Seq           int64
+		FullSync      bool
+		RecordsOnPage int
+	}
+	// Record changes of the campaigns since the sync token
+	Record struct {
+		SyncToken          string                        `json:"syncToken"`
+		HasMore            bool                          `json:"hasMore"`
+		Campaigns          []campaignhelper.RecordOutput `json:"campaigns"`
+		DeletedCampaignIDs []int                         `json:"deletedCampaignIDs"`
+	}
+)
+
+// @Summary Get campaign changes
+// @Description  Get the campaigns created or updated and the ids of the campaigns deleted since the sync token. Without a sync token all the campaigns are returned. The response has the sync token of the next request, repeat the request with it while hasMore is set. A campaign is returned once with its latest state even if it changed several times.
+// @Tags campaign
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "getCampaignChanges"
+// @Param syncToken formData string false "MTI"
+// @Param recordsOnPage formData string false "100"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /getCampaignChanges [POST]
+func (getCampaignChanges *GetCampaignChanges) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	err := getCampaignChanges.validate(context)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// One change more than the page tells whether there are more
+	params := getCampaignChanges.InputParameters
+	changes, err := getCampaignChanges.CampaignRepository.GetChanges(ctx,
+		params.Seq,
+		!params.FullSync,
+		params.RecordsOnPage+1,
+	)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	record := Record{
+		Campaigns:          make([]campaignhelper.RecordOutput, 0),
+		DeletedCampaignIDs: make([]int, 0),
+	}
+	if len(changes) > params.RecordsOnPage {
+		changes = changes[:params.RecordsOnPage]
+		record.HasMore = true
+	}
+
+	seq := params.Seq
+	ids := make([]int, 0, len(changes))
+	for _, c := range changes {
+		seq = c.Seq
+		if c.Deleted {
+			record.DeletedCampaignIDs = append(record.DeletedCampaignIDs, c.CampaignID)
+			continue
+		}
+		ids = append(ids, c.CampaignID)
+	}
+	record.SyncToken = EncodeToken(seq)
+
+	if len(ids) > 0 {
+		campaigns, err := getCampaignChanges.CampaignRepository.GetCampaignsByIDs(ctx, ids)
+		if err != nil {
+			return nil, errorcodes.Wrap(err, 1003)
+		}
+		record.Campaigns, err = getCampaignChanges.mapToArray(ctx, campaigns)
+		if err != nil {
+			return nil, errorcodes.Wrap(err, 1003)
+		}
+
+		// A campaign deleted after its change was read is reported as
+		// deleted, its tombstone follows in a later page
+		loaded := make(map[int]bool, len(campaigns))
+		for _, c := range campaigns {
+			loaded[c.ID] = true
+		}
+		if !params.FullSync {
+			for _, id := range ids {
+				if !loaded[id] {
+					record.DeletedCampaignIDs = append(record.DeletedCampaignIDs, id)
+				}
+			}
+		}
+	}
+
+	return &response.Data{
+		Total:           1,
+		TotalInResponse: 1,
+		Records:         []Record{record},
+	}, nil
+}
+
+// mapToArray loads the attributes and child records of the campaigns
+// and maps them to output records
+func (getCampaignChanges *GetCampaignChanges) mapToArray(ctx context.Context, campaigns []campaign.Campaign) ([]campaignhelper.RecordOutput, error) {
+
+	var err error
+	ids := campaign.GetIds(campaigns)
+	relations := campaignhelper.Relations{}
+	relations.Settings, err = getCampaignChanges.SettingsRepository.GetSettings(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.Tiers, err = getCampaignChanges.TierRepository.GetTiers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.ProductSets, err = getCampaignChanges.ProductSetRepository.GetProductSets(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.Schedules, err = getCampaignChanges.ScheduleRepository.GetSchedules(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return getCampaignChanges.CampaignHelper.MapToArray(ctx, campaigns, relations)
+}
+
+// EncodeToken returns the sync token of the sequence number
+func EncodeToken(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(seq, 10)))
+}
+
+// DecodeToken returns the sequence number of the sync token
+func DecodeToken(token string) (int64, bool) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, false
+	}
+	seq, err := strconv.ParseInt(string(decoded), 10, 64)
+	if err != nil || seq < 0 {
+		return 0, false
+	}
+	return seq, true
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &GetCampaignChanges{
+		CampaignRepository:   campaign.New(configuration),
+		SettingsRepository:   settings.New(configuration),
+		TierRepository:       tier.New(configuration),
+		ProductSetRepository: productset.New(configuration),
+		ScheduleRepository:   schedule.New(configuration),
+		CampaignHelper:       campaignhelper.New(configuration),
+		Configuration:        configuration,
+	}
+}
+
+// validate checks the sync token and sets the defaults
+func (getCampaignChanges *GetCampaignChanges) validate(context root.IGinContext) error {
+
+	inputParameters := inputParameters{}
+	token := context.PostForm("syncToken")
+	if token == "" {
+		inputParameters.FullSync = true
+	} else {
+		var ok bool
+		inputParameters.Seq, ok = DecodeToken(token)
+		if !ok {
+			return errorcodes.New("syncToken", 1014)
+		}
+	}
+	inputParameters.RecordsOnPage, _ = strconv.Atoi(context.PostForm("recordsOnPage"))
+
+	// Set defaults
+	if inputParameters.RecordsOnPage <= 0 {
+		inputParameters.RecordsOnPage = DefaultRecordsOnPage
+	}
+	if inputParameters.RecordsOnPage > MaxRecordsOnPage {
+		inputParameters.RecordsOnPage = MaxRecordsOnPage
+	}
+
+	getCampaignChanges.InputParameters = inputParameters
+	return nil
+}
@@ -0,0 +1,99 @@
+package getcampaignchanges
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
+)
+
+type form url.Values
+
+func (f form) PostForm(key string) string { return url.Values(f).Get(key) }
+
+func newHandler(campaigns *campaign.MemoryRepository) *GetCampaignChanges {
+	return &GetCampaignChanges{
+		CampaignRepository:   campaigns,
+		SettingsRepository:   settings.NewMemory(attributes.NewMemory()),
+		TierRepository:       tier.NewMemory(),
+		ProductSetRepository: productset.NewMemory(),
+		ScheduleRepository:   schedule.NewMemory(),
+		CampaignHelper:       &campaignhelper.CampaignHelper{CampaignRepository: campaigns, ConfigRepository: config.NewMemory()},
+	}
+}
+
+func sync(t *testing.T, handler *GetCampaignChanges, token string, records int) Record {
+	data, err := handler.Handle(context.Background(), form{
+		"syncToken":     {token},
+		"recordsOnPage": {strconv.Itoa(records)},
+	})
+	assert.Nil(t, err)
+	return data.Records.([]Record)[0]
+}
+
+func ids(records []campaignhelper.RecordOutput) []int {
+	result := make([]int, len(records))
+	for idx, r := range records {
+		result[idx] = r.CampaignID
+	}
+	return result
+}
+
+func TestGetCampaignChanges_Handle_Pages(t *testing.T) {
+	campaigns := campaign.NewMemory()
+	for idx := 0; idx < 3; idx++ {
+		assert.Nil(t, campaigns.SaveCampaigns(context.Background(), &campaign.Campaign{Name: "campaign"}))
+	}
+	assert.Nil(t, campaigns.DeleteCampaigns(context.Background(), 2))
+	handler := newHandler(campaigns)
+
+	// A full sync has no tombstones
+	first := sync(t, handler, "", 1)
+	assert.True(t, first.HasMore)
+	assert.Equal(t, []int{1}, ids(first.Campaigns))
+
+	// Changes made between the pages follow in the later pages
+	assert.Nil(t, campaigns.UpdateCampaigns(context.Background(), campaign.Campaign{ID: 1, Name: "updated"}))
+	second := sync(t, handler, first.SyncToken, 1)
+	assert.True(t, second.HasMore)
+	assert.Equal(t, []int{3}, ids(second.Campaigns))
+	assert.Empty(t, second.DeletedCampaignIDs)
+	last := sync(t, handler, second.SyncToken, 10)
+	assert.False(t, last.HasMore)
+	assert.Equal(t, []int{2}, last.DeletedCampaignIDs)
+	assert.Equal(t, []int{1}, ids(last.Campaigns))
+	assert.Equal(t, "updated", last.Campaigns[0].Name)
+
+	// Nothing changed since the last token
+	empty := sync(t, handler, last.SyncToken, 10)
+	assert.Equal(t, last.SyncToken, empty.SyncToken)
+	assert.Empty(t, empty.Campaigns)
+	assert.Empty(t, empty.DeletedCampaignIDs)
+}
+
+func TestGetCampaignChanges_Handle_InvalidToken(t *testing.T) {
+	handler := newHandler(campaign.NewMemory())
+
+	for _, token := range []string{"!", EncodeToken(-1)[:1], "YWJj"} {
+		_, err := handler.Handle(context.Background(), form{"syncToken": {token}})
+		assert.NotNil(t, err, token)
+	}
+}
+
+func TestDecodeToken(t *testing.T) {
+	seq, ok := DecodeToken(EncodeToken(12))
+	assert.True(t, ok)
+	assert.Equal(t, int64(12), seq)
+	_, ok = DecodeToken(EncodeToken(-1))
+	assert.False(t, ok)
+}
@@ -1,24 +1,32 @@
 package getcampaigns
 
 import (
+	"context"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/api/requests/root"
 	"github.com/zdarovich/promotion-api/internal/api/response"
 	"github.com/zdarovich/promotion-api/internal/config"
 	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
-	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
 	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
 	"strconv"
+	"time"
 )
 
 type (
 	// GetCampaigns struct
 	GetCampaigns struct {
-		CampaignRepository  campaign.IRepository
-		AttributeRepository attributes.IRepository
-		CampaignHelper      campaignhelper.ICampaignHelper
-		Configuration       *config.Configuration
-		InputParameters     inputParameters
+		CampaignRepository   campaign.IRepository
+		SettingsRepository   settings.IRepository
+		TierRepository       tier.IRepository
+		ProductSetRepository productset.IRepository
+		ScheduleRepository   schedule.IRepository
+		CampaignHelper       campaignhelper.ICampaignHelper
+		Configuration        *config.Configuration
+		InputParameters      inputParameters
 	}
 	// requestParams the parameters that can be used for searching
 	inputParameters struct {
@@ -26,6 +34,7 @@ type (
 		CampaignType  string
 		RecordsOnPage int
 		PageNo        int
+		ActiveNow     bool
 	}
 )
 
@@ -40,11 +49,13 @@ type (
 // @Param campaignID formData string false "1"
 // @Param recordsOnPage formData string false "1"
 // @Param pageNo formData string false "1"
+// @Description  activeNow - Set to 1 to return only the campaigns that are active at the moment of the request, honoring their date range and recurring schedules.
+// @Param activeNow formData string false "1"
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Router /getCampaigns [POST]
-func (getCampaigns *GetCampaigns) Handle(context root.IGinContext) (*response.Data, error) {
+func (getCampaigns *GetCampaigns) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
 
 	err := getCampaigns.validate(context)
 
@@ -52,10 +63,14 @@ func (getCampaigns *GetCampaigns) Handle(context root.IGinContext) (*response.Da
 		return nil, err
 	}
 
+	if getCampaigns.InputParameters.ActiveNow {
+		return getCampaigns.handleActive(ctx, time.Now())
+	}
+
 	var totalRecordsCount int = 0
 	var recordsCount int = 0
 	var records interface{}
-	campaigns, err := getCampaigns.CampaignRepository.GetCampaigns(
+	campaigns, err := getCampaigns.CampaignRepository.GetCampaigns(ctx,
 		getCampaigns.InputParameters.CampaignID,
 		getCampaigns.InputParameters.CampaignType,
 		getCampaigns.InputParameters.RecordsOnPage,
@@ -64,7 +79,7 @@ func (getCampaigns *GetCampaigns) Handle(context root.IGinContext) (*response.Da
 	if err != nil {
 		return nil, errorcodes.Wrap(err, 1003)
 	}
-	totalRecordsCount, err = getCampaigns.CampaignRepository.GetCampaignsCount(
+	totalRecordsCount, err = getCampaigns.CampaignRepository.GetCampaignsCount(ctx,
 		getCampaigns.InputParameters.CampaignID,
 		getCampaigns.InputParameters.CampaignType,
 	)
@@ -72,12 +87,8 @@ func (getCampaigns *GetCampaigns) Handle(context root.IGinContext) (*response.Da
 		return nil, errorcodes.Wrap(err, 1003)
 	}
 
-	attrs, err := getCampaigns.AttributeRepository.GetAttributes(campaign.GetIds(campaigns))
-	if err != nil {
-		return nil, errorcodes.Wrap(err, 1003)
-	}
 	recordsCount = len(campaigns)
-	records, err = getCampaigns.CampaignHelper.MapToArray(campaigns, attrs)
+	records, err = getCampaigns.mapToArray(ctx, campaigns)
 	if err != nil {
 		return nil, errorcodes.Wrap(err, 1003)
 	}
@@ -88,14 +99,85 @@ func (getCampaigns *GetCampaigns) Handle(context root.IGinContext) (*response.Da
 	}, nil
 }
 
+// handleActive returns the page of campaigns that are active at the instant.
+// Schedules can only be evaluated after loading, thus all campaigns within
+// the date range are loaded and paginated in memory
+func (getCampaigns *GetCampaigns) handleActive(ctx context.Context, now time.Time) (*response.Data, error) {
+
+	campaigns, err := getCampaigns.CampaignRepository.GetActiveCampaigns(ctx,
+		now,
+		getCampaigns.InputParameters.CampaignID,
+		getCampaigns.InputParameters.CampaignType,
+	)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	records, err := getCampaigns.mapToArray(ctx, campaigns)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	active := make([]campaignhelper.RecordOutput, 0)
+	for _, record := range records {
+		if campaignhelper.IsActiveAt(record, now) {
+			active = append(active, record)
+		}
+	}
+
+	from := getCampaigns.InputParameters.RecordsOnPage * getCampaigns.InputParameters.PageNo
+	if from > len(active) {
+		from = len(active)
+	}
+	to := from + getCampaigns.InputParameters.RecordsOnPage
+	if to > len(active) {
+		to = len(active)
+	}
+
+	return &response.Data{
+		Total:           len(active),
+		TotalInResponse: to - from,
+		Records:         active[from:to],
+	}, nil
+}
+
+// mapToArray loads the attributes and child records of the campaigns
+// and maps them to output records
+func (getCampaigns *GetCampaigns) mapToArray(ctx context.Context, campaigns []campaign.Campaign) ([]campaignhelper.RecordOutput, error) {
+
+	var err error
+	ids := campaign.GetIds(campaigns)
+	relations := campaignhelper.Relations{}
+	relations.Settings, err = getCampaigns.SettingsRepository.GetSettings(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.Tiers, err = getCampaigns.TierRepository.GetTiers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.ProductSets, err = getCampaigns.ProductSetRepository.GetProductSets(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.Schedules, err = getCampaigns.ScheduleRepository.GetSchedules(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return getCampaigns.CampaignHelper.MapToArray(ctx, campaigns, relations)
+}
+
 // New return configured struct
 func New(configuration *config.Configuration) root.IRoot {
 
 	return &GetCampaigns{
-		CampaignRepository:  campaign.New(configuration),
-		AttributeRepository: attributes.New(configuration),
-		CampaignHelper:      campaignhelper.New(configuration),
-		Configuration:       configuration,
+		CampaignRepository:   campaign.New(configuration),
+		SettingsRepository:   settings.New(configuration),
+		TierRepository:       tier.New(configuration),
+		ProductSetRepository: productset.New(configuration),
+		ScheduleRepository:   schedule.New(configuration),
+		CampaignHelper:       campaignhelper.New(configuration),
+		Configuration:        configuration,
 	}
 }
 
@@ -106,6 +188,7 @@ func (getCampaigns *GetCampaigns) validate(context root.IGinContext) error {
 	inputParameters.CampaignID, _ = strconv.Atoi(context.PostForm("campaignID"))
 	inputParameters.RecordsOnPage, _ = strconv.Atoi(context.PostForm("recordsOnPage"))
 	inputParameters.PageNo, _ = strconv.Atoi(context.PostForm("pageNo"))
+	inputParameters.ActiveNow = context.PostForm("activeNow") == "1"
 
 	// Required parameters
 	//if inputParameters.CampaignID == 0 {
@@ -1 +1,124 @@
 package getcampaigns
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	campaignMocks "github.com/zdarovich/promotion-api/internal/repositories/campaign/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/config"
+	configMocks "github.com/zdarovich/promotion-api/internal/repositories/config/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	productSetMocks "github.com/zdarovich/promotion-api/internal/repositories/productset/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	scheduleMocks "github.com/zdarovich/promotion-api/internal/repositories/schedule/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	settingsMocks "github.com/zdarovich/promotion-api/internal/repositories/settings/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
+	tierMocks "github.com/zdarovich/promotion-api/internal/repositories/tier/mocks"
+)
+
+func TestGetCampaigns_handleActive_FiltersBySchedule(t *testing.T) {
+	// Monday 17:00
+	now := time.Date(2020, time.May, 4, 17, 0, 0, 0, time.UTC)
+	start := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, time.May, 31, 0, 0, 0, 0, time.UTC)
+	campaigns := []campaign.Campaign{
+		{ID: 1, StartDate: start, EndDate: end},
+		{ID: 2, StartDate: start, EndDate: end},
+		{ID: 3, StartDate: start, EndDate: end},
+	}
+
+	cr := new(campaignMocks.IRepository)
+	cr.On("GetActiveCampaigns", mock.Anything, now, 0, "").Return(campaigns, nil)
+	str := new(settingsMocks.IRepository)
+	str.On("GetSettings", mock.Anything, []int{1, 2, 3}).Return(nil, nil)
+	tr := new(tierMocks.IRepository)
+	tr.On("GetTiers", mock.Anything, mock.Anything).Return(nil, nil)
+	pr := new(productSetMocks.IRepository)
+	pr.On("GetProductSets", mock.Anything, mock.Anything).Return(nil, nil)
+	sr := new(scheduleMocks.IRepository)
+	sr.On("GetSchedules", mock.Anything, []int{1, 2, 3}).Return(map[int][]*schedule.Schedule{
+		// weekend only
+		2: {{CampaignID: 2, DaysOfWeek: "6,7"}},
+		// weekday happy hour
+		3: {{CampaignID: 3, DaysOfWeek: "1,2,3,4,5", StartTime: "16:00", EndTime: "18:00"}},
+	}, nil)
+
+	cfr := new(configMocks.IRepository)
+	cfr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{}, nil)
+
+	gc := &GetCampaigns{
+		CampaignRepository:   cr,
+		SettingsRepository:   str,
+		TierRepository:       tr,
+		ProductSetRepository: pr,
+		ScheduleRepository:   sr,
+		CampaignHelper:       &campaignhelper.CampaignHelper{ConfigRepository: cfr},
+		InputParameters: inputParameters{
+			RecordsOnPage: 20,
+			ActiveNow:     true,
+		},
+	}
+
+	data, err := gc.handleActive(context.Background(), now)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, data.Total)
+	assert.Equal(t, 2, data.TotalInResponse)
+	records := data.Records.([]campaignhelper.RecordOutput)
+	assert.Equal(t, 1, records[0].CampaignID)
+	assert.Equal(t, 3, records[1].CampaignID)
+
+	gc.InputParameters.RecordsOnPage = 1
+	gc.InputParameters.PageNo = 1
+
+	data, err = gc.handleActive(context.Background(), now)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, data.Total)
+	assert.Equal(t, 1, data.TotalInResponse)
+	assert.Equal(t, 3, data.Records.([]campaignhelper.RecordOutput)[0].CampaignID)
+}
+
+type form url.Values
+
+func (f form) PostForm(key string) string { return url.Values(f).Get(key) }
+
+func TestGetCampaigns_Handle_InMemory(t *testing.T) {
+	campaigns := campaign.NewMemory()
+	start := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+	for _, campaignType := range []string{"auto", "manual", "auto"} {
+		c := &campaign.Campaign{Type: campaignType, StartDate: start, EndDate: start.AddDate(0, 1, 0)}
+		assert.Nil(t, campaigns.SaveCampaigns(context.Background(), c))
+	}
+	campaignSettings := settings.NewMemory(attributes.NewMemory())
+	assert.Nil(t, campaignSettings.SaveSettings(context.Background(), &settings.Settings{CampaignID: 3, Priority: 2}))
+	tiers := tier.NewMemory()
+	assert.Nil(t, tiers.SaveTiers(context.Background(), []*tier.Tier{{CampaignID: 3, PurchasedAmount: 5, PercentageOff: 10}}))
+
+	gc := &GetCampaigns{
+		CampaignRepository:   campaigns,
+		SettingsRepository:   campaignSettings,
+		TierRepository:       tiers,
+		ProductSetRepository: productset.NewMemory(),
+		ScheduleRepository:   schedule.NewMemory(),
+		CampaignHelper:       &campaignhelper.CampaignHelper{CampaignRepository: campaigns, ConfigRepository: config.NewMemory()},
+	}
+
+	data, err := gc.Handle(context.Background(), form{"recordsOnPage": {"2"}, "pageNo": {"1"}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, data.Total)
+	records := data.Records.([]campaignhelper.RecordOutput)
+	assert.Len(t, records, 1)
+	assert.Equal(t, 3, records[0].CampaignID)
+	assert.Equal(t, 2, records[0].Priority)
+	assert.Len(t, records[0].Tiers, 1)
+}
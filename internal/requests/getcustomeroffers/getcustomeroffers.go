@@ -0,0 +1,172 @@
+package getcustomeroffers
+
+import (
+	"context"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/assignment"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
+	"strconv"
+	"time"
+)
+
+type (
+	// GetCustomerOffers struct
+	GetCustomerOffers struct {
+		CampaignRepository   campaign.IRepository
+		AssignmentRepository assignment.IRepository
+		SettingsRepository   settings.IRepository
+		TierRepository       tier.IRepository
+		ProductSetRepository productset.IRepository
+		ScheduleRepository   schedule.IRepository
+		CampaignHelper       campaignhelper.ICampaignHelper
+		Configuration        *config.Configuration
+		InputParameters      inputParameters
+	}
+	// requestParams the parameters that can be used for searching
+	inputParameters struct {
+		CustomerID int
+	}
+	// offer structure of the output record, the campaign with the expiry of
+	// its assignment to the customer
+	offer struct {
+		campaignhelper.RecordOutput
+		OfferExpires *time.Time `json:"offerExpires"`
+	}
+)
+
+// @Summary Get personalized offers of a customer
+// @Description  Returns the active campaigns the customer has been assigned to with saveCampaignCustomers and whose assignment has not expired. Of the campaigns that do not combine by priority, exclusive, stackingGroup and combinableWith the campaign with the highest priority is returned, ordered by descending priority
+// @Tags campaign
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "getCustomerOffers"
+// @Param customerID formData string true "1"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /getCustomerOffers [POST]
+func (getCustomerOffers *GetCustomerOffers) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	err := getCustomerOffers.validate(context)
+	if err != nil {
+		return nil, err
+	}
+
+	offers, err := getCustomerOffers.getOffers(ctx, time.Now())
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	return &response.Data{
+		Total:           len(offers),
+		TotalInResponse: len(offers),
+		Records:         offers,
+	}, nil
+}
+
+// getOffers returns the campaigns assigned to the customer that are active at
+// the instant and combine by the stacking rules
+func (getCustomerOffers *GetCustomerOffers) getOffers(ctx context.Context, now time.Time) ([]offer, error) {
+
+	assignments, err := getCustomerOffers.AssignmentRepository.GetCustomerAssignments(ctx,
+		getCustomerOffers.InputParameters.CustomerID,
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	expires := make(map[int]int64, len(assignments))
+	campaignIDs := make([]int, len(assignments))
+	for idx, a := range assignments {
+		expires[a.CampaignID] = a.Expires
+		campaignIDs[idx] = a.CampaignID
+	}
+
+	campaigns, err := getCustomerOffers.CampaignRepository.GetCampaignsByIDs(ctx, campaignIDs)
+	if err != nil {
+		return nil, err
+	}
+	ids := campaign.GetIds(campaigns)
+	relations := campaignhelper.Relations{}
+	relations.Settings, err = getCustomerOffers.SettingsRepository.GetSettings(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.Tiers, err = getCustomerOffers.TierRepository.GetTiers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.ProductSets, err = getCustomerOffers.ProductSetRepository.GetProductSets(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.Schedules, err = getCustomerOffers.ScheduleRepository.GetSchedules(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	records, err := getCustomerOffers.CampaignHelper.MapToArray(ctx, campaigns, relations)
+	if err != nil {
+		return nil, err
+	}
+
+	active := make([]campaignhelper.RecordOutput, 0)
+	for _, record := range records {
+		if campaignhelper.IsActiveAt(record, now) {
+			active = append(active, record)
+		}
+	}
+
+	// The offers are applied together, the stacking rules pick the ones
+	// that combine
+	offers := make([]offer, 0)
+	for _, record := range campaignhelper.SelectCombinable(active) {
+		o := offer{RecordOutput: record}
+		if expires[record.CampaignID] > 0 {
+			t := time.Unix(expires[record.CampaignID], 0).UTC()
+			o.OfferExpires = &t
+		}
+		offers = append(offers, o)
+	}
+	return offers, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &GetCustomerOffers{
+		CampaignRepository:   campaign.New(configuration),
+		AssignmentRepository: assignment.New(configuration),
+		SettingsRepository:   settings.New(configuration),
+		TierRepository:       tier.New(configuration),
+		ProductSetRepository: productset.New(configuration),
+		ScheduleRepository:   schedule.New(configuration),
+		CampaignHelper:       campaignhelper.New(configuration),
+		Configuration:        configuration,
+	}
+}
+
+// validate checks if the required parameters have been set
+func (getCustomerOffers *GetCustomerOffers) validate(context root.IGinContext) error {
+
+	inputParameters := inputParameters{}
+	inputParameters.CustomerID, _ = strconv.Atoi(context.PostForm("customerID"))
+
+	// Required parameters
+	if inputParameters.CustomerID == 0 {
+
+		return errorcodes.New("customerID", errorcodes.CodeRequiredParameterMissing)
+	}
+
+	getCustomerOffers.InputParameters = inputParameters
+	return nil
+}
@@ -0,0 +1,128 @@
+package getcustomeroffers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/assignment"
+	assignmentMocks "github.com/zdarovich/promotion-api/internal/repositories/assignment/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	campaignMocks "github.com/zdarovich/promotion-api/internal/repositories/campaign/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/config"
+	configMocks "github.com/zdarovich/promotion-api/internal/repositories/config/mocks"
+	productSetMocks "github.com/zdarovich/promotion-api/internal/repositories/productset/mocks"
+	scheduleMocks "github.com/zdarovich/promotion-api/internal/repositories/schedule/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	settingsMocks "github.com/zdarovich/promotion-api/internal/repositories/settings/mocks"
+	tierMocks "github.com/zdarovich/promotion-api/internal/repositories/tier/mocks"
+)
+
+func TestGetCustomerOffers_getOffers(t *testing.T) {
+	now := time.Date(2020, time.May, 4, 17, 0, 0, 0, time.UTC)
+	start := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, time.May, 31, 0, 0, 0, 0, time.UTC)
+	expires := time.Date(2020, time.May, 10, 0, 0, 0, 0, time.UTC)
+
+	asr := new(assignmentMocks.IRepository)
+	asr.On("GetCustomerAssignments", mock.Anything, 9, now).Return([]*assignment.Assignment{
+		{CampaignID: 1, CustomerID: 9},
+		{CampaignID: 2, CustomerID: 9, Expires: expires.Unix()},
+		{CampaignID: 3, CustomerID: 9},
+	}, nil)
+	cr := new(campaignMocks.IRepository)
+	cr.On("GetCampaignsByIDs", mock.Anything, []int{1, 2, 3}).Return([]campaign.Campaign{
+		{ID: 1, StartDate: start, EndDate: end},
+		{ID: 2, StartDate: start, EndDate: end},
+		// ended
+		{ID: 3, StartDate: start, EndDate: start.Add(24 * time.Hour)},
+	}, nil)
+	str := new(settingsMocks.IRepository)
+	str.On("GetSettings", mock.Anything, []int{1, 2, 3}).Return(nil, nil)
+	tr := new(tierMocks.IRepository)
+	tr.On("GetTiers", mock.Anything, mock.Anything).Return(nil, nil)
+	pr := new(productSetMocks.IRepository)
+	pr.On("GetProductSets", mock.Anything, mock.Anything).Return(nil, nil)
+	sr := new(scheduleMocks.IRepository)
+	sr.On("GetSchedules", mock.Anything, mock.Anything).Return(nil, nil)
+	cfr := new(configMocks.IRepository)
+	cfr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{}, nil)
+
+	gco := &GetCustomerOffers{
+		CampaignRepository:   cr,
+		AssignmentRepository: asr,
+		SettingsRepository:   str,
+		TierRepository:       tr,
+		ProductSetRepository: pr,
+		ScheduleRepository:   sr,
+		CampaignHelper:       &campaignhelper.CampaignHelper{ConfigRepository: cfr},
+		InputParameters:      inputParameters{CustomerID: 9},
+	}
+
+	offers, err := gco.getOffers(context.Background(), now)
+
+	assert.Nil(t, err)
+	assert.Len(t, offers, 2)
+	assert.Equal(t, 1, offers[0].CampaignID)
+	assert.Nil(t, offers[0].OfferExpires)
+	assert.Equal(t, 2, offers[1].CampaignID)
+	assert.Equal(t, expires, *offers[1].OfferExpires)
+}
+
+func TestGetCustomerOffers_getOffers_StackingRules(t *testing.T) {
+	now := time.Date(2020, time.May, 4, 17, 0, 0, 0, time.UTC)
+	start := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, time.May, 31, 0, 0, 0, 0, time.UTC)
+
+	asr := new(assignmentMocks.IRepository)
+	asr.On("GetCustomerAssignments", mock.Anything, 9, now).Return([]*assignment.Assignment{
+		{CampaignID: 1, CustomerID: 9},
+		{CampaignID: 2, CustomerID: 9},
+		{CampaignID: 3, CustomerID: 9},
+		{CampaignID: 4, CustomerID: 9},
+	}, nil)
+	cr := new(campaignMocks.IRepository)
+	cr.On("GetCampaignsByIDs", mock.Anything, []int{1, 2, 3, 4}).Return([]campaign.Campaign{
+		{ID: 1, StartDate: start, EndDate: end},
+		{ID: 2, StartDate: start, EndDate: end},
+		{ID: 3, StartDate: start, EndDate: end},
+		{ID: 4, StartDate: start, EndDate: end},
+	}, nil)
+	str := new(settingsMocks.IRepository)
+	str.On("GetSettings", mock.Anything, []int{1, 2, 3, 4}).Return(map[int]*settings.Settings{
+		2: {CampaignID: 2, Priority: 5, StackingGroup: "coupons"},
+		// same stacking group, lower priority
+		3: {CampaignID: 3, Priority: 3, StackingGroup: "coupons"},
+		// exclusive, lower priority than the others picked
+		4: {CampaignID: 4, Exclusive: 1},
+	}, nil)
+	tr := new(tierMocks.IRepository)
+	tr.On("GetTiers", mock.Anything, mock.Anything).Return(nil, nil)
+	pr := new(productSetMocks.IRepository)
+	pr.On("GetProductSets", mock.Anything, mock.Anything).Return(nil, nil)
+	sr := new(scheduleMocks.IRepository)
+	sr.On("GetSchedules", mock.Anything, mock.Anything).Return(nil, nil)
+	cfr := new(configMocks.IRepository)
+	cfr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{}, nil)
+
+	gco := &GetCustomerOffers{
+		CampaignRepository:   cr,
+		AssignmentRepository: asr,
+		SettingsRepository:   str,
+		TierRepository:       tr,
+		ProductSetRepository: pr,
+		ScheduleRepository:   sr,
+		CampaignHelper:       &campaignhelper.CampaignHelper{ConfigRepository: cfr},
+		InputParameters:      inputParameters{CustomerID: 9},
+	}
+
+	offers, err := gco.getOffers(context.Background(), now)
+
+	assert.Nil(t, err)
+	assert.Len(t, offers, 2)
+	assert.Equal(t, 2, offers[0].CampaignID)
+	assert.Equal(t, 1, offers[1].CampaignID)
+}
@@ -0,0 +1,104 @@
+package getsegments
+
+import (
+	"context"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/segmenthelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/segment"
+	"strconv"
+)
+
+type (
+	// GetSegments struct
+	GetSegments struct {
+		SegmentRepository segment.IRepository
+		SegmentHelper     segmenthelper.ISegmentHelper
+		Configuration     *config.Configuration
+		InputParameters   inputParameters
+	}
+	// requestParams the parameters that can be used for searching
+	inputParameters struct {
+		SegmentID     int
+		RecordsOnPage int
+		PageNo        int
+	}
+)
+
+// @Summary Get customer segments
+// @Description  Get customer segments with their rules
+// @Tags segment
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "getSegments"
+// @Param segmentID formData string false "1"
+// @Param recordsOnPage formData string false "1"
+// @Param pageNo formData string false "1"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /getSegments [POST]
+func (getSegments *GetSegments) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	getSegments.validate(context)
+
+	segments, err := getSegments.SegmentRepository.GetSegments(ctx,
+		getSegments.InputParameters.SegmentID,
+		getSegments.InputParameters.RecordsOnPage,
+		getSegments.InputParameters.PageNo,
+	)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+	totalRecordsCount, err := getSegments.SegmentRepository.GetSegmentsCount(ctx,
+		getSegments.InputParameters.SegmentID,
+	)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	ids := make([]int, len(segments))
+	for idx, s := range segments {
+		ids[idx] = s.ID
+	}
+	rules, err := getSegments.SegmentRepository.GetRules(ctx, ids)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	return &response.Data{
+		Total:           totalRecordsCount,
+		TotalInResponse: len(segments),
+		Records:         getSegments.SegmentHelper.MapToArray(segments, rules),
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &GetSegments{
+		SegmentRepository: segment.New(configuration),
+		SegmentHelper:     segmenthelper.New(configuration),
+		Configuration:     configuration,
+	}
+}
+
+// validate reads the search parameters
+func (getSegments *GetSegments) validate(context root.IGinContext) {
+
+	inputParameters := inputParameters{}
+	inputParameters.SegmentID, _ = strconv.Atoi(context.PostForm("segmentID"))
+	inputParameters.RecordsOnPage, _ = strconv.Atoi(context.PostForm("recordsOnPage"))
+	inputParameters.PageNo, _ = strconv.Atoi(context.PostForm("pageNo"))
+
+	// Set defaults
+	if inputParameters.RecordsOnPage == 0 {
+		inputParameters.RecordsOnPage = 20
+	}
+
+	getSegments.InputParameters = inputParameters
+}
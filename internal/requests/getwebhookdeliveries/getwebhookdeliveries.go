@@ -0,0 +1,78 @@
+package getwebhookdeliveries
+
+import (
+	"context"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/webhook"
+	"strconv"
+)
+
+// DefaultRecordsOnPage records output when recordsOnPage is not set
+const DefaultRecordsOnPage = 20
+
+type (
+	// GetWebhookDeliveries struct
+	GetWebhookDeliveries struct {
+		WebhookRepository webhook.IRepository
+		Configuration     *config.Configuration
+	}
+)
+
+// @Summary Get webhook deliveries
+// @Description  Get the deliveries of the webhooks, the latest first. The dead deliveries failed all their attempts and can be retried with retryWebhookDelivery.
+// @Tags webhook
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "getWebhookDeliveries"
+// @Param webhookID formData string false "1"
+// @Description  status - pending, delivered or dead.
+// @Param status formData string false "dead"
+// @Param recordsOnPage formData string false "20"
+// @Param pageNo formData string false "0"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /getWebhookDeliveries [POST]
+func (getWebhookDeliveries *GetWebhookDeliveries) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	webhookID, _ := strconv.Atoi(context.PostForm("webhookID"))
+	status := context.PostForm("status")
+	if status != "" && status != webhook.StatusPending && status != webhook.StatusDelivered && status != webhook.StatusDead {
+		return nil, errorcodes.New("status", 1014)
+	}
+	recordsOnPage, _ := strconv.Atoi(context.PostForm("recordsOnPage"))
+	if recordsOnPage <= 0 {
+		recordsOnPage = DefaultRecordsOnPage
+	}
+	pageNo, _ := strconv.Atoi(context.PostForm("pageNo"))
+
+	deliveries, err := getWebhookDeliveries.WebhookRepository.GetDeliveries(ctx, webhookID, status, recordsOnPage, pageNo)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+	total, err := getWebhookDeliveries.WebhookRepository.GetDeliveriesCount(ctx, webhookID, status)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	return &response.Data{
+		Total:           total,
+		TotalInResponse: len(deliveries),
+		Records:         webhookhelper.MapDeliveriesToOutput(deliveries),
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &GetWebhookDeliveries{
+		WebhookRepository: webhook.New(configuration),
+		Configuration:     configuration,
+	}
+}
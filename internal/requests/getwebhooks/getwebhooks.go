@@ -0,0 +1,59 @@
+package getwebhooks
+
+import (
+	"context"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/webhook"
+)
+
+type (
+	// GetWebhooks struct
+	GetWebhooks struct {
+		WebhookRepository webhook.IRepository
+		Configuration     *config.Configuration
+	}
+)
+
+// @Summary Get webhooks
+// @Description  Get the webhooks of the account, the secrets are not output
+// @Tags webhook
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "getWebhooks"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /getWebhooks [POST]
+func (getWebhooks *GetWebhooks) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	webhooks, err := getWebhooks.WebhookRepository.GetWebhooks(ctx)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	records := make([]webhookhelper.Record, len(webhooks))
+	for idx, w := range webhooks {
+		records[idx] = webhookhelper.MapToOutput(w, false)
+	}
+
+	return &response.Data{
+		Total:           len(records),
+		TotalInResponse: len(records),
+		Records:         records,
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &GetWebhooks{
+		WebhookRepository: webhook.New(configuration),
+		Configuration:     configuration,
+	}
+}
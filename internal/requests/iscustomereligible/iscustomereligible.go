@@ -0,0 +1,203 @@
+package iscustomereligible
+
+import (
+	"context"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/helpers/segmenthelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/assignment"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// IsCustomerEligible struct
+	IsCustomerEligible struct {
+		CampaignRepository   campaign.IRepository
+		AssignmentRepository assignment.IRepository
+		SettingsRepository   settings.IRepository
+		TierRepository       tier.IRepository
+		ProductSetRepository productset.IRepository
+		ScheduleRepository   schedule.IRepository
+		CampaignHelper       campaignhelper.ICampaignHelper
+		SegmentHelper        segmenthelper.ISegmentHelper
+		Configuration        *config.Configuration
+		InputParameters      inputParameters
+	}
+	// requestParams the customer profile
+	inputParameters struct {
+		Customer segmenthelper.Customer
+	}
+)
+
+// @Summary Get campaigns the customer is eligible for
+// @Description  Returns the campaigns active at the moment of the request whose customer group and customer segment targeting the customer profile satisfies. Personalized campaigns are returned only when assigned to the customer. Of the campaigns that do not combine by priority, exclusive, stackingGroup and combinableWith the campaign with the highest priority is returned, ordered by descending priority.
+// @Tags segment
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "isCustomerEligible"
+// @Param customerID formData string false "1"
+// @Param customerGroupID formData string false "1"
+// @Param birthday formData string false "1990-05-04"
+// @Param loyaltyTier formData string false "gold"
+// @Param signupDate formData string false "2019-03-01"
+// @Param totalSpend formData string false "1200.50"
+// @Param tags formData string false "newsletter,vip"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /isCustomerEligible [POST]
+func (isCustomerEligible *IsCustomerEligible) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	err := isCustomerEligible.validate(context)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := isCustomerEligible.getEligible(ctx, time.Now())
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	return &response.Data{
+		Total:           len(records),
+		TotalInResponse: len(records),
+		Records:         records,
+	}, nil
+}
+
+// getEligible returns the campaigns active at the instant the customer is
+// eligible for that combine by the stacking rules
+func (isCustomerEligible *IsCustomerEligible) getEligible(ctx context.Context, now time.Time) ([]campaignhelper.RecordOutput, error) {
+
+	campaigns, err := isCustomerEligible.CampaignRepository.GetActiveCampaigns(ctx, now, 0, "")
+	if err != nil {
+		return nil, err
+	}
+
+	ids := campaign.GetIds(campaigns)
+	relations := campaignhelper.Relations{}
+	relations.Settings, err = isCustomerEligible.SettingsRepository.GetSettings(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.Tiers, err = isCustomerEligible.TierRepository.GetTiers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.ProductSets, err = isCustomerEligible.ProductSetRepository.GetProductSets(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	relations.Schedules, err = isCustomerEligible.ScheduleRepository.GetSchedules(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	records, err := isCustomerEligible.CampaignHelper.MapToArray(ctx, campaigns, relations)
+	if err != nil {
+		return nil, err
+	}
+
+	customer := isCustomerEligible.InputParameters.Customer
+	segmentIDs, err := isCustomerEligible.SegmentHelper.GetCustomerSegments(ctx, customer, campaignhelper.GetCustomerSegmentIDs(records))
+	if err != nil {
+		return nil, err
+	}
+
+	assigned := make([]int, 0)
+	if customer.CustomerID > 0 {
+		assignments, err := isCustomerEligible.AssignmentRepository.GetCustomerAssignments(ctx, customer.CustomerID, now)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range assignments {
+			assigned = append(assigned, a.CampaignID)
+		}
+	}
+
+	eligible := make([]campaignhelper.RecordOutput, 0)
+	for _, record := range records {
+		if campaignhelper.IsActiveAt(record, now) &&
+			campaignhelper.IsCustomerEligible(record, customer.CustomerGroupID, segmentIDs) &&
+			campaignhelper.IsCustomerAssigned(record, assigned) {
+			eligible = append(eligible, record)
+		}
+	}
+	return campaignhelper.SelectCombinable(eligible), nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &IsCustomerEligible{
+		CampaignRepository:   campaign.New(configuration),
+		AssignmentRepository: assignment.New(configuration),
+		SettingsRepository:   settings.New(configuration),
+		TierRepository:       tier.New(configuration),
+		ProductSetRepository: productset.New(configuration),
+		ScheduleRepository:   schedule.New(configuration),
+		CampaignHelper:       campaignhelper.New(configuration),
+		SegmentHelper:        segmenthelper.New(configuration),
+		Configuration:        configuration,
+	}
+}
+
+// validate reads the customer profile
+func (isCustomerEligible *IsCustomerEligible) validate(context root.IGinContext) error {
+
+	customer := segmenthelper.Customer{
+		LoyaltyTier: strings.TrimSpace(context.PostForm("loyaltyTier")),
+	}
+	for field, value := range map[string]*int{
+		"customerID":      &customer.CustomerID,
+		"customerGroupID": &customer.CustomerGroupID,
+	} {
+		if formVal := context.PostForm(field); formVal != "" {
+			i, err := strconv.Atoi(formVal)
+			if err != nil || i < 0 {
+				return errorcodes.New(field, 1014)
+			}
+			*value = i
+		}
+	}
+	for field, value := range map[string]*time.Time{
+		"birthday":   &customer.Birthday,
+		"signupDate": &customer.SignupDate,
+	} {
+		if formVal := context.PostForm(field); formVal != "" {
+			t, err := time.Parse(segmenthelper.DateFormat, formVal)
+			if err != nil {
+				return errorcodes.New(field, 1014)
+			}
+			*value = t
+		}
+	}
+	if formVal := context.PostForm("totalSpend"); formVal != "" {
+		f, err := strconv.ParseFloat(formVal, 64)
+		if err != nil || f < 0 {
+			return errorcodes.New("totalSpend", 1014)
+		}
+		customer.TotalSpend = f
+	}
+	if formVal := context.PostForm("tags"); formVal != "" {
+		for _, tag := range strings.Split(formVal, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				customer.Tags = append(customer.Tags, tag)
+			}
+		}
+	}
+
+	isCustomerEligible.InputParameters = inputParameters{Customer: customer}
+	return nil
+}
@@ -0,0 +1,152 @@
+package iscustomereligible
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/helpers/segmenthelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/assignment"
+	assignmentMocks "github.com/zdarovich/promotion-api/internal/repositories/assignment/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	campaignMocks "github.com/zdarovich/promotion-api/internal/repositories/campaign/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/config"
+	configMocks "github.com/zdarovich/promotion-api/internal/repositories/config/mocks"
+	productSetMocks "github.com/zdarovich/promotion-api/internal/repositories/productset/mocks"
+	scheduleMocks "github.com/zdarovich/promotion-api/internal/repositories/schedule/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/segment"
+	segmentMocks "github.com/zdarovich/promotion-api/internal/repositories/segment/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	settingsMocks "github.com/zdarovich/promotion-api/internal/repositories/settings/mocks"
+	tierMocks "github.com/zdarovich/promotion-api/internal/repositories/tier/mocks"
+)
+
+func TestIsCustomerEligible_getEligible(t *testing.T) {
+	now := time.Date(2020, time.May, 4, 17, 0, 0, 0, time.UTC)
+	start := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, time.May, 31, 0, 0, 0, 0, time.UTC)
+	campaigns := []campaign.Campaign{
+		{ID: 1, StartDate: start, EndDate: end},
+		{ID: 2, StartDate: start, EndDate: end},
+		{ID: 3, StartDate: start, EndDate: end},
+		{ID: 4, StartDate: start, EndDate: end},
+		{ID: 5, StartDate: start, EndDate: end},
+		{ID: 6, StartDate: start, EndDate: end},
+	}
+
+	cr := new(campaignMocks.IRepository)
+	cr.On("GetActiveCampaigns", mock.Anything, now, 0, "").Return(campaigns, nil)
+	str := new(settingsMocks.IRepository)
+	str.On("GetSettings", mock.Anything, []int{1, 2, 3, 4, 5, 6}).Return(map[int]*settings.Settings{
+		// gold members
+		2: {CampaignID: 2, CustomerSegmentIDs: []int{10}},
+		// big spenders
+		3: {CampaignID: 3, CustomerSegmentIDs: []int{11}},
+		// other customer group
+		4: {CampaignID: 4, CustomerGroupIDs: []int{7}},
+		// personalized offers
+		5: {CampaignID: 5, CustomerAllowlist: 1},
+		6: {CampaignID: 6, CustomerAllowlist: 1},
+	}, nil)
+	tr := new(tierMocks.IRepository)
+	tr.On("GetTiers", mock.Anything, mock.Anything).Return(nil, nil)
+	pr := new(productSetMocks.IRepository)
+	pr.On("GetProductSets", mock.Anything, mock.Anything).Return(nil, nil)
+	sr := new(scheduleMocks.IRepository)
+	sr.On("GetSchedules", mock.Anything, mock.Anything).Return(nil, nil)
+	cfr := new(configMocks.IRepository)
+	cfr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{}, nil)
+	segr := new(segmentMocks.IRepository)
+	segr.On("GetRules", mock.Anything, []int{10, 11}).Return(map[int][]*segment.Rule{
+		10: {{SegmentID: 10, Attribute: "loyaltyTier", Operator: "eq", Value: "gold"}},
+		11: {{SegmentID: 11, Attribute: "totalSpend", Operator: "gte", Value: "5000"}},
+	}, nil)
+
+	asr := new(assignmentMocks.IRepository)
+	asr.On("GetCustomerAssignments", mock.Anything, 9, now).Return([]*assignment.Assignment{{CampaignID: 6, CustomerID: 9}}, nil)
+
+	ice := &IsCustomerEligible{
+		CampaignRepository:   cr,
+		AssignmentRepository: asr,
+		SettingsRepository:   str,
+		TierRepository:       tr,
+		ProductSetRepository: pr,
+		ScheduleRepository:   sr,
+		CampaignHelper:       &campaignhelper.CampaignHelper{ConfigRepository: cfr},
+		SegmentHelper:        &segmenthelper.SegmentHelper{SegmentRepository: segr},
+		InputParameters: inputParameters{
+			Customer: segmenthelper.Customer{CustomerID: 9, CustomerGroupID: 3, LoyaltyTier: "gold", TotalSpend: 100},
+		},
+	}
+
+	records, err := ice.getEligible(context.Background(), now)
+
+	assert.Nil(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, 1, records[0].CampaignID)
+	assert.Equal(t, 2, records[1].CampaignID)
+	assert.Equal(t, 6, records[2].CampaignID)
+}
+
+func TestIsCustomerEligible_getEligible_Exclusive(t *testing.T) {
+	now := time.Date(2020, time.May, 4, 17, 0, 0, 0, time.UTC)
+	start := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, time.May, 31, 0, 0, 0, 0, time.UTC)
+	campaigns := []campaign.Campaign{
+		{ID: 1, StartDate: start, EndDate: end},
+		{ID: 2, StartDate: start, EndDate: end},
+		{ID: 3, StartDate: start, EndDate: end},
+	}
+
+	cr := new(campaignMocks.IRepository)
+	cr.On("GetActiveCampaigns", mock.Anything, now, 0, "").Return(campaigns, nil)
+	str := new(settingsMocks.IRepository)
+	str.On("GetSettings", mock.Anything, []int{1, 2, 3}).Return(map[int]*settings.Settings{
+		// exclusive with the highest priority, applied alone
+		2: {CampaignID: 2, Priority: 10, Exclusive: 1},
+		3: {CampaignID: 3, Priority: 1, CombinableWith: []int{1}},
+	}, nil)
+	tr := new(tierMocks.IRepository)
+	tr.On("GetTiers", mock.Anything, mock.Anything).Return(nil, nil)
+	pr := new(productSetMocks.IRepository)
+	pr.On("GetProductSets", mock.Anything, mock.Anything).Return(nil, nil)
+	sr := new(scheduleMocks.IRepository)
+	sr.On("GetSchedules", mock.Anything, mock.Anything).Return(nil, nil)
+	cfr := new(configMocks.IRepository)
+	cfr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{}, nil)
+	segr := new(segmentMocks.IRepository)
+	segr.On("GetRules", mock.Anything, mock.Anything).Return(map[int][]*segment.Rule{}, nil)
+
+	ice := &IsCustomerEligible{
+		CampaignRepository:   cr,
+		SettingsRepository:   str,
+		TierRepository:       tr,
+		ProductSetRepository: pr,
+		ScheduleRepository:   sr,
+		CampaignHelper:       &campaignhelper.CampaignHelper{ConfigRepository: cfr},
+		SegmentHelper:        &segmenthelper.SegmentHelper{SegmentRepository: segr},
+	}
+
+	records, err := ice.getEligible(context.Background(), now)
+
+	assert.Nil(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, 2, records[0].CampaignID)
+
+	// Without the exclusive campaign the combinable ones are returned by priority
+	str.ExpectedCalls = nil
+	str.On("GetSettings", mock.Anything, []int{1, 2, 3}).Return(map[int]*settings.Settings{
+		2: {CampaignID: 2, Priority: 10, StackingGroup: "coupons"},
+		3: {CampaignID: 3, Priority: 1, CombinableWith: []int{1}},
+	}, nil)
+
+	records, err = ice.getEligible(context.Background(), now)
+
+	assert.Nil(t, err)
+	assert.Len(t, records, 2)
+	assert.Equal(t, 2, records[0].CampaignID)
+	assert.Equal(t, 1, records[1].CampaignID)
+}
@@ -0,0 +1,79 @@
+package retrywebhookdelivery
+
+import (
+	"context"
+	"database/sql"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/webhook"
+	"strconv"
+	"time"
+)
+
+type (
+	// RetryWebhookDelivery struct
+	RetryWebhookDelivery struct {
+		WebhookRepository webhook.IRepository
+		Configuration     *config.Configuration
+	}
+)
+
+// @Summary Retry webhook delivery
+// @Description  Sends a dead delivery again, its attempts start over. The error of its last attempt is kept until it is sent.
+// @Tags webhook
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "retryWebhookDelivery"
+// @Param deliveryID formData string true "1"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /retryWebhookDelivery [POST]
+func (retryWebhookDelivery *RetryWebhookDelivery) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	deliveryID, _ := strconv.Atoi(context.PostForm("deliveryID"))
+	if deliveryID == 0 {
+		return nil, errorcodes.New("deliveryID", errorcodes.CodeRequiredParameterMissing)
+	}
+
+	d, err := retryWebhookDelivery.WebhookRepository.GetDelivery(ctx, deliveryID)
+	if err == sql.ErrNoRows {
+		return nil, errorcodes.New("deliveryID", 1014)
+	}
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+	// Only the dead-letter list is retried, the pending deliveries are
+	// retried by the delivery service
+	if d.Status != webhook.StatusDead {
+		return nil, errorcodes.New("deliveryID", 1014)
+	}
+
+	d.Status = webhook.StatusPending
+	d.Attempts = 0
+	d.NextAttempt = time.Now().Unix()
+	err = retryWebhookDelivery.WebhookRepository.UpdateDelivery(ctx, d)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	return &response.Data{
+		Total:           1,
+		TotalInResponse: 1,
+		Records:         webhookhelper.MapDeliveriesToOutput([]webhook.Delivery{d}),
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &RetryWebhookDelivery{
+		WebhookRepository: webhook.New(configuration),
+		Configuration:     configuration,
+	}
+}
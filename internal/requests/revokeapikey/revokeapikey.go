@@ -0,0 +1,75 @@
+package revokeapikey
+
+import (
+	"context"
+	"errors"
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/apikey"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+	"strconv"
+	"time"
+)
+
+type (
+	// RevokeAPIKey struct
+	RevokeAPIKey struct {
+		APIKeyRepository apikey.IRepository
+		UserRepository   user.IRepository
+		Configuration    *config.Configuration
+	}
+)
+
+// @Summary Revoke API key
+// @Description  Revokes an API key, the requests sent with it are unauthenticated from then on. The key is kept with the time and the user who revoked it.
+// @Tags apikey
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "revokeAPIKey"
+// @Param apiKeyID formData string true "1"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /revokeAPIKey [POST]
+func (revokeAPIKey *RevokeAPIKey) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	userEntity, err := caller.User(ctx, revokeAPIKey.UserRepository, context.PostForm("sessionKey"))
+	if err != nil || userEntity.ID == 0 {
+		return nil, errors.New("userEntity not found")
+	}
+
+	apiKeyID, _ := strconv.Atoi(context.PostForm("apiKeyID"))
+	if apiKeyID == 0 {
+		return nil, errorcodes.New("apiKeyID", errorcodes.CodeRequiredParameterMissing)
+	}
+
+	err = revokeAPIKey.APIKeyRepository.RevokeAPIKey(ctx, apiKeyID, time.Now().Unix(), userEntity.ShortName)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+	keys, err := revokeAPIKey.APIKeyRepository.GetAPIKeys(ctx)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	return &response.Data{
+		Total:           len(keys),
+		TotalInResponse: 0,
+		Records:         []interface{}{},
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &RevokeAPIKey{
+		APIKeyRepository: apikey.New(configuration),
+		UserRepository:   user.New(configuration),
+		Configuration:    configuration,
+	}
+}
@@ -0,0 +1,137 @@
+package savecampaigncustomers
+
+import (
+	"context"
+	"errors"
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/assignment"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+	"strconv"
+	"time"
+)
+
+type (
+	// SaveCampaignCustomers struct
+	SaveCampaignCustomers struct {
+		CampaignRepository   campaign.IRepository
+		AssignmentRepository assignment.IRepository
+		CampaignHelper       campaignhelper.ICampaignHelper
+		UserRepository       user.IRepository
+		Configuration        *config.Configuration
+	}
+	// record structure of the output record
+	record struct {
+		CampaignID     int `json:"campaignID"`
+		Assigned       int `json:"assigned"`
+		TotalCustomers int `json:"totalCustomers"`
+	}
+)
+
+// @Summary Assign customers to a personalized campaign
+// @Description  Assigns a list of customers to a campaign restricted to a customer allowlist. Assigning an already assigned customer updates the expiry of the assignment.
+// @Tags campaign
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "saveCampaignCustomers"
+// @Param campaignID formData string true "1"
+// @Description  customerIDs - Customer IDs separated by commas, semicolons, spaces or line breaks, at most 100000 per request.
+// @Param customerIDs formData string true "1,2,3"
+// @Description  expires - The date and time the assignments expire in the timezone of the campaign. The assignments do not expire when not set.
+// @Param expires formData string false "2020-12-31"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /saveCampaignCustomers [POST]
+func (saveCampaignCustomers *SaveCampaignCustomers) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	userEntity, err := caller.User(ctx, saveCampaignCustomers.UserRepository, context.PostForm("sessionKey"))
+	if err != nil || userEntity.ID == 0 {
+		return nil, errors.New("userEntity not found")
+	}
+
+	campaignID, _ := strconv.Atoi(context.PostForm("campaignID"))
+	if campaignID == 0 {
+		return nil, errorcodes.New("campaignID", errorcodes.CodeRequiredParameterMissing)
+	}
+	customerIDs, err := campaignhelper.ParseCustomerIDs(context.PostForm("customerIDs"))
+	if err != nil {
+		return nil, errorcodes.New("customerIDs", 1014)
+	}
+	if len(customerIDs) == 0 {
+		return nil, errorcodes.New("customerIDs", errorcodes.CodeRequiredParameterMissing)
+	}
+	if len(customerIDs) > campaignhelper.MaxCustomerIDs {
+		return nil, errors.New("1195")
+	}
+
+	campaigns, err := saveCampaignCustomers.CampaignRepository.GetCampaignsByIDs(ctx, []int{campaignID})
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+	if len(campaigns) == 0 {
+		return nil, errorcodes.New("campaignID", 1014)
+	}
+
+	var expires int64
+	if formVal := context.PostForm("expires"); formVal != "" {
+		loc, err := saveCampaignCustomers.CampaignHelper.GetLocation(ctx, campaigns[0].WarehouseID)
+		if err != nil {
+			return nil, errorcodes.Wrap(err, 1006)
+		}
+		t, err := campaignhelper.ParseDateTime(formVal, loc, true)
+		if err != nil {
+			return nil, errorcodes.New("expires", 1014)
+		}
+		expires = t.Unix()
+	}
+
+	now := time.Now().Unix()
+	assignments := make([]*assignment.Assignment, len(customerIDs))
+	for idx, customerID := range customerIDs {
+		assignments[idx] = &assignment.Assignment{
+			CampaignID: campaignID,
+			CustomerID: customerID,
+			Expires:    expires,
+			Added:      now,
+		}
+	}
+	err = saveCampaignCustomers.AssignmentRepository.SaveAssignments(ctx, assignments)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := saveCampaignCustomers.AssignmentRepository.GetAssignmentsCount(ctx, campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response.Data{
+		Total:           1,
+		TotalInResponse: 1,
+		Records: []record{{
+			CampaignID:     campaignID,
+			Assigned:       len(assignments),
+			TotalCustomers: total,
+		}},
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &SaveCampaignCustomers{
+		CampaignRepository:   campaign.New(configuration),
+		AssignmentRepository: assignment.New(configuration),
+		CampaignHelper:       campaignhelper.New(configuration),
+		UserRepository:       user.New(configuration),
+		Configuration:        configuration,
+	}
+}
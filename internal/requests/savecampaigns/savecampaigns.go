@@ -1,17 +1,23 @@
 package savecampaigns
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
-	"fmt"
-	"github.com/sirupsen/logrus"
+	"github.com/jmoiron/sqlx"
+	"github.com/zdarovich/promotion-api/internal/api/caller"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/api/requests/root"
 	"github.com/zdarovich/promotion-api/internal/api/response"
 	"github.com/zdarovich/promotion-api/internal/config"
 	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
 	"github.com/zdarovich/promotion-api/internal/log"
-	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
 	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
 	"github.com/zdarovich/promotion-api/internal/repositories/user"
 	"reflect"
 	"strconv"
@@ -19,56 +25,22 @@ import (
 	"time"
 )
 
+// outputTimeout time the output of the saved campaign is read in, after the
+// request context may be done
+const outputTimeout = 5 * time.Second
+
 type (
 	// SaveCampaigns struct
 	SaveCampaigns struct {
-		CampaignRepository campaign.IRepository
-		AttrsRepository    attributes.IRepository
-		CampaignHelper     campaignhelper.ICampaignHelper
-		UserRepository     user.IRepository
-		Configuration      *config.Configuration
-	}
-
-	inputParametersAttrs struct {
-		AwardedBrandID                                       int    `json:"awardedBrandID"`
-		DiscountForOneLine                                   int    `json:"discountForOneLine"`
-		RequiredCouponID                                     string `json:"requiredCouponID"`
-		RequiredCouponCode                                   string `json:"requiredCouponCode"`
-		PurchasedProducts                                    string `json:"purchasedProducts"`
-		AwardedProducts                                      string `json:"awardedProducts"`
-		ExcludedProducts                                     string `json:"excludedProducts"`
-		PercentageOffExcludedProducts                        string `json:"percentageOffExcludedProducts"`
-		PercentageOffIncludedProducts                        string `json:"percentageOffIncludedProducts"`
-		SumOffExcludedProducts                               string `json:"sumOffExcludedProducts"`
-		SumOffIncludedProducts                               string `json:"sumOffIncludedProducts"`
-		AwardedAmount                                        int    `json:"awardedAmount"`
-		PurchasedProductCategoryID                           int    `json:"purchasedProductCategoryID"`
-		AwardedProductCategoryID                             int    `json:"awardedProductCategoryID"`
-		MaximumPointsDiscount                                int    `json:"maximumPointsDiscount"`
-		CustomerCanUseOnlyOnce                               int    `json:"customerCanUseOnlyOnce"`
-		PriceAtLeast                                         int    `json:"priceAtLeast"`
-		PriceAtMost                                          int    `json:"priceAtMost"`
-		RequiresManagerOverride                              int    `json:"requiresManagerOverride"`
-		SumOffMatchingItems                                  int    `json:"sumOffMatchingItems"`
-		ExcludeDiscountedFromPercentageOffEntirePurchase     int    `json:"excludeDiscountedFromPercentageOffEntirePurchase"`
-		ExcludePromotionItemsFromPercentageOffEntirePurchase int    `json:"excludePromotionItemsFromPercentageOffEntirePurchase"`
-		ReasonID                                             int    `json:"reasonID"`
-		SpecialUnitPrice                                     int    `json:"specialUnitPrice"`
-		MaxItemsWithSpecialUnitPrice                         int    `json:"maxItemsWithSpecialUnitPrice"`
-		RedemptionLimit                                      int    `json:"redemptionLimit"`
-		StoreGroup                                           string `json:"storeGroup"`
-		CanBeAppliedManuallyMultipleTimes                    int    `json:"canBeAppliedManuallyMultipleTimes"`
-		PurchasedBrandID                                     int    `json:"purchasedBrandID"`
-		AwardedProductGroupID                                int    `json:"awardedProductGroupID"`
-		LowestPriceItemIsAwarded                             int    `json:"lowestPriceItemIsAwarded"`
-		PurchasedProductSubsidies                            string `json:"purchasedProductSubsidies"`
-		AwardedProductSubsidies                              string `json:"awardedProductSubsidies"`
-		StoreRegionIDs                                       string `json:"storeRegionIDs"`
-		CustomerGroupIDs                                     string `json:"customerGroupIDs"`
-		PercentageOffMatchingItems                           int    `json:"percentageOffMatchingItems"`
-		PurchasedProductGroupID                              int    `json:"purchasedProductGroupID"`
-		RewardPoints                                         int    `json:"rewardPoints"`
-		PercentageOffEntirePurchase                          int    `json:"percentageOffEntirePurchase"`
+		CampaignRepository   campaign.IRepository
+		SettingsRepository   settings.IRepository
+		TierRepository       tier.IRepository
+		ProductSetRepository productset.IRepository
+		ScheduleRepository   schedule.IRepository
+		CampaignHelper       campaignhelper.ICampaignHelper
+		WebhookHelper        webhookhelper.IWebhookHelper
+		UserRepository       user.IRepository
+		Configuration        *config.Configuration
 	}
 )
 
@@ -81,10 +53,10 @@ type (
 // @Param clientCode formData string true "ERPLY client code"
 // @Param request formData string true "saveCampaign"
 // @Param campaignID formData string false "1"
-// @Description  startDate - Promotion start date.
-// @Param startDate formData string false "2006-01-02"
-// @Description  endDate - Promotion end date.
-// @Param endDate formData string false "2006-01-02"
+// @Description  startDate - Promotion start, a date or a date and time. It is interpreted in the timezone of the warehouse (conf "timezone_warehouse_<warehouseID>") or of the account (conf "timezone"), UTC when neither is set. A date means the start of the day.
+// @Param startDate formData string false "2006-01-02 15:04:05"
+// @Description  endDate - Promotion end, a date or a date and time in the same timezone as "startDate". A date means the end of the day.
+// @Param endDate formData string false "2006-01-02 15:04:05"
 // @Description  name - Promotion name. Use either general parameter "name" or one or more of the following parameters if you need to set the names in specific languages. To have multilingual names enabled, please contact our customer support. An error will be returned if you attempt to set a name in a specific language and the multilingual names are not enabled on your account.
 // @Param name formData string false "test"
 // @Description  warehouseID - Set this field if you want the promotion to be available only in a specific store.Fields "warehouseID", "storeGroup" and "storeRegionIDs" are mutually exclusive: only one restriction can be set at a time, otherwise error code 1110 will be returned.
@@ -95,6 +67,10 @@ type (
 // @Param awardedBrandID formData string false "1"
 // @Param storeRegionIDs formData string false "1,2,3"
 // @Param customerGroupIDs formData string false "1,2,3"
+// @Description  customerSegmentIDs - Set this field if you want the promotion to be available only to the customers of specific customer segments. A customer qualifies when belonging to any of the segments.
+// @Param customerSegmentIDs formData string false "1,2,3"
+// @Description  customerAllowlist - Set to 1 to make the promotion a personalized offer available only to the customers assigned to it with saveCampaignCustomers.
+// @Param customerAllowlist formData string false "1 or 0"
 // @Param lowestPriceItemIsAwarded formData string false "1 or 0"
 // @Param percentageOFF formData string false "1"
 // @Param sumOFF formData string false "1"
@@ -152,23 +128,47 @@ type (
 // @Param percentageOffEntirePurchase formData string false "1"
 // @Param sumOffEntirePurchase formData string false "1"
 // @Param specialPrice formData string false "1"
+// @Description  tiers - A JSON array of ordered tiers, e.g. "buy 2 get 10% off, buy 3 get 20% off". Every tier has exactly one threshold ("purchasedAmount" or "purchaseTotalValue", the same one for all tiers, ascending) and exactly one award ("percentageOFF", "sumOFF" or "specialPrice"). Tiers replace the campaign level threshold and award fields.
+// @Param tiers formData string false "[{\"purchasedAmount\":2,\"percentageOFF\":10},{\"purchasedAmount\":3,\"percentageOFF\":20}]"
+// @Description  productSets - A JSON array of named product sets for bundle and mix-and-match campaigns, e.g. "1 burger + 1 drink + 1 fries" or "any 3 from list A and 1 from list B". Every set has a unique "name", an "amount" and exactly one of "products", "productGroupID" or "productCategoryID". Product sets replace the purchased products, thresholds and awards of the campaign.
+// @Param productSets formData string false "[{\"name\":\"burger\",\"amount\":1,\"products\":[\"1\",\"2\"]},{\"name\":\"drink\",\"amount\":1,\"productGroupID\":3}]"
+// @Description  bundlePrice - Price of the whole bundle. Fields "bundlePrice", "bundleSumOff" and "bundlePercentageOff" are mutually exclusive and require "productSets".
+// @Param bundlePrice formData string false "9.99"
+// @Param bundleSumOff formData string false "1"
+// @Param bundlePercentageOff formData string false "1"
+// @Description  schedules - A JSON array of recurrence rules on top of the start and end date, e.g. weekday happy hours or every Tuesday. Every rule may restrict "daysOfWeek" (1 is Monday, 7 is Sunday), "daysOfMonth" (negative values count from the end of the month), "weeksOfMonth" (together with "daysOfWeek", e.g. the first Tuesday) and a "startTime"/"endTime" window in HH:MM. The campaign is active when any of the rules matches.
+// @Param schedules formData string false "[{\"daysOfWeek\":[1,2,3,4,5],\"startTime\":\"16:00\",\"endTime\":\"18:00\"}]"
+// @Description  priority - Campaigns with a higher priority are applied first when promotions are combined.
+// @Param priority formData string false "1"
+// @Description  exclusive - Set to 1 if no other promotion may be applied together with this one. Field "exclusive" cannot be used together with "stackingGroup" or "combinableWith".
+// @Param exclusive formData string false "1 or 0"
+// @Description  stackingGroup - Only one promotion of the same stacking group is applied to a sale.
+// @Param stackingGroup formData string false "seasonal"
+// @Description  combinableWith - A comma-separated list of campaign IDs this promotion may be combined with. When set, the promotion is not combined with any other campaign.
+// @Param combinableWith formData string false "1,2,3"
 // @Success 200 {object} response.SuccessResponse
 // @Failure 400 {object} response.ErrorResponse
 // @Failure 404 {object} response.ErrorResponse
 // @Router /saveCampaign [POST]
-func (saveCampaigns *SaveCampaigns) Handle(context root.IGinContext) (*response.Data, error) {
+func (saveCampaigns *SaveCampaigns) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
 
-	userEntity, err := saveCampaigns.UserRepository.GetUserBySessionKey(context.PostForm("sessionKey"))
+	userEntity, err := caller.User(ctx, saveCampaigns.UserRepository, context.PostForm("sessionKey"))
 	if err != nil || userEntity.ID == 0 {
 		return nil, errors.New("userEntity not found")
 	}
 
-	record, err := getRecord(context)
+	warehouseID, _ := strconv.Atoi(context.PostForm("warehouseID"))
+	loc, err := saveCampaigns.CampaignHelper.GetLocation(ctx, warehouseID)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1006)
+	}
+
+	record, err := getRecord(context, loc)
 	if err != nil {
 		return nil, err
 	}
 
-	err = saveCampaigns.CampaignHelper.Validate(record)
+	err = saveCampaigns.CampaignHelper.Validate(ctx, record)
 	if err != nil {
 		return nil, err
 	}
@@ -176,60 +176,57 @@ func (saveCampaigns *SaveCampaigns) Handle(context root.IGinContext) (*response.
 	now := time.Now().Unix()
 
 	c := campaign.Campaign{
-		record.CampaignID,
-		record.StartDate,
-		record.EndDate,
-		record.Name,
-		record.WarehouseID,
-		record.PurchasedAmount,
-		record.PurchasedProductGroupID,
-		record.PurchaseTotalValue,
-		record.LowestPriceItemIsAwarded,
-		record.SpecialPrice,
-		record.PercentageOFF,
-		record.SumOFF,
-		record.AwardedProductGroupID,
-		record.PercentageOffEntirePurchase,
-		record.SumOffEntirePurchase,
-		record.RewardPoints,
-		record.PercentageOffMatchingItems,
-		record.Type,
-		now,
-		userEntity.ShortName,
-		0,
-		"",
+		ID:                      record.CampaignID,
+		StartDate:               record.StartDate.UTC(),
+		EndDate:                 record.EndDate.UTC(),
+		Name:                    record.Name,
+		WarehouseID:             record.WarehouseID,
+		PurchasedAmount:         record.PurchasedAmount,
+		PurchasedProdgroupID:    record.PurchasedProductGroupID,
+		PurchaseTotalValue:      record.PurchaseTotalValue,
+		AwardLowestPricedItem:   record.LowestPriceItemIsAwarded,
+		SpecialPrice:            record.SpecialPrice,
+		PercentageOff:           record.PercentageOFF,
+		SumOff:                  record.SumOFF,
+		AwardedProdgroupID:      record.AwardedProductGroupID,
+		PercentageOffAllItems:   record.PercentageOffEntirePurchase,
+		SumOffEntirePurchase:    record.SumOffEntirePurchase,
+		Rewardpoints:            record.RewardPoints,
+		PercentageOffAnyOneLine: record.PercentageOffMatchingItems,
+		Type:                    record.Type,
+		Added:                   now,
+		Addedby:                 userEntity.ShortName,
 	}
-	err = saveCampaigns.CampaignRepository.SaveCampaigns(&c)
+	// The campaign is saved as a new campaign, also with a campaign id
+	authenticated, _ := caller.FromContext(ctx)
+	relations := campaignhelper.Relations{}
+	err = saveCampaigns.CampaignRepository.SaveCampaignsWithRecords(ctx, &c,
+		saveCampaigns.saveRecords(record, authenticated.ClientCode, &c, &relations))
 
 	if err != nil {
 		return nil, err
 	}
 
-	ipas, err := getInputParametersAttrs(record)
-	if err != nil {
-		return nil, err
-	}
+	return saveCampaigns.output(c, relations)
+}
 
-	attrs, err := getAttributes(ipas, &c)
-	if err != nil {
-		return nil, err
-	}
-	err = saveCampaigns.AttrsRepository.SaveAttributes(attrs)
+// output returns the output of the saved campaign. The campaign is committed,
+// it is read on a context of its own: an error after the deadline would have
+// the client save it again
+func (saveCampaigns *SaveCampaigns) output(c campaign.Campaign, relations campaignhelper.Relations) (*response.Data, error) {
 
-	if err != nil {
-		return nil, err
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), outputTimeout)
+	defer cancel()
 
-	var totalRecordsCount = 0
 	var recordsCount = 0
-	totalRecordsCount, err = saveCampaigns.CampaignRepository.GetCampaignsCount(
+	totalRecordsCount, err := saveCampaigns.CampaignRepository.GetCampaignsCount(ctx,
 		0,
 		"",
 	)
 	if err != nil {
 		return nil, err
 	}
-	output, err := saveCampaigns.CampaignHelper.MapToArray(append([]campaign.Campaign{}, c), map[int][]*attributes.Attribute{c.ID: attrs})
+	output, err := saveCampaigns.CampaignHelper.MapToArray(ctx, append([]campaign.Campaign{}, c), relations)
 	if err != nil {
 		return nil, err
 	}
@@ -246,15 +243,68 @@ func (saveCampaigns *SaveCampaigns) Handle(context root.IGinContext) (*response.
 func New(configuration *config.Configuration) root.IRoot {
 
 	return &SaveCampaigns{
-		CampaignRepository: campaign.New(configuration),
-		AttrsRepository:    attributes.New(configuration),
-		CampaignHelper:     campaignhelper.New(configuration),
-		UserRepository:     user.New(configuration),
-		Configuration:      configuration,
+		CampaignRepository:   campaign.New(configuration),
+		SettingsRepository:   settings.New(configuration),
+		TierRepository:       tier.New(configuration),
+		ProductSetRepository: productset.New(configuration),
+		ScheduleRepository:   schedule.New(configuration),
+		CampaignHelper:       campaignhelper.New(configuration),
+		WebhookHelper:        webhookhelper.New(configuration),
+		UserRepository:       user.New(configuration),
+		Configuration:        configuration,
+	}
+}
+
+// saveRecords returns the records of the campaign saved in its transaction
+// and sets them in the relations. The created event is recorded in the
+// outbox with events enabled, it is posted to the webhooks otherwise
+func (saveCampaigns *SaveCampaigns) saveRecords(
+	record *campaignhelper.Record,
+	clientCode string,
+	c *campaign.Campaign,
+	relations *campaignhelper.Relations,
+) campaign.Records {
+
+	return func(ctx context.Context, tx *sqlx.Tx, campaignID int) error {
+		campaignSettings := campaignhelper.MapSettingsToDatabase(record, campaignID)
+		err := saveCampaigns.SettingsRepository.SaveSettingsTx(ctx, tx, campaignSettings)
+		if err != nil {
+			return err
+		}
+
+		tiers := campaignhelper.MapTiersToDatabase(record, campaignID)
+		err = saveCampaigns.TierRepository.SaveTiersTx(ctx, tx, tiers)
+		if err != nil {
+			return err
+		}
+
+		sets := campaignhelper.MapProductSetsToDatabase(record, campaignID)
+		err = saveCampaigns.ProductSetRepository.SaveProductSetsTx(ctx, tx, sets)
+		if err != nil {
+			return err
+		}
+
+		schedules := campaignhelper.MapSchedulesToDatabase(record, campaignID)
+		err = saveCampaigns.ScheduleRepository.SaveSchedulesTx(ctx, tx, schedules)
+		if err != nil {
+			return err
+		}
+
+		*relations = campaignhelper.Relations{
+			Settings:    map[int]*settings.Settings{campaignID: campaignSettings},
+			Tiers:       map[int][]*tier.Tier{campaignID: tiers},
+			ProductSets: map[int][]*productset.ProductSet{campaignID: sets},
+			Schedules:   map[int][]*schedule.Schedule{campaignID: schedules},
+		}
+
+		if saveCampaigns.Configuration.Events.Enabled {
+			return nil
+		}
+		return saveCampaigns.WebhookHelper.PublishTx(ctx, tx, clientCode, webhookhelper.EventCreated, *c)
 	}
 }
 
-func getRecord(c root.IGinContext) (*campaignhelper.Record, error) {
+func getRecord(c root.IGinContext, loc *time.Location) (*campaignhelper.Record, error) {
 	rec := campaignhelper.Record{}
 
 	v := reflect.ValueOf(&rec)
@@ -272,6 +322,12 @@ func getRecord(c root.IGinContext) (*campaignhelper.Record, error) {
 		case reflect.String:
 			val.SetString(formVal)
 		case reflect.Slice:
+			if val.Type().Elem().Kind() == reflect.Struct {
+				if err := json.Unmarshal([]byte(formVal), val.Addr().Interface()); err != nil {
+					return nil, errorcodes.New(field, 1014)
+				}
+				continue
+			}
 			s := strings.Split(formVal, ",")
 			if len(s) == 0 {
 				return nil, errorcodes.New(field, 1014)
@@ -315,7 +371,7 @@ func getRecord(c root.IGinContext) (*campaignhelper.Record, error) {
 			}
 		case reflect.Struct:
 			if val.Type().String() == "time.Time" {
-				if t, err := time.Parse("2006-01-02", formVal); err != nil || t.IsZero() {
+				if t, err := campaignhelper.ParseDateTime(formVal, loc, field == "endDate"); err != nil || t.IsZero() {
 					return nil, errorcodes.New(field, 1014)
 				} else {
 					val.Set(reflect.ValueOf(t))
@@ -327,97 +383,3 @@ func getRecord(c root.IGinContext) (*campaignhelper.Record, error) {
 	}
 	return &rec, nil
 }
-
-func getInputParametersAttrs(r *campaignhelper.Record) (inputParametersAttrs, error) {
-	v := reflect.ValueOf(r)
-	v = reflect.Indirect(v)
-	t := v.Type()
-	vals := make(map[string]reflect.Value)
-
-	for i := 0; i < v.NumField(); i++ {
-		val := v.Field(i)
-		if val.IsZero() {
-			continue
-		}
-		field := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
-		vals[field] = val
-	}
-	ipa := inputParametersAttrs{}
-	v = reflect.ValueOf(&ipa)
-	v = reflect.Indirect(v)
-	t = v.Type()
-	for i := 0; i < v.NumField(); i++ {
-		field := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
-		val, ok := vals[field]
-		if !ok {
-			continue
-		}
-		switch val.Kind() {
-		case reflect.Slice:
-			switch val.Type().String() {
-			case "[]int":
-				log.Info(val.Interface().([]int))
-				v.Field(i).SetString(strings.Trim(strings.Join(strings.Fields(fmt.Sprint(val.Interface().([]int))), ","), "[]"))
-			case "[]string":
-				v.Field(i).SetString(strings.Join(val.Interface().([]string), ","))
-			}
-
-		case reflect.Bool:
-			var bitSet int64 = 0
-			if val.Interface().(bool) {
-				bitSet = 1
-			}
-			v.Field(i).SetInt(bitSet)
-		default:
-			v.Field(i).Set(val)
-		}
-	}
-	return ipa, nil
-}
-
-func getAttributes(ipa inputParametersAttrs, c *campaign.Campaign) ([]*attributes.Attribute, error) {
-	attrs := make([]*attributes.Attribute, 0)
-
-	v := reflect.ValueOf(ipa)
-	t := v.Type()
-	v = reflect.Indirect(v)
-	for i := 0; i < v.NumField(); i++ {
-		field := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
-		val := v.Field(i)
-
-		if val.IsZero() {
-			continue
-		}
-		switch val.Kind() {
-		case reflect.String:
-			attrs = append(attrs, &attributes.Attribute{
-				ObjID:     c.ID,
-				ObjTable:  "campaign",
-				Name:      field,
-				Type:      attributes.TEXT,
-				ValueText: val.String(),
-			})
-		case reflect.Float32, reflect.Float64:
-			attrs = append(attrs, &attributes.Attribute{
-				ObjID:       c.ID,
-				ObjTable:    "campaign",
-				Name:        field,
-				Type:        attributes.DOUBLE,
-				ValueDouble: val.Float(),
-			})
-		case reflect.Int, reflect.Int64:
-			attrs = append(attrs, &attributes.Attribute{
-				ObjID:    c.ID,
-				ObjTable: "campaign",
-				Name:     field,
-				Type:     attributes.INT,
-				ValueInt: int(val.Int()),
-			})
-		default:
-			logrus.Error(t.Field(i).Name, ": ", v.Field(i).Kind())
-		}
-
-	}
-
-	return attrs, nil
-}
@@ -1,21 +1,36 @@
 package savecampaigns
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/proullon/ramsql/driver"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	ctxMocks "github.com/zdarovich/promotion-api/internal/api/requests/root/mocks"
 	"github.com/zdarovich/promotion-api/internal/api/response"
 	config2 "github.com/zdarovich/promotion-api/internal/config"
 	sqlx2 "github.com/zdarovich/promotion-api/internal/database/sqlx"
 	"github.com/zdarovich/promotion-api/internal/helpers/campaignhelper"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
+	webhookMocks "github.com/zdarovich/promotion-api/internal/helpers/webhookhelper/mocks"
 	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
 	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
 	"github.com/zdarovich/promotion-api/internal/repositories/config"
 	configMocks "github.com/zdarovich/promotion-api/internal/repositories/config/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/productset"
+	productSetMocks "github.com/zdarovich/promotion-api/internal/repositories/productset/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/schedule"
+	scheduleMocks "github.com/zdarovich/promotion-api/internal/repositories/schedule/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/session"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	settingsMocks "github.com/zdarovich/promotion-api/internal/repositories/settings/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/tier"
+	tierMocks "github.com/zdarovich/promotion-api/internal/repositories/tier/mocks"
 	"github.com/zdarovich/promotion-api/internal/repositories/user"
 	userMocks "github.com/zdarovich/promotion-api/internal/repositories/user/mocks"
+	"net/url"
 	"testing"
 	"time"
 )
@@ -42,7 +57,9 @@ func TestSaveCampaigns_Handle_WithNoAttributes_ReturnSuccess(t *testing.T) {
 	}
 
 	cr := new(configMocks.IRepository)
-	cr.On("GetConfigByName", "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "vertical").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "timezone_warehouse_1").Return(config.Conf{}, nil)
+	cr.On("GetConfigByName", mock.Anything, "timezone").Return(config.Conf{}, nil)
 
 	ch := new(campaignhelper.CampaignHelper)
 	ch.ConfigRepository = cr
@@ -51,18 +68,31 @@ func TestSaveCampaigns_Handle_WithNoAttributes_ReturnSuccess(t *testing.T) {
 	c := new(config2.Configuration)
 	sc.Configuration = c
 
-	ar := new(attributes.Repository)
-	sc.AttrsRepository = ar
+	str := new(settingsMocks.IRepository)
+	str.On("SaveSettingsTx", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	sc.SettingsRepository = str
+
+	tr := new(tierMocks.IRepository)
+	tr.On("SaveTiersTx", mock.Anything, mock.Anything, []*tier.Tier{}).Return(nil)
+	sc.TierRepository = tr
+
+	pr := new(productSetMocks.IRepository)
+	pr.On("SaveProductSetsTx", mock.Anything, mock.Anything, []*productset.ProductSet{}).Return(nil)
+	sc.ProductSetRepository = pr
+
+	sr := new(scheduleMocks.IRepository)
+	sr.On("SaveSchedulesTx", mock.Anything, mock.Anything, []*schedule.Schedule{}).Return(nil)
+	sc.ScheduleRepository = sr
 
 	startDate := time.Date(2099, time.April, 12, 0, 0, 0, 0, time.UTC)
-	endDate := time.Date(2099, time.April, 13, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2099, time.April, 13, 23, 59, 59, 0, time.UTC)
 
 	cm := new(campaign.Repository)
 	cm.Database = &db
 	sc.CampaignRepository = cm
 
 	ur := new(userMocks.IRepository)
-	ur.On("GetUserBySessionKey", "test").Return(user.User{ID: 1, Name: "test"}, nil)
+	ur.On("GetUserBySessionKey", mock.Anything, "test").Return(user.User{ID: 1, Name: "test"}, nil)
 	sc.UserRepository = ur
 
 	ginCtx := new(ctxMocks.IGinContext)
@@ -95,6 +125,8 @@ func TestSaveCampaigns_Handle_WithNoAttributes_ReturnSuccess(t *testing.T) {
 	ginCtx.On("PostForm", "awardedProductSubsidies").Return("", nil)
 	ginCtx.On("PostForm", "storeRegionIDs").Return("", nil)
 	ginCtx.On("PostForm", "customerGroupIDs").Return("", nil)
+	ginCtx.On("PostForm", "customerSegmentIDs").Return("", nil)
+	ginCtx.On("PostForm", "customerAllowlist").Return("", nil)
 	ginCtx.On("PostForm", "awardedAmount").Return("", nil)
 	ginCtx.On("PostForm", "purchasedProductCategoryID").Return("", nil)
 	ginCtx.On("PostForm", "awardedProductCategoryID").Return("", nil)
@@ -123,12 +155,22 @@ func TestSaveCampaigns_Handle_WithNoAttributes_ReturnSuccess(t *testing.T) {
 	ginCtx.On("PostForm", "percentageOffEntirePurchase").Return("", nil)
 	ginCtx.On("PostForm", "sumOffEntirePurchase").Return("", nil)
 	ginCtx.On("PostForm", "specialPrice").Return("", nil)
+	ginCtx.On("PostForm", "tiers").Return("", nil)
+	ginCtx.On("PostForm", "productSets").Return("", nil)
+	ginCtx.On("PostForm", "bundlePrice").Return("", nil)
+	ginCtx.On("PostForm", "bundleSumOff").Return("", nil)
+	ginCtx.On("PostForm", "bundlePercentageOff").Return("", nil)
+	ginCtx.On("PostForm", "schedules").Return("", nil)
+	ginCtx.On("PostForm", "priority").Return("", nil)
+	ginCtx.On("PostForm", "exclusive").Return("", nil)
+	ginCtx.On("PostForm", "stackingGroup").Return("", nil)
+	ginCtx.On("PostForm", "combinableWith").Return("", nil)
 	ginCtx.On("PostForm", "added").Return("", nil)
 	ginCtx.On("PostForm", "addedby").Return("", nil)
 	ginCtx.On("PostForm", "changed").Return("", nil)
 	ginCtx.On("PostForm", "changedby").Return("", nil)
 
-	actual, err := sc.Handle(ginCtx)
+	actual, err := sc.Handle(context.Background(), ginCtx)
 	assert.Nil(t, err)
 
 	expected := response.Data{
@@ -139,6 +181,9 @@ func TestSaveCampaigns_Handle_WithNoAttributes_ReturnSuccess(t *testing.T) {
 				CampaignID:        0,
 				StartDate:         startDate,
 				EndDate:           endDate,
+				StartDateLocal:    "2099-04-12T00:00:00Z",
+				EndDateLocal:      "2099-04-13T23:59:59Z",
+				Timezone:          "UTC",
 				Type:              "auto",
 				Name:              "test",
 				WarehouseID:       1,
@@ -151,3 +196,209 @@ func TestSaveCampaigns_Handle_WithNoAttributes_ReturnSuccess(t *testing.T) {
 
 	assert.Equal(t, &expected, actual)
 }
+
+type form url.Values
+
+func (f form) PostForm(key string) string { return url.Values(f).Get(key) }
+
+func TestSaveCampaigns_Handle_InMemory(t *testing.T) {
+	sessions := session.NewMemory()
+	sessions.AddSession(session.Session{User: "admin", Key: "key"})
+	users := user.NewMemory(sessions)
+	users.AddUser(user.User{Name: "Administrator", ShortName: "admin"})
+	confs := config.NewMemory()
+	confs.SetConfig(config.Conf{Name: "timezone", Value: "Europe/Tallinn"})
+	campaigns := campaign.NewMemory()
+	campaignSettings := settings.NewMemory(attributes.NewMemory())
+	tiers := tier.NewMemory()
+	webhooks := new(webhookMocks.IWebhookHelper)
+	webhooks.On("PublishTx", mock.Anything, mock.Anything, "", webhookhelper.EventCreated, mock.MatchedBy(func(c campaign.Campaign) bool {
+		return c.ID == 1 && c.Name == "in memory"
+	})).Return(nil)
+
+	sc := &SaveCampaigns{
+		CampaignRepository:   campaigns,
+		SettingsRepository:   campaignSettings,
+		TierRepository:       tiers,
+		ProductSetRepository: productset.NewMemory(),
+		ScheduleRepository:   schedule.NewMemory(),
+		CampaignHelper:       &campaignhelper.CampaignHelper{CampaignRepository: campaigns, ConfigRepository: confs},
+		WebhookHelper:        webhooks,
+		UserRepository:       users,
+		Configuration:        new(config2.Configuration),
+	}
+	start := time.Now().AddDate(0, 1, 0)
+
+	data, err := sc.Handle(context.Background(), form{
+		"sessionKey":                  {"key"},
+		"name":                        {"in memory"},
+		"type":                        {"auto"},
+		"warehouseID":                 {"1"},
+		"startDate":                   {start.Format("2006-01-02")},
+		"endDate":                     {start.AddDate(0, 1, 0).Format("2006-01-02")},
+		"purchasedProducts":           {"milk,cookie"},
+		"purchasedAmount":             {"2"},
+		"percentageOffEntirePurchase": {"10"},
+		"priority":                    {"3"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, data.Total)
+	records := data.Records.([]campaignhelper.RecordOutput)
+	assert.Equal(t, 1, records[0].CampaignID)
+	assert.Equal(t, "admin", records[0].Addedby)
+	assert.Equal(t, "Europe/Tallinn", records[0].Timezone)
+
+	saved, _ := campaigns.GetCampaigns(context.Background(), 1, "", 20, 0)
+	assert.Equal(t, "in memory", saved[0].Name)
+	stored, _ := campaignSettings.GetSettings(context.Background(), []int{1})
+	assert.Equal(t, []string{"milk", "cookie"}, stored[1].Products[settings.ListPurchasedProducts])
+	assert.Equal(t, 3, stored[1].Priority)
+	changes, _ := campaigns.GetChanges(context.Background(), 0, true, 20)
+	assert.Len(t, changes, 1)
+	assert.Equal(t, int64(1), changes[0].Seq)
+	webhooks.AssertExpectations(t)
+
+	_, err = sc.Handle(context.Background(), form{"sessionKey": {"unknown"}})
+	assert.NotNil(t, err)
+}
+
+func TestSaveCampaigns_Handle_RecordsFail_CampaignNotSaved(t *testing.T) {
+	sessions := session.NewMemory()
+	sessions.AddSession(session.Session{User: "admin", Key: "key"})
+	users := user.NewMemory(sessions)
+	users.AddUser(user.User{Name: "Administrator", ShortName: "admin"})
+	campaigns := campaign.NewMemory()
+	tiers := new(tierMocks.IRepository)
+	tiers.On("SaveTiersTx", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("tiers not saved"))
+	webhooks := new(webhookMocks.IWebhookHelper)
+
+	sc := &SaveCampaigns{
+		CampaignRepository:   campaigns,
+		SettingsRepository:   settings.NewMemory(attributes.NewMemory()),
+		TierRepository:       tiers,
+		ProductSetRepository: productset.NewMemory(),
+		ScheduleRepository:   schedule.NewMemory(),
+		CampaignHelper:       &campaignhelper.CampaignHelper{CampaignRepository: campaigns, ConfigRepository: config.NewMemory()},
+		WebhookHelper:        webhooks,
+		UserRepository:       users,
+		Configuration:        new(config2.Configuration),
+	}
+	start := time.Now().AddDate(0, 1, 0)
+
+	_, err := sc.Handle(context.Background(), form{
+		"sessionKey":                  {"key"},
+		"name":                        {"in memory"},
+		"type":                        {"auto"},
+		"warehouseID":                 {"1"},
+		"startDate":                   {start.Format("2006-01-02")},
+		"endDate":                     {start.AddDate(0, 1, 0).Format("2006-01-02")},
+		"purchasedProducts":           {"milk,cookie"},
+		"purchasedAmount":             {"2"},
+		"percentageOffEntirePurchase": {"10"},
+	})
+
+	assert.EqualError(t, err, "tiers not saved")
+	count, _ := campaigns.GetCampaignsCount(context.Background(), 0, "")
+	assert.Equal(t, 0, count)
+	changes, _ := campaigns.GetChanges(context.Background(), 0, true, 20)
+	assert.Len(t, changes, 0)
+	webhooks.AssertNotCalled(t, "PublishTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSaveCampaigns_Handle_EventsEnabled_NotPostedToWebhooks(t *testing.T) {
+	sessions := session.NewMemory()
+	sessions.AddSession(session.Session{User: "admin", Key: "key"})
+	users := user.NewMemory(sessions)
+	users.AddUser(user.User{Name: "Administrator", ShortName: "admin"})
+	campaigns := campaign.NewMemory()
+	webhooks := new(webhookMocks.IWebhookHelper)
+	configuration := new(config2.Configuration)
+	configuration.Events.Enabled = true
+
+	sc := &SaveCampaigns{
+		CampaignRepository:   campaigns,
+		SettingsRepository:   settings.NewMemory(attributes.NewMemory()),
+		TierRepository:       tier.NewMemory(),
+		ProductSetRepository: productset.NewMemory(),
+		ScheduleRepository:   schedule.NewMemory(),
+		CampaignHelper:       &campaignhelper.CampaignHelper{CampaignRepository: campaigns, ConfigRepository: config.NewMemory()},
+		WebhookHelper:        webhooks,
+		UserRepository:       users,
+		Configuration:        configuration,
+	}
+	start := time.Now().AddDate(0, 1, 0)
+
+	_, err := sc.Handle(context.Background(), form{
+		"sessionKey":                  {"key"},
+		"name":                        {"in memory"},
+		"type":                        {"auto"},
+		"warehouseID":                 {"1"},
+		"startDate":                   {start.Format("2006-01-02")},
+		"endDate":                     {start.AddDate(0, 1, 0).Format("2006-01-02")},
+		"purchasedProducts":           {"milk,cookie"},
+		"purchasedAmount":             {"2"},
+		"percentageOffEntirePurchase": {"10"},
+	})
+
+	assert.Nil(t, err)
+	webhooks.AssertNotCalled(t, "PublishTx", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// cancelledAfterSave cancels the request context once the campaign is saved
+type cancelledAfterSave struct {
+	*campaign.MemoryRepository
+	cancel context.CancelFunc
+}
+
+func (repository cancelledAfterSave) SaveCampaignsWithRecords(ctx context.Context, c *campaign.Campaign, records campaign.Records) error {
+	defer repository.cancel()
+	return repository.MemoryRepository.SaveCampaignsWithRecords(ctx, c, records)
+}
+
+func (repository cancelledAfterSave) GetCampaignsCount(ctx context.Context, campaignID int, campaignType string) (int, error) {
+	if ctx.Err() != nil {
+		return 0, ctx.Err()
+	}
+	return repository.MemoryRepository.GetCampaignsCount(ctx, campaignID, campaignType)
+}
+
+func TestSaveCampaigns_Handle_CancelledAfterSave_ReturnSuccess(t *testing.T) {
+	sessions := session.NewMemory()
+	sessions.AddSession(session.Session{User: "admin", Key: "key"})
+	users := user.NewMemory(sessions)
+	users.AddUser(user.User{Name: "Administrator", ShortName: "admin"})
+	ctx, cancel := context.WithCancel(context.Background())
+	campaigns := cancelledAfterSave{MemoryRepository: campaign.NewMemory(), cancel: cancel}
+	webhooks := new(webhookMocks.IWebhookHelper)
+	webhooks.On("PublishTx", mock.Anything, mock.Anything, "", webhookhelper.EventCreated, mock.Anything).Return(nil)
+
+	sc := &SaveCampaigns{
+		CampaignRepository:   campaigns,
+		SettingsRepository:   settings.NewMemory(attributes.NewMemory()),
+		TierRepository:       tier.NewMemory(),
+		ProductSetRepository: productset.NewMemory(),
+		ScheduleRepository:   schedule.NewMemory(),
+		CampaignHelper:       &campaignhelper.CampaignHelper{CampaignRepository: campaigns, ConfigRepository: config.NewMemory()},
+		WebhookHelper:        webhooks,
+		UserRepository:       users,
+		Configuration:        new(config2.Configuration),
+	}
+	start := time.Now().AddDate(0, 1, 0)
+
+	data, err := sc.Handle(ctx, form{
+		"sessionKey":                  {"key"},
+		"name":                        {"in memory"},
+		"type":                        {"auto"},
+		"warehouseID":                 {"1"},
+		"startDate":                   {start.Format("2006-01-02")},
+		"endDate":                     {start.AddDate(0, 1, 0).Format("2006-01-02")},
+		"purchasedProducts":           {"milk,cookie"},
+		"purchasedAmount":             {"2"},
+		"percentageOffEntirePurchase": {"10"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, data.Total)
+	assert.NotNil(t, ctx.Err())
+}
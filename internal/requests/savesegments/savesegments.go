@@ -0,0 +1,129 @@
+package savesegments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/segmenthelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/segment"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// SaveSegments struct
+	SaveSegments struct {
+		SegmentRepository segment.IRepository
+		SegmentHelper     segmenthelper.ISegmentHelper
+		UserRepository    user.IRepository
+		Configuration     *config.Configuration
+	}
+)
+
+// @Summary Save customer segment
+// @Description  Create or update a customer segment. A segment is a set of rules over customer attributes, a customer belongs to the segment when all rules match.
+// @Tags segment
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "saveSegments"
+// @Description  segmentID - Set to update an existing segment, its rules are replaced.
+// @Param segmentID formData string false "1"
+// @Param name formData string true "VIP birthdays"
+// @Param description formData string false "Gold members with a birthday this month"
+// @Description  rules - A JSON array of rules. Supported attributes and operators: "birthdayMonth" (1-12; eq, neq, gt, gte, lt, lte, in), "loyaltyTier" (eq, neq, in), "signupDate" (YYYY-MM-DD; eq, neq, gt, gte, lt, lte), "totalSpend" (eq, neq, gt, gte, lt, lte), "tags" (contains, notContains, in). Operator "in" takes a comma-separated list.
+// @Param rules formData string true "[{\"attribute\":\"loyaltyTier\",\"operator\":\"eq\",\"value\":\"gold\"}]"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /saveSegments [POST]
+func (saveSegments *SaveSegments) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	userEntity, err := caller.User(ctx, saveSegments.UserRepository, context.PostForm("sessionKey"))
+	if err != nil || userEntity.ID == 0 {
+		return nil, errors.New("userEntity not found")
+	}
+
+	record, err := getRecord(context)
+	if err != nil {
+		return nil, err
+	}
+
+	err = saveSegments.SegmentHelper.Validate(record)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.SegmentID > 0 {
+		count, err := saveSegments.SegmentRepository.GetSegmentsCount(ctx, record.SegmentID)
+		if err != nil {
+			return nil, errorcodes.Wrap(err, 1003)
+		}
+		if count == 0 {
+			return nil, errorcodes.New("segmentID", 1014)
+		}
+	}
+
+	now := time.Now().Unix()
+	s := segment.Segment{
+		ID:          record.SegmentID,
+		Name:        strings.TrimSpace(record.Name),
+		Description: record.Description,
+		Added:       now,
+		Addedby:     userEntity.ShortName,
+		Changed:     now,
+		Changedby:   userEntity.ShortName,
+	}
+	rules := saveSegments.SegmentHelper.MapRulesToDatabase(record)
+	err = saveSegments.SegmentRepository.SaveSegment(ctx, &s, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	output := saveSegments.SegmentHelper.MapToArray([]segment.Segment{s}, map[int][]*segment.Rule{s.ID: rules})
+
+	return &response.Data{
+		Total:           len(output),
+		TotalInResponse: len(output),
+		Records:         output,
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &SaveSegments{
+		SegmentRepository: segment.New(configuration),
+		SegmentHelper:     segmenthelper.New(configuration),
+		UserRepository:    user.New(configuration),
+		Configuration:     configuration,
+	}
+}
+
+func getRecord(c root.IGinContext) (*segmenthelper.Record, error) {
+	rec := segmenthelper.Record{
+		Name:        c.PostForm("name"),
+		Description: c.PostForm("description"),
+	}
+	if segmentID := c.PostForm("segmentID"); segmentID != "" {
+		id, err := strconv.Atoi(segmentID)
+		if err != nil || id < 0 {
+			return nil, errorcodes.New("segmentID", 1014)
+		}
+		rec.SegmentID = id
+	}
+	if rules := c.PostForm("rules"); rules != "" {
+		if err := json.Unmarshal([]byte(rules), &rec.Rules); err != nil {
+			return nil, errorcodes.New("rules", 1014)
+		}
+	}
+	return &rec, nil
+}
@@ -0,0 +1,152 @@
+package savewebhook
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
+	"github.com/zdarovich/promotion-api/internal/api/requests/root"
+	"github.com/zdarovich/promotion-api/internal/api/response"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/user"
+	"github.com/zdarovich/promotion-api/internal/repositories/webhook"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// SaveWebhook struct
+	SaveWebhook struct {
+		WebhookRepository webhook.IRepository
+		UserRepository    user.IRepository
+		Configuration     *config.Configuration
+	}
+)
+
+// @Summary Save webhook
+// @Description  Creates or updates a webhook. The events of the campaigns it is subscribed to are posted to its url, signed with its secret. The secret is output only when it is generated or changed.
+// @Tags webhook
+// @Accept  application/x-www-form-urlencoded
+// @Produce  json
+// @Param sessionKey formData string true "ERPLY session key"
+// @Param clientCode formData string true "ERPLY client code"
+// @Param request formData string true "saveWebhook"
+// @Description  webhookID - The webhook to update, a new webhook is created when not set.
+// @Param webhookID formData string false "1"
+// @Param url formData string false "https://pos.example.com/hooks"
+// @Description  events - Comma-separated list of campaign.created, campaign.updated, campaign.deleted, campaign.activated and campaign.expired.
+// @Param events formData string false "campaign.created,campaign.deleted"
+// @Description  secret - At least 16 characters, a secret is generated for a new webhook when not set.
+// @Param secret formData string false "whsec_..."
+// @Param active formData string false "1"
+// @Success 200 {object} response.SuccessResponse
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 404 {object} response.ErrorResponse
+// @Router /saveWebhook [POST]
+func (saveWebhook *SaveWebhook) Handle(ctx context.Context, context root.IGinContext) (*response.Data, error) {
+
+	userEntity, err := caller.User(ctx, saveWebhook.UserRepository, context.PostForm("sessionKey"))
+	if err != nil || userEntity.ID == 0 {
+		return nil, errors.New("userEntity not found")
+	}
+
+	w := &webhook.Webhook{Active: true}
+	if formVal := context.PostForm("webhookID"); formVal != "" {
+		webhookID, err := strconv.Atoi(formVal)
+		if err != nil || webhookID <= 0 {
+			return nil, errorcodes.New("webhookID", 1014)
+		}
+		existing, err := saveWebhook.WebhookRepository.GetWebhook(ctx, webhookID)
+		if err == sql.ErrNoRows {
+			return nil, errorcodes.New("webhookID", 1014)
+		}
+		if err != nil {
+			return nil, errorcodes.Wrap(err, 1003)
+		}
+		w = &existing
+	}
+
+	secretChanged, err := validate(context, w)
+	if err != nil {
+		return nil, err
+	}
+	if w.Secret == "" {
+		w.Secret, err = webhookhelper.GenerateSecret()
+		if err != nil {
+			return nil, err
+		}
+		secretChanged = true
+	}
+
+	now := time.Now().Unix()
+	if w.ID == 0 {
+		w.Added = now
+		w.Addedby = userEntity.ShortName
+	}
+	w.Changed = now
+	w.Changedby = userEntity.ShortName
+	err = saveWebhook.WebhookRepository.SaveWebhook(ctx, w)
+	if err != nil {
+		return nil, errorcodes.Wrap(err, 1003)
+	}
+
+	return &response.Data{
+		Total:           1,
+		TotalInResponse: 1,
+		Records:         []webhookhelper.Record{webhookhelper.MapToOutput(*w, secretChanged)},
+	}, nil
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) root.IRoot {
+
+	return &SaveWebhook{
+		WebhookRepository: webhook.New(configuration),
+		UserRepository:    user.New(configuration),
+		Configuration:     configuration,
+	}
+}
+
+// validate sets the input parameters on the webhook and returns whether its
+// secret is set. The url and the events are required for a new webhook, the
+// parameters not set are kept on update
+func validate(context root.IGinContext, w *webhook.Webhook) (bool, error) {
+
+	if formVal := context.PostForm("url"); formVal != "" || w.ID == 0 {
+		if formVal == "" {
+			return false, errorcodes.New("url", errorcodes.CodeRequiredParameterMissing)
+		}
+		if !webhookhelper.ValidURL(formVal) {
+			return false, errorcodes.New("url", 1014)
+		}
+		w.URL = formVal
+	}
+	if formVal := context.PostForm("events"); formVal != "" || w.ID == 0 {
+		if formVal == "" {
+			return false, errorcodes.New("events", errorcodes.CodeRequiredParameterMissing)
+		}
+		events, ok := webhookhelper.ParseEvents(formVal)
+		if !ok {
+			return false, errorcodes.New("events", 1014)
+		}
+		w.Events = strings.Join(events, ",")
+	}
+	if formVal := context.PostForm("active"); formVal != "" {
+		if formVal != "0" && formVal != "1" {
+			return false, errorcodes.New("active", 1014)
+		}
+		w.Active = formVal == "1"
+	}
+	formVal := context.PostForm("secret")
+	if formVal == "" {
+		return false, nil
+	}
+	if len(formVal) < webhookhelper.MinSecretLength || len(formVal) > webhookhelper.MaxSecretLength {
+		return false, errorcodes.New("secret", 1014)
+	}
+	w.Secret = formVal
+	return true, nil
+}
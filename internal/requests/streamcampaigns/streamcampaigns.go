@@ -0,0 +1,143 @@
+package streamcampaigns
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/zdarovich/promotion-api/internal/api/caller"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes/v2"
+	response2 "github.com/zdarovich/promotion-api/internal/api/response/v2"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/service/campaignstream"
+
+	"github.com/gin-gonic/gin"
+)
+
+type (
+	// StreamCampaigns struct
+	StreamCampaigns struct {
+		Configuration *config.Configuration
+		Stream        campaignstream.IStream
+	}
+	// writer writes the events to the response as server-sent events
+	writer struct {
+		w       io.Writer
+		flusher http.Flusher
+	}
+)
+
+// @Summary Stream campaign events
+// @Description  Stream the campaign events as server-sent events: created, updated and deleted. The id of an event is sent back as the Last-Event-ID header to resume after it, without it the stream starts with the next event. A comment is sent as a heartbeat while there are no events.
+// @Tags campaign
+// @Produce  text/event-stream
+// @Param sessionKey header string true "ERPLY session key"
+// @Param clientCode header string true "ERPLY client code"
+// @Param Last-Event-ID header string false "12"
+// @Param warehouseID query string false "1"
+// @Param storeGroup query string false "1"
+// @Success 200 {string} string
+// @Failure 400 {object} response.ErrorResponse
+// @Failure 500 {object} response.ErrorResponse
+// @Router /campaigns/stream [GET]
+func (streamCampaigns *StreamCampaigns) Handle(context *gin.Context) {
+
+	filter := campaignstream.Filter{StoreGroup: context.Query("storeGroup")}
+	if value := context.Query("warehouseID"); value != "" {
+		warehouseID, err := strconv.Atoi(value)
+		if err != nil {
+			streamCampaigns.error(context, http.StatusBadRequest, errorcodes.New("warehouseID", errorcodes.CodeInvalidParameter))
+			return
+		}
+		filter.WarehouseID = warehouseID
+	}
+
+	var lastEventID int
+	value := context.GetHeader("Last-Event-ID")
+	resume := value != ""
+	if resume {
+		var err error
+		lastEventID, err = strconv.Atoi(value)
+		if err != nil || lastEventID < 0 {
+			streamCampaigns.error(context, http.StatusBadRequest, errorcodes.New("Last-Event-ID", errorcodes.CodeInvalidParameter))
+			return
+		}
+	}
+
+	ctx := context.Request.Context()
+	authenticated, _ := caller.FromContext(ctx)
+	subscription, err := streamCampaigns.Stream.Subscribe(ctx, authenticated.ClientCode, filter)
+	if err != nil {
+		log.Error(fmt.Sprintf("campaign stream of %s: not subscribed: %s", authenticated.ClientCode, err))
+		streamCampaigns.error(context, http.StatusInternalServerError, errorcodes.New("", errorcodes.CodeDatabase))
+		return
+	}
+	defer subscription.Close()
+
+	header := context.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Proxies pass the events on as they come
+	header.Set("X-Accel-Buffering", "no")
+	context.Status(http.StatusOK)
+
+	w := &writer{w: context.Writer, flusher: context.Writer}
+	err = w.Heartbeat()
+	if err == nil {
+		err = subscription.Receive(ctx, lastEventID, resume, w)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Error(fmt.Sprintf("campaign stream of %s: %s", authenticated.ClientCode, err))
+	}
+}
+
+// New return configured struct
+func New(configuration *config.Configuration) *StreamCampaigns {
+
+	return &StreamCampaigns{
+		Configuration: configuration,
+		Stream:        campaignstream.New(configuration),
+	}
+}
+
+// error responds with the error before the stream has started
+func (streamCampaigns *StreamCampaigns) error(context *gin.Context, status int, err *errorcodes.CodeError) {
+
+	response := response2.New(streamCampaigns.Configuration)
+	response.Error(context, status, err)
+}
+
+// Event writes the event, a line of the data per line of the body
+func (w *writer) Event(e campaignstream.Event) error {
+
+	var buffer bytes.Buffer
+	fmt.Fprintf(&buffer, "id: %d\nevent: %s\n", e.ID, e.Event)
+	for _, line := range bytes.Split(e.Data, []byte("\n")) {
+		buffer.WriteString("data: ")
+		buffer.Write(line)
+		buffer.WriteString("\n")
+	}
+	buffer.WriteString("\n")
+
+	return w.write(buffer.Bytes())
+}
+
+// Heartbeat writes a comment, it keeps the connection open
+func (w *writer) Heartbeat() error {
+
+	return w.write([]byte(": heartbeat\n\n"))
+}
+
+func (w *writer) write(data []byte) error {
+
+	_, err := w.w.Write(data)
+	if err != nil {
+		return err
+	}
+	w.flusher.Flush()
+	return nil
+}
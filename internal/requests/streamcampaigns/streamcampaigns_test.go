@@ -0,0 +1,45 @@
+package streamcampaigns
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/service/campaignstream"
+)
+
+func TestWriter_Event(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	w := &writer{w: recorder, flusher: recorder}
+
+	assert.Nil(t, w.Event(campaignstream.Event{ID: 12, Event: "campaign.updated", Data: []byte("{\"a\":1}\n{\"b\":2}")}))
+	assert.Nil(t, w.Heartbeat())
+
+	assert.Equal(t, "id: 12\nevent: campaign.updated\ndata: {\"a\":1}\ndata: {\"b\":2}\n\n: heartbeat\n\n", recorder.Body.String())
+	assert.True(t, recorder.Flushed)
+}
+
+func TestStreamCampaigns_Handle_InvalidParameters(t *testing.T) {
+	handler := &StreamCampaigns{Configuration: &config.Configuration{}}
+
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/v1/campaigns/stream?warehouseID=main", nil),
+		func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/v1/campaigns/stream", nil)
+			r.Header.Set("Last-Event-ID", "-1")
+			return r
+		}(),
+	} {
+		recorder := httptest.NewRecorder()
+		context, _ := gin.CreateTestContext(recorder)
+		context.Request = request
+
+		handler.Handle(context)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "2015")
+	}
+}
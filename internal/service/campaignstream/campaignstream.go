@@ -0,0 +1,527 @@
+package campaignstream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/outbox"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/service/databasediscovery"
+	"github.com/zdarovich/promotion-api/internal/service/outboxrelay"
+)
+
+// Defaults of the stream configuration
+const (
+	DefaultInterval   = time.Second
+	DefaultHeartbeat  = 15 * time.Second
+	DefaultBufferSize = 100
+)
+
+// pageSize messages read from the outbox at a time
+const pageSize = 100
+
+type (
+	// Stream sends the campaign events recorded in the outbox of a tenant
+	// to its subscribers. One poller per tenant with subscribers reads the
+	// outbox after the last message it has read and fans the events out
+	Stream struct {
+		Configuration     config.Configuration
+		DatabaseDiscovery databasediscovery.IDatabaseDiscovery
+		repository        func(configuration *config.Configuration) outbox.IRepository
+		settings          func(configuration *config.Configuration) settings.IRepository
+		interval          time.Duration
+		heartbeat         time.Duration
+		mutex             sync.Mutex
+		tenants           map[string]*tenant
+	}
+	// IStream interface
+	IStream interface {
+		Subscribe(ctx context.Context, clientCode string, filter Filter) (*Subscription, error)
+	}
+	// Subscription subscriber of the events of a tenant, it is closed when
+	// the subscriber leaves
+	Subscription struct {
+		stream  *Stream
+		tenant  *tenant
+		filter  Filter
+		from    int
+		events  chan Event
+		dropped chan struct{}
+	}
+	// Writer sends the events and the heartbeats to the subscriber
+	Writer interface {
+		Event(e Event) error
+		Heartbeat() error
+	}
+	// Event campaign event of the stream, ID is the id of the outbox message
+	// and Data the envelope published to the bus
+	Event struct {
+		ID          int
+		Event       string
+		CampaignID  int
+		WarehouseID int
+		StoreGroup  string
+		Data        []byte
+	}
+	// Filter of the events of a subscriber, empty fields match all
+	Filter struct {
+		WarehouseID int
+		StoreGroup  string
+	}
+	tenant struct {
+		clientCode    string
+		configuration config.Configuration
+		subscribers   map[*Subscription]bool
+		joined        int
+		cursor        int
+		storeGroups   int
+		stop          chan struct{}
+	}
+)
+
+// New returns new configured stream. The configuration is copied, the
+// database of every tenant is set on a copy of its own
+func New(configuration *config.Configuration) IStream {
+
+	return &Stream{
+		Configuration:     *configuration,
+		DatabaseDiscovery: databasediscovery.New(configuration),
+		repository: func(configuration *config.Configuration) outbox.IRepository {
+			return outbox.New(configuration)
+		},
+		settings: func(configuration *config.Configuration) settings.IRepository {
+			return settings.New(configuration)
+		},
+		interval:  seconds(configuration.Stream.Interval, DefaultInterval),
+		heartbeat: seconds(configuration.Stream.Heartbeat, DefaultHeartbeat),
+		tenants:   make(map[string]*tenant),
+	}
+}
+
+// Matches returns whether the event is of a campaign of the warehouse and
+// the store group of the filter. A campaign not restricted to a warehouse
+// or a store group matches all
+func (filter Filter) Matches(e Event) bool {
+
+	if filter.WarehouseID != 0 && e.WarehouseID != 0 && e.WarehouseID != filter.WarehouseID {
+		return false
+	}
+	if filter.StoreGroup != "" && e.StoreGroup != "" && e.StoreGroup != filter.StoreGroup {
+		return false
+	}
+	return true
+}
+
+// Subscribe joins the subscriber to the events of the tenant. The events
+// are sent by Receive
+func (stream *Stream) Subscribe(ctx context.Context, clientCode string, filter Filter) (*Subscription, error) {
+
+	t, err := stream.join(ctx, clientCode)
+	if err != nil {
+		return nil, err
+	}
+
+	bufferSize := stream.Configuration.Stream.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	return &Subscription{
+		stream: stream,
+		tenant: t,
+		filter: filter,
+		events: make(chan Event, bufferSize),
+	}, nil
+}
+
+// Receive sends the events after the event with the id to the writer, the
+// events from now on without resume, until the context is done. The events
+// the poller has read before are read from the outbox first, and again when
+// the subscriber falls more than the buffer behind
+func (subscription *Subscription) Receive(ctx context.Context, lastEventID int, resume bool, w Writer) error {
+
+	stream := subscription.stream
+	last := lastEventID
+	if !resume {
+		stream.mutex.Lock()
+		last = subscription.tenant.cursor
+		stream.mutex.Unlock()
+	}
+
+	ticker := time.NewTicker(stream.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		last, err = subscription.register(ctx, last, w)
+		if err != nil {
+			return err
+		}
+		last, err = subscription.forward(ctx, last, w, ticker)
+		if err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+}
+
+// Close leaves the events of the tenant
+func (subscription *Subscription) Close() {
+
+	stream := subscription.stream
+	t := subscription.tenant
+
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	if t.subscribers[subscription] {
+		delete(t.subscribers, subscription)
+		if subscription.filter.StoreGroup != "" {
+			t.storeGroups--
+		}
+	}
+	t.joined--
+	if t.joined == 0 {
+		delete(stream.tenants, t.clientCode)
+		close(t.stop)
+	}
+}
+
+// register catches up with the poller and registers the subscriber, the
+// later events are sent by the poller. It returns the id it has sent up to
+func (subscription *Subscription) register(ctx context.Context, last int, w Writer) (int, error) {
+
+	stream := subscription.stream
+	t := subscription.tenant
+	for {
+		stream.mutex.Lock()
+		cursor := t.cursor
+		if last >= cursor {
+			subscription.from = last
+			subscription.events = make(chan Event, cap(subscription.events))
+			subscription.dropped = make(chan struct{})
+			t.subscribers[subscription] = true
+			if subscription.filter.StoreGroup != "" {
+				t.storeGroups++
+			}
+		}
+		stream.mutex.Unlock()
+		if last >= cursor {
+			return last, nil
+		}
+
+		var err error
+		last, err = stream.catchUp(ctx, t, subscription.filter, last, cursor, w)
+		if err != nil {
+			return last, err
+		}
+	}
+}
+
+// forward sends the events of the poller and the heartbeats until the
+// context is done or the subscriber is dropped, and returns the id it has
+// sent up to
+func (subscription *Subscription) forward(ctx context.Context, last int, w Writer, ticker *time.Ticker) (int, error) {
+
+	for {
+		select {
+		case <-ctx.Done():
+			return last, nil
+		case <-subscription.dropped:
+			// The buffered events are sent before the subscriber catches up
+			for {
+				select {
+				case e := <-subscription.events:
+					err := w.Event(e)
+					if err != nil {
+						return last, err
+					}
+					last = e.ID
+				default:
+					return last, nil
+				}
+			}
+		case e := <-subscription.events:
+			err := w.Event(e)
+			if err != nil {
+				return last, err
+			}
+			last = e.ID
+		case <-ticker.C:
+			err := w.Heartbeat()
+			if err != nil {
+				return last, err
+			}
+		}
+	}
+}
+
+// catchUp sends the events after last up to the cursor of the poller and
+// returns the id it has sent up to
+func (stream *Stream) catchUp(ctx context.Context, t *tenant, filter Filter, last int, cursor int, w Writer) (int, error) {
+
+	repository := stream.repository(&t.configuration)
+	for last < cursor {
+		messages, err := repository.GetAfter(ctx, last, pageSize)
+		if err != nil {
+			return last, err
+		}
+		if len(messages) > 0 && messages[len(messages)-1].ID > cursor {
+			// The poller sends the later ones
+			for idx, m := range messages {
+				if m.ID > cursor {
+					messages = messages[:idx]
+					break
+				}
+			}
+		}
+		if len(messages) == 0 {
+			// The messages up to the cursor were rolled back or deleted
+			return cursor, nil
+		}
+
+		events, err := stream.events(ctx, t, messages, filter.StoreGroup != "")
+		if err != nil {
+			return last, err
+		}
+		for _, e := range events {
+			if filter.Matches(e) {
+				err := w.Event(e)
+				if err != nil {
+					return last, err
+				}
+			}
+		}
+		last = messages[len(messages)-1].ID
+	}
+
+	return last, nil
+}
+
+// join returns the tenant of the client code, its poller is started for
+// the first subscriber on the database of the tenant
+func (stream *Stream) join(ctx context.Context, clientCode string) (*tenant, error) {
+
+	stream.mutex.Lock()
+	if t, ok := stream.tenants[clientCode]; ok {
+		t.joined++
+		stream.mutex.Unlock()
+		return t, nil
+	}
+	stream.mutex.Unlock()
+
+	configuration, err := stream.database(ctx, clientCode)
+	if err != nil {
+		return nil, err
+	}
+	t := &tenant{
+		clientCode:    clientCode,
+		configuration: configuration,
+		subscribers:   make(map[*Subscription]bool),
+		joined:        1,
+		stop:          make(chan struct{}),
+	}
+	cursor, err := stream.repository(&t.configuration).GetLastID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.cursor = cursor
+
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	if existing, ok := stream.tenants[clientCode]; ok {
+		// Joined while the last id was read
+		existing.joined++
+		return existing, nil
+	}
+	stream.tenants[clientCode] = t
+	go stream.poll(t)
+
+	return t, nil
+}
+
+// database returns a copy of the configuration with the database of the
+// tenant
+func (stream *Stream) database(ctx context.Context, clientCode string) (config.Configuration, error) {
+
+	configuration := stream.Configuration
+	// The events of a message id are read after the ones before it are
+	// committed, the replicas may not have them yet
+	configuration.Database.Replicas = nil
+	if !configuration.Database.Discovery.Enabled {
+		return configuration, nil
+	}
+
+	database, err := stream.DatabaseDiscovery.GetDatabase(ctx, clientCode)
+	if err != nil {
+		return configuration, err
+	}
+	configuration.Database.Name = database.DatabaseName
+	configuration.Database.Server = database.Host
+	configuration.Database.Port = database.Port
+	configuration.Database.Username = database.User
+	configuration.Database.Password = database.Password
+	if database.Driver != "" {
+		configuration.Database.Driver = database.Driver
+	}
+	return configuration, nil
+}
+
+// poll reads the outbox of the tenant every interval and sends the events
+// to its subscribers until the last one has left
+func (stream *Stream) poll(t *tenant) {
+
+	ticker := time.NewTicker(stream.interval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-t.stop
+		cancel()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := stream.read(ctx, t)
+			if err != nil && ctx.Err() == nil {
+				log.Error(fmt.Sprintf("campaign stream of %s: outbox not read: %s", t.clientCode, err))
+			}
+		}
+	}
+}
+
+// read sends the messages of the outbox after the cursor of the tenant to
+// its subscribers
+func (stream *Stream) read(ctx context.Context, t *tenant) error {
+
+	repository := stream.repository(&t.configuration)
+	for {
+		stream.mutex.Lock()
+		cursor := t.cursor
+		storeGroups := t.storeGroups > 0
+		stream.mutex.Unlock()
+
+		messages, err := repository.GetAfter(ctx, cursor, pageSize)
+		if err != nil || len(messages) == 0 {
+			return err
+		}
+		events, err := stream.events(ctx, t, messages, storeGroups)
+		if err != nil {
+			return err
+		}
+
+		stream.mutex.Lock()
+		t.cursor = messages[len(messages)-1].ID
+		subscribers := make([]*Subscription, 0, len(t.subscribers))
+		for subscription := range t.subscribers {
+			subscribers = append(subscribers, subscription)
+		}
+		stream.mutex.Unlock()
+
+		for _, subscription := range subscribers {
+			subscription.send(events)
+		}
+		if len(messages) < pageSize {
+			return nil
+		}
+	}
+}
+
+// send buffers the events of the subscriber. A subscriber with a full
+// buffer is dropped, it reads the outbox at its own pace until it has caught
+// up again
+func (subscription *Subscription) send(events []Event) {
+
+	for _, e := range events {
+		if e.ID <= subscription.from || !subscription.filter.Matches(e) {
+			continue
+		}
+		select {
+		case subscription.events <- e:
+		default:
+			subscription.drop()
+			return
+		}
+	}
+}
+
+// drop takes the subscriber off the poller
+func (subscription *Subscription) drop() {
+
+	stream := subscription.stream
+	stream.mutex.Lock()
+	defer stream.mutex.Unlock()
+
+	t := subscription.tenant
+	if t.subscribers[subscription] {
+		delete(t.subscribers, subscription)
+		if subscription.filter.StoreGroup != "" {
+			t.storeGroups--
+		}
+		close(subscription.dropped)
+	}
+}
+
+// events returns the events of the campaign messages, with the store groups
+// of the campaigns when they are needed
+func (stream *Stream) events(ctx context.Context, t *tenant, messages []outbox.Message, storeGroups bool) ([]Event, error) {
+
+	events := make([]Event, 0, len(messages))
+	ids := make([]int, 0)
+	for _, m := range messages {
+		if m.Aggregate != campaign.Aggregate {
+			continue
+		}
+		message, err := outboxrelay.Message(t.clientCode, m)
+		if err != nil {
+			return nil, err
+		}
+		var payload campaign.EventPayload
+		if err := json.Unmarshal([]byte(m.Payload), &payload); err != nil {
+			log.Warn(fmt.Sprintf("campaign stream of %s: payload of message %d not read: %s", t.clientCode, m.ID, err))
+		}
+		events = append(events, Event{
+			ID:          m.ID,
+			Event:       m.Event,
+			CampaignID:  m.AggregateID,
+			WarehouseID: payload.WarehouseID,
+			Data:        message.Body,
+		})
+		if m.Event != campaign.EventDeleted {
+			ids = append(ids, m.AggregateID)
+		}
+	}
+	if !storeGroups || len(ids) == 0 {
+		return events, nil
+	}
+
+	campaignSettings, err := stream.settings(&t.configuration).GetSettings(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for idx := range events {
+		if s, ok := campaignSettings[events[idx].CampaignID]; ok && s != nil {
+			events[idx].StoreGroup = s.StoreGroup
+		}
+	}
+
+	return events, nil
+}
+
+// seconds returns the configured seconds, the default when not configured
+func seconds(configured int, defaultDuration time.Duration) time.Duration {
+
+	if configured <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(configured) * time.Second
+}
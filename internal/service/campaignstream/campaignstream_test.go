@@ -0,0 +1,248 @@
+package campaignstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/outbox"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	"github.com/zdarovich/promotion-api/internal/service/databasediscovery"
+	"github.com/zdarovich/promotion-api/internal/service/databasediscovery/mocks"
+)
+
+type writerMock struct {
+	mutex  sync.Mutex
+	events []Event
+	block  chan struct{}
+}
+
+func (w *writerMock) Event(e Event) error {
+	if w.block != nil {
+		<-w.block
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.events = append(w.events, e)
+	return nil
+}
+
+func (w *writerMock) Heartbeat() error { return nil }
+
+func (w *writerMock) ids() []int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	ids := make([]int, len(w.events))
+	for idx, e := range w.events {
+		ids[idx] = e.ID
+	}
+	return ids
+}
+
+func newStream(messages *outbox.MemoryRepository, campaignSettings *settings.MemoryRepository, bufferSize int) *Stream {
+	s := &Stream{
+		repository: func(configuration *config.Configuration) outbox.IRepository { return messages },
+		settings:   func(configuration *config.Configuration) settings.IRepository { return campaignSettings },
+		interval:   5 * time.Millisecond,
+		heartbeat:  time.Hour,
+		tenants:    make(map[string]*tenant),
+	}
+	s.Configuration.Stream.BufferSize = bufferSize
+	return s
+}
+
+func add(t *testing.T, messages *outbox.MemoryRepository, event string, c campaign.Campaign) {
+	m, err := campaign.OutboxMessage(event, c, 100)
+	require.Nil(t, err)
+	messages.Add(m)
+}
+
+func receive(s *Stream, filter Filter, lastEventID int, resume bool, w Writer) (context.CancelFunc, chan error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	subscription, err := s.Subscribe(ctx, "1", filter)
+	if err != nil {
+		done <- err
+		return cancel, done
+	}
+	go func() {
+		defer subscription.Close()
+		done <- subscription.Receive(ctx, lastEventID, resume, w)
+	}()
+	return cancel, done
+}
+
+func TestFilter_Matches(t *testing.T) {
+	assert.True(t, Filter{}.Matches(Event{WarehouseID: 2, StoreGroup: "north"}))
+	assert.True(t, Filter{WarehouseID: 2}.Matches(Event{WarehouseID: 2}))
+	assert.True(t, Filter{WarehouseID: 2}.Matches(Event{}))
+	assert.False(t, Filter{WarehouseID: 2}.Matches(Event{WarehouseID: 3}))
+	assert.True(t, Filter{StoreGroup: "north"}.Matches(Event{StoreGroup: "north"}))
+	assert.False(t, Filter{StoreGroup: "north"}.Matches(Event{StoreGroup: "south"}))
+	assert.True(t, Filter{WarehouseID: 2, StoreGroup: "north"}.Matches(Event{WarehouseID: 2}))
+}
+
+func TestStream_Live(t *testing.T) {
+	messages := outbox.NewMemory()
+	add(t, messages, campaign.EventCreated, campaign.Campaign{ID: 1})
+	s := newStream(messages, nil, 10)
+
+	w := &writerMock{}
+	cancel, done := receive(s, Filter{WarehouseID: 2}, 0, false, w)
+
+	// The events from now on of the warehouse and of all warehouses
+	assert.Eventually(t, func() bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return len(s.tenants["1"].subscribers) == 1
+	}, time.Second, time.Millisecond)
+	add(t, messages, campaign.EventUpdated, campaign.Campaign{ID: 1, WarehouseID: 3})
+	add(t, messages, campaign.EventUpdated, campaign.Campaign{ID: 2, WarehouseID: 2})
+	add(t, messages, campaign.EventDeleted, campaign.Campaign{ID: 4})
+	assert.Eventually(t, func() bool { return len(w.ids()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []int{3, 4}, w.ids())
+
+	var envelope struct {
+		ClientCode  string `json:"clientCode"`
+		AggregateID int    `json:"aggregateID"`
+		Event       string `json:"event"`
+	}
+	require.Nil(t, json.Unmarshal(w.events[0].Data, &envelope))
+	assert.Equal(t, "1", envelope.ClientCode)
+	assert.Equal(t, 2, envelope.AggregateID)
+	assert.Equal(t, campaign.EventUpdated, envelope.Event)
+	assert.Equal(t, campaign.EventDeleted, w.events[1].Event)
+
+	cancel()
+	assert.Nil(t, <-done)
+
+	// The poller stops with the last subscriber
+	assert.Eventually(t, func() bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return len(s.tenants) == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestStream_Resume(t *testing.T) {
+	messages := outbox.NewMemory()
+	for id := 1; id <= 250; id++ {
+		add(t, messages, campaign.EventUpdated, campaign.Campaign{ID: id})
+	}
+	campaignSettings := settings.NewMemory(attributes.NewMemory())
+	require.Nil(t, campaignSettings.SaveSettings(context.Background(), &settings.Settings{CampaignID: 3, StoreGroup: "south"}))
+	require.Nil(t, campaignSettings.SaveSettings(context.Background(), &settings.Settings{CampaignID: 251, StoreGroup: "north"}))
+	s := newStream(messages, campaignSettings, 10)
+
+	// The events missed are read from the outbox, more than a page of them
+	w := &writerMock{}
+	cancel, done := receive(s, Filter{StoreGroup: "north"}, 1, true, w)
+	assert.Eventually(t, func() bool { return len(w.ids()) == 248 }, time.Second, time.Millisecond)
+	ids := w.ids()
+	assert.Equal(t, 2, ids[0])
+	assert.Equal(t, 4, ids[1])
+	assert.Equal(t, 250, ids[len(ids)-1])
+
+	add(t, messages, campaign.EventUpdated, campaign.Campaign{ID: 251})
+	add(t, messages, campaign.EventUpdated, campaign.Campaign{ID: 3})
+	assert.Eventually(t, func() bool { return len(w.ids()) == 249 }, time.Second, time.Millisecond)
+	assert.Equal(t, 251, w.ids()[248])
+
+	cancel()
+	assert.Nil(t, <-done)
+}
+
+func TestStream_TenantDatabases(t *testing.T) {
+	discovery := &mocks.IDatabaseDiscovery{}
+	discovery.On("GetDatabase", mock.Anything, "1").Return(databasediscovery.Database{DatabaseName: "tenant1", Host: "db1", Port: 3306}, nil)
+	discovery.On("GetDatabase", mock.Anything, "2").Return(databasediscovery.Database{DatabaseName: "tenant2", Host: "db2", Port: 5432, Driver: "postgres"}, nil)
+	discovery.On("GetDatabase", mock.Anything, "3").Return(databasediscovery.Database{}, errors.New("1014"))
+
+	var mutex sync.Mutex
+	databases := make(map[string]bool)
+	messages := outbox.NewMemory()
+	s := newStream(messages, nil, 10)
+	s.Configuration.Database.Discovery.Enabled = true
+	s.Configuration.Database.Name = "shared"
+	s.DatabaseDiscovery = discovery
+	s.repository = func(configuration *config.Configuration) outbox.IRepository {
+		mutex.Lock()
+		defer mutex.Unlock()
+		databases[configuration.Database.Driver+":"+configuration.Database.Server+"/"+configuration.Database.Name] = true
+		return messages
+	}
+
+	// Each tenant polls its own database, the configuration of the stream
+	// is left as it is
+	first, err := s.Subscribe(context.Background(), "1", Filter{})
+	require.Nil(t, err)
+	defer first.Close()
+	second, err := s.Subscribe(context.Background(), "2", Filter{})
+	require.Nil(t, err)
+	defer second.Close()
+	again, err := s.Subscribe(context.Background(), "1", Filter{})
+	require.Nil(t, err)
+	defer again.Close()
+
+	mutex.Lock()
+	assert.Equal(t, map[string]bool{":db1/tenant1": true, "postgres:db2/tenant2": true}, databases)
+	mutex.Unlock()
+	assert.Equal(t, "shared", s.Configuration.Database.Name)
+	discovery.AssertNumberOfCalls(t, "GetDatabase", 2)
+
+	_, err = s.Subscribe(context.Background(), "3", Filter{})
+	assert.EqualError(t, err, "1014")
+	s.mutex.Lock()
+	assert.Len(t, s.tenants, 2)
+	s.mutex.Unlock()
+}
+
+func TestStream_SlowConsumer(t *testing.T) {
+	messages := outbox.NewMemory()
+	s := newStream(messages, nil, 3)
+
+	fast := &writerMock{}
+	cancelFast, doneFast := receive(s, Filter{}, 0, false, fast)
+	defer cancelFast()
+	slow := &writerMock{block: make(chan struct{})}
+	cancelSlow, doneSlow := receive(s, Filter{}, 0, false, slow)
+	defer cancelSlow()
+	assert.Eventually(t, func() bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return s.tenants["1"] != nil && len(s.tenants["1"].subscribers) == 2
+	}, time.Second, time.Millisecond)
+
+	// The slow subscriber falls behind and catches up from the outbox
+	for id := 1; id <= 5; id++ {
+		add(t, messages, campaign.EventUpdated, campaign.Campaign{ID: id})
+	}
+	assert.Eventually(t, func() bool { return len(fast.ids()) == 5 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		return len(s.tenants["1"].subscribers) == 1
+	}, time.Second, time.Millisecond)
+	close(slow.block)
+	assert.Eventually(t, func() bool { return len(slow.ids()) == 5 }, time.Second, time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, slow.ids())
+
+	// It gets the live events again
+	add(t, messages, campaign.EventUpdated, campaign.Campaign{ID: 6})
+	assert.Eventually(t, func() bool { return len(slow.ids()) == 6 }, time.Second, time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, fast.ids())
+
+	cancelSlow()
+	assert.Nil(t, <-doneSlow)
+	cancelFast()
+	assert.Nil(t, <-doneFast)
+}
@@ -1,6 +1,7 @@
 package databasediscovery
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
@@ -17,16 +18,24 @@ type (
 	}
 	// IDatabaseDiscovery interface
 	IDatabaseDiscovery interface {
-		GetDatabase(clientCode string) (Database, error)
+		GetDatabase(ctx context.Context, clientCode string) (Database, error)
+		GetDatabases(ctx context.Context) ([]Database, error)
 	}
 	// Database dtabase struct object
 	Database struct {
-		Tenant       string `json:"tenant"`
-		DatabaseName string `json:"databaseName"`
-		Host         string `json:"host"`
-		Port         int    `json:"port"`
-		User         string `json:"username"`
-		Password     string `json:"password"`
+		Tenant       string    `json:"tenant"`
+		DatabaseName string    `json:"databaseName"`
+		Host         string    `json:"host"`
+		Port         int       `json:"port"`
+		User         string    `json:"username"`
+		Password     string    `json:"password"`
+		Driver       string    `json:"driver"`
+		Replicas     []Replica `json:"replicas"`
+	}
+	// Replica read replica of the tenant database
+	Replica struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
 	}
 )
 
@@ -39,15 +48,19 @@ func New(configuration *config.Configuration) IDatabaseDiscovery {
 }
 
 // GetDatabase gets details of the database for current client
-func (databasediscovery *DatabaseDiscovery) GetDatabase(clientCode string) (Database, error) {
+func (databasediscovery *DatabaseDiscovery) GetDatabase(ctx context.Context, clientCode string) (Database, error) {
 
 	var httpClient = &http.Client{
 		Timeout: time.Duration(databasediscovery.Configuration.Database.Discovery.Timeout) * time.Second,
 	}
 
-	result, err := httpClient.Get(
-		databasediscovery.Configuration.Database.Discovery.Server + "/v1/tenants/" + clientCode,
+	request, _ := http.NewRequestWithContext(
+		ctx,
+		"GET",
+		databasediscovery.Configuration.Database.Discovery.Server+"/v1/tenants/"+clientCode,
+		nil,
 	)
+	result, err := httpClient.Do(request)
 
 	if err != nil {
 		databasediscovery.logError(err.Error())
@@ -66,6 +79,38 @@ func (databasediscovery *DatabaseDiscovery) GetDatabase(clientCode string) (Data
 	return database, nil
 }
 
+// GetDatabases gets details of the databases of all tenants
+func (databasediscovery *DatabaseDiscovery) GetDatabases(ctx context.Context) ([]Database, error) {
+
+	var httpClient = &http.Client{
+		Timeout: time.Duration(databasediscovery.Configuration.Database.Discovery.Timeout) * time.Second,
+	}
+
+	request, _ := http.NewRequestWithContext(
+		ctx,
+		"GET",
+		databasediscovery.Configuration.Database.Discovery.Server+"/v1/tenants",
+		nil,
+	)
+	result, err := httpClient.Do(request)
+
+	if err != nil {
+		databasediscovery.logError(err.Error())
+		return nil, errors.New(errorcodes.CodeDBDiscovery)
+	}
+	defer result.Body.Close()
+
+	var databases []Database
+	err = json.NewDecoder(result.Body).Decode(&databases)
+
+	if err != nil {
+		databasediscovery.logError(err.Error())
+		return nil, errors.New(errorcodes.CodeDBDiscovery)
+	}
+
+	return databases, nil
+}
+
 // logs errors
 func (databasediscovery *DatabaseDiscovery) logError(errorMessage interface{}) {
 	log.Error(errorMessage)
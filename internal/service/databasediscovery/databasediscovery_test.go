@@ -1,7 +1,12 @@
 package databasediscovery
 
 import (
+	"context"
+	"errors"
+	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
 	"github.com/zdarovich/promotion-api/internal/config"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -15,3 +20,36 @@ func Test_New(t *testing.T) {
 
 	assert.NotNil(t, result)
 }
+
+func Test_GetDatabases(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/tenants", r.URL.Path)
+		w.Write([]byte(`[{"tenant":"100","databaseName":"crmx_100","host":"0.0.0.0","port":3306}]`))
+	}))
+	defer server.Close()
+
+	configuration := &config.Configuration{}
+	configuration.Database.Discovery.Server = server.URL
+	configuration.Database.Discovery.Timeout = 1
+
+	result, err := New(configuration).GetDatabases(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Database{{Tenant: "100", DatabaseName: "crmx_100", Host: "0.0.0.0", Port: 3306}}, result)
+}
+
+func Test_GetDatabases_InvalidResponse(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{`))
+	}))
+	defer server.Close()
+
+	configuration := &config.Configuration{}
+	configuration.Database.Discovery.Server = server.URL
+
+	_, err := New(configuration).GetDatabases(context.Background())
+
+	assert.Equal(t, errors.New(errorcodes.CodeDBDiscovery), err)
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	databasediscovery "github.com/zdarovich/promotion-api/internal/service/databasediscovery"
 )
@@ -12,20 +14,43 @@ type IDatabaseDiscovery struct {
 	mock.Mock
 }
 
-// GetDatabase provides a mock function with given fields: clientCode
-func (_m *IDatabaseDiscovery) GetDatabase(clientCode string) (databasediscovery.Database, error) {
-	ret := _m.Called(clientCode)
+// GetDatabase provides a mock function with given fields: ctx, clientCode
+func (_m *IDatabaseDiscovery) GetDatabase(ctx context.Context, clientCode string) (databasediscovery.Database, error) {
+	ret := _m.Called(ctx, clientCode)
 
 	var r0 databasediscovery.Database
-	if rf, ok := ret.Get(0).(func(string) databasediscovery.Database); ok {
-		r0 = rf(clientCode)
+	if rf, ok := ret.Get(0).(func(context.Context, string) databasediscovery.Database); ok {
+		r0 = rf(ctx, clientCode)
 	} else {
 		r0 = ret.Get(0).(databasediscovery.Database)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(clientCode)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, clientCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDatabases provides a mock function with given fields: ctx
+func (_m *IDatabaseDiscovery) GetDatabases(ctx context.Context) ([]databasediscovery.Database, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []databasediscovery.Database
+	if rf, ok := ret.Get(0).(func(context.Context) []databasediscovery.Database); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]databasediscovery.Database)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
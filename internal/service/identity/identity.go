@@ -1,6 +1,7 @@
 package identity
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/zdarovich/promotion-api/internal/api/errorcodes"
@@ -21,7 +22,7 @@ type (
 	}
 	// IIdentity interface
 	IIdentity interface {
-		GenerateNewJWT(clientCode string, sessionKey string) (string, error)
+		GenerateNewJWT(ctx context.Context, clientCode string, sessionKey string) (string, error)
 	}
 	identityResponse struct {
 		Result struct {
@@ -43,13 +44,13 @@ func New(configuration *config.Configuration) IIdentity {
 }
 
 // GenerateNewJWT generates a new JWT
-func (identity *Identity) GenerateNewJWT(clientCode string, sessionKey string) (string, error) {
+func (identity *Identity) GenerateNewJWT(ctx context.Context, clientCode string, sessionKey string) (string, error) {
 
 	var httpClient = &http.Client{
 		Timeout: time.Duration(identity.Configuration.Identity.Timeout) * time.Second,
 	}
 
-	data, err := identity.getURLValues(clientCode, sessionKey)
+	data, err := identity.getURLValues(ctx, clientCode, sessionKey)
 
 	if err != nil {
 		identity.logError("identity session key failure", data, err.Error())
@@ -58,7 +59,7 @@ func (identity *Identity) GenerateNewJWT(clientCode string, sessionKey string) (
 
 	url := identity.Configuration.Identity.Server
 
-	req, _ := http.NewRequest("POST", url, strings.NewReader(data.Encode()))
+	req, _ := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(data.Encode()))
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	result, err := httpClient.Do(req)
 
@@ -87,10 +88,10 @@ func (identity *Identity) GenerateNewJWT(clientCode string, sessionKey string) (
 }
 
 // composes the parameters for the token request
-func (identity *Identity) getURLValues(clientCode string, sessionKey string) (url.Values, error) {
+func (identity *Identity) getURLValues(ctx context.Context, clientCode string, sessionKey string) (url.Values, error) {
 
 	v := url.Values{}
-	user, err := identity.UserRepository.GetUserBySessionKey(sessionKey)
+	user, err := identity.UserRepository.GetUserBySessionKey(ctx, sessionKey)
 
 	if err != nil {
 		return v, err
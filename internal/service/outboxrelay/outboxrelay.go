@@ -0,0 +1,239 @@
+package outboxrelay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/bus"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/outbox"
+	"github.com/zdarovich/promotion-api/internal/service/databasediscovery"
+)
+
+// Defaults of the events configuration
+const (
+	DefaultInterval  = 5 * time.Second
+	DefaultBatchSize = 100
+	DefaultRetention = 7 * 24 * time.Hour
+)
+
+type (
+	// OutboxRelay publishes the messages of the outbox of every tenant to
+	// the bus. A message is marked published once the bus has it, a message
+	// published but not marked is published again: the delivery is at least
+	// once. The messages of an aggregate are published in the order they
+	// were recorded, one instance relays the outbox of a tenant at a time
+	OutboxRelay struct {
+		Configuration     config.Configuration
+		DatabaseDiscovery databasediscovery.IDatabaseDiscovery
+		Bus               bus.IBus
+		repository        func(configuration *config.Configuration) outbox.IRepository
+		now               func() time.Time
+	}
+	// IOutboxRelay interface
+	IOutboxRelay interface {
+		Run(ctx context.Context)
+		Relay(ctx context.Context, configuration *config.Configuration, clientCode string) error
+	}
+	// Envelope body of the messages published to the bus, Data is the
+	// payload of the event
+	Envelope struct {
+		ID          string          `json:"id"`
+		ClientCode  string          `json:"clientCode,omitempty"`
+		Aggregate   string          `json:"aggregate"`
+		AggregateID int             `json:"aggregateID"`
+		Event       string          `json:"event"`
+		Added       int64           `json:"added"`
+		Data        json.RawMessage `json:"data"`
+	}
+)
+
+// New returns new configured outbox relay publishing to the bus. The
+// configuration is copied, the database of every tenant is set on a copy of
+// its own
+func New(configuration *config.Configuration, b bus.IBus) IOutboxRelay {
+
+	return &OutboxRelay{
+		Configuration:     *configuration,
+		DatabaseDiscovery: databasediscovery.New(configuration),
+		Bus:               b,
+		repository: func(configuration *config.Configuration) outbox.IRepository {
+			return outbox.New(configuration)
+		},
+		now: time.Now,
+	}
+}
+
+// Run relays the outboxes of all tenants every interval until the context is
+// done
+func (outboxRelay *OutboxRelay) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(seconds(outboxRelay.Configuration.Events.Interval, DefaultInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			outboxRelay.relayAll(ctx)
+		}
+	}
+}
+
+// Relay publishes the pending messages of the outbox of the tenant. A
+// failed message is published again at the next relay, the later messages
+// of its aggregate wait for it
+func (outboxRelay *OutboxRelay) Relay(ctx context.Context, configuration *config.Configuration, clientCode string) error {
+
+	repository := outboxRelay.repository(configuration)
+	timeout := seconds(outboxRelay.Configuration.Events.Timeout, bus.DefaultTimeout)
+
+	// The lease outlasts the publishing of the batch, no message is
+	// published after it has less than a timeout left
+	now := outboxRelay.now()
+	until := now.Add(seconds(outboxRelay.Configuration.Events.Interval, DefaultInterval) + 3*timeout)
+	claimed, err := repository.ClaimRelay(ctx, now.Unix(), until.Unix())
+	if err != nil || !claimed {
+		return err
+	}
+	defer func() {
+		err := repository.ReleaseRelay(context.Background(), until.Unix())
+		if err != nil {
+			log.Error(fmt.Sprintf("outbox of %s: relay not released: %s", clientCode, err))
+		}
+	}()
+
+	batchSize := outboxRelay.Configuration.Events.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	pending, err := repository.GetPending(ctx, batchSize)
+	if err != nil {
+		return err
+	}
+
+	blocked := make(map[string]bool)
+	for _, m := range pending {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if outboxRelay.now().Add(timeout).After(until) {
+			break
+		}
+		message, err := Message(clientCode, m)
+		if err != nil {
+			return err
+		}
+		if blocked[message.Key] {
+			continue
+		}
+
+		publishCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = outboxRelay.Bus.Publish(publishCtx, message)
+		cancel()
+		if err != nil {
+			blocked[message.Key] = true
+			log.Error(fmt.Sprintf("outbox of %s: message %d not published: %s", clientCode, m.ID, err))
+			continue
+		}
+
+		err = repository.MarkPublished(ctx, m.ID, outboxRelay.now().Unix())
+		if err != nil {
+			return err
+		}
+	}
+
+	retention := seconds(outboxRelay.Configuration.Events.Retention, DefaultRetention)
+	return repository.DeletePublished(ctx, outboxRelay.now().Add(-retention).Unix())
+}
+
+// relayAll relays the outbox of every tenant. Without database discovery the
+// configured database is the only tenant
+func (outboxRelay *OutboxRelay) relayAll(ctx context.Context) {
+
+	if !outboxRelay.Configuration.Database.Discovery.Enabled {
+		configuration := outboxRelay.Configuration
+		outboxRelay.relay(ctx, &configuration, "")
+		return
+	}
+
+	databases, err := outboxRelay.DatabaseDiscovery.GetDatabases(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("outbox: tenants not listed: %s", err))
+		return
+	}
+
+	for _, database := range databases {
+		configuration := outboxRelay.Configuration
+		configuration.Database.Name = database.DatabaseName
+		configuration.Database.Server = database.Host
+		configuration.Database.Port = database.Port
+		configuration.Database.Username = database.User
+		configuration.Database.Password = database.Password
+		if database.Driver != "" {
+			configuration.Database.Driver = database.Driver
+		}
+		// The published marks are read back, the replicas may not have them yet
+		configuration.Database.Replicas = nil
+		outboxRelay.relay(ctx, &configuration, database.Tenant)
+	}
+}
+
+func (outboxRelay *OutboxRelay) relay(ctx context.Context, configuration *config.Configuration, clientCode string) {
+
+	err := outboxRelay.Relay(ctx, configuration, clientCode)
+	if err != nil {
+		log.Error(fmt.Sprintf("outbox of %s: relay failed: %s", clientCode, err))
+	}
+}
+
+// Message returns the bus message of the outbox message of the tenant. The
+// id is unique across the tenants, the key is the aggregate of the tenant
+func Message(clientCode string, m outbox.Message) (bus.Message, error) {
+
+	id := strconv.Itoa(m.ID)
+	key := m.Aggregate + "." + strconv.Itoa(m.AggregateID)
+	if clientCode != "" {
+		id = clientCode + "-" + id
+		key = clientCode + "." + key
+	}
+
+	data := json.RawMessage(m.Payload)
+	if strings.TrimSpace(m.Payload) == "" {
+		data = json.RawMessage("null")
+	}
+	body, err := json.Marshal(Envelope{
+		ID:          id,
+		ClientCode:  clientCode,
+		Aggregate:   m.Aggregate,
+		AggregateID: m.AggregateID,
+		Event:       m.Event,
+		Added:       m.Added,
+		Data:        data,
+	})
+	if err != nil {
+		return bus.Message{}, err
+	}
+
+	return bus.Message{
+		ID:    id,
+		Key:   key,
+		Event: m.Event,
+		Body:  body,
+	}, nil
+}
+
+// seconds returns the configured seconds, the default when not configured
+func seconds(configured int, defaultDuration time.Duration) time.Duration {
+
+	if configured <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(configured) * time.Second
+}
@@ -0,0 +1,188 @@
+package outboxrelay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/bus"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/outbox"
+	"github.com/zdarovich/promotion-api/internal/service/databasediscovery"
+)
+
+// keyBus bus failing the messages of the keys set
+type keyBus struct {
+	mutex    sync.Mutex
+	failing  map[string]bool
+	messages []bus.Message
+}
+
+func (b *keyBus) Publish(ctx context.Context, m bus.Message) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.failing[m.Key] {
+		return errors.New("unavailable")
+	}
+	b.messages = append(b.messages, m)
+	return nil
+}
+
+func (b *keyBus) Close() error { return nil }
+
+type fixture struct {
+	relay     *OutboxRelay
+	outbox    *outbox.MemoryRepository
+	campaigns *campaign.MemoryRepository
+	bus       *keyBus
+	now       time.Time
+}
+
+func newFixture() *fixture {
+
+	f := &fixture{
+		outbox:    outbox.NewMemory(),
+		campaigns: campaign.NewMemory(),
+		bus:       &keyBus{failing: map[string]bool{}},
+		now:       time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	f.campaigns.Outbox = f.outbox
+
+	configuration := &config.Configuration{}
+	configuration.Events.Enabled = true
+	f.relay = New(configuration, f.bus).(*OutboxRelay)
+	f.relay.repository = func(*config.Configuration) outbox.IRepository {
+		return f.outbox
+	}
+	f.relay.now = func() time.Time { return f.now }
+	return f
+}
+
+func events(messages []bus.Message) []string {
+
+	var events []string
+	for _, m := range messages {
+		events = append(events, m.Key+" "+m.Event)
+	}
+	return events
+}
+
+func TestRelay_PublishesInOrder(t *testing.T) {
+	f := newFixture()
+	spring := &campaign.Campaign{Name: "spring"}
+	require.Nil(t, f.campaigns.SaveCampaigns(context.Background(), spring))
+	require.Nil(t, f.campaigns.DeleteCampaigns(context.Background(), spring.ID))
+
+	require.Nil(t, f.relay.Relay(context.Background(), &config.Configuration{}, "104235"))
+
+	assert.Equal(t, []string{"104235.campaign.1 campaign.created", "104235.campaign.1 campaign.deleted"}, events(f.bus.messages))
+	var envelope Envelope
+	require.Nil(t, json.Unmarshal(f.bus.messages[0].Body, &envelope))
+	assert.Equal(t, "104235-1", envelope.ID)
+	assert.Equal(t, "104235", envelope.ClientCode)
+	assert.Equal(t, campaign.Aggregate, envelope.Aggregate)
+	assert.Equal(t, 1, envelope.AggregateID)
+	var payload campaign.EventPayload
+	require.Nil(t, json.Unmarshal(envelope.Data, &payload))
+	assert.Equal(t, "spring", payload.Name)
+
+	pending, _ := f.outbox.GetPending(context.Background(), 10)
+	assert.Empty(t, pending)
+
+	// Published once
+	f.now = f.now.Add(time.Minute)
+	require.Nil(t, f.relay.Relay(context.Background(), &config.Configuration{}, "104235"))
+	assert.Len(t, f.bus.messages, 2)
+}
+
+func TestRelay_FailedMessageHoldsItsAggregate(t *testing.T) {
+	f := newFixture()
+	spring := &campaign.Campaign{Name: "spring"}
+	summer := &campaign.Campaign{Name: "summer"}
+	require.Nil(t, f.campaigns.SaveCampaigns(context.Background(), spring))
+	require.Nil(t, f.campaigns.SaveCampaigns(context.Background(), summer))
+	require.Nil(t, f.campaigns.DeleteCampaigns(context.Background(), spring.ID))
+	f.bus.failing["campaign.1"] = true
+
+	require.Nil(t, f.relay.Relay(context.Background(), &config.Configuration{}, ""))
+
+	assert.Equal(t, []string{"campaign.2 campaign.created"}, events(f.bus.messages))
+	pending, _ := f.outbox.GetPending(context.Background(), 10)
+	assert.Len(t, pending, 2)
+
+	f.bus.failing["campaign.1"] = false
+	f.now = f.now.Add(time.Minute)
+	require.Nil(t, f.relay.Relay(context.Background(), &config.Configuration{}, ""))
+
+	assert.Equal(t, []string{"campaign.2 campaign.created", "campaign.1 campaign.created", "campaign.1 campaign.deleted"}, events(f.bus.messages))
+}
+
+func TestRelay_ClaimedByAnotherInstance(t *testing.T) {
+	f := newFixture()
+	require.Nil(t, f.campaigns.SaveCampaigns(context.Background(), &campaign.Campaign{}))
+	claimed, _ := f.outbox.ClaimRelay(context.Background(), f.now.Unix(), f.now.Add(time.Minute).Unix())
+	require.True(t, claimed)
+
+	require.Nil(t, f.relay.Relay(context.Background(), &config.Configuration{}, ""))
+	assert.Empty(t, f.bus.messages)
+
+	// The claim of the other instance has expired
+	f.now = f.now.Add(time.Minute)
+	require.Nil(t, f.relay.Relay(context.Background(), &config.Configuration{}, ""))
+	assert.Len(t, f.bus.messages, 1)
+}
+
+func TestRelay_DeletesPublishedAfterRetention(t *testing.T) {
+	f := newFixture()
+	f.relay.Configuration.Events.Retention = 3600
+	require.Nil(t, f.campaigns.SaveCampaigns(context.Background(), &campaign.Campaign{}))
+	require.Nil(t, f.relay.Relay(context.Background(), &config.Configuration{}, ""))
+	assert.Len(t, f.outbox.GetMessages(), 1)
+
+	f.now = f.now.Add(2 * time.Hour)
+	require.Nil(t, f.relay.Relay(context.Background(), &config.Configuration{}, ""))
+	assert.Empty(t, f.outbox.GetMessages())
+}
+
+type discovery struct {
+	databases []databasediscovery.Database
+}
+
+func (d *discovery) GetDatabase(ctx context.Context, clientCode string) (databasediscovery.Database, error) {
+	return databasediscovery.Database{}, nil
+}
+
+func (d *discovery) GetDatabases(ctx context.Context) ([]databasediscovery.Database, error) {
+	return d.databases, nil
+}
+
+func TestRelayAll_Tenants(t *testing.T) {
+	f := newFixture()
+	f.relay.Configuration.Database.Discovery.Enabled = true
+	f.relay.Configuration.Database.Name = "default"
+	f.relay.DatabaseDiscovery = &discovery{databases: []databasediscovery.Database{
+		{Tenant: "104235", DatabaseName: "tenant_104235", Replicas: []databasediscovery.Replica{{Host: "replica"}}},
+		{Tenant: "104236", DatabaseName: "tenant_104236"},
+	}}
+	var tenants []string
+	f.relay.repository = func(configuration *config.Configuration) outbox.IRepository {
+		assert.Empty(t, configuration.Database.Replicas)
+		tenants = append(tenants, configuration.Database.Name)
+		repository := outbox.NewMemory()
+		repository.Add(&outbox.Message{Aggregate: campaign.Aggregate, AggregateID: 1, Event: campaign.EventCreated, Payload: "{}"})
+		return repository
+	}
+
+	f.relay.relayAll(context.Background())
+
+	assert.Equal(t, []string{"tenant_104235", "tenant_104236"}, tenants)
+	assert.Equal(t, []string{"104235.campaign.1 campaign.created", "104236.campaign.1 campaign.created"}, events(f.bus.messages))
+	assert.Equal(t, "default", f.relay.Configuration.Database.Name)
+}
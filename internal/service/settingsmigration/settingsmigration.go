@@ -0,0 +1,134 @@
+package settingsmigration
+
+import (
+	"context"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+)
+
+// DefaultBatchSize number of campaigns migrated per batch
+const DefaultBatchSize = 100
+
+type (
+	// SettingsMigration struct
+	SettingsMigration struct {
+		CampaignRepository  campaign.IRepository
+		AttributeRepository attributes.IRepository
+		SettingsRepository  settings.IRepository
+		BatchSize           int
+		DryRun              bool
+	}
+	// ISettingsMigration interface
+	ISettingsMigration interface {
+		Migrate(ctx context.Context) (Report, error)
+	}
+	// Report result of a migration run
+	Report struct {
+		Campaigns int `json:"campaigns"`
+		Migrated  int `json:"migrated"`
+		Skipped   int `json:"skipped"`
+		Truncated int `json:"truncated"`
+	}
+)
+
+// New returns new configured settings migration
+func New(configuration *config.Configuration, batchSize int, dryRun bool) ISettingsMigration {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	return &SettingsMigration{
+		CampaignRepository:  campaign.New(configuration),
+		AttributeRepository: attributes.New(configuration),
+		SettingsRepository:  settings.New(configuration),
+		BatchSize:           batchSize,
+		DryRun:              dryRun,
+	}
+}
+
+// Migrate copies the attributes of all campaigns to the typed settings tables.
+// The campaigns are migrated in batches while the API keeps serving requests:
+// campaigns saved in the meantime already have typed settings and are skipped.
+// In dry run mode nothing is written
+func (migration *SettingsMigration) Migrate(ctx context.Context) (Report, error) {
+	var report Report
+	seen := make(map[int]bool)
+
+	for page := 0; ; page++ {
+		cs, err := migration.CampaignRepository.GetCampaigns(ctx, 0, "", migration.BatchSize, page)
+		if err != nil {
+			return report, err
+		}
+
+		ids := make([]int, 0, len(cs))
+		for _, c := range cs {
+			if seen[c.ID] {
+				continue
+			}
+			seen[c.ID] = true
+			ids = append(ids, c.ID)
+		}
+		report.Campaigns += len(ids)
+
+		err = migration.migrateBatch(ctx, ids, &report)
+		if err != nil {
+			return report, err
+		}
+
+		if len(cs) < migration.BatchSize {
+			return report, nil
+		}
+	}
+}
+
+func (migration *SettingsMigration) migrateBatch(ctx context.Context, campaignIDs []int, report *Report) error {
+	if len(campaignIDs) == 0 {
+		return nil
+	}
+
+	attrs, err := migration.AttributeRepository.GetAttributes(ctx, campaignIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range campaignIDs {
+		campaignAttrs := attrs[id]
+		if len(campaignAttrs) == 0 {
+			report.Skipped++
+			continue
+		}
+		if IsTruncated(campaignAttrs) {
+			report.Truncated++
+			log.Infof("campaign %d has attributes that may have been truncated", id)
+		}
+		if migration.DryRun {
+			report.Migrated++
+			continue
+		}
+
+		migrated, err := migration.SettingsRepository.MigrateSettings(ctx, settings.FromAttributes(id, campaignAttrs))
+		if err != nil {
+			return err
+		}
+		if migrated {
+			report.Migrated++
+		} else {
+			report.Skipped++
+		}
+	}
+	return nil
+}
+
+// IsTruncated checks whether any text attribute fills the whole legacy column
+func IsTruncated(attrs []*attributes.Attribute) bool {
+	for _, attr := range attrs {
+		if len(attr.ValueText) >= attributes.ValueTextLength {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package settingsmigration
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/repositories/attributes"
+	attributesMocks "github.com/zdarovich/promotion-api/internal/repositories/attributes/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	campaignMocks "github.com/zdarovich/promotion-api/internal/repositories/campaign/mocks"
+	"github.com/zdarovich/promotion-api/internal/repositories/settings"
+	settingsMocks "github.com/zdarovich/promotion-api/internal/repositories/settings/mocks"
+)
+
+func Test_New(t *testing.T) {
+
+	result := New(&config.Configuration{}, 0, false)
+
+	assert.NotNil(t, result)
+	assert.Equal(t, DefaultBatchSize, result.(*SettingsMigration).BatchSize)
+}
+
+func TestSettingsMigration_Migrate(t *testing.T) {
+	cr := new(campaignMocks.IRepository)
+	cr.On("GetCampaigns", mock.Anything, 0, "", 2, 0).Return([]campaign.Campaign{{ID: 1}, {ID: 2}}, nil)
+	cr.On("GetCampaigns", mock.Anything, 0, "", 2, 1).Return([]campaign.Campaign{{ID: 3}}, nil)
+	ar := new(attributesMocks.IRepository)
+	ar.On("GetAttributes", mock.Anything, []int{1, 2}).Return(map[int][]*attributes.Attribute{
+		1: {{ObjID: 1, Name: "priority", Type: attributes.INT, ValueInt: 5}},
+		2: {{ObjID: 2, Name: "purchasedProducts", Type: attributes.TEXT, ValueText: "1,2"}},
+	}, nil)
+	ar.On("GetAttributes", mock.Anything, []int{3}).Return(map[int][]*attributes.Attribute{}, nil)
+	sr := new(settingsMocks.IRepository)
+	sr.On("MigrateSettings", mock.Anything, mock.MatchedBy(func(s *settings.Settings) bool { return s.CampaignID == 1 })).Return(true, nil)
+	sr.On("MigrateSettings", mock.Anything, mock.MatchedBy(func(s *settings.Settings) bool { return s.CampaignID == 2 })).Return(false, nil)
+
+	m := &SettingsMigration{
+		CampaignRepository:  cr,
+		AttributeRepository: ar,
+		SettingsRepository:  sr,
+		BatchSize:           2,
+	}
+	report, err := m.Migrate(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, Report{Campaigns: 3, Migrated: 1, Skipped: 2}, report)
+	sr.AssertNumberOfCalls(t, "MigrateSettings", 2)
+}
+
+func TestSettingsMigration_Migrate_DryRun(t *testing.T) {
+	cr := new(campaignMocks.IRepository)
+	cr.On("GetCampaigns", mock.Anything, 0, "", 10, 0).Return([]campaign.Campaign{{ID: 1}}, nil)
+	ar := new(attributesMocks.IRepository)
+	ar.On("GetAttributes", mock.Anything, []int{1}).Return(map[int][]*attributes.Attribute{
+		1: {{ObjID: 1, Name: "purchasedProducts", Type: attributes.TEXT, ValueText: strings.Repeat("1,", 128)[:255]}},
+	}, nil)
+	sr := new(settingsMocks.IRepository)
+
+	m := &SettingsMigration{
+		CampaignRepository:  cr,
+		AttributeRepository: ar,
+		SettingsRepository:  sr,
+		BatchSize:           10,
+		DryRun:              true,
+	}
+	report, err := m.Migrate(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, Report{Campaigns: 1, Migrated: 1, Truncated: 1}, report)
+	sr.AssertNotCalled(t, "MigrateSettings", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,399 @@
+package webhookdelivery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
+	"github.com/zdarovich/promotion-api/internal/log"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/webhook"
+	"github.com/zdarovich/promotion-api/internal/service/databasediscovery"
+)
+
+// Defaults of the webhooks configuration
+const (
+	DefaultInterval     = 10 * time.Second
+	DefaultTimeout      = 10 * time.Second
+	DefaultMaxAttempts  = 8
+	DefaultRetryBackoff = 30 * time.Second
+)
+
+const (
+	// MaxBackoff longest time between two attempts of a delivery
+	MaxBackoff = 6 * time.Hour
+	// BatchSize deliveries sent per tenant and interval at most
+	BatchSize = 100
+	// MaxErrorLength length of the last_error column
+	MaxErrorLength = 255
+)
+
+// ErrAddressNotAllowed the webhook resolves to an address of the internal network
+var ErrAddressNotAllowed = errors.New("webhook address not allowed")
+
+// internalNetworks addresses the webhooks are not sent to: loopback, private,
+// shared, link-local (cloud metadata), multicast and unspecified addresses
+var internalNetworks = parseNetworks(
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16", "172.16.0.0/12",
+	"192.168.0.0/16", "224.0.0.0/4", "240.0.0.0/4",
+	"::/128", "::1/128", "fc00::/7", "fe80::/10", "ff00::/8",
+)
+
+type (
+	// WebhookDelivery sends the pending deliveries of the webhooks of every
+	// tenant and records the activation and expiry of the campaigns. The
+	// instances claim the deliveries and the sweeps in the database, so that
+	// every delivery is sent by one instance at a time
+	WebhookDelivery struct {
+		Configuration     config.Configuration
+		DatabaseDiscovery databasediscovery.IDatabaseDiscovery
+		Client            *http.Client
+		repositories      func(configuration *config.Configuration) (webhook.IRepository, campaign.IRepository)
+		now               func() time.Time
+	}
+	// IWebhookDelivery interface
+	IWebhookDelivery interface {
+		Run(ctx context.Context)
+		Deliver(ctx context.Context, configuration *config.Configuration, clientCode string)
+	}
+)
+
+// New returns new configured webhook delivery. The configuration is copied,
+// the database of every tenant is set on a copy of its own
+func New(configuration *config.Configuration) IWebhookDelivery {
+
+	return &WebhookDelivery{
+		Configuration:     *configuration,
+		DatabaseDiscovery: databasediscovery.New(configuration),
+		Client:            NewClient(seconds(configuration.Webhooks.Timeout, DefaultTimeout), configuration.Webhooks.AllowInternal),
+		repositories: func(configuration *config.Configuration) (webhook.IRepository, campaign.IRepository) {
+			return webhook.New(configuration), campaign.New(configuration)
+		},
+		now: time.Now,
+	}
+}
+
+// Run delivers the webhooks of all tenants every interval until the context
+// is done
+func (webhookDelivery *WebhookDelivery) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(seconds(webhookDelivery.Configuration.Webhooks.Interval, DefaultInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			webhookDelivery.deliverAll(ctx)
+		}
+	}
+}
+
+// Deliver records the campaigns of the tenant activated or expired since the
+// last sweep and sends its deliveries that are due
+func (webhookDelivery *WebhookDelivery) Deliver(ctx context.Context, configuration *config.Configuration, clientCode string) {
+
+	webhooks, campaigns := webhookDelivery.repositories(configuration)
+
+	err := webhookDelivery.sweep(ctx, webhooks, campaigns, clientCode)
+	if err != nil {
+		log.Error(fmt.Sprintf("webhooks of %s: campaign sweep failed: %s", clientCode, err))
+	}
+
+	err = webhookDelivery.deliverDue(ctx, webhooks)
+	if err != nil {
+		log.Error(fmt.Sprintf("webhooks of %s: delivery failed: %s", clientCode, err))
+	}
+}
+
+// deliverAll delivers the webhooks of every tenant. Without database
+// discovery the configured database is the only tenant
+func (webhookDelivery *WebhookDelivery) deliverAll(ctx context.Context) {
+
+	if !webhookDelivery.Configuration.Database.Discovery.Enabled {
+		configuration := webhookDelivery.Configuration
+		webhookDelivery.Deliver(ctx, &configuration, "")
+		return
+	}
+
+	databases, err := webhookDelivery.DatabaseDiscovery.GetDatabases(ctx)
+	if err != nil {
+		log.Error(fmt.Sprintf("webhooks: tenants not listed: %s", err))
+		return
+	}
+
+	for _, database := range databases {
+		configuration := webhookDelivery.Configuration
+		configuration.Database.Name = database.DatabaseName
+		configuration.Database.Server = database.Host
+		configuration.Database.Port = database.Port
+		configuration.Database.Username = database.User
+		configuration.Database.Password = database.Password
+		if database.Driver != "" {
+			configuration.Database.Driver = database.Driver
+		}
+		// The claims are read back, the replicas may not have them yet
+		configuration.Database.Replicas = nil
+		webhookDelivery.Deliver(ctx, &configuration, database.Tenant)
+	}
+}
+
+// sweep records the activated and expired events of the campaigns starting
+// and ending since the last sweep. The first sweep of a tenant starts the
+// period without recording events
+func (webhookDelivery *WebhookDelivery) sweep(ctx context.Context, webhooks webhook.IRepository, campaigns campaign.IRepository, clientCode string) error {
+
+	if !webhookDelivery.Configuration.Webhooks.Enabled {
+		return nil
+	}
+
+	swept, err := webhooks.GetSwept(ctx)
+	if err != nil {
+		return err
+	}
+	now := webhookDelivery.now()
+	if swept >= now.Unix() {
+		return nil
+	}
+
+	claimed, err := webhooks.ClaimSweep(ctx, swept, now.Unix())
+	if err != nil || !claimed || swept == 0 {
+		return err
+	}
+
+	from := time.Unix(swept, 0)
+	changed, err := campaigns.GetCampaignsStartingOrEnding(ctx, from, now)
+	if err != nil || len(changed) == 0 {
+		return err
+	}
+
+	subscribed, err := webhooks.GetWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []*webhook.Delivery
+	for _, c := range changed {
+		events := make([]string, 0, 2)
+		if c.StartDate.After(from) && !c.StartDate.After(now) {
+			events = append(events, webhookhelper.EventActivated)
+		}
+		if !c.EndDate.Before(from) && c.EndDate.Before(now) {
+			events = append(events, webhookhelper.EventExpired)
+		}
+		for _, event := range events {
+			d, err := webhookhelper.Deliveries(subscribed, clientCode, event, c, now.Unix())
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, d...)
+		}
+	}
+
+	return webhooks.SaveDeliveries(ctx, deliveries)
+}
+
+// deliverDue sends the deliveries that are due
+func (webhookDelivery *WebhookDelivery) deliverDue(ctx context.Context, webhooks webhook.IRepository) error {
+
+	now := webhookDelivery.now()
+	due, err := webhooks.GetDueDeliveries(ctx, now.Unix(), BatchSize)
+	if err != nil || len(due) == 0 {
+		return err
+	}
+
+	subscribed, err := webhooks.GetWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	byID := make(map[int]webhook.Webhook, len(subscribed))
+	for _, w := range subscribed {
+		byID[w.ID] = w
+	}
+
+	// A claimed delivery is not due again before the attempt has timed out
+	lease := webhookDelivery.Client.Timeout + seconds(webhookDelivery.Configuration.Webhooks.Interval, DefaultInterval)
+	for _, d := range due {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		claimed, err := webhooks.ClaimDelivery(ctx, d, now.Add(lease).Unix())
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		w, ok := byID[d.WebhookID]
+		if !ok || !w.Active {
+			// Kept in the dead-letter list, it can be retried once the
+			// webhook is active again
+			d.Status = webhook.StatusDead
+			d.LastError = "webhook is not active"
+		} else {
+			webhookDelivery.attempt(ctx, w, &d)
+		}
+
+		err = webhooks.UpdateDelivery(ctx, d)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attempt sends the delivery to the webhook and sets its status. A failed
+// delivery is retried after the backoff of its attempts, the delivery is
+// dead once it has failed the maximum attempts
+func (webhookDelivery *WebhookDelivery) attempt(ctx context.Context, w webhook.Webhook, d *webhook.Delivery) {
+
+	d.Attempts++
+	status, err := webhookDelivery.send(ctx, w, *d)
+	d.LastStatus = status
+	now := webhookDelivery.now()
+
+	if err == nil {
+		d.Status = webhook.StatusDelivered
+		d.Delivered = now.Unix()
+		d.LastError = ""
+		return
+	}
+
+	d.LastError = err.Error()
+	if len(d.LastError) > MaxErrorLength {
+		d.LastError = d.LastError[:MaxErrorLength]
+	}
+	maxAttempts := webhookDelivery.Configuration.Webhooks.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	if d.Attempts >= maxAttempts {
+		d.Status = webhook.StatusDead
+		log.Warn(fmt.Sprintf("webhook delivery %d to %s is dead after %d attempts: %s", d.ID, w.URL, d.Attempts, d.LastError))
+		return
+	}
+	d.NextAttempt = now.Add(Backoff(d.Attempts, seconds(webhookDelivery.Configuration.Webhooks.RetryBackoff, DefaultRetryBackoff))).Unix()
+}
+
+// send posts the payload of the delivery signed with the secret of the
+// webhook and returns the HTTP status. Statuses other than 2xx are errors
+func (webhookDelivery *WebhookDelivery) send(ctx context.Context, w webhook.Webhook, d webhook.Delivery) (int, error) {
+
+	body := []byte(d.Payload)
+	timestamp := webhookDelivery.now().Unix()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(webhookhelper.HeaderEvent, d.Event)
+	request.Header.Set(webhookhelper.HeaderDelivery, strconv.Itoa(d.ID))
+	request.Header.Set(webhookhelper.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(webhookhelper.HeaderSignature, webhookhelper.Sign(w.Secret, timestamp, body))
+
+	response, err := webhookDelivery.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("endpoint responded %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
+
+// NewClient returns the client the deliveries are sent with. It does not
+// follow redirects and, unless allowInternal is set, refuses to connect to the
+// internal network. The address is checked when it is dialed, a host name that
+// resolves to another address later is checked again
+func NewClient(timeout time.Duration, allowInternal bool) *http.Client {
+
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowInternal {
+		dialer.Control = checkAddress
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the webhook
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkAddress refuses the connections to the internal networks
+func checkAddress(network string, address string, _ syscall.RawConn) error {
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ErrAddressNotAllowed
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, n := range internalNetworks {
+		if n.Contains(ip) {
+			return ErrAddressNotAllowed
+		}
+	}
+	return nil
+}
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+
+	networks := make([]*net.IPNet, len(cidrs))
+	for idx, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[idx] = n
+	}
+	return networks
+}
+
+// Backoff returns the time before the attempt after the failed attempt, the
+// backoff doubles with every attempt up to MaxBackoff
+func Backoff(attempts int, backoff time.Duration) time.Duration {
+
+	for i := 1; i < attempts && backoff < MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > MaxBackoff {
+		return MaxBackoff
+	}
+	return backoff
+}
+
+// seconds returns the configured seconds, the default when not configured
+func seconds(configured int, defaultDuration time.Duration) time.Duration {
+
+	if configured <= 0 {
+		return defaultDuration
+	}
+	return time.Duration(configured) * time.Second
+}
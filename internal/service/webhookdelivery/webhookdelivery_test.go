@@ -0,0 +1,287 @@
+package webhookdelivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zdarovich/promotion-api/internal/config"
+	"github.com/zdarovich/promotion-api/internal/helpers/webhookhelper"
+	"github.com/zdarovich/promotion-api/internal/repositories/campaign"
+	"github.com/zdarovich/promotion-api/internal/repositories/webhook"
+	"github.com/zdarovich/promotion-api/internal/service/databasediscovery"
+)
+
+// endpoint local stand-in of a webhook endpoint, it answers the statuses in
+// order and then 204
+type endpoint struct {
+	mutex    sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	e.requests = append(e.requests, r)
+	e.bodies = append(e.bodies, body)
+
+	status := http.StatusNoContent
+	if len(e.statuses) > 0 {
+		status, e.statuses = e.statuses[0], e.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+type fixture struct {
+	delivery  *WebhookDelivery
+	webhooks  *webhook.MemoryRepository
+	campaigns *campaign.MemoryRepository
+	endpoint  *endpoint
+	server    *httptest.Server
+	now       time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+
+	f := &fixture{
+		webhooks:  webhook.NewMemory(),
+		campaigns: campaign.NewMemory(),
+		endpoint:  &endpoint{},
+		now:       time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	f.server = httptest.NewServer(f.endpoint)
+	t.Cleanup(f.server.Close)
+
+	configuration := &config.Configuration{}
+	configuration.Webhooks.Enabled = true
+	configuration.Webhooks.MaxAttempts = 3
+	configuration.Webhooks.RetryBackoff = 60
+	configuration.Webhooks.AllowInternal = true
+	f.delivery = New(configuration).(*WebhookDelivery)
+	f.delivery.repositories = func(*config.Configuration) (webhook.IRepository, campaign.IRepository) {
+		return f.webhooks, f.campaigns
+	}
+	f.delivery.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *fixture) publish(t *testing.T, event string, c campaign.Campaign) {
+
+	webhooks, _ := f.webhooks.GetWebhooks(context.Background())
+	deliveries, err := webhookhelper.Deliveries(webhooks, "104235", event, c, f.now.Unix())
+	require.Nil(t, err)
+	require.Nil(t, f.webhooks.SaveDeliveries(context.Background(), deliveries))
+}
+
+func TestDeliver_SignsThePayload(t *testing.T) {
+	f := newFixture(t)
+	require.Nil(t, f.webhooks.SaveWebhook(context.Background(), &webhook.Webhook{URL: f.server.URL, Secret: "whsec_test", Events: webhookhelper.EventCreated, Active: true}))
+	f.publish(t, webhookhelper.EventCreated, campaign.Campaign{ID: 4, Name: "Spring"})
+
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+
+	require.Len(t, f.endpoint.requests, 1)
+	request := f.endpoint.requests[0]
+	assert.Equal(t, http.MethodPost, request.Method)
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	assert.Equal(t, webhookhelper.EventCreated, request.Header.Get(webhookhelper.HeaderEvent))
+	assert.Equal(t, "1", request.Header.Get(webhookhelper.HeaderDelivery))
+	timestamp, err := strconv.ParseInt(request.Header.Get(webhookhelper.HeaderTimestamp), 10, 64)
+	require.Nil(t, err)
+	assert.Equal(t, webhookhelper.Sign("whsec_test", timestamp, f.endpoint.bodies[0]), request.Header.Get(webhookhelper.HeaderSignature))
+
+	var payload webhookhelper.Payload
+	require.Nil(t, json.Unmarshal(f.endpoint.bodies[0], &payload))
+	assert.Equal(t, 4, payload.Data.CampaignID)
+
+	d, _ := f.webhooks.GetDelivery(context.Background(), 1)
+	assert.Equal(t, webhook.StatusDelivered, d.Status)
+	assert.Equal(t, 1, d.Attempts)
+	assert.Equal(t, http.StatusNoContent, d.LastStatus)
+	assert.Equal(t, f.now.Unix(), d.Delivered)
+
+	// Delivered once
+	f.now = f.now.Add(time.Hour)
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+	assert.Len(t, f.endpoint.requests, 1)
+}
+
+func TestDeliver_RetriesWithBackoffUntilDead(t *testing.T) {
+	f := newFixture(t)
+	f.endpoint.statuses = []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable}
+	require.Nil(t, f.webhooks.SaveWebhook(context.Background(), &webhook.Webhook{URL: f.server.URL, Events: webhookhelper.EventDeleted, Active: true}))
+	f.publish(t, webhookhelper.EventDeleted, campaign.Campaign{ID: 4})
+	started := f.now
+
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+	d, _ := f.webhooks.GetDelivery(context.Background(), 1)
+	assert.Equal(t, webhook.StatusPending, d.Status)
+	assert.Equal(t, http.StatusInternalServerError, d.LastStatus)
+	assert.Equal(t, "endpoint responded 500 Internal Server Error", d.LastError)
+	assert.Equal(t, started.Add(time.Minute).Unix(), d.NextAttempt)
+
+	// Not retried before the backoff
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+	assert.Len(t, f.endpoint.requests, 1)
+
+	f.now = started.Add(time.Minute)
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+	d, _ = f.webhooks.GetDelivery(context.Background(), 1)
+	assert.Equal(t, 2, d.Attempts)
+	assert.Equal(t, f.now.Add(2*time.Minute).Unix(), d.NextAttempt)
+
+	f.now = f.now.Add(2 * time.Minute)
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+	d, _ = f.webhooks.GetDelivery(context.Background(), 1)
+	assert.Equal(t, webhook.StatusDead, d.Status)
+	assert.Equal(t, 3, d.Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, d.LastStatus)
+
+	f.now = f.now.Add(time.Hour)
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+	assert.Len(t, f.endpoint.requests, 3)
+	dead, _ := f.webhooks.GetDeliveries(context.Background(), 0, webhook.StatusDead, 20, 0)
+	assert.Len(t, dead, 1)
+}
+
+func TestDeliver_UnreachableAndInactive(t *testing.T) {
+	f := newFixture(t)
+	require.Nil(t, f.webhooks.SaveWebhook(context.Background(), &webhook.Webhook{URL: "http://127.0.0.1:1", Events: webhookhelper.EventCreated, Active: true}))
+	require.Nil(t, f.webhooks.SaveWebhook(context.Background(), &webhook.Webhook{URL: f.server.URL, Events: webhookhelper.EventCreated, Active: true}))
+	f.publish(t, webhookhelper.EventCreated, campaign.Campaign{ID: 4})
+	require.Nil(t, f.webhooks.SaveWebhook(context.Background(), &webhook.Webhook{ID: 2, URL: f.server.URL, Events: webhookhelper.EventCreated, Active: false}))
+
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+
+	unreachable, _ := f.webhooks.GetDelivery(context.Background(), 1)
+	assert.Equal(t, webhook.StatusPending, unreachable.Status)
+	assert.Equal(t, 0, unreachable.LastStatus)
+	assert.NotEmpty(t, unreachable.LastError)
+	inactive, _ := f.webhooks.GetDelivery(context.Background(), 2)
+	assert.Equal(t, webhook.StatusDead, inactive.Status)
+	assert.Equal(t, 0, inactive.Attempts)
+	assert.Empty(t, f.endpoint.requests)
+}
+
+func TestDeliver_SweepsActivatedAndExpiredCampaigns(t *testing.T) {
+	f := newFixture(t)
+	require.Nil(t, f.webhooks.SaveWebhook(context.Background(), &webhook.Webhook{URL: f.server.URL,
+		Events: webhookhelper.EventActivated + "," + webhookhelper.EventExpired, Active: true}))
+	spring := &campaign.Campaign{Name: "spring", StartDate: f.now.Add(30 * time.Second), EndDate: f.now.Add(24 * time.Hour)}
+	winter := &campaign.Campaign{Name: "winter", StartDate: f.now.AddDate(0, -3, 0), EndDate: f.now.Add(20 * time.Second)}
+	require.Nil(t, f.campaigns.SaveCampaigns(context.Background(), spring))
+	require.Nil(t, f.campaigns.SaveCampaigns(context.Background(), winter))
+
+	// The first sweep starts the period
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+	swept, _ := f.webhooks.GetSwept(context.Background())
+	assert.Equal(t, f.now.Unix(), swept)
+	count, _ := f.webhooks.GetDeliveriesCount(context.Background(), 0, "")
+	assert.Equal(t, 0, count)
+
+	f.now = f.now.Add(time.Minute)
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+
+	require.Len(t, f.endpoint.requests, 2)
+	events := map[string]string{}
+	for idx, request := range f.endpoint.requests {
+		var payload webhookhelper.Payload
+		require.Nil(t, json.Unmarshal(f.endpoint.bodies[idx], &payload))
+		events[request.Header.Get(webhookhelper.HeaderEvent)] = payload.Data.Name
+	}
+	assert.Equal(t, map[string]string{webhookhelper.EventActivated: "spring", webhookhelper.EventExpired: "winter"}, events)
+
+	// Swept once
+	f.now = f.now.Add(time.Minute)
+	f.delivery.Deliver(context.Background(), &config.Configuration{}, "104235")
+	assert.Len(t, f.endpoint.requests, 2)
+}
+
+type discovery struct {
+	databases []databasediscovery.Database
+}
+
+func (d *discovery) GetDatabase(ctx context.Context, clientCode string) (databasediscovery.Database, error) {
+	return databasediscovery.Database{}, nil
+}
+
+func (d *discovery) GetDatabases(ctx context.Context) ([]databasediscovery.Database, error) {
+	return d.databases, nil
+}
+
+func TestDeliverAll_Tenants(t *testing.T) {
+	f := newFixture(t)
+	f.delivery.Configuration.Database.Discovery.Enabled = true
+	f.delivery.Configuration.Database.Name = "default"
+	f.delivery.DatabaseDiscovery = &discovery{databases: []databasediscovery.Database{
+		{Tenant: "104235", DatabaseName: "tenant_104235", Replicas: []databasediscovery.Replica{{Host: "replica"}}},
+		{Tenant: "104236", DatabaseName: "tenant_104236"},
+	}}
+	var tenants []string
+	f.delivery.repositories = func(configuration *config.Configuration) (webhook.IRepository, campaign.IRepository) {
+		assert.Empty(t, configuration.Database.Replicas)
+		tenants = append(tenants, configuration.Database.Name)
+		return webhook.NewMemory(), campaign.NewMemory()
+	}
+
+	f.delivery.deliverAll(context.Background())
+
+	assert.Equal(t, []string{"tenant_104235", "tenant_104236"}, tenants)
+	assert.Equal(t, "default", f.delivery.Configuration.Database.Name)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Backoff(1, 30*time.Second))
+	assert.Equal(t, 4*time.Minute, Backoff(4, 30*time.Second))
+	assert.Equal(t, MaxBackoff, Backoff(20, 30*time.Second))
+}
+
+func TestNewClient_RefusesInternalNetwork(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(time.Second, false).Post(server.URL, "application/json", nil)
+	assert.True(t, errors.Is(err, ErrAddressNotAllowed), err)
+
+	response, err := NewClient(time.Second, true).Post(server.URL, "application/json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	response.Body.Close()
+
+	for _, address := range []string{"169.254.169.254:80", "10.1.2.3:443", "192.168.0.1:80", "[::1]:80", "[fd00::1]:80", "[::ffff:127.0.0.1]:80"} {
+		assert.Equal(t, ErrAddressNotAllowed, checkAddress("tcp", address, nil), address)
+	}
+	assert.Nil(t, checkAddress("tcp", "93.184.216.34:443", nil))
+	assert.Nil(t, checkAddress("tcp6", "[2606:2800:220:1::1]:443", nil))
+}
+
+func TestNewClient_DoesNotFollowRedirects(t *testing.T) {
+
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer server.Close()
+
+	response, err := NewClient(time.Second, true).Post(server.URL, "application/json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTemporaryRedirect, response.StatusCode)
+	response.Body.Close()
+}